@@ -22,12 +22,20 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Uploads that complete while malware scanning is enabled are SCANNING
+// until found clean, and then COMMITTED, or QUARANTINED if not. Only
+// COMMITTED blobs can be downloaded.
 type UploadState int32
 
 const (
 	UploadState_UPLOAD_STATE_UNSPECIFIED UploadState = 0
 	UploadState_PENDING                  UploadState = 1
 	UploadState_COMMITTED                UploadState = 2
+	UploadState_SCANNING                 UploadState = 3
+	UploadState_QUARANTINED              UploadState = 4
+	// The stored content was found missing or damaged. A holder can upload it
+	// again to restore the blob.
+	UploadState_MISSING UploadState = 5
 )
 
 // Enum value maps for UploadState.
@@ -36,11 +44,17 @@ var (
 		0: "UPLOAD_STATE_UNSPECIFIED",
 		1: "PENDING",
 		2: "COMMITTED",
+		3: "SCANNING",
+		4: "QUARANTINED",
+		5: "MISSING",
 	}
 	UploadState_value = map[string]int32{
 		"UPLOAD_STATE_UNSPECIFIED": 0,
 		"PENDING":                  1,
 		"COMMITTED":                2,
+		"SCANNING":                 3,
+		"QUARANTINED":              4,
+		"MISSING":                  5,
 	}
 )
 
@@ -71,10 +85,160 @@ func (UploadState) EnumDescriptor() ([]byte, []int) {
 	return file_blob_v1_blob_proto_rawDescGZIP(), []int{0}
 }
 
+type ContentDisposition int32
+
+const (
+	ContentDisposition_CONTENT_DISPOSITION_UNSPECIFIED ContentDisposition = 0
+	ContentDisposition_INLINE                          ContentDisposition = 1
+	ContentDisposition_ATTACHMENT                      ContentDisposition = 2
+)
+
+// Enum value maps for ContentDisposition.
+var (
+	ContentDisposition_name = map[int32]string{
+		0: "CONTENT_DISPOSITION_UNSPECIFIED",
+		1: "INLINE",
+		2: "ATTACHMENT",
+	}
+	ContentDisposition_value = map[string]int32{
+		"CONTENT_DISPOSITION_UNSPECIFIED": 0,
+		"INLINE":                          1,
+		"ATTACHMENT":                      2,
+	}
+)
+
+func (x ContentDisposition) Enum() *ContentDisposition {
+	p := new(ContentDisposition)
+	*p = x
+	return p
+}
+
+func (x ContentDisposition) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ContentDisposition) Descriptor() protoreflect.EnumDescriptor {
+	return file_blob_v1_blob_proto_enumTypes[1].Descriptor()
+}
+
+func (ContentDisposition) Type() protoreflect.EnumType {
+	return &file_blob_v1_blob_proto_enumTypes[1]
+}
+
+func (x ContentDisposition) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ContentDisposition.Descriptor instead.
+func (ContentDisposition) EnumDescriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{1}
+}
+
+type RenditionFormat int32
+
+const (
+	RenditionFormat_RENDITION_FORMAT_UNSPECIFIED RenditionFormat = 0
+	RenditionFormat_JPEG                         RenditionFormat = 1
+	// Lossless WebP.
+	RenditionFormat_WEBP RenditionFormat = 2
+)
+
+// Enum value maps for RenditionFormat.
+var (
+	RenditionFormat_name = map[int32]string{
+		0: "RENDITION_FORMAT_UNSPECIFIED",
+		1: "JPEG",
+		2: "WEBP",
+	}
+	RenditionFormat_value = map[string]int32{
+		"RENDITION_FORMAT_UNSPECIFIED": 0,
+		"JPEG":                         1,
+		"WEBP":                         2,
+	}
+)
+
+func (x RenditionFormat) Enum() *RenditionFormat {
+	p := new(RenditionFormat)
+	*p = x
+	return p
+}
+
+func (x RenditionFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RenditionFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_blob_v1_blob_proto_enumTypes[2].Descriptor()
+}
+
+func (RenditionFormat) Type() protoreflect.EnumType {
+	return &file_blob_v1_blob_proto_enumTypes[2]
+}
+
+func (x RenditionFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RenditionFormat.Descriptor instead.
+func (RenditionFormat) EnumDescriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{2}
+}
+
+type BlobEventKind int32
+
+const (
+	BlobEventKind_BLOB_EVENT_KIND_UNSPECIFIED BlobEventKind = 0
+	BlobEventKind_BLOB_COMMITTED              BlobEventKind = 1
+	BlobEventKind_BLOB_DELETED                BlobEventKind = 2
+)
+
+// Enum value maps for BlobEventKind.
+var (
+	BlobEventKind_name = map[int32]string{
+		0: "BLOB_EVENT_KIND_UNSPECIFIED",
+		1: "BLOB_COMMITTED",
+		2: "BLOB_DELETED",
+	}
+	BlobEventKind_value = map[string]int32{
+		"BLOB_EVENT_KIND_UNSPECIFIED": 0,
+		"BLOB_COMMITTED":              1,
+		"BLOB_DELETED":                2,
+	}
+)
+
+func (x BlobEventKind) Enum() *BlobEventKind {
+	p := new(BlobEventKind)
+	*p = x
+	return p
+}
+
+func (x BlobEventKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BlobEventKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_blob_v1_blob_proto_enumTypes[3].Descriptor()
+}
+
+func (BlobEventKind) Type() protoreflect.EnumType {
+	return &file_blob_v1_blob_proto_enumTypes[3]
+}
+
+func (x BlobEventKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BlobEventKind.Descriptor instead.
+func (BlobEventKind) EnumDescriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{3}
+}
+
 type PartUploadURL struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	PartNumber      int32                  `protobuf:"varint,1,opt,name=part_number,json=partNumber,proto3" json:"part_number,omitempty"`
 	PresignedPutUrl string                 `protobuf:"bytes,2,opt,name=presigned_put_url,json=presignedPutUrl,proto3" json:"presigned_put_url,omitempty"`
+	// Headers signed into the URL that must be sent with the PUT unchanged.
+	RequiredHeaders map[string]string `protobuf:"bytes,3,rep,name=required_headers,json=requiredHeaders,proto3" json:"required_headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -123,6 +287,13 @@ func (x *PartUploadURL) GetPresignedPutUrl() string {
 	return ""
 }
 
+func (x *PartUploadURL) GetRequiredHeaders() map[string]string {
+	if x != nil {
+		return x.RequiredHeaders
+	}
+	return nil
+}
+
 type CompletedPart struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PartNumber    int32                  `protobuf:"varint,1,opt,name=part_number,json=partNumber,proto3" json:"part_number,omitempty"`
@@ -175,29 +346,29 @@ func (x *CompletedPart) GetEtag() string {
 	return ""
 }
 
-type InitiateUploadRequest struct {
+type UploadedPart struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
-	SizeBytes     int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
-	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	PartNumber    int32                  `protobuf:"varint,1,opt,name=part_number,json=partNumber,proto3" json:"part_number,omitempty"`
+	Etag          string                 `protobuf:"bytes,2,opt,name=etag,proto3" json:"etag,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *InitiateUploadRequest) Reset() {
-	*x = InitiateUploadRequest{}
+func (x *UploadedPart) Reset() {
+	*x = UploadedPart{}
 	mi := &file_blob_v1_blob_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *InitiateUploadRequest) String() string {
+func (x *UploadedPart) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InitiateUploadRequest) ProtoMessage() {}
+func (*UploadedPart) ProtoMessage() {}
 
-func (x *InitiateUploadRequest) ProtoReflect() protoreflect.Message {
+func (x *UploadedPart) ProtoReflect() protoreflect.Message {
 	mi := &file_blob_v1_blob_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -209,55 +380,55 @@ func (x *InitiateUploadRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InitiateUploadRequest.ProtoReflect.Descriptor instead.
-func (*InitiateUploadRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use UploadedPart.ProtoReflect.Descriptor instead.
+func (*UploadedPart) Descriptor() ([]byte, []int) {
 	return file_blob_v1_blob_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *InitiateUploadRequest) GetBlobId() string {
+func (x *UploadedPart) GetPartNumber() int32 {
 	if x != nil {
-		return x.BlobId
+		return x.PartNumber
 	}
-	return ""
+	return 0
 }
 
-func (x *InitiateUploadRequest) GetSizeBytes() int64 {
+func (x *UploadedPart) GetEtag() string {
 	if x != nil {
-		return x.SizeBytes
+		return x.Etag
 	}
-	return 0
+	return ""
 }
 
-func (x *InitiateUploadRequest) GetContentType() string {
+func (x *UploadedPart) GetSizeBytes() int64 {
 	if x != nil {
-		return x.ContentType
+		return x.SizeBytes
 	}
-	return ""
+	return 0
 }
 
-type InitiateUploadResponse struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	AlreadyExists   bool                   `protobuf:"varint,1,opt,name=already_exists,json=alreadyExists,proto3" json:"already_exists,omitempty"`
-	PresignedPutUrl string                 `protobuf:"bytes,2,opt,name=presigned_put_url,json=presignedPutUrl,proto3" json:"presigned_put_url,omitempty"`
-	UrlExpiresAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=url_expires_at,json=urlExpiresAt,proto3" json:"url_expires_at,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+type PartRange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PartNumber    int32                  `protobuf:"varint,1,opt,name=part_number,json=partNumber,proto3" json:"part_number,omitempty"`
+	Offset        int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *InitiateUploadResponse) Reset() {
-	*x = InitiateUploadResponse{}
+func (x *PartRange) Reset() {
+	*x = PartRange{}
 	mi := &file_blob_v1_blob_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *InitiateUploadResponse) String() string {
+func (x *PartRange) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InitiateUploadResponse) ProtoMessage() {}
+func (*PartRange) ProtoMessage() {}
 
-func (x *InitiateUploadResponse) ProtoReflect() protoreflect.Message {
+func (x *PartRange) ProtoReflect() protoreflect.Message {
 	mi := &file_blob_v1_blob_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -269,53 +440,53 @@ func (x *InitiateUploadResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InitiateUploadResponse.ProtoReflect.Descriptor instead.
-func (*InitiateUploadResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use PartRange.ProtoReflect.Descriptor instead.
+func (*PartRange) Descriptor() ([]byte, []int) {
 	return file_blob_v1_blob_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *InitiateUploadResponse) GetAlreadyExists() bool {
+func (x *PartRange) GetPartNumber() int32 {
 	if x != nil {
-		return x.AlreadyExists
+		return x.PartNumber
 	}
-	return false
+	return 0
 }
 
-func (x *InitiateUploadResponse) GetPresignedPutUrl() string {
+func (x *PartRange) GetOffset() int64 {
 	if x != nil {
-		return x.PresignedPutUrl
+		return x.Offset
 	}
-	return ""
+	return 0
 }
 
-func (x *InitiateUploadResponse) GetUrlExpiresAt() *timestamppb.Timestamp {
+func (x *PartRange) GetSizeBytes() int64 {
 	if x != nil {
-		return x.UrlExpiresAt
+		return x.SizeBytes
 	}
-	return nil
+	return 0
 }
 
-type CompleteUploadRequest struct {
+type PlanUploadRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,1,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CompleteUploadRequest) Reset() {
-	*x = CompleteUploadRequest{}
+func (x *PlanUploadRequest) Reset() {
+	*x = PlanUploadRequest{}
 	mi := &file_blob_v1_blob_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CompleteUploadRequest) String() string {
+func (x *PlanUploadRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CompleteUploadRequest) ProtoMessage() {}
+func (*PlanUploadRequest) ProtoMessage() {}
 
-func (x *CompleteUploadRequest) ProtoReflect() protoreflect.Message {
+func (x *PlanUploadRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_blob_v1_blob_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -327,40 +498,42 @@ func (x *CompleteUploadRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CompleteUploadRequest.ProtoReflect.Descriptor instead.
-func (*CompleteUploadRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use PlanUploadRequest.ProtoReflect.Descriptor instead.
+func (*PlanUploadRequest) Descriptor() ([]byte, []int) {
 	return file_blob_v1_blob_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *CompleteUploadRequest) GetBlobId() string {
+func (x *PlanUploadRequest) GetSizeBytes() int64 {
 	if x != nil {
-		return x.BlobId
+		return x.SizeBytes
 	}
-	return ""
+	return 0
 }
 
-type CompleteUploadResponse struct {
+type PlanUploadResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
-	CommittedAt   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=committed_at,json=committedAt,proto3" json:"committed_at,omitempty"`
+	Multipart     bool                   `protobuf:"varint,1,opt,name=multipart,proto3" json:"multipart,omitempty"`
+	PartSizeBytes int64                  `protobuf:"varint,2,opt,name=part_size_bytes,json=partSizeBytes,proto3" json:"part_size_bytes,omitempty"`
+	PartCount     int32                  `protobuf:"varint,3,opt,name=part_count,json=partCount,proto3" json:"part_count,omitempty"`
+	Parts         []*PartRange           `protobuf:"bytes,4,rep,name=parts,proto3" json:"parts,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CompleteUploadResponse) Reset() {
-	*x = CompleteUploadResponse{}
+func (x *PlanUploadResponse) Reset() {
+	*x = PlanUploadResponse{}
 	mi := &file_blob_v1_blob_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CompleteUploadResponse) String() string {
+func (x *PlanUploadResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CompleteUploadResponse) ProtoMessage() {}
+func (*PlanUploadResponse) ProtoMessage() {}
 
-func (x *CompleteUploadResponse) ProtoReflect() protoreflect.Message {
+func (x *PlanUploadResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_blob_v1_blob_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -372,49 +545,66 @@ func (x *CompleteUploadResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CompleteUploadResponse.ProtoReflect.Descriptor instead.
-func (*CompleteUploadResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use PlanUploadResponse.ProtoReflect.Descriptor instead.
+func (*PlanUploadResponse) Descriptor() ([]byte, []int) {
 	return file_blob_v1_blob_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *CompleteUploadResponse) GetBlobId() string {
+func (x *PlanUploadResponse) GetMultipart() bool {
 	if x != nil {
-		return x.BlobId
+		return x.Multipart
 	}
-	return ""
+	return false
 }
 
-func (x *CompleteUploadResponse) GetCommittedAt() *timestamppb.Timestamp {
+func (x *PlanUploadResponse) GetPartSizeBytes() int64 {
 	if x != nil {
-		return x.CommittedAt
+		return x.PartSizeBytes
+	}
+	return 0
+}
+
+func (x *PlanUploadResponse) GetPartCount() int32 {
+	if x != nil {
+		return x.PartCount
+	}
+	return 0
+}
+
+func (x *PlanUploadResponse) GetParts() []*PartRange {
+	if x != nil {
+		return x.Parts
 	}
 	return nil
 }
 
-type InitiateMultipartUploadRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
-	SizeBytes     int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
-	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
-	PartCount     int32                  `protobuf:"varint,4,opt,name=part_count,json=partCount,proto3" json:"part_count,omitempty"`
+type InitiateUploadRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	BlobId      string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	SizeBytes   int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	ContentType string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	// Store a new blob encrypted under its own data key. The key is sent to
+	// the object store with every request as SSE-C headers, which presigned
+	// requests list in required_headers. Ignored for blobs that already exist.
+	Encrypt       bool `protobuf:"varint,4,opt,name=encrypt,proto3" json:"encrypt,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *InitiateMultipartUploadRequest) Reset() {
-	*x = InitiateMultipartUploadRequest{}
+func (x *InitiateUploadRequest) Reset() {
+	*x = InitiateUploadRequest{}
 	mi := &file_blob_v1_blob_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *InitiateMultipartUploadRequest) String() string {
+func (x *InitiateUploadRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InitiateMultipartUploadRequest) ProtoMessage() {}
+func (*InitiateUploadRequest) ProtoMessage() {}
 
-func (x *InitiateMultipartUploadRequest) ProtoReflect() protoreflect.Message {
+func (x *InitiateUploadRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_blob_v1_blob_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -426,63 +616,64 @@ func (x *InitiateMultipartUploadRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InitiateMultipartUploadRequest.ProtoReflect.Descriptor instead.
-func (*InitiateMultipartUploadRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use InitiateUploadRequest.ProtoReflect.Descriptor instead.
+func (*InitiateUploadRequest) Descriptor() ([]byte, []int) {
 	return file_blob_v1_blob_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *InitiateMultipartUploadRequest) GetBlobId() string {
+func (x *InitiateUploadRequest) GetBlobId() string {
 	if x != nil {
 		return x.BlobId
 	}
 	return ""
 }
 
-func (x *InitiateMultipartUploadRequest) GetSizeBytes() int64 {
+func (x *InitiateUploadRequest) GetSizeBytes() int64 {
 	if x != nil {
 		return x.SizeBytes
 	}
 	return 0
 }
 
-func (x *InitiateMultipartUploadRequest) GetContentType() string {
+func (x *InitiateUploadRequest) GetContentType() string {
 	if x != nil {
 		return x.ContentType
 	}
 	return ""
 }
 
-func (x *InitiateMultipartUploadRequest) GetPartCount() int32 {
+func (x *InitiateUploadRequest) GetEncrypt() bool {
 	if x != nil {
-		return x.PartCount
+		return x.Encrypt
 	}
-	return 0
+	return false
 }
 
-type InitiateMultipartUploadResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AlreadyExists bool                   `protobuf:"varint,1,opt,name=already_exists,json=alreadyExists,proto3" json:"already_exists,omitempty"`
-	UploadId      string                 `protobuf:"bytes,2,opt,name=upload_id,json=uploadId,proto3" json:"upload_id,omitempty"`
-	Parts         []*PartUploadURL       `protobuf:"bytes,3,rep,name=parts,proto3" json:"parts,omitempty"`
-	UrlExpiresAt  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=url_expires_at,json=urlExpiresAt,proto3" json:"url_expires_at,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type InitiateUploadResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AlreadyExists   bool                   `protobuf:"varint,1,opt,name=already_exists,json=alreadyExists,proto3" json:"already_exists,omitempty"`
+	PresignedPutUrl string                 `protobuf:"bytes,2,opt,name=presigned_put_url,json=presignedPutUrl,proto3" json:"presigned_put_url,omitempty"`
+	UrlExpiresAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=url_expires_at,json=urlExpiresAt,proto3" json:"url_expires_at,omitempty"`
+	// Headers signed into the URL that must be sent with the PUT unchanged.
+	RequiredHeaders map[string]string `protobuf:"bytes,4,rep,name=required_headers,json=requiredHeaders,proto3" json:"required_headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
-func (x *InitiateMultipartUploadResponse) Reset() {
-	*x = InitiateMultipartUploadResponse{}
+func (x *InitiateUploadResponse) Reset() {
+	*x = InitiateUploadResponse{}
 	mi := &file_blob_v1_blob_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *InitiateMultipartUploadResponse) String() string {
+func (x *InitiateUploadResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InitiateMultipartUploadResponse) ProtoMessage() {}
+func (*InitiateUploadResponse) ProtoMessage() {}
 
-func (x *InitiateMultipartUploadResponse) ProtoReflect() protoreflect.Message {
+func (x *InitiateUploadResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_blob_v1_blob_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -494,40 +685,290 @@ func (x *InitiateMultipartUploadResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InitiateMultipartUploadResponse.ProtoReflect.Descriptor instead.
-func (*InitiateMultipartUploadResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use InitiateUploadResponse.ProtoReflect.Descriptor instead.
+func (*InitiateUploadResponse) Descriptor() ([]byte, []int) {
 	return file_blob_v1_blob_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *InitiateMultipartUploadResponse) GetAlreadyExists() bool {
+func (x *InitiateUploadResponse) GetAlreadyExists() bool {
 	if x != nil {
 		return x.AlreadyExists
 	}
 	return false
 }
 
-func (x *InitiateMultipartUploadResponse) GetUploadId() string {
+func (x *InitiateUploadResponse) GetPresignedPutUrl() string {
 	if x != nil {
-		return x.UploadId
+		return x.PresignedPutUrl
 	}
 	return ""
 }
 
-func (x *InitiateMultipartUploadResponse) GetParts() []*PartUploadURL {
+func (x *InitiateUploadResponse) GetUrlExpiresAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Parts
+		return x.UrlExpiresAt
 	}
 	return nil
 }
 
-func (x *InitiateMultipartUploadResponse) GetUrlExpiresAt() *timestamppb.Timestamp {
+func (x *InitiateUploadResponse) GetRequiredHeaders() map[string]string {
 	if x != nil {
-		return x.UrlExpiresAt
+		return x.RequiredHeaders
 	}
 	return nil
 }
 
-type CompleteMultipartUploadRequest struct {
+type CompleteUploadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteUploadRequest) Reset() {
+	*x = CompleteUploadRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteUploadRequest) ProtoMessage() {}
+
+func (x *CompleteUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteUploadRequest.ProtoReflect.Descriptor instead.
+func (*CompleteUploadRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CompleteUploadRequest) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+type CompleteUploadResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	BlobId string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	// Unset while upload_state is SCANNING.
+	CommittedAt   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=committed_at,json=committedAt,proto3" json:"committed_at,omitempty"`
+	UploadState   UploadState            `protobuf:"varint,3,opt,name=upload_state,json=uploadState,proto3,enum=blob.v1.UploadState" json:"upload_state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteUploadResponse) Reset() {
+	*x = CompleteUploadResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteUploadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteUploadResponse) ProtoMessage() {}
+
+func (x *CompleteUploadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteUploadResponse.ProtoReflect.Descriptor instead.
+func (*CompleteUploadResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CompleteUploadResponse) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *CompleteUploadResponse) GetCommittedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CommittedAt
+	}
+	return nil
+}
+
+func (x *CompleteUploadResponse) GetUploadState() UploadState {
+	if x != nil {
+		return x.UploadState
+	}
+	return UploadState_UPLOAD_STATE_UNSPECIFIED
+}
+
+type InitiateMultipartUploadRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	BlobId      string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	SizeBytes   int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	ContentType string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	PartCount   int32                  `protobuf:"varint,4,opt,name=part_count,json=partCount,proto3" json:"part_count,omitempty"`
+	// As in InitiateUploadRequest.
+	Encrypt       bool `protobuf:"varint,5,opt,name=encrypt,proto3" json:"encrypt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InitiateMultipartUploadRequest) Reset() {
+	*x = InitiateMultipartUploadRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InitiateMultipartUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitiateMultipartUploadRequest) ProtoMessage() {}
+
+func (x *InitiateMultipartUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitiateMultipartUploadRequest.ProtoReflect.Descriptor instead.
+func (*InitiateMultipartUploadRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *InitiateMultipartUploadRequest) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *InitiateMultipartUploadRequest) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *InitiateMultipartUploadRequest) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *InitiateMultipartUploadRequest) GetPartCount() int32 {
+	if x != nil {
+		return x.PartCount
+	}
+	return 0
+}
+
+func (x *InitiateMultipartUploadRequest) GetEncrypt() bool {
+	if x != nil {
+		return x.Encrypt
+	}
+	return false
+}
+
+type InitiateMultipartUploadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AlreadyExists bool                   `protobuf:"varint,1,opt,name=already_exists,json=alreadyExists,proto3" json:"already_exists,omitempty"`
+	UploadId      string                 `protobuf:"bytes,2,opt,name=upload_id,json=uploadId,proto3" json:"upload_id,omitempty"`
+	Parts         []*PartUploadURL       `protobuf:"bytes,3,rep,name=parts,proto3" json:"parts,omitempty"`
+	UrlExpiresAt  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=url_expires_at,json=urlExpiresAt,proto3" json:"url_expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InitiateMultipartUploadResponse) Reset() {
+	*x = InitiateMultipartUploadResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InitiateMultipartUploadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitiateMultipartUploadResponse) ProtoMessage() {}
+
+func (x *InitiateMultipartUploadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitiateMultipartUploadResponse.ProtoReflect.Descriptor instead.
+func (*InitiateMultipartUploadResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *InitiateMultipartUploadResponse) GetAlreadyExists() bool {
+	if x != nil {
+		return x.AlreadyExists
+	}
+	return false
+}
+
+func (x *InitiateMultipartUploadResponse) GetUploadId() string {
+	if x != nil {
+		return x.UploadId
+	}
+	return ""
+}
+
+func (x *InitiateMultipartUploadResponse) GetParts() []*PartUploadURL {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+func (x *InitiateMultipartUploadResponse) GetUrlExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UrlExpiresAt
+	}
+	return nil
+}
+
+type CompleteMultipartUploadRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
 	UploadId      string                 `protobuf:"bytes,2,opt,name=upload_id,json=uploadId,proto3" json:"upload_id,omitempty"`
@@ -538,19 +979,1874 @@ type CompleteMultipartUploadRequest struct {
 
 func (x *CompleteMultipartUploadRequest) Reset() {
 	*x = CompleteMultipartUploadRequest{}
-	mi := &file_blob_v1_blob_proto_msgTypes[8]
+	mi := &file_blob_v1_blob_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteMultipartUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteMultipartUploadRequest) ProtoMessage() {}
+
+func (x *CompleteMultipartUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteMultipartUploadRequest.ProtoReflect.Descriptor instead.
+func (*CompleteMultipartUploadRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CompleteMultipartUploadRequest) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *CompleteMultipartUploadRequest) GetUploadId() string {
+	if x != nil {
+		return x.UploadId
+	}
+	return ""
+}
+
+func (x *CompleteMultipartUploadRequest) GetParts() []*CompletedPart {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+type CompleteMultipartUploadResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	BlobId string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	// Unset while upload_state is SCANNING.
+	CommittedAt   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=committed_at,json=committedAt,proto3" json:"committed_at,omitempty"`
+	UploadState   UploadState            `protobuf:"varint,3,opt,name=upload_state,json=uploadState,proto3,enum=blob.v1.UploadState" json:"upload_state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteMultipartUploadResponse) Reset() {
+	*x = CompleteMultipartUploadResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteMultipartUploadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteMultipartUploadResponse) ProtoMessage() {}
+
+func (x *CompleteMultipartUploadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteMultipartUploadResponse.ProtoReflect.Descriptor instead.
+func (*CompleteMultipartUploadResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CompleteMultipartUploadResponse) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *CompleteMultipartUploadResponse) GetCommittedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CommittedAt
+	}
+	return nil
+}
+
+func (x *CompleteMultipartUploadResponse) GetUploadState() UploadState {
+	if x != nil {
+		return x.UploadState
+	}
+	return UploadState_UPLOAD_STATE_UNSPECIFIED
+}
+
+type AbortMultipartUploadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	UploadId      string                 `protobuf:"bytes,2,opt,name=upload_id,json=uploadId,proto3" json:"upload_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AbortMultipartUploadRequest) Reset() {
+	*x = AbortMultipartUploadRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AbortMultipartUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AbortMultipartUploadRequest) ProtoMessage() {}
+
+func (x *AbortMultipartUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AbortMultipartUploadRequest.ProtoReflect.Descriptor instead.
+func (*AbortMultipartUploadRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *AbortMultipartUploadRequest) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *AbortMultipartUploadRequest) GetUploadId() string {
+	if x != nil {
+		return x.UploadId
+	}
+	return ""
+}
+
+type AbortMultipartUploadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AbortMultipartUploadResponse) Reset() {
+	*x = AbortMultipartUploadResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AbortMultipartUploadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AbortMultipartUploadResponse) ProtoMessage() {}
+
+func (x *AbortMultipartUploadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AbortMultipartUploadResponse.ProtoReflect.Descriptor instead.
+func (*AbortMultipartUploadResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{15}
+}
+
+type ResumeMultipartUploadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeMultipartUploadRequest) Reset() {
+	*x = ResumeMultipartUploadRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeMultipartUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeMultipartUploadRequest) ProtoMessage() {}
+
+func (x *ResumeMultipartUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeMultipartUploadRequest.ProtoReflect.Descriptor instead.
+func (*ResumeMultipartUploadRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ResumeMultipartUploadRequest) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+type ResumeMultipartUploadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AlreadyExists bool                   `protobuf:"varint,1,opt,name=already_exists,json=alreadyExists,proto3" json:"already_exists,omitempty"`
+	UploadId      string                 `protobuf:"bytes,2,opt,name=upload_id,json=uploadId,proto3" json:"upload_id,omitempty"`
+	UploadedParts []*UploadedPart        `protobuf:"bytes,3,rep,name=uploaded_parts,json=uploadedParts,proto3" json:"uploaded_parts,omitempty"`
+	MissingParts  []*PartUploadURL       `protobuf:"bytes,4,rep,name=missing_parts,json=missingParts,proto3" json:"missing_parts,omitempty"`
+	UrlExpiresAt  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=url_expires_at,json=urlExpiresAt,proto3" json:"url_expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeMultipartUploadResponse) Reset() {
+	*x = ResumeMultipartUploadResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeMultipartUploadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeMultipartUploadResponse) ProtoMessage() {}
+
+func (x *ResumeMultipartUploadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeMultipartUploadResponse.ProtoReflect.Descriptor instead.
+func (*ResumeMultipartUploadResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ResumeMultipartUploadResponse) GetAlreadyExists() bool {
+	if x != nil {
+		return x.AlreadyExists
+	}
+	return false
+}
+
+func (x *ResumeMultipartUploadResponse) GetUploadId() string {
+	if x != nil {
+		return x.UploadId
+	}
+	return ""
+}
+
+func (x *ResumeMultipartUploadResponse) GetUploadedParts() []*UploadedPart {
+	if x != nil {
+		return x.UploadedParts
+	}
+	return nil
+}
+
+func (x *ResumeMultipartUploadResponse) GetMissingParts() []*PartUploadURL {
+	if x != nil {
+		return x.MissingParts
+	}
+	return nil
+}
+
+func (x *ResumeMultipartUploadResponse) GetUrlExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UrlExpiresAt
+	}
+	return nil
+}
+
+// The first UploadStreamRequest carries metadata; every following one
+// carries the next chunk of content.
+type UploadStreamRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*UploadStreamRequest_Metadata
+	//	*UploadStreamRequest_Chunk
+	Payload       isUploadStreamRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadStreamRequest) Reset() {
+	*x = UploadStreamRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadStreamRequest) ProtoMessage() {}
+
+func (x *UploadStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadStreamRequest.ProtoReflect.Descriptor instead.
+func (*UploadStreamRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UploadStreamRequest) GetPayload() isUploadStreamRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *UploadStreamRequest) GetMetadata() *UploadStreamMetadata {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadStreamRequest_Metadata); ok {
+			return x.Metadata
+		}
+	}
+	return nil
+}
+
+func (x *UploadStreamRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadStreamRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isUploadStreamRequest_Payload interface {
+	isUploadStreamRequest_Payload()
+}
+
+type UploadStreamRequest_Metadata struct {
+	Metadata *UploadStreamMetadata `protobuf:"bytes,1,opt,name=metadata,proto3,oneof"`
+}
+
+type UploadStreamRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*UploadStreamRequest_Metadata) isUploadStreamRequest_Payload() {}
+
+func (*UploadStreamRequest_Chunk) isUploadStreamRequest_Payload() {}
+
+type UploadStreamMetadata struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ContentType string                 `protobuf:"bytes,1,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	// Optional. When set, the upload is rejected unless the content hashes to it.
+	ExpectedBlobId string `protobuf:"bytes,2,opt,name=expected_blob_id,json=expectedBlobId,proto3" json:"expected_blob_id,omitempty"`
+	// As in InitiateUploadRequest; the content is decrypted again on download.
+	Encrypt       bool `protobuf:"varint,3,opt,name=encrypt,proto3" json:"encrypt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadStreamMetadata) Reset() {
+	*x = UploadStreamMetadata{}
+	mi := &file_blob_v1_blob_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadStreamMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadStreamMetadata) ProtoMessage() {}
+
+func (x *UploadStreamMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadStreamMetadata.ProtoReflect.Descriptor instead.
+func (*UploadStreamMetadata) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *UploadStreamMetadata) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *UploadStreamMetadata) GetExpectedBlobId() string {
+	if x != nil {
+		return x.ExpectedBlobId
+	}
+	return ""
+}
+
+func (x *UploadStreamMetadata) GetEncrypt() bool {
+	if x != nil {
+		return x.Encrypt
+	}
+	return false
+}
+
+type UploadStreamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	AlreadyExists bool                   `protobuf:"varint,3,opt,name=already_exists,json=alreadyExists,proto3" json:"already_exists,omitempty"`
+	// Unset while upload_state is SCANNING.
+	CommittedAt   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=committed_at,json=committedAt,proto3" json:"committed_at,omitempty"`
+	UploadState   UploadState            `protobuf:"varint,5,opt,name=upload_state,json=uploadState,proto3,enum=blob.v1.UploadState" json:"upload_state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadStreamResponse) Reset() {
+	*x = UploadStreamResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadStreamResponse) ProtoMessage() {}
+
+func (x *UploadStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadStreamResponse.ProtoReflect.Descriptor instead.
+func (*UploadStreamResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *UploadStreamResponse) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *UploadStreamResponse) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *UploadStreamResponse) GetAlreadyExists() bool {
+	if x != nil {
+		return x.AlreadyExists
+	}
+	return false
+}
+
+func (x *UploadStreamResponse) GetCommittedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CommittedAt
+	}
+	return nil
+}
+
+func (x *UploadStreamResponse) GetUploadState() UploadState {
+	if x != nil {
+		return x.UploadState
+	}
+	return UploadState_UPLOAD_STATE_UNSPECIFIED
+}
+
+type DownloadStreamRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	BlobId string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	Offset int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Number of bytes to read from offset; 0 reads to the end of the blob.
+	Length        int64 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadStreamRequest) Reset() {
+	*x = DownloadStreamRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadStreamRequest) ProtoMessage() {}
+
+func (x *DownloadStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadStreamRequest.ProtoReflect.Descriptor instead.
+func (*DownloadStreamRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DownloadStreamRequest) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *DownloadStreamRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *DownloadStreamRequest) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+// Only the first DownloadStreamResponse sets content_type and size_bytes.
+type DownloadStreamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	ContentType   string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadStreamResponse) Reset() {
+	*x = DownloadStreamResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadStreamResponse) ProtoMessage() {}
+
+func (x *DownloadStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadStreamResponse.ProtoReflect.Descriptor instead.
+func (*DownloadStreamResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DownloadStreamResponse) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *DownloadStreamResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *DownloadStreamResponse) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+type GetDownloadURLRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	BlobId     string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	TtlSeconds int32                  `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	// Overrides for the download response. A filename without a disposition
+	// implies ATTACHMENT. Values are validated; malformed ones are rejected
+	// with INVALID_ARGUMENT.
+	Disposition         ContentDisposition `protobuf:"varint,3,opt,name=disposition,proto3,enum=blob.v1.ContentDisposition" json:"disposition,omitempty"`
+	Filename            string             `protobuf:"bytes,4,opt,name=filename,proto3" json:"filename,omitempty"`
+	ResponseContentType string             `protobuf:"bytes,5,opt,name=response_content_type,json=responseContentType,proto3" json:"response_content_type,omitempty"`
+	CacheControl        string             `protobuf:"bytes,6,opt,name=cache_control,json=cacheControl,proto3" json:"cache_control,omitempty"`
+	// Optional byte range signed into the URL. Both zero selects the whole
+	// blob; a zero range_length reads to the end.
+	RangeOffset   int64 `protobuf:"varint,7,opt,name=range_offset,json=rangeOffset,proto3" json:"range_offset,omitempty"`
+	RangeLength   int64 `protobuf:"varint,8,opt,name=range_length,json=rangeLength,proto3" json:"range_length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDownloadURLRequest) Reset() {
+	*x = GetDownloadURLRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDownloadURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDownloadURLRequest) ProtoMessage() {}
+
+func (x *GetDownloadURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDownloadURLRequest.ProtoReflect.Descriptor instead.
+func (*GetDownloadURLRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetDownloadURLRequest) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *GetDownloadURLRequest) GetTtlSeconds() int32 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *GetDownloadURLRequest) GetDisposition() ContentDisposition {
+	if x != nil {
+		return x.Disposition
+	}
+	return ContentDisposition_CONTENT_DISPOSITION_UNSPECIFIED
+}
+
+func (x *GetDownloadURLRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *GetDownloadURLRequest) GetResponseContentType() string {
+	if x != nil {
+		return x.ResponseContentType
+	}
+	return ""
+}
+
+func (x *GetDownloadURLRequest) GetCacheControl() string {
+	if x != nil {
+		return x.CacheControl
+	}
+	return ""
+}
+
+func (x *GetDownloadURLRequest) GetRangeOffset() int64 {
+	if x != nil {
+		return x.RangeOffset
+	}
+	return 0
+}
+
+func (x *GetDownloadURLRequest) GetRangeLength() int64 {
+	if x != nil {
+		return x.RangeLength
+	}
+	return 0
+}
+
+type GetDownloadURLResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PresignedGetUrl string                 `protobuf:"bytes,1,opt,name=presigned_get_url,json=presignedGetUrl,proto3" json:"presigned_get_url,omitempty"`
+	UrlExpiresAt    *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=url_expires_at,json=urlExpiresAt,proto3" json:"url_expires_at,omitempty"`
+	// Headers signed into the URL that must be sent with the GET unchanged.
+	RequiredHeaders map[string]string `protobuf:"bytes,3,rep,name=required_headers,json=requiredHeaders,proto3" json:"required_headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetDownloadURLResponse) Reset() {
+	*x = GetDownloadURLResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDownloadURLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDownloadURLResponse) ProtoMessage() {}
+
+func (x *GetDownloadURLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDownloadURLResponse.ProtoReflect.Descriptor instead.
+func (*GetDownloadURLResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetDownloadURLResponse) GetPresignedGetUrl() string {
+	if x != nil {
+		return x.PresignedGetUrl
+	}
+	return ""
+}
+
+func (x *GetDownloadURLResponse) GetUrlExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UrlExpiresAt
+	}
+	return nil
+}
+
+func (x *GetDownloadURLResponse) GetRequiredHeaders() map[string]string {
+	if x != nil {
+		return x.RequiredHeaders
+	}
+	return nil
+}
+
+// RenditionSpec selects a derived image: the source scaled down to fit a
+// max_dimension square and re-encoded as format. Only specs the service is
+// configured to generate can be requested.
+type RenditionSpec struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        RenditionFormat        `protobuf:"varint,1,opt,name=format,proto3,enum=blob.v1.RenditionFormat" json:"format,omitempty"`
+	MaxDimension  int32                  `protobuf:"varint,2,opt,name=max_dimension,json=maxDimension,proto3" json:"max_dimension,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenditionSpec) Reset() {
+	*x = RenditionSpec{}
+	mi := &file_blob_v1_blob_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenditionSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenditionSpec) ProtoMessage() {}
+
+func (x *RenditionSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenditionSpec.ProtoReflect.Descriptor instead.
+func (*RenditionSpec) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RenditionSpec) GetFormat() RenditionFormat {
+	if x != nil {
+		return x.Format
+	}
+	return RenditionFormat_RENDITION_FORMAT_UNSPECIFIED
+}
+
+func (x *RenditionSpec) GetMaxDimension() int32 {
+	if x != nil {
+		return x.MaxDimension
+	}
+	return 0
+}
+
+type GetRenditionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	Spec          *RenditionSpec         `protobuf:"bytes,2,opt,name=spec,proto3" json:"spec,omitempty"`
+	TtlSeconds    int32                  `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRenditionRequest) Reset() {
+	*x = GetRenditionRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRenditionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRenditionRequest) ProtoMessage() {}
+
+func (x *GetRenditionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRenditionRequest.ProtoReflect.Descriptor instead.
+func (*GetRenditionRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *GetRenditionRequest) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *GetRenditionRequest) GetSpec() *RenditionSpec {
+	if x != nil {
+		return x.Spec
+	}
+	return nil
+}
+
+func (x *GetRenditionRequest) GetTtlSeconds() int32 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+// GetRenditionResponse describes the rendition, which is a blob of its own
+// that the caller reads through the URL without holding a reference to it.
+type GetRenditionResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RenditionBlobId string                 `protobuf:"bytes,1,opt,name=rendition_blob_id,json=renditionBlobId,proto3" json:"rendition_blob_id,omitempty"`
+	SizeBytes       int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	ContentType     string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	PresignedGetUrl string                 `protobuf:"bytes,4,opt,name=presigned_get_url,json=presignedGetUrl,proto3" json:"presigned_get_url,omitempty"`
+	UrlExpiresAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=url_expires_at,json=urlExpiresAt,proto3" json:"url_expires_at,omitempty"`
+	RequiredHeaders map[string]string      `protobuf:"bytes,6,rep,name=required_headers,json=requiredHeaders,proto3" json:"required_headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetRenditionResponse) Reset() {
+	*x = GetRenditionResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRenditionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRenditionResponse) ProtoMessage() {}
+
+func (x *GetRenditionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRenditionResponse.ProtoReflect.Descriptor instead.
+func (*GetRenditionResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetRenditionResponse) GetRenditionBlobId() string {
+	if x != nil {
+		return x.RenditionBlobId
+	}
+	return ""
+}
+
+func (x *GetRenditionResponse) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *GetRenditionResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *GetRenditionResponse) GetPresignedGetUrl() string {
+	if x != nil {
+		return x.PresignedGetUrl
+	}
+	return ""
+}
+
+func (x *GetRenditionResponse) GetUrlExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UrlExpiresAt
+	}
+	return nil
+}
+
+func (x *GetRenditionResponse) GetRequiredHeaders() map[string]string {
+	if x != nil {
+		return x.RequiredHeaders
+	}
+	return nil
+}
+
+type GetBlobInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlobInfoRequest) Reset() {
+	*x = GetBlobInfoRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlobInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlobInfoRequest) ProtoMessage() {}
+
+func (x *GetBlobInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlobInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetBlobInfoRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetBlobInfoRequest) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+type GetBlobInfoResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	BlobId      string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	SizeBytes   int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	ContentType string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	UploadState UploadState            `protobuf:"varint,4,opt,name=upload_state,json=uploadState,proto3,enum=blob.v1.UploadState" json:"upload_state,omitempty"`
+	CommittedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=committed_at,json=committedAt,proto3" json:"committed_at,omitempty"`
+	// Sniffed from the content on commit; empty while PENDING.
+	DetectedContentType string `protobuf:"bytes,6,opt,name=detected_content_type,json=detectedContentType,proto3" json:"detected_content_type,omitempty"`
+	Encrypted           bool   `protobuf:"varint,7,opt,name=encrypted,proto3" json:"encrypted,omitempty"`
+	// Unset when no retention period applies.
+	RetainUntil   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=retain_until,json=retainUntil,proto3" json:"retain_until,omitempty"`
+	LegalHold     bool                   `protobuf:"varint,9,opt,name=legal_hold,json=legalHold,proto3" json:"legal_hold,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlobInfoResponse) Reset() {
+	*x = GetBlobInfoResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlobInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlobInfoResponse) ProtoMessage() {}
+
+func (x *GetBlobInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlobInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetBlobInfoResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetBlobInfoResponse) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *GetBlobInfoResponse) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *GetBlobInfoResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *GetBlobInfoResponse) GetUploadState() UploadState {
+	if x != nil {
+		return x.UploadState
+	}
+	return UploadState_UPLOAD_STATE_UNSPECIFIED
+}
+
+func (x *GetBlobInfoResponse) GetCommittedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CommittedAt
+	}
+	return nil
+}
+
+func (x *GetBlobInfoResponse) GetDetectedContentType() string {
+	if x != nil {
+		return x.DetectedContentType
+	}
+	return ""
+}
+
+func (x *GetBlobInfoResponse) GetEncrypted() bool {
+	if x != nil {
+		return x.Encrypted
+	}
+	return false
+}
+
+func (x *GetBlobInfoResponse) GetRetainUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RetainUntil
+	}
+	return nil
+}
+
+func (x *GetBlobInfoResponse) GetLegalHold() bool {
+	if x != nil {
+		return x.LegalHold
+	}
+	return false
+}
+
+type BlobInfo struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	BlobId              string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	SizeBytes           int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	ContentType         string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	UploadState         UploadState            `protobuf:"varint,4,opt,name=upload_state,json=uploadState,proto3,enum=blob.v1.UploadState" json:"upload_state,omitempty"`
+	CreatedAt           *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	CommittedAt         *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=committed_at,json=committedAt,proto3" json:"committed_at,omitempty"`
+	DetectedContentType string                 `protobuf:"bytes,7,opt,name=detected_content_type,json=detectedContentType,proto3" json:"detected_content_type,omitempty"`
+	Encrypted           bool                   `protobuf:"varint,8,opt,name=encrypted,proto3" json:"encrypted,omitempty"`
+	RetainUntil         *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=retain_until,json=retainUntil,proto3" json:"retain_until,omitempty"`
+	LegalHold           bool                   `protobuf:"varint,10,opt,name=legal_hold,json=legalHold,proto3" json:"legal_hold,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *BlobInfo) Reset() {
+	*x = BlobInfo{}
+	mi := &file_blob_v1_blob_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlobInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlobInfo) ProtoMessage() {}
+
+func (x *BlobInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlobInfo.ProtoReflect.Descriptor instead.
+func (*BlobInfo) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *BlobInfo) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *BlobInfo) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *BlobInfo) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *BlobInfo) GetUploadState() UploadState {
+	if x != nil {
+		return x.UploadState
+	}
+	return UploadState_UPLOAD_STATE_UNSPECIFIED
+}
+
+func (x *BlobInfo) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *BlobInfo) GetCommittedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CommittedAt
+	}
+	return nil
+}
+
+func (x *BlobInfo) GetDetectedContentType() string {
+	if x != nil {
+		return x.DetectedContentType
+	}
+	return ""
+}
+
+func (x *BlobInfo) GetEncrypted() bool {
+	if x != nil {
+		return x.Encrypted
+	}
+	return false
+}
+
+func (x *BlobInfo) GetRetainUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RetainUntil
+	}
+	return nil
+}
+
+func (x *BlobInfo) GetLegalHold() bool {
+	if x != nil {
+		return x.LegalHold
+	}
+	return false
+}
+
+type BatchGetBlobInfoRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// At most 1000 IDs.
+	BlobIds       []string `protobuf:"bytes,1,rep,name=blob_ids,json=blobIds,proto3" json:"blob_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetBlobInfoRequest) Reset() {
+	*x = BatchGetBlobInfoRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetBlobInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetBlobInfoRequest) ProtoMessage() {}
+
+func (x *BatchGetBlobInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetBlobInfoRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetBlobInfoRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *BatchGetBlobInfoRequest) GetBlobIds() []string {
+	if x != nil {
+		return x.BlobIds
+	}
+	return nil
+}
+
+// BlobInfoResult carries either the blob's info or the status code and
+// message GetBlobInfo would have failed with for that ID.
+type BlobInfoResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	Info          *BlobInfo              `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`
+	ErrorCode     int32                  `protobuf:"varint,3,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"` // google.rpc.Code
+	ErrorMessage  string                 `protobuf:"bytes,4,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlobInfoResult) Reset() {
+	*x = BlobInfoResult{}
+	mi := &file_blob_v1_blob_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlobInfoResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlobInfoResult) ProtoMessage() {}
+
+func (x *BlobInfoResult) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlobInfoResult.ProtoReflect.Descriptor instead.
+func (*BlobInfoResult) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *BlobInfoResult) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *BlobInfoResult) GetInfo() *BlobInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *BlobInfoResult) GetErrorCode() int32 {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return 0
+}
+
+func (x *BlobInfoResult) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+type BatchGetBlobInfoResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One result per requested ID, in request order.
+	Results       []*BlobInfoResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetBlobInfoResponse) Reset() {
+	*x = BatchGetBlobInfoResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetBlobInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetBlobInfoResponse) ProtoMessage() {}
+
+func (x *BatchGetBlobInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetBlobInfoResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetBlobInfoResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *BatchGetBlobInfoResponse) GetResults() []*BlobInfoResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// ListBlobsRequest lists the blobs the caller holds a reference to. Unset
+// filters match everything; *_after bounds are inclusive and *_before
+// bounds exclusive.
+type ListBlobsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	PageSize  int32                  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken string                 `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	State     UploadState            `protobuf:"varint,3,opt,name=state,proto3,enum=blob.v1.UploadState" json:"state,omitempty"`
+	// Exact media type, or a major type such as "image/*".
+	ContentType     string                 `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	CreatedAfter    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	CommittedAfter  *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=committed_after,json=committedAfter,proto3" json:"committed_after,omitempty"`
+	CommittedBefore *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=committed_before,json=committedBefore,proto3" json:"committed_before,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ListBlobsRequest) Reset() {
+	*x = ListBlobsRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBlobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBlobsRequest) ProtoMessage() {}
+
+func (x *ListBlobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBlobsRequest.ProtoReflect.Descriptor instead.
+func (*ListBlobsRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ListBlobsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListBlobsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListBlobsRequest) GetState() UploadState {
+	if x != nil {
+		return x.State
+	}
+	return UploadState_UPLOAD_STATE_UNSPECIFIED
+}
+
+func (x *ListBlobsRequest) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *ListBlobsRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *ListBlobsRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+func (x *ListBlobsRequest) GetCommittedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CommittedAfter
+	}
+	return nil
+}
+
+func (x *ListBlobsRequest) GetCommittedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CommittedBefore
+	}
+	return nil
+}
+
+type ListBlobsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Blobs []*BlobInfo            `protobuf:"bytes,1,rep,name=blobs,proto3" json:"blobs,omitempty"`
+	// Empty when there are no more results.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBlobsResponse) Reset() {
+	*x = ListBlobsResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBlobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBlobsResponse) ProtoMessage() {}
+
+func (x *ListBlobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBlobsResponse.ProtoReflect.Descriptor instead.
+func (*ListBlobsResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ListBlobsResponse) GetBlobs() []*BlobInfo {
+	if x != nil {
+		return x.Blobs
+	}
+	return nil
+}
+
+func (x *ListBlobsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type BlobEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Strictly increasing; pass the last one seen as after_sequence to resume.
+	Sequence      int64                  `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Kind          BlobEventKind          `protobuf:"varint,2,opt,name=kind,proto3,enum=blob.v1.BlobEventKind" json:"kind,omitempty"`
+	BlobId        string                 `protobuf:"bytes,3,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,4,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	ContentType   string                 `protobuf:"bytes,5,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlobEvent) Reset() {
+	*x = BlobEvent{}
+	mi := &file_blob_v1_blob_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlobEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlobEvent) ProtoMessage() {}
+
+func (x *BlobEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlobEvent.ProtoReflect.Descriptor instead.
+func (*BlobEvent) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *BlobEvent) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *BlobEvent) GetKind() BlobEventKind {
+	if x != nil {
+		return x.Kind
+	}
+	return BlobEventKind_BLOB_EVENT_KIND_UNSPECIFIED
+}
+
+func (x *BlobEvent) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *BlobEvent) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *BlobEvent) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *BlobEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+// WatchBlobEventsRequest streams every retained event after after_sequence,
+// then new events as they are recorded, until the client cancels. Zero
+// starts at the oldest retained event.
+type WatchBlobEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AfterSequence int64                  `protobuf:"varint,1,opt,name=after_sequence,json=afterSequence,proto3" json:"after_sequence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchBlobEventsRequest) Reset() {
+	*x = WatchBlobEventsRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchBlobEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBlobEventsRequest) ProtoMessage() {}
+
+func (x *WatchBlobEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBlobEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchBlobEventsRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *WatchBlobEventsRequest) GetAfterSequence() int64 {
+	if x != nil {
+		return x.AfterSequence
+	}
+	return 0
+}
+
+type WatchBlobEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *BlobEvent             `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchBlobEventsResponse) Reset() {
+	*x = WatchBlobEventsResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchBlobEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBlobEventsResponse) ProtoMessage() {}
+
+func (x *WatchBlobEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBlobEventsResponse.ProtoReflect.Descriptor instead.
+func (*WatchBlobEventsResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *WatchBlobEventsResponse) GetEvent() *BlobEvent {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type AddReferenceRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	BlobId string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	// Subject to grant the reference to. Only callers that hold a reference
+	// may grant one; others get NOT_FOUND. Empty names the caller.
+	Subject       string `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddReferenceRequest) Reset() {
+	*x = AddReferenceRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddReferenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddReferenceRequest) ProtoMessage() {}
+
+func (x *AddReferenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddReferenceRequest.ProtoReflect.Descriptor instead.
+func (*AddReferenceRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *AddReferenceRequest) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *AddReferenceRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+type AddReferenceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddReferenceResponse) Reset() {
+	*x = AddReferenceResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CompleteMultipartUploadRequest) String() string {
+func (x *AddReferenceResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CompleteMultipartUploadRequest) ProtoMessage() {}
+func (*AddReferenceResponse) ProtoMessage() {}
 
-func (x *CompleteMultipartUploadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_blob_v1_blob_proto_msgTypes[8]
+func (x *AddReferenceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -561,55 +2857,33 @@ func (x *CompleteMultipartUploadRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CompleteMultipartUploadRequest.ProtoReflect.Descriptor instead.
-func (*CompleteMultipartUploadRequest) Descriptor() ([]byte, []int) {
-	return file_blob_v1_blob_proto_rawDescGZIP(), []int{8}
-}
-
-func (x *CompleteMultipartUploadRequest) GetBlobId() string {
-	if x != nil {
-		return x.BlobId
-	}
-	return ""
-}
-
-func (x *CompleteMultipartUploadRequest) GetUploadId() string {
-	if x != nil {
-		return x.UploadId
-	}
-	return ""
-}
-
-func (x *CompleteMultipartUploadRequest) GetParts() []*CompletedPart {
-	if x != nil {
-		return x.Parts
-	}
-	return nil
+// Deprecated: Use AddReferenceResponse.ProtoReflect.Descriptor instead.
+func (*AddReferenceResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{40}
 }
 
-type CompleteMultipartUploadResponse struct {
+type ReleaseReferenceRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
-	CommittedAt   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=committed_at,json=committedAt,proto3" json:"committed_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CompleteMultipartUploadResponse) Reset() {
-	*x = CompleteMultipartUploadResponse{}
-	mi := &file_blob_v1_blob_proto_msgTypes[9]
+func (x *ReleaseReferenceRequest) Reset() {
+	*x = ReleaseReferenceRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CompleteMultipartUploadResponse) String() string {
+func (x *ReleaseReferenceRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CompleteMultipartUploadResponse) ProtoMessage() {}
+func (*ReleaseReferenceRequest) ProtoMessage() {}
 
-func (x *CompleteMultipartUploadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_blob_v1_blob_proto_msgTypes[9]
+func (x *ReleaseReferenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -620,48 +2894,82 @@ func (x *CompleteMultipartUploadResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CompleteMultipartUploadResponse.ProtoReflect.Descriptor instead.
-func (*CompleteMultipartUploadResponse) Descriptor() ([]byte, []int) {
-	return file_blob_v1_blob_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use ReleaseReferenceRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseReferenceRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *CompleteMultipartUploadResponse) GetBlobId() string {
+func (x *ReleaseReferenceRequest) GetBlobId() string {
 	if x != nil {
 		return x.BlobId
 	}
 	return ""
 }
 
-func (x *CompleteMultipartUploadResponse) GetCommittedAt() *timestamppb.Timestamp {
+type ReleaseReferenceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseReferenceResponse) Reset() {
+	*x = ReleaseReferenceResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseReferenceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseReferenceResponse) ProtoMessage() {}
+
+func (x *ReleaseReferenceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[42]
 	if x != nil {
-		return x.CommittedAt
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-type AbortMultipartUploadRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
-	UploadId      string                 `protobuf:"bytes,2,opt,name=upload_id,json=uploadId,proto3" json:"upload_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+// Deprecated: Use ReleaseReferenceResponse.ProtoReflect.Descriptor instead.
+func (*ReleaseReferenceResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *AbortMultipartUploadRequest) Reset() {
-	*x = AbortMultipartUploadRequest{}
-	mi := &file_blob_v1_blob_proto_msgTypes[10]
+// Usage is the storage charged to one owner: the committed blobs it holds
+// references to. Limits of zero are unlimited.
+type Usage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Bytes          int64                  `protobuf:"varint,1,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	Objects        int64                  `protobuf:"varint,2,opt,name=objects,proto3" json:"objects,omitempty"`
+	MaxBytes       int64                  `protobuf:"varint,3,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	MaxObjects     int64                  `protobuf:"varint,4,opt,name=max_objects,json=maxObjects,proto3" json:"max_objects,omitempty"`
+	MaxObjectBytes int64                  `protobuf:"varint,5,opt,name=max_object_bytes,json=maxObjectBytes,proto3" json:"max_object_bytes,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Usage) Reset() {
+	*x = Usage{}
+	mi := &file_blob_v1_blob_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AbortMultipartUploadRequest) String() string {
+func (x *Usage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AbortMultipartUploadRequest) ProtoMessage() {}
+func (*Usage) ProtoMessage() {}
 
-func (x *AbortMultipartUploadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_blob_v1_blob_proto_msgTypes[10]
+func (x *Usage) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -672,46 +2980,67 @@ func (x *AbortMultipartUploadRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AbortMultipartUploadRequest.ProtoReflect.Descriptor instead.
-func (*AbortMultipartUploadRequest) Descriptor() ([]byte, []int) {
-	return file_blob_v1_blob_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use Usage.ProtoReflect.Descriptor instead.
+func (*Usage) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *AbortMultipartUploadRequest) GetBlobId() string {
+func (x *Usage) GetBytes() int64 {
 	if x != nil {
-		return x.BlobId
+		return x.Bytes
 	}
-	return ""
+	return 0
 }
 
-func (x *AbortMultipartUploadRequest) GetUploadId() string {
+func (x *Usage) GetObjects() int64 {
 	if x != nil {
-		return x.UploadId
+		return x.Objects
 	}
-	return ""
+	return 0
 }
 
-type AbortMultipartUploadResponse struct {
+func (x *Usage) GetMaxBytes() int64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *Usage) GetMaxObjects() int64 {
+	if x != nil {
+		return x.MaxObjects
+	}
+	return 0
+}
+
+func (x *Usage) GetMaxObjectBytes() int64 {
+	if x != nil {
+		return x.MaxObjectBytes
+	}
+	return 0
+}
+
+type GetUsageRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AbortMultipartUploadResponse) Reset() {
-	*x = AbortMultipartUploadResponse{}
-	mi := &file_blob_v1_blob_proto_msgTypes[11]
+func (x *GetUsageRequest) Reset() {
+	*x = GetUsageRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AbortMultipartUploadResponse) String() string {
+func (x *GetUsageRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AbortMultipartUploadResponse) ProtoMessage() {}
+func (*GetUsageRequest) ProtoMessage() {}
 
-func (x *AbortMultipartUploadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_blob_v1_blob_proto_msgTypes[11]
+func (x *GetUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -722,34 +3051,35 @@ func (x *AbortMultipartUploadResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AbortMultipartUploadResponse.ProtoReflect.Descriptor instead.
-func (*AbortMultipartUploadResponse) Descriptor() ([]byte, []int) {
-	return file_blob_v1_blob_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use GetUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetUsageRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{44}
 }
 
-type GetDownloadURLRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
-	TtlSeconds    int32                  `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+type GetUsageResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Subject *Usage                 `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	// Unset when the caller's token names no client.
+	Client        *Usage `protobuf:"bytes,2,opt,name=client,proto3" json:"client,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetDownloadURLRequest) Reset() {
-	*x = GetDownloadURLRequest{}
-	mi := &file_blob_v1_blob_proto_msgTypes[12]
+func (x *GetUsageResponse) Reset() {
+	*x = GetUsageResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetDownloadURLRequest) String() string {
+func (x *GetUsageResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetDownloadURLRequest) ProtoMessage() {}
+func (*GetUsageResponse) ProtoMessage() {}
 
-func (x *GetDownloadURLRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_blob_v1_blob_proto_msgTypes[12]
+func (x *GetUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -760,48 +3090,51 @@ func (x *GetDownloadURLRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetDownloadURLRequest.ProtoReflect.Descriptor instead.
-func (*GetDownloadURLRequest) Descriptor() ([]byte, []int) {
-	return file_blob_v1_blob_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use GetUsageResponse.ProtoReflect.Descriptor instead.
+func (*GetUsageResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{45}
 }
 
-func (x *GetDownloadURLRequest) GetBlobId() string {
+func (x *GetUsageResponse) GetSubject() *Usage {
 	if x != nil {
-		return x.BlobId
+		return x.Subject
 	}
-	return ""
+	return nil
 }
 
-func (x *GetDownloadURLRequest) GetTtlSeconds() int32 {
+func (x *GetUsageResponse) GetClient() *Usage {
 	if x != nil {
-		return x.TtlSeconds
+		return x.Client
 	}
-	return 0
+	return nil
 }
 
-type GetDownloadURLResponse struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	PresignedGetUrl string                 `protobuf:"bytes,1,opt,name=presigned_get_url,json=presignedGetUrl,proto3" json:"presigned_get_url,omitempty"`
-	UrlExpiresAt    *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=url_expires_at,json=urlExpiresAt,proto3" json:"url_expires_at,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+// A blob under retention or a legal hold is never deleted, whether or not it
+// is still referenced. Every change is recorded in the audit log.
+type SetRetentionRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	BlobId string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	// Must be in the future, and no earlier than any retention already set.
+	RetainUntil   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=retain_until,json=retainUntil,proto3" json:"retain_until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetDownloadURLResponse) Reset() {
-	*x = GetDownloadURLResponse{}
-	mi := &file_blob_v1_blob_proto_msgTypes[13]
+func (x *SetRetentionRequest) Reset() {
+	*x = SetRetentionRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetDownloadURLResponse) String() string {
+func (x *SetRetentionRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetDownloadURLResponse) ProtoMessage() {}
+func (*SetRetentionRequest) ProtoMessage() {}
 
-func (x *GetDownloadURLResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_blob_v1_blob_proto_msgTypes[13]
+func (x *SetRetentionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -812,47 +3145,46 @@ func (x *GetDownloadURLResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetDownloadURLResponse.ProtoReflect.Descriptor instead.
-func (*GetDownloadURLResponse) Descriptor() ([]byte, []int) {
-	return file_blob_v1_blob_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use SetRetentionRequest.ProtoReflect.Descriptor instead.
+func (*SetRetentionRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{46}
 }
 
-func (x *GetDownloadURLResponse) GetPresignedGetUrl() string {
+func (x *SetRetentionRequest) GetBlobId() string {
 	if x != nil {
-		return x.PresignedGetUrl
+		return x.BlobId
 	}
 	return ""
 }
 
-func (x *GetDownloadURLResponse) GetUrlExpiresAt() *timestamppb.Timestamp {
+func (x *SetRetentionRequest) GetRetainUntil() *timestamppb.Timestamp {
 	if x != nil {
-		return x.UrlExpiresAt
+		return x.RetainUntil
 	}
 	return nil
 }
 
-type GetBlobInfoRequest struct {
+type SetRetentionResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetBlobInfoRequest) Reset() {
-	*x = GetBlobInfoRequest{}
-	mi := &file_blob_v1_blob_proto_msgTypes[14]
+func (x *SetRetentionResponse) Reset() {
+	*x = SetRetentionResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetBlobInfoRequest) String() string {
+func (x *SetRetentionResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetBlobInfoRequest) ProtoMessage() {}
+func (*SetRetentionResponse) ProtoMessage() {}
 
-func (x *GetBlobInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_blob_v1_blob_proto_msgTypes[14]
+func (x *SetRetentionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -863,44 +3195,36 @@ func (x *GetBlobInfoRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetBlobInfoRequest.ProtoReflect.Descriptor instead.
-func (*GetBlobInfoRequest) Descriptor() ([]byte, []int) {
-	return file_blob_v1_blob_proto_rawDescGZIP(), []int{14}
-}
-
-func (x *GetBlobInfoRequest) GetBlobId() string {
-	if x != nil {
-		return x.BlobId
-	}
-	return ""
+// Deprecated: Use SetRetentionResponse.ProtoReflect.Descriptor instead.
+func (*SetRetentionResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{47}
 }
 
-type GetBlobInfoResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
-	SizeBytes     int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
-	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
-	UploadState   UploadState            `protobuf:"varint,4,opt,name=upload_state,json=uploadState,proto3,enum=blob.v1.UploadState" json:"upload_state,omitempty"`
-	CommittedAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=committed_at,json=committedAt,proto3" json:"committed_at,omitempty"`
+type SetLegalHoldRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	BlobId string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	// False releases the hold.
+	LegalHold     bool   `protobuf:"varint,2,opt,name=legal_hold,json=legalHold,proto3" json:"legal_hold,omitempty"`
+	Reason        string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetBlobInfoResponse) Reset() {
-	*x = GetBlobInfoResponse{}
-	mi := &file_blob_v1_blob_proto_msgTypes[15]
+func (x *SetLegalHoldRequest) Reset() {
+	*x = SetLegalHoldRequest{}
+	mi := &file_blob_v1_blob_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetBlobInfoResponse) String() string {
+func (x *SetLegalHoldRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetBlobInfoResponse) ProtoMessage() {}
+func (*SetLegalHoldRequest) ProtoMessage() {}
 
-func (x *GetBlobInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_blob_v1_blob_proto_msgTypes[15]
+func (x *SetLegalHoldRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -911,80 +3235,134 @@ func (x *GetBlobInfoResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetBlobInfoResponse.ProtoReflect.Descriptor instead.
-func (*GetBlobInfoResponse) Descriptor() ([]byte, []int) {
-	return file_blob_v1_blob_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use SetLegalHoldRequest.ProtoReflect.Descriptor instead.
+func (*SetLegalHoldRequest) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{48}
 }
 
-func (x *GetBlobInfoResponse) GetBlobId() string {
+func (x *SetLegalHoldRequest) GetBlobId() string {
 	if x != nil {
 		return x.BlobId
 	}
 	return ""
 }
 
-func (x *GetBlobInfoResponse) GetSizeBytes() int64 {
+func (x *SetLegalHoldRequest) GetLegalHold() bool {
 	if x != nil {
-		return x.SizeBytes
+		return x.LegalHold
 	}
-	return 0
+	return false
 }
 
-func (x *GetBlobInfoResponse) GetContentType() string {
+func (x *SetLegalHoldRequest) GetReason() string {
 	if x != nil {
-		return x.ContentType
+		return x.Reason
 	}
 	return ""
 }
 
-func (x *GetBlobInfoResponse) GetUploadState() UploadState {
-	if x != nil {
-		return x.UploadState
-	}
-	return UploadState_UPLOAD_STATE_UNSPECIFIED
+type SetLegalHoldResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetBlobInfoResponse) GetCommittedAt() *timestamppb.Timestamp {
+func (x *SetLegalHoldResponse) Reset() {
+	*x = SetLegalHoldResponse{}
+	mi := &file_blob_v1_blob_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLegalHoldResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLegalHoldResponse) ProtoMessage() {}
+
+func (x *SetLegalHoldResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blob_v1_blob_proto_msgTypes[49]
 	if x != nil {
-		return x.CommittedAt
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLegalHoldResponse.ProtoReflect.Descriptor instead.
+func (*SetLegalHoldResponse) Descriptor() ([]byte, []int) {
+	return file_blob_v1_blob_proto_rawDescGZIP(), []int{49}
 }
 
 var File_blob_v1_blob_proto protoreflect.FileDescriptor
 
 const file_blob_v1_blob_proto_rawDesc = "" +
 	"\n" +
-	"\x12blob/v1/blob.proto\x12\ablob.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\\\n" +
+	"\x12blob/v1/blob.proto\x12\ablob.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf8\x01\n" +
 	"\rPartUploadURL\x12\x1f\n" +
 	"\vpart_number\x18\x01 \x01(\x05R\n" +
 	"partNumber\x12*\n" +
-	"\x11presigned_put_url\x18\x02 \x01(\tR\x0fpresignedPutUrl\"D\n" +
+	"\x11presigned_put_url\x18\x02 \x01(\tR\x0fpresignedPutUrl\x12V\n" +
+	"\x10required_headers\x18\x03 \x03(\v2+.blob.v1.PartUploadURL.RequiredHeadersEntryR\x0frequiredHeaders\x1aB\n" +
+	"\x14RequiredHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"D\n" +
 	"\rCompletedPart\x12\x1f\n" +
 	"\vpart_number\x18\x01 \x01(\x05R\n" +
 	"partNumber\x12\x12\n" +
-	"\x04etag\x18\x02 \x01(\tR\x04etag\"r\n" +
+	"\x04etag\x18\x02 \x01(\tR\x04etag\"b\n" +
+	"\fUploadedPart\x12\x1f\n" +
+	"\vpart_number\x18\x01 \x01(\x05R\n" +
+	"partNumber\x12\x12\n" +
+	"\x04etag\x18\x02 \x01(\tR\x04etag\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x03 \x01(\x03R\tsizeBytes\"c\n" +
+	"\tPartRange\x12\x1f\n" +
+	"\vpart_number\x18\x01 \x01(\x05R\n" +
+	"partNumber\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x03 \x01(\x03R\tsizeBytes\"2\n" +
+	"\x11PlanUploadRequest\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x01 \x01(\x03R\tsizeBytes\"\xa3\x01\n" +
+	"\x12PlanUploadResponse\x12\x1c\n" +
+	"\tmultipart\x18\x01 \x01(\bR\tmultipart\x12&\n" +
+	"\x0fpart_size_bytes\x18\x02 \x01(\x03R\rpartSizeBytes\x12\x1d\n" +
+	"\n" +
+	"part_count\x18\x03 \x01(\x05R\tpartCount\x12(\n" +
+	"\x05parts\x18\x04 \x03(\v2\x12.blob.v1.PartRangeR\x05parts\"\x8c\x01\n" +
 	"\x15InitiateUploadRequest\x12\x17\n" +
 	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12\x1d\n" +
 	"\n" +
 	"size_bytes\x18\x02 \x01(\x03R\tsizeBytes\x12!\n" +
-	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\"\xad\x01\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x18\n" +
+	"\aencrypt\x18\x04 \x01(\bR\aencrypt\"\xd2\x02\n" +
 	"\x16InitiateUploadResponse\x12%\n" +
 	"\x0ealready_exists\x18\x01 \x01(\bR\ralreadyExists\x12*\n" +
 	"\x11presigned_put_url\x18\x02 \x01(\tR\x0fpresignedPutUrl\x12@\n" +
-	"\x0eurl_expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\furlExpiresAt\"0\n" +
+	"\x0eurl_expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\furlExpiresAt\x12_\n" +
+	"\x10required_headers\x18\x04 \x03(\v24.blob.v1.InitiateUploadResponse.RequiredHeadersEntryR\x0frequiredHeaders\x1aB\n" +
+	"\x14RequiredHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"0\n" +
 	"\x15CompleteUploadRequest\x12\x17\n" +
-	"\ablob_id\x18\x01 \x01(\tR\x06blobId\"p\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\"\xa9\x01\n" +
 	"\x16CompleteUploadResponse\x12\x17\n" +
 	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12=\n" +
-	"\fcommitted_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\vcommittedAt\"\x9a\x01\n" +
+	"\fcommitted_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\vcommittedAt\x127\n" +
+	"\fupload_state\x18\x03 \x01(\x0e2\x14.blob.v1.UploadStateR\vuploadState\"\xb4\x01\n" +
 	"\x1eInitiateMultipartUploadRequest\x12\x17\n" +
 	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12\x1d\n" +
 	"\n" +
 	"size_bytes\x18\x02 \x01(\x03R\tsizeBytes\x12!\n" +
 	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x1d\n" +
 	"\n" +
-	"part_count\x18\x04 \x01(\x05R\tpartCount\"\xd5\x01\n" +
+	"part_count\x18\x04 \x01(\x05R\tpartCount\x12\x18\n" +
+	"\aencrypt\x18\x05 \x01(\bR\aencrypt\"\xd5\x01\n" +
 	"\x1fInitiateMultipartUploadResponse\x12%\n" +
 	"\x0ealready_exists\x18\x01 \x01(\bR\ralreadyExists\x12\x1b\n" +
 	"\tupload_id\x18\x02 \x01(\tR\buploadId\x12,\n" +
@@ -993,42 +3371,219 @@ const file_blob_v1_blob_proto_rawDesc = "" +
 	"\x1eCompleteMultipartUploadRequest\x12\x17\n" +
 	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12\x1b\n" +
 	"\tupload_id\x18\x02 \x01(\tR\buploadId\x12,\n" +
-	"\x05parts\x18\x03 \x03(\v2\x16.blob.v1.CompletedPartR\x05parts\"y\n" +
+	"\x05parts\x18\x03 \x03(\v2\x16.blob.v1.CompletedPartR\x05parts\"\xb2\x01\n" +
 	"\x1fCompleteMultipartUploadResponse\x12\x17\n" +
 	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12=\n" +
-	"\fcommitted_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\vcommittedAt\"S\n" +
+	"\fcommitted_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\vcommittedAt\x127\n" +
+	"\fupload_state\x18\x03 \x01(\x0e2\x14.blob.v1.UploadStateR\vuploadState\"S\n" +
 	"\x1bAbortMultipartUploadRequest\x12\x17\n" +
 	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12\x1b\n" +
 	"\tupload_id\x18\x02 \x01(\tR\buploadId\"\x1e\n" +
-	"\x1cAbortMultipartUploadResponse\"Q\n" +
+	"\x1cAbortMultipartUploadResponse\"7\n" +
+	"\x1cResumeMultipartUploadRequest\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\"\xa0\x02\n" +
+	"\x1dResumeMultipartUploadResponse\x12%\n" +
+	"\x0ealready_exists\x18\x01 \x01(\bR\ralreadyExists\x12\x1b\n" +
+	"\tupload_id\x18\x02 \x01(\tR\buploadId\x12<\n" +
+	"\x0euploaded_parts\x18\x03 \x03(\v2\x15.blob.v1.UploadedPartR\ruploadedParts\x12;\n" +
+	"\rmissing_parts\x18\x04 \x03(\v2\x16.blob.v1.PartUploadURLR\fmissingParts\x12@\n" +
+	"\x0eurl_expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\furlExpiresAt\"u\n" +
+	"\x13UploadStreamRequest\x12;\n" +
+	"\bmetadata\x18\x01 \x01(\v2\x1d.blob.v1.UploadStreamMetadataH\x00R\bmetadata\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\t\n" +
+	"\apayload\"}\n" +
+	"\x14UploadStreamMetadata\x12!\n" +
+	"\fcontent_type\x18\x01 \x01(\tR\vcontentType\x12(\n" +
+	"\x10expected_blob_id\x18\x02 \x01(\tR\x0eexpectedBlobId\x12\x18\n" +
+	"\aencrypt\x18\x03 \x01(\bR\aencrypt\"\xed\x01\n" +
+	"\x14UploadStreamResponse\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x02 \x01(\x03R\tsizeBytes\x12%\n" +
+	"\x0ealready_exists\x18\x03 \x01(\bR\ralreadyExists\x12=\n" +
+	"\fcommitted_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vcommittedAt\x127\n" +
+	"\fupload_state\x18\x05 \x01(\x0e2\x14.blob.v1.UploadStateR\vuploadState\"`\n" +
+	"\x15DownloadStreamRequest\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x03R\x06length\"p\n" +
+	"\x16DownloadStreamResponse\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\fR\x05chunk\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x03 \x01(\x03R\tsizeBytes\"\xcb\x02\n" +
 	"\x15GetDownloadURLRequest\x12\x17\n" +
 	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12\x1f\n" +
 	"\vttl_seconds\x18\x02 \x01(\x05R\n" +
-	"ttlSeconds\"\x86\x01\n" +
+	"ttlSeconds\x12=\n" +
+	"\vdisposition\x18\x03 \x01(\x0e2\x1b.blob.v1.ContentDispositionR\vdisposition\x12\x1a\n" +
+	"\bfilename\x18\x04 \x01(\tR\bfilename\x122\n" +
+	"\x15response_content_type\x18\x05 \x01(\tR\x13responseContentType\x12#\n" +
+	"\rcache_control\x18\x06 \x01(\tR\fcacheControl\x12!\n" +
+	"\frange_offset\x18\a \x01(\x03R\vrangeOffset\x12!\n" +
+	"\frange_length\x18\b \x01(\x03R\vrangeLength\"\xab\x02\n" +
 	"\x16GetDownloadURLResponse\x12*\n" +
 	"\x11presigned_get_url\x18\x01 \x01(\tR\x0fpresignedGetUrl\x12@\n" +
-	"\x0eurl_expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\furlExpiresAt\"-\n" +
+	"\x0eurl_expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\furlExpiresAt\x12_\n" +
+	"\x10required_headers\x18\x03 \x03(\v24.blob.v1.GetDownloadURLResponse.RequiredHeadersEntryR\x0frequiredHeaders\x1aB\n" +
+	"\x14RequiredHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"f\n" +
+	"\rRenditionSpec\x120\n" +
+	"\x06format\x18\x01 \x01(\x0e2\x18.blob.v1.RenditionFormatR\x06format\x12#\n" +
+	"\rmax_dimension\x18\x02 \x01(\x05R\fmaxDimension\"{\n" +
+	"\x13GetRenditionRequest\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12*\n" +
+	"\x04spec\x18\x02 \x01(\v2\x16.blob.v1.RenditionSpecR\x04spec\x12\x1f\n" +
+	"\vttl_seconds\x18\x03 \x01(\x05R\n" +
+	"ttlSeconds\"\x95\x03\n" +
+	"\x14GetRenditionResponse\x12*\n" +
+	"\x11rendition_blob_id\x18\x01 \x01(\tR\x0frenditionBlobId\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x02 \x01(\x03R\tsizeBytes\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12*\n" +
+	"\x11presigned_get_url\x18\x04 \x01(\tR\x0fpresignedGetUrl\x12@\n" +
+	"\x0eurl_expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\furlExpiresAt\x12]\n" +
+	"\x10required_headers\x18\x06 \x03(\v22.blob.v1.GetRenditionResponse.RequiredHeadersEntryR\x0frequiredHeaders\x1aB\n" +
+	"\x14RequiredHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"-\n" +
 	"\x12GetBlobInfoRequest\x12\x17\n" +
-	"\ablob_id\x18\x01 \x01(\tR\x06blobId\"\xe8\x01\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\"\x98\x03\n" +
 	"\x13GetBlobInfoResponse\x12\x17\n" +
 	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12\x1d\n" +
 	"\n" +
 	"size_bytes\x18\x02 \x01(\x03R\tsizeBytes\x12!\n" +
 	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x127\n" +
 	"\fupload_state\x18\x04 \x01(\x0e2\x14.blob.v1.UploadStateR\vuploadState\x12=\n" +
-	"\fcommitted_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vcommittedAt*G\n" +
+	"\fcommitted_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vcommittedAt\x122\n" +
+	"\x15detected_content_type\x18\x06 \x01(\tR\x13detectedContentType\x12\x1c\n" +
+	"\tencrypted\x18\a \x01(\bR\tencrypted\x12=\n" +
+	"\fretain_until\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\vretainUntil\x12\x1d\n" +
+	"\n" +
+	"legal_hold\x18\t \x01(\bR\tlegalHold\"\xc8\x03\n" +
+	"\bBlobInfo\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x02 \x01(\x03R\tsizeBytes\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x127\n" +
+	"\fupload_state\x18\x04 \x01(\x0e2\x14.blob.v1.UploadStateR\vuploadState\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12=\n" +
+	"\fcommitted_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vcommittedAt\x122\n" +
+	"\x15detected_content_type\x18\a \x01(\tR\x13detectedContentType\x12\x1c\n" +
+	"\tencrypted\x18\b \x01(\bR\tencrypted\x12=\n" +
+	"\fretain_until\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\vretainUntil\x12\x1d\n" +
+	"\n" +
+	"legal_hold\x18\n" +
+	" \x01(\bR\tlegalHold\"4\n" +
+	"\x17BatchGetBlobInfoRequest\x12\x19\n" +
+	"\bblob_ids\x18\x01 \x03(\tR\ablobIds\"\x94\x01\n" +
+	"\x0eBlobInfoResult\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12%\n" +
+	"\x04info\x18\x02 \x01(\v2\x11.blob.v1.BlobInfoR\x04info\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x03 \x01(\x05R\terrorCode\x12#\n" +
+	"\rerror_message\x18\x04 \x01(\tR\ferrorMessage\"M\n" +
+	"\x18BatchGetBlobInfoResponse\x121\n" +
+	"\aresults\x18\x01 \x03(\v2\x17.blob.v1.BlobInfoResultR\aresults\"\xad\x03\n" +
+	"\x10ListBlobsRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12*\n" +
+	"\x05state\x18\x03 \x01(\x0e2\x14.blob.v1.UploadStateR\x05state\x12!\n" +
+	"\fcontent_type\x18\x04 \x01(\tR\vcontentType\x12?\n" +
+	"\rcreated_after\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\fcreatedAfter\x12A\n" +
+	"\x0ecreated_before\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\rcreatedBefore\x12C\n" +
+	"\x0fcommitted_after\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x0ecommittedAfter\x12E\n" +
+	"\x10committed_before\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\x0fcommittedBefore\"d\n" +
+	"\x11ListBlobsResponse\x12'\n" +
+	"\x05blobs\x18\x01 \x03(\v2\x11.blob.v1.BlobInfoR\x05blobs\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xeb\x01\n" +
+	"\tBlobEvent\x12\x1a\n" +
+	"\bsequence\x18\x01 \x01(\x03R\bsequence\x12*\n" +
+	"\x04kind\x18\x02 \x01(\x0e2\x16.blob.v1.BlobEventKindR\x04kind\x12\x17\n" +
+	"\ablob_id\x18\x03 \x01(\tR\x06blobId\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x04 \x01(\x03R\tsizeBytes\x12!\n" +
+	"\fcontent_type\x18\x05 \x01(\tR\vcontentType\x12;\n" +
+	"\voccurred_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\"?\n" +
+	"\x16WatchBlobEventsRequest\x12%\n" +
+	"\x0eafter_sequence\x18\x01 \x01(\x03R\rafterSequence\"C\n" +
+	"\x17WatchBlobEventsResponse\x12(\n" +
+	"\x05event\x18\x01 \x01(\v2\x12.blob.v1.BlobEventR\x05event\"H\n" +
+	"\x13AddReferenceRequest\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12\x18\n" +
+	"\asubject\x18\x02 \x01(\tR\asubject\"\x16\n" +
+	"\x14AddReferenceResponse\"2\n" +
+	"\x17ReleaseReferenceRequest\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\"\x1a\n" +
+	"\x18ReleaseReferenceResponse\"\x9f\x01\n" +
+	"\x05Usage\x12\x14\n" +
+	"\x05bytes\x18\x01 \x01(\x03R\x05bytes\x12\x18\n" +
+	"\aobjects\x18\x02 \x01(\x03R\aobjects\x12\x1b\n" +
+	"\tmax_bytes\x18\x03 \x01(\x03R\bmaxBytes\x12\x1f\n" +
+	"\vmax_objects\x18\x04 \x01(\x03R\n" +
+	"maxObjects\x12(\n" +
+	"\x10max_object_bytes\x18\x05 \x01(\x03R\x0emaxObjectBytes\"\x11\n" +
+	"\x0fGetUsageRequest\"d\n" +
+	"\x10GetUsageResponse\x12(\n" +
+	"\asubject\x18\x01 \x01(\v2\x0e.blob.v1.UsageR\asubject\x12&\n" +
+	"\x06client\x18\x02 \x01(\v2\x0e.blob.v1.UsageR\x06client\"m\n" +
+	"\x13SetRetentionRequest\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12=\n" +
+	"\fretain_until\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\vretainUntil\"\x16\n" +
+	"\x14SetRetentionResponse\"e\n" +
+	"\x13SetLegalHoldRequest\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12\x1d\n" +
+	"\n" +
+	"legal_hold\x18\x02 \x01(\bR\tlegalHold\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\x16\n" +
+	"\x14SetLegalHoldResponse*s\n" +
 	"\vUploadState\x12\x1c\n" +
 	"\x18UPLOAD_STATE_UNSPECIFIED\x10\x00\x12\v\n" +
 	"\aPENDING\x10\x01\x12\r\n" +
-	"\tCOMMITTED\x10\x022\x91\x05\n" +
-	"\vBlobService\x12Q\n" +
+	"\tCOMMITTED\x10\x02\x12\f\n" +
+	"\bSCANNING\x10\x03\x12\x0f\n" +
+	"\vQUARANTINED\x10\x04\x12\v\n" +
+	"\aMISSING\x10\x05*U\n" +
+	"\x12ContentDisposition\x12#\n" +
+	"\x1fCONTENT_DISPOSITION_UNSPECIFIED\x10\x00\x12\n" +
+	"\n" +
+	"\x06INLINE\x10\x01\x12\x0e\n" +
+	"\n" +
+	"ATTACHMENT\x10\x02*G\n" +
+	"\x0fRenditionFormat\x12 \n" +
+	"\x1cRENDITION_FORMAT_UNSPECIFIED\x10\x00\x12\b\n" +
+	"\x04JPEG\x10\x01\x12\b\n" +
+	"\x04WEBP\x10\x02*V\n" +
+	"\rBlobEventKind\x12\x1f\n" +
+	"\x1bBLOB_EVENT_KIND_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eBLOB_COMMITTED\x10\x01\x12\x10\n" +
+	"\fBLOB_DELETED\x10\x022\xa7\r\n" +
+	"\vBlobService\x12E\n" +
+	"\n" +
+	"PlanUpload\x12\x1a.blob.v1.PlanUploadRequest\x1a\x1b.blob.v1.PlanUploadResponse\x12Q\n" +
 	"\x0eInitiateUpload\x12\x1e.blob.v1.InitiateUploadRequest\x1a\x1f.blob.v1.InitiateUploadResponse\x12Q\n" +
 	"\x0eCompleteUpload\x12\x1e.blob.v1.CompleteUploadRequest\x1a\x1f.blob.v1.CompleteUploadResponse\x12l\n" +
 	"\x17InitiateMultipartUpload\x12'.blob.v1.InitiateMultipartUploadRequest\x1a(.blob.v1.InitiateMultipartUploadResponse\x12l\n" +
 	"\x17CompleteMultipartUpload\x12'.blob.v1.CompleteMultipartUploadRequest\x1a(.blob.v1.CompleteMultipartUploadResponse\x12c\n" +
-	"\x14AbortMultipartUpload\x12$.blob.v1.AbortMultipartUploadRequest\x1a%.blob.v1.AbortMultipartUploadResponse\x12Q\n" +
-	"\x0eGetDownloadURL\x12\x1e.blob.v1.GetDownloadURLRequest\x1a\x1f.blob.v1.GetDownloadURLResponse\x12H\n" +
-	"\vGetBlobInfo\x12\x1b.blob.v1.GetBlobInfoRequest\x1a\x1c.blob.v1.GetBlobInfoResponseB:Z8github.com/barn0w1/hss-science/server/gen/blob/v1;blobv1b\x06proto3"
+	"\x14AbortMultipartUpload\x12$.blob.v1.AbortMultipartUploadRequest\x1a%.blob.v1.AbortMultipartUploadResponse\x12f\n" +
+	"\x15ResumeMultipartUpload\x12%.blob.v1.ResumeMultipartUploadRequest\x1a&.blob.v1.ResumeMultipartUploadResponse\x12M\n" +
+	"\fUploadStream\x12\x1c.blob.v1.UploadStreamRequest\x1a\x1d.blob.v1.UploadStreamResponse(\x01\x12S\n" +
+	"\x0eDownloadStream\x12\x1e.blob.v1.DownloadStreamRequest\x1a\x1f.blob.v1.DownloadStreamResponse0\x01\x12Q\n" +
+	"\x0eGetDownloadURL\x12\x1e.blob.v1.GetDownloadURLRequest\x1a\x1f.blob.v1.GetDownloadURLResponse\x12K\n" +
+	"\fGetRendition\x12\x1c.blob.v1.GetRenditionRequest\x1a\x1d.blob.v1.GetRenditionResponse\x12H\n" +
+	"\vGetBlobInfo\x12\x1b.blob.v1.GetBlobInfoRequest\x1a\x1c.blob.v1.GetBlobInfoResponse\x12W\n" +
+	"\x10BatchGetBlobInfo\x12 .blob.v1.BatchGetBlobInfoRequest\x1a!.blob.v1.BatchGetBlobInfoResponse\x12B\n" +
+	"\tListBlobs\x12\x19.blob.v1.ListBlobsRequest\x1a\x1a.blob.v1.ListBlobsResponse\x12V\n" +
+	"\x0fWatchBlobEvents\x12\x1f.blob.v1.WatchBlobEventsRequest\x1a .blob.v1.WatchBlobEventsResponse0\x01\x12K\n" +
+	"\fAddReference\x12\x1c.blob.v1.AddReferenceRequest\x1a\x1d.blob.v1.AddReferenceResponse\x12W\n" +
+	"\x10ReleaseReference\x12 .blob.v1.ReleaseReferenceRequest\x1a!.blob.v1.ReleaseReferenceResponse\x12?\n" +
+	"\bGetUsage\x12\x18.blob.v1.GetUsageRequest\x1a\x19.blob.v1.GetUsageResponse\x12K\n" +
+	"\fSetRetention\x12\x1c.blob.v1.SetRetentionRequest\x1a\x1d.blob.v1.SetRetentionResponse\x12K\n" +
+	"\fSetLegalHold\x12\x1c.blob.v1.SetLegalHoldRequest\x1a\x1d.blob.v1.SetLegalHoldResponseB:Z8github.com/barn0w1/hss-science/server/gen/blob/v1;blobv1b\x06proto3"
 
 var (
 	file_blob_v1_blob_proto_rawDescOnce sync.Once
@@ -1042,57 +3597,160 @@ func file_blob_v1_blob_proto_rawDescGZIP() []byte {
 	return file_blob_v1_blob_proto_rawDescData
 }
 
-var file_blob_v1_blob_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_blob_v1_blob_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_blob_v1_blob_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_blob_v1_blob_proto_msgTypes = make([]protoimpl.MessageInfo, 54)
 var file_blob_v1_blob_proto_goTypes = []any{
 	(UploadState)(0),                        // 0: blob.v1.UploadState
-	(*PartUploadURL)(nil),                   // 1: blob.v1.PartUploadURL
-	(*CompletedPart)(nil),                   // 2: blob.v1.CompletedPart
-	(*InitiateUploadRequest)(nil),           // 3: blob.v1.InitiateUploadRequest
-	(*InitiateUploadResponse)(nil),          // 4: blob.v1.InitiateUploadResponse
-	(*CompleteUploadRequest)(nil),           // 5: blob.v1.CompleteUploadRequest
-	(*CompleteUploadResponse)(nil),          // 6: blob.v1.CompleteUploadResponse
-	(*InitiateMultipartUploadRequest)(nil),  // 7: blob.v1.InitiateMultipartUploadRequest
-	(*InitiateMultipartUploadResponse)(nil), // 8: blob.v1.InitiateMultipartUploadResponse
-	(*CompleteMultipartUploadRequest)(nil),  // 9: blob.v1.CompleteMultipartUploadRequest
-	(*CompleteMultipartUploadResponse)(nil), // 10: blob.v1.CompleteMultipartUploadResponse
-	(*AbortMultipartUploadRequest)(nil),     // 11: blob.v1.AbortMultipartUploadRequest
-	(*AbortMultipartUploadResponse)(nil),    // 12: blob.v1.AbortMultipartUploadResponse
-	(*GetDownloadURLRequest)(nil),           // 13: blob.v1.GetDownloadURLRequest
-	(*GetDownloadURLResponse)(nil),          // 14: blob.v1.GetDownloadURLResponse
-	(*GetBlobInfoRequest)(nil),              // 15: blob.v1.GetBlobInfoRequest
-	(*GetBlobInfoResponse)(nil),             // 16: blob.v1.GetBlobInfoResponse
-	(*timestamppb.Timestamp)(nil),           // 17: google.protobuf.Timestamp
+	(ContentDisposition)(0),                 // 1: blob.v1.ContentDisposition
+	(RenditionFormat)(0),                    // 2: blob.v1.RenditionFormat
+	(BlobEventKind)(0),                      // 3: blob.v1.BlobEventKind
+	(*PartUploadURL)(nil),                   // 4: blob.v1.PartUploadURL
+	(*CompletedPart)(nil),                   // 5: blob.v1.CompletedPart
+	(*UploadedPart)(nil),                    // 6: blob.v1.UploadedPart
+	(*PartRange)(nil),                       // 7: blob.v1.PartRange
+	(*PlanUploadRequest)(nil),               // 8: blob.v1.PlanUploadRequest
+	(*PlanUploadResponse)(nil),              // 9: blob.v1.PlanUploadResponse
+	(*InitiateUploadRequest)(nil),           // 10: blob.v1.InitiateUploadRequest
+	(*InitiateUploadResponse)(nil),          // 11: blob.v1.InitiateUploadResponse
+	(*CompleteUploadRequest)(nil),           // 12: blob.v1.CompleteUploadRequest
+	(*CompleteUploadResponse)(nil),          // 13: blob.v1.CompleteUploadResponse
+	(*InitiateMultipartUploadRequest)(nil),  // 14: blob.v1.InitiateMultipartUploadRequest
+	(*InitiateMultipartUploadResponse)(nil), // 15: blob.v1.InitiateMultipartUploadResponse
+	(*CompleteMultipartUploadRequest)(nil),  // 16: blob.v1.CompleteMultipartUploadRequest
+	(*CompleteMultipartUploadResponse)(nil), // 17: blob.v1.CompleteMultipartUploadResponse
+	(*AbortMultipartUploadRequest)(nil),     // 18: blob.v1.AbortMultipartUploadRequest
+	(*AbortMultipartUploadResponse)(nil),    // 19: blob.v1.AbortMultipartUploadResponse
+	(*ResumeMultipartUploadRequest)(nil),    // 20: blob.v1.ResumeMultipartUploadRequest
+	(*ResumeMultipartUploadResponse)(nil),   // 21: blob.v1.ResumeMultipartUploadResponse
+	(*UploadStreamRequest)(nil),             // 22: blob.v1.UploadStreamRequest
+	(*UploadStreamMetadata)(nil),            // 23: blob.v1.UploadStreamMetadata
+	(*UploadStreamResponse)(nil),            // 24: blob.v1.UploadStreamResponse
+	(*DownloadStreamRequest)(nil),           // 25: blob.v1.DownloadStreamRequest
+	(*DownloadStreamResponse)(nil),          // 26: blob.v1.DownloadStreamResponse
+	(*GetDownloadURLRequest)(nil),           // 27: blob.v1.GetDownloadURLRequest
+	(*GetDownloadURLResponse)(nil),          // 28: blob.v1.GetDownloadURLResponse
+	(*RenditionSpec)(nil),                   // 29: blob.v1.RenditionSpec
+	(*GetRenditionRequest)(nil),             // 30: blob.v1.GetRenditionRequest
+	(*GetRenditionResponse)(nil),            // 31: blob.v1.GetRenditionResponse
+	(*GetBlobInfoRequest)(nil),              // 32: blob.v1.GetBlobInfoRequest
+	(*GetBlobInfoResponse)(nil),             // 33: blob.v1.GetBlobInfoResponse
+	(*BlobInfo)(nil),                        // 34: blob.v1.BlobInfo
+	(*BatchGetBlobInfoRequest)(nil),         // 35: blob.v1.BatchGetBlobInfoRequest
+	(*BlobInfoResult)(nil),                  // 36: blob.v1.BlobInfoResult
+	(*BatchGetBlobInfoResponse)(nil),        // 37: blob.v1.BatchGetBlobInfoResponse
+	(*ListBlobsRequest)(nil),                // 38: blob.v1.ListBlobsRequest
+	(*ListBlobsResponse)(nil),               // 39: blob.v1.ListBlobsResponse
+	(*BlobEvent)(nil),                       // 40: blob.v1.BlobEvent
+	(*WatchBlobEventsRequest)(nil),          // 41: blob.v1.WatchBlobEventsRequest
+	(*WatchBlobEventsResponse)(nil),         // 42: blob.v1.WatchBlobEventsResponse
+	(*AddReferenceRequest)(nil),             // 43: blob.v1.AddReferenceRequest
+	(*AddReferenceResponse)(nil),            // 44: blob.v1.AddReferenceResponse
+	(*ReleaseReferenceRequest)(nil),         // 45: blob.v1.ReleaseReferenceRequest
+	(*ReleaseReferenceResponse)(nil),        // 46: blob.v1.ReleaseReferenceResponse
+	(*Usage)(nil),                           // 47: blob.v1.Usage
+	(*GetUsageRequest)(nil),                 // 48: blob.v1.GetUsageRequest
+	(*GetUsageResponse)(nil),                // 49: blob.v1.GetUsageResponse
+	(*SetRetentionRequest)(nil),             // 50: blob.v1.SetRetentionRequest
+	(*SetRetentionResponse)(nil),            // 51: blob.v1.SetRetentionResponse
+	(*SetLegalHoldRequest)(nil),             // 52: blob.v1.SetLegalHoldRequest
+	(*SetLegalHoldResponse)(nil),            // 53: blob.v1.SetLegalHoldResponse
+	nil,                                     // 54: blob.v1.PartUploadURL.RequiredHeadersEntry
+	nil,                                     // 55: blob.v1.InitiateUploadResponse.RequiredHeadersEntry
+	nil,                                     // 56: blob.v1.GetDownloadURLResponse.RequiredHeadersEntry
+	nil,                                     // 57: blob.v1.GetRenditionResponse.RequiredHeadersEntry
+	(*timestamppb.Timestamp)(nil),           // 58: google.protobuf.Timestamp
 }
 var file_blob_v1_blob_proto_depIdxs = []int32{
-	17, // 0: blob.v1.InitiateUploadResponse.url_expires_at:type_name -> google.protobuf.Timestamp
-	17, // 1: blob.v1.CompleteUploadResponse.committed_at:type_name -> google.protobuf.Timestamp
-	1,  // 2: blob.v1.InitiateMultipartUploadResponse.parts:type_name -> blob.v1.PartUploadURL
-	17, // 3: blob.v1.InitiateMultipartUploadResponse.url_expires_at:type_name -> google.protobuf.Timestamp
-	2,  // 4: blob.v1.CompleteMultipartUploadRequest.parts:type_name -> blob.v1.CompletedPart
-	17, // 5: blob.v1.CompleteMultipartUploadResponse.committed_at:type_name -> google.protobuf.Timestamp
-	17, // 6: blob.v1.GetDownloadURLResponse.url_expires_at:type_name -> google.protobuf.Timestamp
-	0,  // 7: blob.v1.GetBlobInfoResponse.upload_state:type_name -> blob.v1.UploadState
-	17, // 8: blob.v1.GetBlobInfoResponse.committed_at:type_name -> google.protobuf.Timestamp
-	3,  // 9: blob.v1.BlobService.InitiateUpload:input_type -> blob.v1.InitiateUploadRequest
-	5,  // 10: blob.v1.BlobService.CompleteUpload:input_type -> blob.v1.CompleteUploadRequest
-	7,  // 11: blob.v1.BlobService.InitiateMultipartUpload:input_type -> blob.v1.InitiateMultipartUploadRequest
-	9,  // 12: blob.v1.BlobService.CompleteMultipartUpload:input_type -> blob.v1.CompleteMultipartUploadRequest
-	11, // 13: blob.v1.BlobService.AbortMultipartUpload:input_type -> blob.v1.AbortMultipartUploadRequest
-	13, // 14: blob.v1.BlobService.GetDownloadURL:input_type -> blob.v1.GetDownloadURLRequest
-	15, // 15: blob.v1.BlobService.GetBlobInfo:input_type -> blob.v1.GetBlobInfoRequest
-	4,  // 16: blob.v1.BlobService.InitiateUpload:output_type -> blob.v1.InitiateUploadResponse
-	6,  // 17: blob.v1.BlobService.CompleteUpload:output_type -> blob.v1.CompleteUploadResponse
-	8,  // 18: blob.v1.BlobService.InitiateMultipartUpload:output_type -> blob.v1.InitiateMultipartUploadResponse
-	10, // 19: blob.v1.BlobService.CompleteMultipartUpload:output_type -> blob.v1.CompleteMultipartUploadResponse
-	12, // 20: blob.v1.BlobService.AbortMultipartUpload:output_type -> blob.v1.AbortMultipartUploadResponse
-	14, // 21: blob.v1.BlobService.GetDownloadURL:output_type -> blob.v1.GetDownloadURLResponse
-	16, // 22: blob.v1.BlobService.GetBlobInfo:output_type -> blob.v1.GetBlobInfoResponse
-	16, // [16:23] is the sub-list for method output_type
-	9,  // [9:16] is the sub-list for method input_type
-	9,  // [9:9] is the sub-list for extension type_name
-	9,  // [9:9] is the sub-list for extension extendee
-	0,  // [0:9] is the sub-list for field type_name
+	54, // 0: blob.v1.PartUploadURL.required_headers:type_name -> blob.v1.PartUploadURL.RequiredHeadersEntry
+	7,  // 1: blob.v1.PlanUploadResponse.parts:type_name -> blob.v1.PartRange
+	58, // 2: blob.v1.InitiateUploadResponse.url_expires_at:type_name -> google.protobuf.Timestamp
+	55, // 3: blob.v1.InitiateUploadResponse.required_headers:type_name -> blob.v1.InitiateUploadResponse.RequiredHeadersEntry
+	58, // 4: blob.v1.CompleteUploadResponse.committed_at:type_name -> google.protobuf.Timestamp
+	0,  // 5: blob.v1.CompleteUploadResponse.upload_state:type_name -> blob.v1.UploadState
+	4,  // 6: blob.v1.InitiateMultipartUploadResponse.parts:type_name -> blob.v1.PartUploadURL
+	58, // 7: blob.v1.InitiateMultipartUploadResponse.url_expires_at:type_name -> google.protobuf.Timestamp
+	5,  // 8: blob.v1.CompleteMultipartUploadRequest.parts:type_name -> blob.v1.CompletedPart
+	58, // 9: blob.v1.CompleteMultipartUploadResponse.committed_at:type_name -> google.protobuf.Timestamp
+	0,  // 10: blob.v1.CompleteMultipartUploadResponse.upload_state:type_name -> blob.v1.UploadState
+	6,  // 11: blob.v1.ResumeMultipartUploadResponse.uploaded_parts:type_name -> blob.v1.UploadedPart
+	4,  // 12: blob.v1.ResumeMultipartUploadResponse.missing_parts:type_name -> blob.v1.PartUploadURL
+	58, // 13: blob.v1.ResumeMultipartUploadResponse.url_expires_at:type_name -> google.protobuf.Timestamp
+	23, // 14: blob.v1.UploadStreamRequest.metadata:type_name -> blob.v1.UploadStreamMetadata
+	58, // 15: blob.v1.UploadStreamResponse.committed_at:type_name -> google.protobuf.Timestamp
+	0,  // 16: blob.v1.UploadStreamResponse.upload_state:type_name -> blob.v1.UploadState
+	1,  // 17: blob.v1.GetDownloadURLRequest.disposition:type_name -> blob.v1.ContentDisposition
+	58, // 18: blob.v1.GetDownloadURLResponse.url_expires_at:type_name -> google.protobuf.Timestamp
+	56, // 19: blob.v1.GetDownloadURLResponse.required_headers:type_name -> blob.v1.GetDownloadURLResponse.RequiredHeadersEntry
+	2,  // 20: blob.v1.RenditionSpec.format:type_name -> blob.v1.RenditionFormat
+	29, // 21: blob.v1.GetRenditionRequest.spec:type_name -> blob.v1.RenditionSpec
+	58, // 22: blob.v1.GetRenditionResponse.url_expires_at:type_name -> google.protobuf.Timestamp
+	57, // 23: blob.v1.GetRenditionResponse.required_headers:type_name -> blob.v1.GetRenditionResponse.RequiredHeadersEntry
+	0,  // 24: blob.v1.GetBlobInfoResponse.upload_state:type_name -> blob.v1.UploadState
+	58, // 25: blob.v1.GetBlobInfoResponse.committed_at:type_name -> google.protobuf.Timestamp
+	58, // 26: blob.v1.GetBlobInfoResponse.retain_until:type_name -> google.protobuf.Timestamp
+	0,  // 27: blob.v1.BlobInfo.upload_state:type_name -> blob.v1.UploadState
+	58, // 28: blob.v1.BlobInfo.created_at:type_name -> google.protobuf.Timestamp
+	58, // 29: blob.v1.BlobInfo.committed_at:type_name -> google.protobuf.Timestamp
+	58, // 30: blob.v1.BlobInfo.retain_until:type_name -> google.protobuf.Timestamp
+	34, // 31: blob.v1.BlobInfoResult.info:type_name -> blob.v1.BlobInfo
+	36, // 32: blob.v1.BatchGetBlobInfoResponse.results:type_name -> blob.v1.BlobInfoResult
+	0,  // 33: blob.v1.ListBlobsRequest.state:type_name -> blob.v1.UploadState
+	58, // 34: blob.v1.ListBlobsRequest.created_after:type_name -> google.protobuf.Timestamp
+	58, // 35: blob.v1.ListBlobsRequest.created_before:type_name -> google.protobuf.Timestamp
+	58, // 36: blob.v1.ListBlobsRequest.committed_after:type_name -> google.protobuf.Timestamp
+	58, // 37: blob.v1.ListBlobsRequest.committed_before:type_name -> google.protobuf.Timestamp
+	34, // 38: blob.v1.ListBlobsResponse.blobs:type_name -> blob.v1.BlobInfo
+	3,  // 39: blob.v1.BlobEvent.kind:type_name -> blob.v1.BlobEventKind
+	58, // 40: blob.v1.BlobEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	40, // 41: blob.v1.WatchBlobEventsResponse.event:type_name -> blob.v1.BlobEvent
+	47, // 42: blob.v1.GetUsageResponse.subject:type_name -> blob.v1.Usage
+	47, // 43: blob.v1.GetUsageResponse.client:type_name -> blob.v1.Usage
+	58, // 44: blob.v1.SetRetentionRequest.retain_until:type_name -> google.protobuf.Timestamp
+	8,  // 45: blob.v1.BlobService.PlanUpload:input_type -> blob.v1.PlanUploadRequest
+	10, // 46: blob.v1.BlobService.InitiateUpload:input_type -> blob.v1.InitiateUploadRequest
+	12, // 47: blob.v1.BlobService.CompleteUpload:input_type -> blob.v1.CompleteUploadRequest
+	14, // 48: blob.v1.BlobService.InitiateMultipartUpload:input_type -> blob.v1.InitiateMultipartUploadRequest
+	16, // 49: blob.v1.BlobService.CompleteMultipartUpload:input_type -> blob.v1.CompleteMultipartUploadRequest
+	18, // 50: blob.v1.BlobService.AbortMultipartUpload:input_type -> blob.v1.AbortMultipartUploadRequest
+	20, // 51: blob.v1.BlobService.ResumeMultipartUpload:input_type -> blob.v1.ResumeMultipartUploadRequest
+	22, // 52: blob.v1.BlobService.UploadStream:input_type -> blob.v1.UploadStreamRequest
+	25, // 53: blob.v1.BlobService.DownloadStream:input_type -> blob.v1.DownloadStreamRequest
+	27, // 54: blob.v1.BlobService.GetDownloadURL:input_type -> blob.v1.GetDownloadURLRequest
+	30, // 55: blob.v1.BlobService.GetRendition:input_type -> blob.v1.GetRenditionRequest
+	32, // 56: blob.v1.BlobService.GetBlobInfo:input_type -> blob.v1.GetBlobInfoRequest
+	35, // 57: blob.v1.BlobService.BatchGetBlobInfo:input_type -> blob.v1.BatchGetBlobInfoRequest
+	38, // 58: blob.v1.BlobService.ListBlobs:input_type -> blob.v1.ListBlobsRequest
+	41, // 59: blob.v1.BlobService.WatchBlobEvents:input_type -> blob.v1.WatchBlobEventsRequest
+	43, // 60: blob.v1.BlobService.AddReference:input_type -> blob.v1.AddReferenceRequest
+	45, // 61: blob.v1.BlobService.ReleaseReference:input_type -> blob.v1.ReleaseReferenceRequest
+	48, // 62: blob.v1.BlobService.GetUsage:input_type -> blob.v1.GetUsageRequest
+	50, // 63: blob.v1.BlobService.SetRetention:input_type -> blob.v1.SetRetentionRequest
+	52, // 64: blob.v1.BlobService.SetLegalHold:input_type -> blob.v1.SetLegalHoldRequest
+	9,  // 65: blob.v1.BlobService.PlanUpload:output_type -> blob.v1.PlanUploadResponse
+	11, // 66: blob.v1.BlobService.InitiateUpload:output_type -> blob.v1.InitiateUploadResponse
+	13, // 67: blob.v1.BlobService.CompleteUpload:output_type -> blob.v1.CompleteUploadResponse
+	15, // 68: blob.v1.BlobService.InitiateMultipartUpload:output_type -> blob.v1.InitiateMultipartUploadResponse
+	17, // 69: blob.v1.BlobService.CompleteMultipartUpload:output_type -> blob.v1.CompleteMultipartUploadResponse
+	19, // 70: blob.v1.BlobService.AbortMultipartUpload:output_type -> blob.v1.AbortMultipartUploadResponse
+	21, // 71: blob.v1.BlobService.ResumeMultipartUpload:output_type -> blob.v1.ResumeMultipartUploadResponse
+	24, // 72: blob.v1.BlobService.UploadStream:output_type -> blob.v1.UploadStreamResponse
+	26, // 73: blob.v1.BlobService.DownloadStream:output_type -> blob.v1.DownloadStreamResponse
+	28, // 74: blob.v1.BlobService.GetDownloadURL:output_type -> blob.v1.GetDownloadURLResponse
+	31, // 75: blob.v1.BlobService.GetRendition:output_type -> blob.v1.GetRenditionResponse
+	33, // 76: blob.v1.BlobService.GetBlobInfo:output_type -> blob.v1.GetBlobInfoResponse
+	37, // 77: blob.v1.BlobService.BatchGetBlobInfo:output_type -> blob.v1.BatchGetBlobInfoResponse
+	39, // 78: blob.v1.BlobService.ListBlobs:output_type -> blob.v1.ListBlobsResponse
+	42, // 79: blob.v1.BlobService.WatchBlobEvents:output_type -> blob.v1.WatchBlobEventsResponse
+	44, // 80: blob.v1.BlobService.AddReference:output_type -> blob.v1.AddReferenceResponse
+	46, // 81: blob.v1.BlobService.ReleaseReference:output_type -> blob.v1.ReleaseReferenceResponse
+	49, // 82: blob.v1.BlobService.GetUsage:output_type -> blob.v1.GetUsageResponse
+	51, // 83: blob.v1.BlobService.SetRetention:output_type -> blob.v1.SetRetentionResponse
+	53, // 84: blob.v1.BlobService.SetLegalHold:output_type -> blob.v1.SetLegalHoldResponse
+	65, // [65:85] is the sub-list for method output_type
+	45, // [45:65] is the sub-list for method input_type
+	45, // [45:45] is the sub-list for extension type_name
+	45, // [45:45] is the sub-list for extension extendee
+	0,  // [0:45] is the sub-list for field type_name
 }
 
 func init() { file_blob_v1_blob_proto_init() }
@@ -1100,13 +3758,17 @@ func file_blob_v1_blob_proto_init() {
 	if File_blob_v1_blob_proto != nil {
 		return
 	}
+	file_blob_v1_blob_proto_msgTypes[18].OneofWrappers = []any{
+		(*UploadStreamRequest_Metadata)(nil),
+		(*UploadStreamRequest_Chunk)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_blob_v1_blob_proto_rawDesc), len(file_blob_v1_blob_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   16,
+			NumEnums:      4,
+			NumMessages:   54,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
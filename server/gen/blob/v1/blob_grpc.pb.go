@@ -19,26 +19,57 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
+	BlobService_PlanUpload_FullMethodName              = "/blob.v1.BlobService/PlanUpload"
 	BlobService_InitiateUpload_FullMethodName          = "/blob.v1.BlobService/InitiateUpload"
 	BlobService_CompleteUpload_FullMethodName          = "/blob.v1.BlobService/CompleteUpload"
 	BlobService_InitiateMultipartUpload_FullMethodName = "/blob.v1.BlobService/InitiateMultipartUpload"
 	BlobService_CompleteMultipartUpload_FullMethodName = "/blob.v1.BlobService/CompleteMultipartUpload"
 	BlobService_AbortMultipartUpload_FullMethodName    = "/blob.v1.BlobService/AbortMultipartUpload"
+	BlobService_ResumeMultipartUpload_FullMethodName   = "/blob.v1.BlobService/ResumeMultipartUpload"
+	BlobService_UploadStream_FullMethodName            = "/blob.v1.BlobService/UploadStream"
+	BlobService_DownloadStream_FullMethodName          = "/blob.v1.BlobService/DownloadStream"
 	BlobService_GetDownloadURL_FullMethodName          = "/blob.v1.BlobService/GetDownloadURL"
+	BlobService_GetRendition_FullMethodName            = "/blob.v1.BlobService/GetRendition"
 	BlobService_GetBlobInfo_FullMethodName             = "/blob.v1.BlobService/GetBlobInfo"
+	BlobService_BatchGetBlobInfo_FullMethodName        = "/blob.v1.BlobService/BatchGetBlobInfo"
+	BlobService_ListBlobs_FullMethodName               = "/blob.v1.BlobService/ListBlobs"
+	BlobService_WatchBlobEvents_FullMethodName         = "/blob.v1.BlobService/WatchBlobEvents"
+	BlobService_AddReference_FullMethodName            = "/blob.v1.BlobService/AddReference"
+	BlobService_ReleaseReference_FullMethodName        = "/blob.v1.BlobService/ReleaseReference"
+	BlobService_GetUsage_FullMethodName                = "/blob.v1.BlobService/GetUsage"
+	BlobService_SetRetention_FullMethodName            = "/blob.v1.BlobService/SetRetention"
+	BlobService_SetLegalHold_FullMethodName            = "/blob.v1.BlobService/SetLegalHold"
 )
 
 // BlobServiceClient is the client API for BlobService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type BlobServiceClient interface {
+	PlanUpload(ctx context.Context, in *PlanUploadRequest, opts ...grpc.CallOption) (*PlanUploadResponse, error)
 	InitiateUpload(ctx context.Context, in *InitiateUploadRequest, opts ...grpc.CallOption) (*InitiateUploadResponse, error)
 	CompleteUpload(ctx context.Context, in *CompleteUploadRequest, opts ...grpc.CallOption) (*CompleteUploadResponse, error)
 	InitiateMultipartUpload(ctx context.Context, in *InitiateMultipartUploadRequest, opts ...grpc.CallOption) (*InitiateMultipartUploadResponse, error)
 	CompleteMultipartUpload(ctx context.Context, in *CompleteMultipartUploadRequest, opts ...grpc.CallOption) (*CompleteMultipartUploadResponse, error)
 	AbortMultipartUpload(ctx context.Context, in *AbortMultipartUploadRequest, opts ...grpc.CallOption) (*AbortMultipartUploadResponse, error)
+	ResumeMultipartUpload(ctx context.Context, in *ResumeMultipartUploadRequest, opts ...grpc.CallOption) (*ResumeMultipartUploadResponse, error)
+	UploadStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadStreamRequest, UploadStreamResponse], error)
+	DownloadStream(ctx context.Context, in *DownloadStreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadStreamResponse], error)
 	GetDownloadURL(ctx context.Context, in *GetDownloadURLRequest, opts ...grpc.CallOption) (*GetDownloadURLResponse, error)
+	// Serves renditions generated in the background after the source commits,
+	// returning UNAVAILABLE until the requested one exists.
+	GetRendition(ctx context.Context, in *GetRenditionRequest, opts ...grpc.CallOption) (*GetRenditionResponse, error)
 	GetBlobInfo(ctx context.Context, in *GetBlobInfoRequest, opts ...grpc.CallOption) (*GetBlobInfoResponse, error)
+	BatchGetBlobInfo(ctx context.Context, in *BatchGetBlobInfoRequest, opts ...grpc.CallOption) (*BatchGetBlobInfoResponse, error)
+	ListBlobs(ctx context.Context, in *ListBlobsRequest, opts ...grpc.CallOption) (*ListBlobsResponse, error)
+	// Streams events for every tenant's blobs, so it is meant for internal
+	// processors only and requires the blob.admin scope.
+	WatchBlobEvents(ctx context.Context, in *WatchBlobEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchBlobEventsResponse], error)
+	AddReference(ctx context.Context, in *AddReferenceRequest, opts ...grpc.CallOption) (*AddReferenceResponse, error)
+	ReleaseReference(ctx context.Context, in *ReleaseReferenceRequest, opts ...grpc.CallOption) (*ReleaseReferenceResponse, error)
+	GetUsage(ctx context.Context, in *GetUsageRequest, opts ...grpc.CallOption) (*GetUsageResponse, error)
+	// Restricted to the configured retention clients.
+	SetRetention(ctx context.Context, in *SetRetentionRequest, opts ...grpc.CallOption) (*SetRetentionResponse, error)
+	SetLegalHold(ctx context.Context, in *SetLegalHoldRequest, opts ...grpc.CallOption) (*SetLegalHoldResponse, error)
 }
 
 type blobServiceClient struct {
@@ -49,6 +80,16 @@ func NewBlobServiceClient(cc grpc.ClientConnInterface) BlobServiceClient {
 	return &blobServiceClient{cc}
 }
 
+func (c *blobServiceClient) PlanUpload(ctx context.Context, in *PlanUploadRequest, opts ...grpc.CallOption) (*PlanUploadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlanUploadResponse)
+	err := c.cc.Invoke(ctx, BlobService_PlanUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *blobServiceClient) InitiateUpload(ctx context.Context, in *InitiateUploadRequest, opts ...grpc.CallOption) (*InitiateUploadResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(InitiateUploadResponse)
@@ -99,6 +140,48 @@ func (c *blobServiceClient) AbortMultipartUpload(ctx context.Context, in *AbortM
 	return out, nil
 }
 
+func (c *blobServiceClient) ResumeMultipartUpload(ctx context.Context, in *ResumeMultipartUploadRequest, opts ...grpc.CallOption) (*ResumeMultipartUploadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResumeMultipartUploadResponse)
+	err := c.cc.Invoke(ctx, BlobService_ResumeMultipartUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blobServiceClient) UploadStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadStreamRequest, UploadStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BlobService_ServiceDesc.Streams[0], BlobService_UploadStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadStreamRequest, UploadStreamResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BlobService_UploadStreamClient = grpc.ClientStreamingClient[UploadStreamRequest, UploadStreamResponse]
+
+func (c *blobServiceClient) DownloadStream(ctx context.Context, in *DownloadStreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BlobService_ServiceDesc.Streams[1], BlobService_DownloadStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DownloadStreamRequest, DownloadStreamResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BlobService_DownloadStreamClient = grpc.ServerStreamingClient[DownloadStreamResponse]
+
 func (c *blobServiceClient) GetDownloadURL(ctx context.Context, in *GetDownloadURLRequest, opts ...grpc.CallOption) (*GetDownloadURLResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetDownloadURLResponse)
@@ -109,6 +192,16 @@ func (c *blobServiceClient) GetDownloadURL(ctx context.Context, in *GetDownloadU
 	return out, nil
 }
 
+func (c *blobServiceClient) GetRendition(ctx context.Context, in *GetRenditionRequest, opts ...grpc.CallOption) (*GetRenditionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRenditionResponse)
+	err := c.cc.Invoke(ctx, BlobService_GetRendition_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *blobServiceClient) GetBlobInfo(ctx context.Context, in *GetBlobInfoRequest, opts ...grpc.CallOption) (*GetBlobInfoResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetBlobInfoResponse)
@@ -119,17 +212,124 @@ func (c *blobServiceClient) GetBlobInfo(ctx context.Context, in *GetBlobInfoRequ
 	return out, nil
 }
 
+func (c *blobServiceClient) BatchGetBlobInfo(ctx context.Context, in *BatchGetBlobInfoRequest, opts ...grpc.CallOption) (*BatchGetBlobInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetBlobInfoResponse)
+	err := c.cc.Invoke(ctx, BlobService_BatchGetBlobInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blobServiceClient) ListBlobs(ctx context.Context, in *ListBlobsRequest, opts ...grpc.CallOption) (*ListBlobsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBlobsResponse)
+	err := c.cc.Invoke(ctx, BlobService_ListBlobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blobServiceClient) WatchBlobEvents(ctx context.Context, in *WatchBlobEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchBlobEventsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BlobService_ServiceDesc.Streams[2], BlobService_WatchBlobEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchBlobEventsRequest, WatchBlobEventsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BlobService_WatchBlobEventsClient = grpc.ServerStreamingClient[WatchBlobEventsResponse]
+
+func (c *blobServiceClient) AddReference(ctx context.Context, in *AddReferenceRequest, opts ...grpc.CallOption) (*AddReferenceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddReferenceResponse)
+	err := c.cc.Invoke(ctx, BlobService_AddReference_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blobServiceClient) ReleaseReference(ctx context.Context, in *ReleaseReferenceRequest, opts ...grpc.CallOption) (*ReleaseReferenceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReleaseReferenceResponse)
+	err := c.cc.Invoke(ctx, BlobService_ReleaseReference_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blobServiceClient) GetUsage(ctx context.Context, in *GetUsageRequest, opts ...grpc.CallOption) (*GetUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUsageResponse)
+	err := c.cc.Invoke(ctx, BlobService_GetUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blobServiceClient) SetRetention(ctx context.Context, in *SetRetentionRequest, opts ...grpc.CallOption) (*SetRetentionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetRetentionResponse)
+	err := c.cc.Invoke(ctx, BlobService_SetRetention_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blobServiceClient) SetLegalHold(ctx context.Context, in *SetLegalHoldRequest, opts ...grpc.CallOption) (*SetLegalHoldResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetLegalHoldResponse)
+	err := c.cc.Invoke(ctx, BlobService_SetLegalHold_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BlobServiceServer is the server API for BlobService service.
 // All implementations must embed UnimplementedBlobServiceServer
 // for forward compatibility.
 type BlobServiceServer interface {
+	PlanUpload(context.Context, *PlanUploadRequest) (*PlanUploadResponse, error)
 	InitiateUpload(context.Context, *InitiateUploadRequest) (*InitiateUploadResponse, error)
 	CompleteUpload(context.Context, *CompleteUploadRequest) (*CompleteUploadResponse, error)
 	InitiateMultipartUpload(context.Context, *InitiateMultipartUploadRequest) (*InitiateMultipartUploadResponse, error)
 	CompleteMultipartUpload(context.Context, *CompleteMultipartUploadRequest) (*CompleteMultipartUploadResponse, error)
 	AbortMultipartUpload(context.Context, *AbortMultipartUploadRequest) (*AbortMultipartUploadResponse, error)
+	ResumeMultipartUpload(context.Context, *ResumeMultipartUploadRequest) (*ResumeMultipartUploadResponse, error)
+	UploadStream(grpc.ClientStreamingServer[UploadStreamRequest, UploadStreamResponse]) error
+	DownloadStream(*DownloadStreamRequest, grpc.ServerStreamingServer[DownloadStreamResponse]) error
 	GetDownloadURL(context.Context, *GetDownloadURLRequest) (*GetDownloadURLResponse, error)
+	// Serves renditions generated in the background after the source commits,
+	// returning UNAVAILABLE until the requested one exists.
+	GetRendition(context.Context, *GetRenditionRequest) (*GetRenditionResponse, error)
 	GetBlobInfo(context.Context, *GetBlobInfoRequest) (*GetBlobInfoResponse, error)
+	BatchGetBlobInfo(context.Context, *BatchGetBlobInfoRequest) (*BatchGetBlobInfoResponse, error)
+	ListBlobs(context.Context, *ListBlobsRequest) (*ListBlobsResponse, error)
+	// Streams events for every tenant's blobs, so it is meant for internal
+	// processors only and requires the blob.admin scope.
+	WatchBlobEvents(*WatchBlobEventsRequest, grpc.ServerStreamingServer[WatchBlobEventsResponse]) error
+	AddReference(context.Context, *AddReferenceRequest) (*AddReferenceResponse, error)
+	ReleaseReference(context.Context, *ReleaseReferenceRequest) (*ReleaseReferenceResponse, error)
+	GetUsage(context.Context, *GetUsageRequest) (*GetUsageResponse, error)
+	// Restricted to the configured retention clients.
+	SetRetention(context.Context, *SetRetentionRequest) (*SetRetentionResponse, error)
+	SetLegalHold(context.Context, *SetLegalHoldRequest) (*SetLegalHoldResponse, error)
 	mustEmbedUnimplementedBlobServiceServer()
 }
 
@@ -140,6 +340,9 @@ type BlobServiceServer interface {
 // pointer dereference when methods are called.
 type UnimplementedBlobServiceServer struct{}
 
+func (UnimplementedBlobServiceServer) PlanUpload(context.Context, *PlanUploadRequest) (*PlanUploadResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PlanUpload not implemented")
+}
 func (UnimplementedBlobServiceServer) InitiateUpload(context.Context, *InitiateUploadRequest) (*InitiateUploadResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method InitiateUpload not implemented")
 }
@@ -155,12 +358,48 @@ func (UnimplementedBlobServiceServer) CompleteMultipartUpload(context.Context, *
 func (UnimplementedBlobServiceServer) AbortMultipartUpload(context.Context, *AbortMultipartUploadRequest) (*AbortMultipartUploadResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method AbortMultipartUpload not implemented")
 }
+func (UnimplementedBlobServiceServer) ResumeMultipartUpload(context.Context, *ResumeMultipartUploadRequest) (*ResumeMultipartUploadResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ResumeMultipartUpload not implemented")
+}
+func (UnimplementedBlobServiceServer) UploadStream(grpc.ClientStreamingServer[UploadStreamRequest, UploadStreamResponse]) error {
+	return status.Error(codes.Unimplemented, "method UploadStream not implemented")
+}
+func (UnimplementedBlobServiceServer) DownloadStream(*DownloadStreamRequest, grpc.ServerStreamingServer[DownloadStreamResponse]) error {
+	return status.Error(codes.Unimplemented, "method DownloadStream not implemented")
+}
 func (UnimplementedBlobServiceServer) GetDownloadURL(context.Context, *GetDownloadURLRequest) (*GetDownloadURLResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetDownloadURL not implemented")
 }
+func (UnimplementedBlobServiceServer) GetRendition(context.Context, *GetRenditionRequest) (*GetRenditionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRendition not implemented")
+}
 func (UnimplementedBlobServiceServer) GetBlobInfo(context.Context, *GetBlobInfoRequest) (*GetBlobInfoResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetBlobInfo not implemented")
 }
+func (UnimplementedBlobServiceServer) BatchGetBlobInfo(context.Context, *BatchGetBlobInfoRequest) (*BatchGetBlobInfoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BatchGetBlobInfo not implemented")
+}
+func (UnimplementedBlobServiceServer) ListBlobs(context.Context, *ListBlobsRequest) (*ListBlobsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListBlobs not implemented")
+}
+func (UnimplementedBlobServiceServer) WatchBlobEvents(*WatchBlobEventsRequest, grpc.ServerStreamingServer[WatchBlobEventsResponse]) error {
+	return status.Error(codes.Unimplemented, "method WatchBlobEvents not implemented")
+}
+func (UnimplementedBlobServiceServer) AddReference(context.Context, *AddReferenceRequest) (*AddReferenceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddReference not implemented")
+}
+func (UnimplementedBlobServiceServer) ReleaseReference(context.Context, *ReleaseReferenceRequest) (*ReleaseReferenceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReleaseReference not implemented")
+}
+func (UnimplementedBlobServiceServer) GetUsage(context.Context, *GetUsageRequest) (*GetUsageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetUsage not implemented")
+}
+func (UnimplementedBlobServiceServer) SetRetention(context.Context, *SetRetentionRequest) (*SetRetentionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetRetention not implemented")
+}
+func (UnimplementedBlobServiceServer) SetLegalHold(context.Context, *SetLegalHoldRequest) (*SetLegalHoldResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetLegalHold not implemented")
+}
 func (UnimplementedBlobServiceServer) mustEmbedUnimplementedBlobServiceServer() {}
 func (UnimplementedBlobServiceServer) testEmbeddedByValue()                     {}
 
@@ -182,6 +421,24 @@ func RegisterBlobServiceServer(s grpc.ServiceRegistrar, srv BlobServiceServer) {
 	s.RegisterService(&BlobService_ServiceDesc, srv)
 }
 
+func _BlobService_PlanUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlobServiceServer).PlanUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlobService_PlanUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlobServiceServer).PlanUpload(ctx, req.(*PlanUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _BlobService_InitiateUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(InitiateUploadRequest)
 	if err := dec(in); err != nil {
@@ -272,6 +529,42 @@ func _BlobService_AbortMultipartUpload_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BlobService_ResumeMultipartUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeMultipartUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlobServiceServer).ResumeMultipartUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlobService_ResumeMultipartUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlobServiceServer).ResumeMultipartUpload(ctx, req.(*ResumeMultipartUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlobService_UploadStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BlobServiceServer).UploadStream(&grpc.GenericServerStream[UploadStreamRequest, UploadStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BlobService_UploadStreamServer = grpc.ClientStreamingServer[UploadStreamRequest, UploadStreamResponse]
+
+func _BlobService_DownloadStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BlobServiceServer).DownloadStream(m, &grpc.GenericServerStream[DownloadStreamRequest, DownloadStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BlobService_DownloadStreamServer = grpc.ServerStreamingServer[DownloadStreamResponse]
+
 func _BlobService_GetDownloadURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetDownloadURLRequest)
 	if err := dec(in); err != nil {
@@ -290,6 +583,24 @@ func _BlobService_GetDownloadURL_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BlobService_GetRendition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRenditionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlobServiceServer).GetRendition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlobService_GetRendition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlobServiceServer).GetRendition(ctx, req.(*GetRenditionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _BlobService_GetBlobInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetBlobInfoRequest)
 	if err := dec(in); err != nil {
@@ -308,6 +619,143 @@ func _BlobService_GetBlobInfo_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BlobService_BatchGetBlobInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetBlobInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlobServiceServer).BatchGetBlobInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlobService_BatchGetBlobInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlobServiceServer).BatchGetBlobInfo(ctx, req.(*BatchGetBlobInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlobService_ListBlobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBlobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlobServiceServer).ListBlobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlobService_ListBlobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlobServiceServer).ListBlobs(ctx, req.(*ListBlobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlobService_WatchBlobEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchBlobEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BlobServiceServer).WatchBlobEvents(m, &grpc.GenericServerStream[WatchBlobEventsRequest, WatchBlobEventsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BlobService_WatchBlobEventsServer = grpc.ServerStreamingServer[WatchBlobEventsResponse]
+
+func _BlobService_AddReference_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddReferenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlobServiceServer).AddReference(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlobService_AddReference_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlobServiceServer).AddReference(ctx, req.(*AddReferenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlobService_ReleaseReference_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseReferenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlobServiceServer).ReleaseReference(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlobService_ReleaseReference_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlobServiceServer).ReleaseReference(ctx, req.(*ReleaseReferenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlobService_GetUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlobServiceServer).GetUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlobService_GetUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlobServiceServer).GetUsage(ctx, req.(*GetUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlobService_SetRetention_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRetentionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlobServiceServer).SetRetention(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlobService_SetRetention_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlobServiceServer).SetRetention(ctx, req.(*SetRetentionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlobService_SetLegalHold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLegalHoldRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlobServiceServer).SetLegalHold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlobService_SetLegalHold_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlobServiceServer).SetLegalHold(ctx, req.(*SetLegalHoldRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BlobService_ServiceDesc is the grpc.ServiceDesc for BlobService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -315,6 +763,10 @@ var BlobService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "blob.v1.BlobService",
 	HandlerType: (*BlobServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PlanUpload",
+			Handler:    _BlobService_PlanUpload_Handler,
+		},
 		{
 			MethodName: "InitiateUpload",
 			Handler:    _BlobService_InitiateUpload_Handler,
@@ -335,15 +787,67 @@ var BlobService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AbortMultipartUpload",
 			Handler:    _BlobService_AbortMultipartUpload_Handler,
 		},
+		{
+			MethodName: "ResumeMultipartUpload",
+			Handler:    _BlobService_ResumeMultipartUpload_Handler,
+		},
 		{
 			MethodName: "GetDownloadURL",
 			Handler:    _BlobService_GetDownloadURL_Handler,
 		},
+		{
+			MethodName: "GetRendition",
+			Handler:    _BlobService_GetRendition_Handler,
+		},
 		{
 			MethodName: "GetBlobInfo",
 			Handler:    _BlobService_GetBlobInfo_Handler,
 		},
+		{
+			MethodName: "BatchGetBlobInfo",
+			Handler:    _BlobService_BatchGetBlobInfo_Handler,
+		},
+		{
+			MethodName: "ListBlobs",
+			Handler:    _BlobService_ListBlobs_Handler,
+		},
+		{
+			MethodName: "AddReference",
+			Handler:    _BlobService_AddReference_Handler,
+		},
+		{
+			MethodName: "ReleaseReference",
+			Handler:    _BlobService_ReleaseReference_Handler,
+		},
+		{
+			MethodName: "GetUsage",
+			Handler:    _BlobService_GetUsage_Handler,
+		},
+		{
+			MethodName: "SetRetention",
+			Handler:    _BlobService_SetRetention_Handler,
+		},
+		{
+			MethodName: "SetLegalHold",
+			Handler:    _BlobService_SetLegalHold_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadStream",
+			Handler:       _BlobService_UploadStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadStream",
+			Handler:       _BlobService_DownloadStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchBlobEvents",
+			Handler:       _BlobService_WatchBlobEvents_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "blob/v1/blob.proto",
 }
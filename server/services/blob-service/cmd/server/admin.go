@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+// The operator subcommands print their results to stdout as JSON, one value
+// per line, so they can be piped into jq. Failures are logged as the server
+// logs them and exit with status 1; a malformed command line exits with 2.
+
+const listPageSize = 500
+
+// blobJSON is a blob as the operator subcommands print it.
+type blobJSON struct {
+	ID                  domain.BlobID      `json:"id"`
+	State               domain.UploadState `json:"state"`
+	SizeBytes           int64              `json:"size_bytes"`
+	ContentType         string             `json:"content_type"`
+	DetectedContentType string             `json:"detected_content_type,omitempty"`
+	CreatedAt           time.Time          `json:"created_at"`
+	CommittedAt         *time.Time         `json:"committed_at,omitempty"`
+	ReleasedAt          *time.Time         `json:"released_at,omitempty"`
+	QuarantineReason    string             `json:"quarantine_reason,omitempty"`
+	Encrypted           bool               `json:"encrypted"`
+	KeyID               string             `json:"key_id,omitempty"`
+	ReplicatedAt        *time.Time         `json:"replicated_at,omitempty"`
+	RetainUntil         *time.Time         `json:"retain_until,omitempty"`
+	LegalHold           bool               `json:"legal_hold"`
+}
+
+func toBlobJSON(b *domain.Blob) blobJSON {
+	return blobJSON{
+		ID:                  b.ID,
+		State:               b.State,
+		SizeBytes:           b.SizeBytes,
+		ContentType:         b.ContentType,
+		DetectedContentType: b.DetectedContentType,
+		CreatedAt:           b.CreatedAt,
+		CommittedAt:         b.CommittedAt,
+		ReleasedAt:          b.ReleasedAt,
+		QuarantineReason:    b.QuarantineReason,
+		Encrypted:           b.KeyID != "",
+		KeyID:               b.KeyID,
+		ReplicatedAt:        b.ReplicatedAt,
+		RetainUntil:         b.RetainUntil,
+		LegalHold:           b.LegalHold,
+	}
+}
+
+func printJSON(v any) {
+	_ = json.NewEncoder(os.Stdout).Encode(v)
+}
+
+// usageError reports a malformed command line and exits with status 2.
+func usageError(usage string) {
+	fmt.Fprintln(os.Stderr, "usage: blob-service "+usage)
+	os.Exit(2)
+}
+
+func fail(logger *slog.Logger, msg string, err error, args ...any) {
+	logger.Error(msg, append([]any{"error", err}, args...)...)
+	os.Exit(1)
+}
+
+func runStat(ctx context.Context, blobApp *app.App, args []string, logger *slog.Logger) {
+	if len(args) != 1 {
+		usageError("stat <blob-id>")
+	}
+	blob, err := blobApp.StatBlob(ctx, domain.BlobID(args[0]))
+	if err != nil {
+		fail(logger, "stat failed", err, "blob_id", args[0])
+	}
+	printJSON(toBlobJSON(blob))
+}
+
+func runList(ctx context.Context, blobApp *app.App, args []string, logger *slog.Logger) {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	state := flags.String("state", "", "only list blobs in this state: pending, scanning, committed, quarantined or missing")
+	_ = flags.Parse(args)
+	if flags.NArg() != 0 {
+		usageError("list [--state <state>]")
+	}
+	want := domain.UploadState(strings.ToUpper(*state))
+	switch want {
+	case "", domain.StatePending, domain.StateScanning, domain.StateCommitted, domain.StateQuarantined, domain.StateMissing:
+	default:
+		usageError("list [--state pending|scanning|committed|quarantined|missing]")
+	}
+
+	var after domain.BlobID
+	for {
+		blobs, err := blobApp.ListAllBlobs(ctx, want, after, listPageSize)
+		if err != nil {
+			fail(logger, "list failed", err)
+		}
+		for _, b := range blobs {
+			printJSON(toBlobJSON(b))
+		}
+		if len(blobs) < listPageSize {
+			return
+		}
+		after = blobs[len(blobs)-1].ID
+	}
+}
+
+func runPresignGet(ctx context.Context, blobApp *app.App, args []string, logger *slog.Logger) {
+	flags := flag.NewFlagSet("presign-get", flag.ExitOnError)
+	ttl := flags.Duration("ttl", 15*time.Minute, "how long the URL is valid, capped at PRESIGN_GET_MAX_TTL")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		usageError("presign-get [--ttl <duration>] <blob-id>")
+	}
+	result, err := blobApp.PresignBlobDownload(ctx, domain.BlobID(flags.Arg(0)), *ttl)
+	if err != nil {
+		fail(logger, "presign failed", err, "blob_id", flags.Arg(0))
+	}
+	printJSON(map[string]any{
+		"url":              result.PresignedGetURL,
+		"required_headers": result.RequiredHeaders,
+		"expires_at":       result.ExpiresAt,
+	})
+}
+
+func runImport(ctx context.Context, blobApp *app.App, args []string, logger *slog.Logger) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	subject := flags.String("subject", "", "subject to hold the reference to the imported blob (required)")
+	clientID := flags.String("client", "", "client ID to import as, for content type and quota policies")
+	contentType := flags.String("content-type", "", "content type to store; sniffed from the file if empty")
+	encrypt := flags.Bool("encrypt", false, "encrypt the blob under its own data key")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 || *subject == "" {
+		usageError("import --subject <subject> [--client <id>] [--content-type <type>] [--encrypt] <file>")
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		fail(logger, "import failed", err)
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		fail(logger, "import failed", err)
+	}
+
+	caller := domain.Caller{Subject: *subject, ClientID: *clientID}
+	upload, err := blobApp.ImportBlob(ctx, caller, f, info.Size(), *contentType, *encrypt)
+	if err != nil {
+		fail(logger, "import failed", err, "file", flags.Arg(0))
+	}
+	printJSON(map[string]any{"id": upload.BlobID, "size_bytes": upload.SizeBytes, "state": upload.State})
+	if upload.State != domain.StateCommitted {
+		fail(logger, "import failed", fmt.Errorf("blob is %s, not %s", upload.State, domain.StateCommitted), "file", flags.Arg(0), "blob_id", upload.BlobID)
+	}
+}
+
+// runExport writes the blob to a temporary file beside path and renames it
+// into place once its hash checks out, so path never holds partial or
+// corrupt content.
+func runExport(ctx context.Context, blobApp *app.App, args []string, logger *slog.Logger) {
+	if len(args) != 2 {
+		usageError("export <blob-id> <path>")
+	}
+	id, path := domain.BlobID(args[0]), args[1]
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		fail(logger, "export failed", err)
+	}
+
+	blob, err := blobApp.ExportBlob(ctx, id, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		fail(logger, "export failed", err, "blob_id", id)
+	}
+	logger.Info("export complete", "blob_id", id, "path", path, "size_bytes", blob.SizeBytes)
+}
+
+func runGC(ctx context.Context, blobApp *app.App, args []string, logger *slog.Logger) {
+	flags := flag.NewFlagSet("gc", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "report the blobs that would be deleted without deleting them")
+	_ = flags.Parse(args)
+	if flags.NArg() != 0 {
+		usageError("gc [--dry-run]")
+	}
+	report, err := blobApp.CollectGarbage(ctx, *dryRun)
+	if report != nil {
+		printJSON(map[string]any{
+			"dry_run":     report.DryRun,
+			"blob_ids":    report.BlobIDs,
+			"freed_bytes": report.FreedBytes,
+		})
+	}
+	if err != nil {
+		fail(logger, "garbage collection failed", err)
+	}
+}
@@ -2,42 +2,55 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 
 	pb "github.com/barn0w1/hss-science/server/gen/blob/v1"
 	"github.com/barn0w1/hss-science/server/services/blob-service/config"
 	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/keyring"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/metrics"
 	"github.com/barn0w1/hss-science/server/services/blob-service/internal/repository/postgres"
+	clamdscanner "github.com/barn0w1/hss-science/server/services/blob-service/internal/scanner/clamd"
+	fsstorage "github.com/barn0w1/hss-science/server/services/blob-service/internal/storage/fs"
+	instrumentedstorage "github.com/barn0w1/hss-science/server/services/blob-service/internal/storage/instrumented"
+	replicatedstorage "github.com/barn0w1/hss-science/server/services/blob-service/internal/storage/replicated"
 	s3storage "github.com/barn0w1/hss-science/server/services/blob-service/internal/storage/s3"
 	grpctransport "github.com/barn0w1/hss-science/server/services/blob-service/internal/transport/grpc"
 	"github.com/barn0w1/hss-science/server/services/blob-service/internal/transport/grpc/interceptor"
+	httptransport "github.com/barn0w1/hss-science/server/services/blob-service/internal/transport/http"
 )
 
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
 
-	cfg, err := config.Load()
-	if err != nil {
-		logger.Error("failed to load config", "error", err)
-		os.Exit(1)
+	subcommand := "server"
+	if len(os.Args) > 1 {
+		subcommand = os.Args[1]
 	}
 
-	ctx := context.Background()
-
-	oidcProvider, err := oidc.NewProvider(ctx, cfg.OIDCIssuerURL)
+	cfg, err := config.Load()
 	if err != nil {
-		logger.Error("failed to init OIDC provider", "error", err, "issuer", cfg.OIDCIssuerURL)
+		logger.Error("failed to load config", "error", err)
 		os.Exit(1)
 	}
 
@@ -53,25 +66,211 @@ func main() {
 	db.SetConnMaxLifetime(time.Duration(cfg.DBConnMaxLifetimeSecs) * time.Second)
 	db.SetConnMaxIdleTime(time.Duration(cfg.DBConnMaxIdleTimeSecs) * time.Second)
 
-	storage, err := s3storage.New(cfg.R2Endpoint, cfg.R2Bucket, cfg.R2AccessKeyID, cfg.R2SecretAccessKey)
+	m := metrics.New()
+	storage, objectHandler, err := newStorage(cfg, m)
 	if err != nil {
-		logger.Error("failed to init R2 client", "error", err)
+		logger.Error("failed to init object storage", "error", err, "backend", cfg.StorageBackend)
 		os.Exit(1)
 	}
 
+	var scanner domain.Scanner
+	if cfg.ClamdAddress != "" {
+		scanner = clamdscanner.New(cfg.ClamdAddress, cfg.ClamdTimeout)
+	}
+
+	var keys domain.KeyWrapper
+	if cfg.CryptoKey != nil {
+		if keys, err = keyring.New(*cfg.CryptoKey, cfg.CryptoKeyPrevious...); err != nil {
+			logger.Error("failed to init keyring", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	repo := postgres.New(db)
 	blobApp := app.New(repo, storage, app.Config{
 		PresignPutTTL:           cfg.PresignPutTTL,
 		PresignGetMaxTTL:        cfg.PresignGetMaxTTL,
 		MultipartThresholdBytes: cfg.MultipartThresholdBytes,
+		GCGracePeriod:           cfg.GCGracePeriod,
+		PendingTTL:              cfg.PendingTTL,
+		EventPollInterval:       cfg.EventPollInterval,
+		EventRetention:          cfg.EventRetention,
+		Quotas:                  quotaPolicy(cfg),
+		Renditions:              cfg.Renditions,
+		RenditionMaxSourceBytes: cfg.RenditionMaxSourceBytes,
+		ContentTypes:            domain.ContentTypePolicy(cfg.ContentTypeAllowlist),
+		Scanner:                 scanner,
+		ScanPollInterval:        cfg.ScanPollInterval,
+		Keys:                    keys,
+		ReplicationPollInterval: cfg.ReplicationPollInterval,
+		RetentionClients:        cfg.RetentionClients,
+		Uploads:                 m,
 	})
 
+	switch subcommand {
+	case "cleanup":
+		runCleanup(context.Background(), blobApp, logger)
+		return
+	case "rotate-keys":
+		runRotateKeys(context.Background(), blobApp, logger)
+		return
+	case "verify":
+		flags := flag.NewFlagSet("verify", flag.ExitOnError)
+		repair := flags.Bool("repair", false, "mark blobs with missing objects MISSING and delete orphaned objects")
+		_ = flags.Parse(os.Args[2:])
+		runVerify(context.Background(), blobApp, *repair, logger)
+		return
+	case "stat":
+		runStat(context.Background(), blobApp, os.Args[2:], logger)
+		return
+	case "list":
+		runList(context.Background(), blobApp, os.Args[2:], logger)
+		return
+	case "presign-get":
+		runPresignGet(context.Background(), blobApp, os.Args[2:], logger)
+		return
+	case "import":
+		runImport(context.Background(), blobApp, os.Args[2:], logger)
+		return
+	case "export":
+		runExport(context.Background(), blobApp, os.Args[2:], logger)
+		return
+	case "gc":
+		runGC(context.Background(), blobApp, os.Args[2:], logger)
+		return
+	case "server":
+		ready := func(ctx context.Context) error { return checkReady(ctx, db, storage) }
+		runServer(cfg, blobApp, objectHandler, m, ready, logger)
+	default:
+		fmt.Fprintln(os.Stderr, "unknown subcommand — valid: server, cleanup, rotate-keys, verify, stat, list, presign-get, import, export, gc")
+		os.Exit(2)
+	}
+}
+
+// newStorage builds the configured object storage backend, replicated to the
+// secondary bucket if one is configured, with each bucket's calls recorded
+// in m. The filesystem backend also returns the HTTP handler that serves its
+// presigned URLs.
+func newStorage(cfg *config.Config, m *metrics.Metrics) (domain.ObjectStorage, http.Handler, error) {
+	primary, handler, err := newPrimaryStorage(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	instrumented := instrumentedstorage.New(primary, "primary", m)
+	if cfg.ReplicaR2Endpoint == "" {
+		return instrumented, handler, nil
+	}
+	replica, err := s3storage.New(cfg.ReplicaR2Endpoint, cfg.ReplicaR2Bucket, cfg.ReplicaR2AccessKeyID, cfg.ReplicaR2SecretAccessKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return replicatedstorage.New(instrumented, instrumentedstorage.New(replica, "replica", m), cfg.ReplicaHealthCheckInterval), handler, nil
+}
+
+// checkReady reports whether Postgres and the bucket both answer.
+func checkReady(ctx context.Context, db *sqlx.DB, storage domain.ObjectStorage) error {
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database: %w", err)
+	}
+	if _, err := storage.ListObjects(ctx, "", 1); err != nil {
+		return fmt.Errorf("object storage: %w", err)
+	}
+	return nil
+}
+
+func newPrimaryStorage(cfg *config.Config) (domain.ObjectStorage, http.Handler, error) {
+	switch cfg.StorageBackend {
+	case config.StorageBackendFS:
+		store, err := fsstorage.New(cfg.FSStorageDir, cfg.FSStorageBaseURL, cfg.FSStorageSigningKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, store.Handler(), nil
+	default:
+		client, err := s3storage.New(cfg.R2Endpoint, cfg.R2Bucket, cfg.R2AccessKeyID, cfg.R2SecretAccessKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		return client, nil, nil
+	}
+}
+
+func runCleanup(ctx context.Context, blobApp *app.App, logger *slog.Logger) {
+	report, err := blobApp.ExpirePending(ctx)
+	if err != nil {
+		logger.Error("pending blob cleanup failed", "error", err,
+			"expired_uploads", report.ExpiredUploads,
+			"aborted_uploads", report.AbortedUploads,
+		)
+		os.Exit(1)
+	}
+	logger.Info("pending blob cleanup complete",
+		"expired_uploads", report.ExpiredUploads,
+		"aborted_uploads", report.AbortedUploads,
+	)
+}
+
+func runRotateKeys(ctx context.Context, blobApp *app.App, logger *slog.Logger) {
+	report, err := blobApp.RotateKeys(ctx)
+	if err != nil || report.Failed > 0 {
+		logger.Error("data key rotation failed", "error", err,
+			"rewrapped", report.Rewrapped,
+			"failed", report.Failed,
+		)
+		os.Exit(1)
+	}
+	logger.Info("data key rotation complete", "rewrapped", report.Rewrapped)
+}
+
+func runVerify(ctx context.Context, blobApp *app.App, repair bool, logger *slog.Logger) {
+	report, err := blobApp.Verify(ctx, repair)
+	logVerifyReport(logger, report)
+	if err != nil || report.Failed > 0 {
+		logger.Error("blob verification failed", "error", err, "failed", report.Failed)
+		os.Exit(1)
+	}
+}
+
+// logVerifyReport logs one line per discrepancy, then a summary.
+func logVerifyReport(logger *slog.Logger, report *app.VerifyReport) {
+	for _, d := range report.Discrepancies {
+		logger.Warn("blob discrepancy",
+			"kind", d.Kind,
+			"key", d.Key,
+			"state", d.State,
+			"expected_bytes", d.ExpectedBytes,
+			"actual_bytes", d.ActualBytes,
+			"repaired", d.Repaired,
+		)
+	}
+	logger.Info("blob verification complete",
+		"repair", report.Repair,
+		"checked_blobs", report.CheckedBlobs,
+		"checked_objects", report.CheckedObjects,
+		"discrepancies", len(report.Discrepancies),
+		"failed", report.Failed,
+	)
+}
+
+func runServer(cfg *config.Config, blobApp *app.App, objectHandler http.Handler, m *metrics.Metrics, ready func(context.Context) error, logger *slog.Logger) {
+	oidcProvider, err := oidc.NewProvider(context.Background(), cfg.OIDCIssuerURL)
+	if err != nil {
+		logger.Error("failed to init OIDC provider", "error", err, "issuer", cfg.OIDCIssuerURL)
+		os.Exit(1)
+	}
+
 	auth := interceptor.NewAuthInterceptor(oidcProvider, "blob-service")
 	grpcSrv := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(auth.Unary()),
-		grpc.ChainStreamInterceptor(auth.Stream()),
+		grpc.ChainUnaryInterceptor(interceptor.UnaryMetrics(m), auth.Unary()),
+		grpc.ChainStreamInterceptor(interceptor.StreamMetrics(m), auth.Stream()),
 	)
-	pb.RegisterBlobServiceServer(grpcSrv, grpctransport.NewServer(blobApp))
+	blobSrv := grpctransport.NewServer(blobApp)
+	pb.RegisterBlobServiceServer(grpcSrv, blobSrv)
+	healthSrv := health.NewServer()
+	healthpb.RegisterHealthServer(grpcSrv, healthSrv)
+	if cfg.GRPCReflectionEnabled {
+		reflection.Register(grpcSrv)
+	}
 
 	listener, err := net.Listen("tcp", cfg.GRPCListenAddr)
 	if err != nil {
@@ -86,11 +285,268 @@ func main() {
 		}
 	}()
 
+	var httpSrv *http.Server
+	if objectHandler != nil || cfg.HTTPGatewayEnabled || cfg.TusEnabled {
+		router := chi.NewRouter()
+		router.Use(chimiddleware.Recoverer)
+		if objectHandler != nil {
+			router.Handle("/objects/*", objectHandler)
+		}
+		if cfg.HTTPGatewayEnabled {
+			gateway := httptransport.NewGateway(blobSrv, auth, cfg.CORSAllowedOrigins)
+			router.Handle("/v1/*", gateway)
+		}
+		if cfg.TusEnabled {
+			tus := httptransport.NewTusHandler(blobApp, auth, cfg.CORSAllowedOrigins)
+			router.Handle("/files", tus)
+			router.Handle("/files/*", tus)
+		}
+
+		// Object transfers can be arbitrarily large, so only the header
+		// read is bounded.
+		httpSrv = &http.Server{
+			Addr:              cfg.HTTPListenAddr,
+			Handler:           router,
+			ReadHeaderTimeout: 5 * time.Second,
+			IdleTimeout:       120 * time.Second,
+		}
+		go func() {
+			logger.Info("blob-service HTTP server starting", "addr", cfg.HTTPListenAddr)
+			if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("HTTP server exited", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
+	opsRouter := chi.NewRouter()
+	opsRouter.Handle("/metrics", m.Handler())
+	opsRouter.Get("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	opsRouter.Get("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if err := ready(r.Context()); err != nil {
+			logger.Warn("readiness check failed", "error", err)
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	opsSrv := &http.Server{
+		Addr:              cfg.MetricsListenAddr,
+		Handler:           opsRouter,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		logger.Info("blob-service metrics server starting", "addr", cfg.MetricsListenAddr)
+		if err := opsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("metrics server exited", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	workerCtx, workerCancel := context.WithCancel(context.Background())
+	go runHealthLoop(workerCtx, healthSrv, ready, cfg.HealthCheckInterval, logger)
+	go runGCLoop(workerCtx, blobApp, cfg.GCInterval, cfg.GCDryRun, logger)
+	go runReaperLoop(workerCtx, blobApp, cfg.ReaperInterval, logger)
+	if len(cfg.Renditions) > 0 {
+		go runRenditionWorker(workerCtx, blobApp, logger)
+	}
+	if cfg.ClamdAddress != "" {
+		go runScanWorker(workerCtx, blobApp, logger)
+	}
+	if cfg.ReplicaR2Endpoint != "" {
+		go runReplicationWorker(workerCtx, blobApp, logger)
+	}
+	if cfg.VerifyInterval > 0 {
+		go runVerifyLoop(workerCtx, blobApp, cfg.VerifyInterval, cfg.VerifyRepair, logger)
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	logger.Info("shutting down blob-service")
+	healthSrv.Shutdown()
+	workerCancel()
 	grpcSrv.GracefulStop()
+	if httpSrv != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		if err := httpSrv.Shutdown(shutdownCtx); err != nil {
+			logger.Error("HTTP server forced to shutdown", "error", err)
+		}
+	}
+	_ = opsSrv.Close()
 	logger.Info("blob-service stopped")
 }
+
+// runHealthLoop reports the server and BlobService as serving to the gRPC
+// health service while the readiness check passes, checking at once and then
+// every interval.
+func runHealthLoop(ctx context.Context, healthSrv *health.Server, ready func(context.Context) error, interval time.Duration, logger *slog.Logger) {
+	serving := healthpb.HealthCheckResponse_UNKNOWN
+	check := func() {
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		defer cancel()
+		next := healthpb.HealthCheckResponse_SERVING
+		if err := ready(checkCtx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			next = healthpb.HealthCheckResponse_NOT_SERVING
+			logger.Warn("health check failed", "error", err)
+		}
+		if next != serving {
+			healthSrv.SetServingStatus("", next)
+			healthSrv.SetServingStatus(pb.BlobService_ServiceDesc.ServiceName, next)
+			serving = next
+		}
+	}
+
+	check()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+func runGCLoop(ctx context.Context, blobApp *app.App, interval time.Duration, dryRun bool, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := blobApp.CollectGarbage(ctx, dryRun)
+			if err != nil {
+				logger.Error("blob garbage collection failed", "error", err)
+			}
+			if report != nil && len(report.BlobIDs) > 0 {
+				logger.Info("blob garbage collection complete",
+					"dry_run", report.DryRun,
+					"blobs", len(report.BlobIDs),
+					"freed_bytes", report.FreedBytes,
+					"blob_ids", report.BlobIDs,
+				)
+			}
+			if pruned, err := blobApp.PruneEvents(ctx); err != nil {
+				logger.Error("blob event pruning failed", "error", err)
+			} else if pruned > 0 {
+				logger.Info("pruned blob events", "events", pruned)
+			}
+		}
+	}
+}
+
+func runReaperLoop(ctx context.Context, blobApp *app.App, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := blobApp.ExpirePending(ctx)
+			if err != nil {
+				logger.Error("pending blob cleanup failed", "error", err)
+			}
+			if report.ExpiredUploads > 0 || report.AbortedUploads > 0 {
+				logger.Info("cleaned up abandoned uploads",
+					"expired_uploads", report.ExpiredUploads,
+					"aborted_uploads", report.AbortedUploads,
+				)
+			}
+		}
+	}
+}
+
+func runVerifyLoop(ctx context.Context, blobApp *app.App, interval time.Duration, repair bool, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := blobApp.Verify(ctx, repair)
+			if err != nil {
+				logger.Error("blob verification failed", "error", err)
+			}
+			logVerifyReport(logger, report)
+		}
+	}
+}
+
+// runRenditionWorker keeps the rendition worker running, restarting it after
+// a pause if reading the event log fails.
+func runRenditionWorker(ctx context.Context, blobApp *app.App, logger *slog.Logger) {
+	for {
+		err := blobApp.RunRenditions(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Error("rendition worker stopped, restarting", "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+// runScanWorker keeps the scan worker running, restarting it after a pause
+// if listing blobs to scan fails.
+func runScanWorker(ctx context.Context, blobApp *app.App, logger *slog.Logger) {
+	for {
+		err := blobApp.RunScanner(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Error("scan worker stopped, restarting", "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+// runReplicationWorker keeps the replication worker running, restarting it
+// after a pause if listing blobs to copy fails.
+func runReplicationWorker(ctx context.Context, blobApp *app.App, logger *slog.Logger) {
+	for {
+		err := blobApp.RunReplication(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Error("replication worker stopped, restarting", "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+func quotaPolicy(cfg *config.Config) domain.QuotaPolicy {
+	limits := func(l config.QuotaLimits) domain.Limits {
+		return domain.Limits{MaxBytes: l.MaxBytes, MaxObjects: l.MaxObjects, MaxObjectBytes: l.MaxObjectBytes}
+	}
+	p := domain.QuotaPolicy{
+		Subject:   limits(cfg.QuotaSubject),
+		Client:    limits(cfg.QuotaClient),
+		Overrides: make(map[string]domain.Limits, len(cfg.QuotaOverrides)),
+	}
+	for k, l := range cfg.QuotaOverrides {
+		p.Overrides[k] = limits(l)
+	}
+	return p
+}
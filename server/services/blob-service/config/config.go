@@ -1,10 +1,15 @@
 package config
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
 )
 
 type ConfigSource interface {
@@ -19,10 +24,24 @@ type MapSource map[string]string
 
 func (m MapSource) Get(key string) string { return m[key] }
 
+const (
+	StorageBackendR2 = "r2"
+	StorageBackendFS = "fs"
+)
+
+// QuotaLimits caps one owner's usage. Zero fields are unlimited.
+type QuotaLimits struct {
+	MaxBytes       int64 `json:"max_bytes"`
+	MaxObjects     int64 `json:"max_objects"`
+	MaxObjectBytes int64 `json:"max_object_bytes"`
+}
+
 type Config struct {
 	GRPCListenAddr          string
+	HTTPListenAddr          string
 	DatabaseURL             string
 	OIDCIssuerURL           string
+	StorageBackend          string
 	R2Endpoint              string
 	R2Bucket                string
 	R2AccessKeyID           string
@@ -31,6 +50,76 @@ type Config struct {
 	PresignGetMaxTTL        time.Duration
 	MultipartThresholdBytes int64
 
+	FSStorageDir        string
+	FSStorageBaseURL    string
+	FSStorageSigningKey []byte
+
+	GCGracePeriod time.Duration
+	GCInterval    time.Duration
+	GCDryRun      bool
+
+	PendingTTL     time.Duration
+	ReaperInterval time.Duration
+
+	EventPollInterval time.Duration
+	EventRetention    time.Duration
+
+	QuotaSubject QuotaLimits
+	QuotaClient  QuotaLimits
+	// QuotaOverrides is keyed by "subject:<sub>" or "client:<client_id>".
+	QuotaOverrides map[string]QuotaLimits
+
+	Renditions              []domain.RenditionSpec
+	RenditionMaxSourceBytes int64
+
+	// ContentTypeAllowlist maps a client ID to the content types it may store.
+	// Unlisted clients may store anything.
+	ContentTypeAllowlist map[string][]string
+
+	// ClamdAddress is "host:port" or "unix:/path". Empty disables malware
+	// scanning and commits uploads as soon as they are verified.
+	ClamdAddress     string
+	ClamdTimeout     time.Duration
+	ScanPollInterval time.Duration
+
+	// CryptoKey wraps the data keys of encrypted blobs; nil disables encryption.
+	// CryptoKeyPrevious still unwrap data keys until rotate-keys re-wraps them.
+	CryptoKey         *[32]byte
+	CryptoKeyPrevious [][32]byte
+
+	// ReplicaR2Endpoint names a bucket that committed blobs are copied to and
+	// downloads fail over to. Empty disables replication.
+	ReplicaR2Endpoint          string
+	ReplicaR2Bucket            string
+	ReplicaR2AccessKeyID       string
+	ReplicaR2SecretAccessKey   string
+	ReplicationPollInterval    time.Duration
+	ReplicaHealthCheckInterval time.Duration
+
+	// VerifyInterval is how often the blobs table is checked against the bucket;
+	// zero disables the check.
+	VerifyInterval time.Duration
+	VerifyRepair   bool
+
+	// HTTPGatewayEnabled serves BlobService as JSON under /v1 on the HTTP
+	// listener. CORSAllowedOrigins lists the browser origins that may call it.
+	HTTPGatewayEnabled bool
+	CORSAllowedOrigins []string
+
+	// TusEnabled serves tus resumable uploads under /files on the HTTP
+	// listener, subject to CORSAllowedOrigins as well.
+	TusEnabled bool
+
+	// RetentionClients are the client IDs allowed to set retention periods
+	// and legal holds. Empty leaves retention unmanageable.
+	RetentionClients []string
+
+	// MetricsListenAddr serves /metrics, /healthz and /readyz.
+	MetricsListenAddr   string
+	HealthCheckInterval time.Duration
+	// GRPCReflectionEnabled registers the gRPC reflection service.
+	GRPCReflectionEnabled bool
+
 	DBMaxOpenConns        int
 	DBMaxIdleConns        int
 	DBConnMaxLifetimeSecs int
@@ -44,21 +133,33 @@ func Load() (*Config, error) {
 func LoadFrom(src ConfigSource) (*Config, error) {
 	cfg := &Config{
 		GRPCListenAddr:    getFrom(src, "GRPC_LISTEN_ADDR", ":50052"),
+		HTTPListenAddr:    getFrom(src, "HTTP_LISTEN_ADDR", ":8082"),
 		DatabaseURL:       src.Get("DATABASE_URL"),
 		OIDCIssuerURL:     src.Get("OIDC_ISSUER_URL"),
+		StorageBackend:    getFrom(src, "STORAGE_BACKEND", StorageBackendR2),
 		R2Endpoint:        src.Get("R2_ENDPOINT"),
 		R2Bucket:          src.Get("R2_BUCKET"),
 		R2AccessKeyID:     src.Get("R2_ACCESS_KEY_ID"),
 		R2SecretAccessKey: src.Get("R2_SECRET_ACCESS_KEY"),
+		FSStorageDir:      src.Get("FS_STORAGE_DIR"),
+		FSStorageBaseURL:  getFrom(src, "FS_STORAGE_BASE_URL", "http://localhost:8082"),
 	}
 
 	required := map[string]string{
-		"DATABASE_URL":         cfg.DatabaseURL,
-		"OIDC_ISSUER_URL":      cfg.OIDCIssuerURL,
-		"R2_ENDPOINT":          cfg.R2Endpoint,
-		"R2_BUCKET":            cfg.R2Bucket,
-		"R2_ACCESS_KEY_ID":     cfg.R2AccessKeyID,
-		"R2_SECRET_ACCESS_KEY": cfg.R2SecretAccessKey,
+		"DATABASE_URL":    cfg.DatabaseURL,
+		"OIDC_ISSUER_URL": cfg.OIDCIssuerURL,
+	}
+	switch cfg.StorageBackend {
+	case StorageBackendR2:
+		required["R2_ENDPOINT"] = cfg.R2Endpoint
+		required["R2_BUCKET"] = cfg.R2Bucket
+		required["R2_ACCESS_KEY_ID"] = cfg.R2AccessKeyID
+		required["R2_SECRET_ACCESS_KEY"] = cfg.R2SecretAccessKey
+	case StorageBackendFS:
+		required["FS_STORAGE_DIR"] = cfg.FSStorageDir
+		required["FS_STORAGE_SIGNING_KEY"] = src.Get("FS_STORAGE_SIGNING_KEY")
+	default:
+		return nil, fmt.Errorf("STORAGE_BACKEND must be %q or %q, got %q", StorageBackendR2, StorageBackendFS, cfg.StorageBackend)
 	}
 	var missing []string
 	for k, v := range required {
@@ -71,6 +172,16 @@ func LoadFrom(src ConfigSource) (*Config, error) {
 	}
 
 	var err error
+	if cfg.StorageBackend == StorageBackendFS {
+		cfg.FSStorageSigningKey, err = hex.DecodeString(src.Get("FS_STORAGE_SIGNING_KEY"))
+		if err != nil {
+			return nil, fmt.Errorf("FS_STORAGE_SIGNING_KEY must be hex-encoded: %w", err)
+		}
+		if len(cfg.FSStorageSigningKey) < 32 {
+			return nil, fmt.Errorf("FS_STORAGE_SIGNING_KEY must be at least 32 bytes (64 hex chars), got %d bytes", len(cfg.FSStorageSigningKey))
+		}
+	}
+
 	putTTL, err := loadInt(src, "PRESIGN_PUT_TTL_SECONDS", 900)
 	if err != nil {
 		return nil, err
@@ -89,6 +200,177 @@ func LoadFrom(src ConfigSource) (*Config, error) {
 	}
 	cfg.MultipartThresholdBytes = threshold
 
+	gcGrace, err := loadInt(src, "GC_GRACE_PERIOD_SECONDS", 7*24*3600)
+	if err != nil {
+		return nil, err
+	}
+	cfg.GCGracePeriod = time.Duration(gcGrace) * time.Second
+
+	gcInterval, err := loadInt(src, "GC_INTERVAL_SECONDS", 3600)
+	if err != nil {
+		return nil, err
+	}
+	cfg.GCInterval = time.Duration(gcInterval) * time.Second
+	cfg.GCDryRun = src.Get("GC_DRY_RUN") == "true"
+
+	pendingTTL, err := loadInt(src, "PENDING_TTL_SECONDS", 24*3600)
+	if err != nil {
+		return nil, err
+	}
+	cfg.PendingTTL = time.Duration(pendingTTL) * time.Second
+
+	reaperInterval, err := loadInt(src, "REAPER_INTERVAL_SECONDS", 3600)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ReaperInterval = time.Duration(reaperInterval) * time.Second
+
+	eventPoll, err := loadInt(src, "EVENT_POLL_INTERVAL_MS", 1000)
+	if err != nil {
+		return nil, err
+	}
+	cfg.EventPollInterval = time.Duration(eventPoll) * time.Millisecond
+
+	eventRetention, err := loadInt(src, "EVENT_RETENTION_SECONDS", 7*24*3600)
+	if err != nil {
+		return nil, err
+	}
+	cfg.EventRetention = time.Duration(eventRetention) * time.Second
+
+	if cfg.QuotaSubject, err = loadQuota(src, "QUOTA_SUBJECT"); err != nil {
+		return nil, err
+	}
+	if cfg.QuotaClient, err = loadQuota(src, "QUOTA_CLIENT"); err != nil {
+		return nil, err
+	}
+	if v := src.Get("QUOTA_OVERRIDES"); v != "" {
+		if err := json.Unmarshal([]byte(v), &cfg.QuotaOverrides); err != nil {
+			return nil, fmt.Errorf("QUOTA_OVERRIDES must be a JSON object of limits: %w", err)
+		}
+		for k := range cfg.QuotaOverrides {
+			if !strings.HasPrefix(k, "subject:") && !strings.HasPrefix(k, "client:") {
+				return nil, fmt.Errorf("QUOTA_OVERRIDES key %q must start with \"subject:\" or \"client:\"", k)
+			}
+		}
+	}
+
+	if v := getFrom(src, "RENDITION_SPECS", "jpeg-256,webp-256,jpeg-1024,webp-1024"); v != "none" {
+		for _, name := range strings.Split(v, ",") {
+			spec, err := domain.ParseRenditionSpec(strings.TrimSpace(name))
+			if err != nil {
+				return nil, fmt.Errorf("RENDITION_SPECS: %w", err)
+			}
+			cfg.Renditions = append(cfg.Renditions, spec)
+		}
+	}
+	cfg.RenditionMaxSourceBytes, err = loadInt64(src, "RENDITION_MAX_SOURCE_BYTES", 50*1024*1024)
+	if err != nil {
+		return nil, err
+	}
+
+	if v := src.Get("CONTENT_TYPE_ALLOWLIST"); v != "" {
+		if err := json.Unmarshal([]byte(v), &cfg.ContentTypeAllowlist); err != nil {
+			return nil, fmt.Errorf("CONTENT_TYPE_ALLOWLIST must be a JSON object of content type lists: %w", err)
+		}
+		for client, patterns := range cfg.ContentTypeAllowlist {
+			for _, p := range patterns {
+				if !strings.Contains(p, "/") {
+					return nil, fmt.Errorf("CONTENT_TYPE_ALLOWLIST entry %q for client %q is not a media type", p, client)
+				}
+			}
+		}
+	}
+
+	cfg.ClamdAddress = src.Get("CLAMD_ADDRESS")
+	clamdTimeout, err := loadInt(src, "CLAMD_TIMEOUT_SECONDS", 300)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ClamdTimeout = time.Duration(clamdTimeout) * time.Second
+	scanPoll, err := loadInt(src, "SCAN_POLL_INTERVAL_SECONDS", 5)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ScanPollInterval = time.Duration(scanPoll) * time.Second
+
+	if v := src.Get("CRYPTO_KEY"); v != "" {
+		key, err := parseCryptoKey("CRYPTO_KEY", v)
+		if err != nil {
+			return nil, err
+		}
+		cfg.CryptoKey = &key
+	}
+	if v := src.Get("CRYPTO_KEY_PREVIOUS"); v != "" {
+		if cfg.CryptoKey == nil {
+			return nil, fmt.Errorf("CRYPTO_KEY_PREVIOUS requires CRYPTO_KEY")
+		}
+		for _, h := range strings.Split(v, ",") {
+			key, err := parseCryptoKey("CRYPTO_KEY_PREVIOUS", strings.TrimSpace(h))
+			if err != nil {
+				return nil, err
+			}
+			cfg.CryptoKeyPrevious = append(cfg.CryptoKeyPrevious, key)
+		}
+	}
+
+	if cfg.ReplicaR2Endpoint = src.Get("REPLICA_R2_ENDPOINT"); cfg.ReplicaR2Endpoint != "" {
+		cfg.ReplicaR2Bucket = src.Get("REPLICA_R2_BUCKET")
+		cfg.ReplicaR2AccessKeyID = src.Get("REPLICA_R2_ACCESS_KEY_ID")
+		cfg.ReplicaR2SecretAccessKey = src.Get("REPLICA_R2_SECRET_ACCESS_KEY")
+		if cfg.ReplicaR2Bucket == "" || cfg.ReplicaR2AccessKeyID == "" || cfg.ReplicaR2SecretAccessKey == "" {
+			return nil, fmt.Errorf("REPLICA_R2_ENDPOINT requires REPLICA_R2_BUCKET, REPLICA_R2_ACCESS_KEY_ID and REPLICA_R2_SECRET_ACCESS_KEY")
+		}
+	}
+	replicationPoll, err := loadInt(src, "REPLICATION_POLL_INTERVAL_SECONDS", 30)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ReplicationPollInterval = time.Duration(replicationPoll) * time.Second
+	healthCheck, err := loadInt(src, "REPLICA_HEALTH_CHECK_SECONDS", 10)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ReplicaHealthCheckInterval = time.Duration(healthCheck) * time.Second
+
+	verifyInterval, err := loadInt(src, "VERIFY_INTERVAL_SECONDS", 0)
+	if err != nil {
+		return nil, err
+	}
+	cfg.VerifyInterval = time.Duration(verifyInterval) * time.Second
+	cfg.VerifyRepair = src.Get("VERIFY_REPAIR") == "true"
+
+	cfg.HTTPGatewayEnabled = src.Get("HTTP_GATEWAY_ENABLED") == "true"
+	cfg.TusEnabled = src.Get("TUS_ENABLED") == "true"
+	if v := src.Get("CORS_ALLOWED_ORIGINS"); v != "" {
+		if !cfg.HTTPGatewayEnabled && !cfg.TusEnabled {
+			return nil, fmt.Errorf("CORS_ALLOWED_ORIGINS requires HTTP_GATEWAY_ENABLED or TUS_ENABLED")
+		}
+		for _, o := range strings.Split(v, ",") {
+			if o = strings.TrimSpace(o); o != "" {
+				cfg.CORSAllowedOrigins = append(cfg.CORSAllowedOrigins, o)
+			}
+		}
+	}
+
+	if v := src.Get("RETENTION_CLIENTS"); v != "" {
+		for _, c := range strings.Split(v, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				cfg.RetentionClients = append(cfg.RetentionClients, c)
+			}
+		}
+	}
+
+	cfg.MetricsListenAddr = getFrom(src, "METRICS_LISTEN_ADDR", ":9092")
+	healthInterval, err := loadInt(src, "HEALTH_CHECK_INTERVAL_SECONDS", 10)
+	if err != nil {
+		return nil, err
+	}
+	if healthInterval <= 0 {
+		return nil, fmt.Errorf("HEALTH_CHECK_INTERVAL_SECONDS must be positive, got %d", healthInterval)
+	}
+	cfg.HealthCheckInterval = time.Duration(healthInterval) * time.Second
+	cfg.GRPCReflectionEnabled = src.Get("GRPC_REFLECTION_ENABLED") == "true"
+
 	cfg.DBMaxOpenConns, err = loadInt(src, "DB_MAX_OPEN_CONNS", 25)
 	if err != nil {
 		return nil, err
@@ -109,6 +391,34 @@ func LoadFrom(src ConfigSource) (*Config, error) {
 	return cfg, nil
 }
 
+func loadQuota(src ConfigSource, prefix string) (QuotaLimits, error) {
+	var (
+		l   QuotaLimits
+		err error
+	)
+	if l.MaxBytes, err = loadInt64(src, prefix+"_MAX_BYTES", 0); err != nil {
+		return l, err
+	}
+	if l.MaxObjects, err = loadInt64(src, prefix+"_MAX_OBJECTS", 0); err != nil {
+		return l, err
+	}
+	l.MaxObjectBytes, err = loadInt64(src, prefix+"_MAX_OBJECT_BYTES", 0)
+	return l, err
+}
+
+func parseCryptoKey(name, hexKey string) ([32]byte, error) {
+	var key [32]byte
+	raw, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return key, fmt.Errorf("%s must be hex-encoded: %w", name, err)
+	}
+	if len(raw) != 32 {
+		return key, fmt.Errorf("%s must be exactly 32 bytes (64 hex chars), got %d bytes", name, len(raw))
+	}
+	copy(key[:], raw)
+	return key, nil
+}
+
 func getFrom(src ConfigSource, key, fallback string) string {
 	if v := src.Get(key); v != "" {
 		return v
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+// The methods in this file serve operators and act on any blob.
+
+// StatBlob returns the blob's record.
+func (a *App) StatBlob(ctx context.Context, id domain.BlobID) (*domain.Blob, error) {
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+	blob, err := a.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("StatBlob: %w", err)
+	}
+	return blob, nil
+}
+
+// ListAllBlobs returns up to limit blobs in the given state, or in any state
+// if it is empty, ordered by ID and starting after afterID.
+func (a *App) ListAllBlobs(ctx context.Context, state domain.UploadState, afterID domain.BlobID, limit int) ([]*domain.Blob, error) {
+	blobs, err := a.repo.ListBlobs(ctx, domain.BlobFilter{State: state}, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ListAllBlobs: %w", err)
+	}
+	return blobs, nil
+}
+
+// PresignBlobDownload signs a URL that downloads the whole blob as an
+// attachment. The TTL is capped as for GetDownloadURL.
+func (a *App) PresignBlobDownload(ctx context.Context, id domain.BlobID, ttl time.Duration) (*GetDownloadURLResult, error) {
+	blob, err := a.StatBlob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := blob.Readable(); err != nil {
+		return nil, fmt.Errorf("PresignBlobDownload: %w", err)
+	}
+	if ttl <= 0 || ttl > a.cfg.PresignGetMaxTTL {
+		ttl = a.cfg.PresignGetMaxTTL
+	}
+
+	objects, err := a.downloadObjects(ctx, blob)
+	if err != nil {
+		return nil, fmt.Errorf("PresignBlobDownload: %w", err)
+	}
+	get := domain.GetOptions{ContentDisposition: domain.DispositionAttachment}
+	req, err := objects.PresignedGetURL(ctx, blob.R2Key, get, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("PresignBlobDownload: %w", err)
+	}
+	return &GetDownloadURLResult{
+		PresignedGetURL: req.URL,
+		RequiredHeaders: req.Headers,
+		ExpiresAt:       req.ExpiresAt,
+	}, nil
+}
+
+// ExportBlob writes the blob's content to w, failing with ErrContentMismatch
+// after the last byte if it does not hash to the blob ID.
+func (a *App) ExportBlob(ctx context.Context, id domain.BlobID, w io.Writer) (*domain.Blob, error) {
+	blob, err := a.StatBlob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := blob.Readable(); err != nil {
+		return nil, fmt.Errorf("ExportBlob: %w", err)
+	}
+	objects, err := a.objects(blob)
+	if err != nil {
+		return nil, fmt.Errorf("ExportBlob: %w", err)
+	}
+	body, err := objects.GetObject(ctx, blob.R2Key)
+	if err != nil {
+		return nil, fmt.Errorf("ExportBlob: %w", err)
+	}
+	defer func() { _ = body.Close() }()
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(w, h), body)
+	if err != nil {
+		return nil, fmt.Errorf("ExportBlob: %w", err)
+	}
+	if n != blob.SizeBytes || domain.BlobID(hex.EncodeToString(h.Sum(nil))) != id {
+		return nil, fmt.Errorf("ExportBlob: %w: stored content does not hash to the blob ID", domain.ErrContentMismatch)
+	}
+	return blob, nil
+}
+
+// ImportBlob uploads r through a resumable upload, so an import that is cut
+// short picks up where it left off when run again.
+func (a *App) ImportBlob(ctx context.Context, caller domain.Caller, r io.ReaderAt, size int64, contentType string, encrypt bool) (*ResumableUpload, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(r, 0, size)); err != nil {
+		return nil, fmt.Errorf("ImportBlob: %w", err)
+	}
+	id := domain.BlobID(hex.EncodeToString(h.Sum(nil)))
+	if contentType == "" {
+		head := make([]byte, min(size, 512))
+		if _, err := r.ReadAt(head, 0); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("ImportBlob: %w", err)
+		}
+		contentType = http.DetectContentType(head)
+	}
+
+	upload, err := a.CreateResumableUpload(ctx, caller, id, size, contentType, encrypt)
+	if err != nil {
+		return nil, fmt.Errorf("ImportBlob: %w", err)
+	}
+	if upload.State != domain.StatePending {
+		return upload, nil
+	}
+	// Each append takes at most a part's worth of the chunk.
+	for upload.Offset < size {
+		upload, err = a.AppendResumableUpload(ctx, caller, id, upload.Offset, io.NewSectionReader(r, upload.Offset, size-upload.Offset), size-upload.Offset)
+		if err != nil {
+			return nil, fmt.Errorf("ImportBlob: %w", err)
+		}
+	}
+	if upload.State == domain.StatePending {
+		// Every byte arrived in an earlier run that failed to complete.
+		if upload, err = a.GetResumableUpload(ctx, caller, id); err != nil {
+			return nil, fmt.Errorf("ImportBlob: %w", err)
+		}
+	}
+	return upload, nil
+}
@@ -0,0 +1,111 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+func TestStatBlob_IgnoresReferences(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	id := seedCommitted(repo, storage, []byte("nobody holds this"))
+	a := newApp(repo, storage)
+
+	blob, err := a.StatBlob(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateCommitted, blob.State)
+
+	_, err = a.StatBlob(context.Background(), domain.BlobID(validID))
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+}
+
+func TestListAllBlobs_ByState(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	committed := seedCommitted(repo, storage, []byte("committed"))
+	quarantined, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	quarantined.State = domain.StateQuarantined
+	repo.seed(quarantined)
+	a := newApp(repo, storage)
+
+	blobs, err := a.ListAllBlobs(context.Background(), domain.StateCommitted, "", 10)
+	require.NoError(t, err)
+	require.Len(t, blobs, 1)
+	assert.Equal(t, committed, blobs[0].ID)
+
+	blobs, err = a.ListAllBlobs(context.Background(), "", "", 10)
+	require.NoError(t, err)
+	assert.Len(t, blobs, 2)
+}
+
+func TestPresignBlobDownload(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{getURL: "https://r2.example.com/get"}
+	id := seedCommitted(repo, storage, []byte("download me"))
+	a := newApp(repo, storage)
+
+	result, err := a.PresignBlobDownload(context.Background(), id, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "https://r2.example.com/get", result.PresignedGetURL)
+	assert.Equal(t, domain.DispositionAttachment, storage.getOptions.ContentDisposition)
+	assert.WithinDuration(t, time.Now().Add(testCfg.PresignGetMaxTTL), result.ExpiresAt, time.Minute)
+}
+
+func TestExportBlob(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	content := []byte("exported byte for byte")
+	id := seedCommitted(repo, storage, content)
+	a := newApp(repo, storage)
+
+	var out bytes.Buffer
+	_, err := a.ExportBlob(context.Background(), id, &out)
+	require.NoError(t, err)
+	assert.Equal(t, content, out.Bytes())
+
+	storage.objects[string(id)] = []byte("bit rot in the bucket!")
+	_, err = a.ExportBlob(context.Background(), id, &bytes.Buffer{})
+	assert.ErrorIs(t, err, domain.ErrContentMismatch)
+}
+
+func TestImportBlob(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{uploadID: "mpu-1"}
+	a := newApp(repo, storage)
+	content := []byte("<html><body>imported</body></html>")
+
+	upload, err := a.ImportBlob(context.Background(), testCaller, bytes.NewReader(content), int64(len(content)), "", false)
+	require.NoError(t, err)
+	assert.Equal(t, sha256Hex(content), upload.BlobID)
+	assert.Equal(t, domain.StateCommitted, upload.State)
+	assert.Equal(t, content, storage.objects[repo.blobs[upload.BlobID].R2Key])
+	assert.Equal(t, "text/html; charset=utf-8", repo.blobs[upload.BlobID].ContentType, "the type is sniffed")
+	assert.True(t, repo.refs[upload.BlobID][testCaller.Subject])
+
+	again, err := a.ImportBlob(context.Background(), testCaller, bytes.NewReader(content), int64(len(content)), "", false)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateCommitted, again.State, "importing committed content is a no-op")
+}
+
+func TestImportBlob_CompletesAfterFailedRun(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{uploadID: "mpu-1", completeErr: assert.AnError}
+	a := newApp(repo, storage)
+	content, _ := resumableContent()
+
+	_, err := a.ImportBlob(context.Background(), testCaller, bytes.NewReader(content), int64(len(content)), "", false)
+	assert.ErrorIs(t, err, assert.AnError)
+
+	storage.completeErr = nil
+	upload, err := a.ImportBlob(context.Background(), testCaller, bytes.NewReader(content), int64(len(content)), "", false)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateCommitted, upload.State, "a rerun completes the upload whose bytes all arrived")
+	assert.Equal(t, content, storage.objects[repo.blobs[upload.BlobID].R2Key])
+}
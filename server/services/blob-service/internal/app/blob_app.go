@@ -2,8 +2,13 @@ package app
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
@@ -13,6 +18,33 @@ type Config struct {
 	PresignPutTTL           time.Duration
 	PresignGetMaxTTL        time.Duration
 	MultipartThresholdBytes int64
+	GCGracePeriod           time.Duration
+	PendingTTL              time.Duration
+	EventPollInterval       time.Duration
+	EventRetention          time.Duration
+	Quotas                  domain.QuotaPolicy
+	// Renditions are generated for every committed image blob, and are the
+	// only specs GetRendition serves.
+	Renditions              []domain.RenditionSpec
+	RenditionMaxSourceBytes int64
+	// ContentTypes restricts what each client may store. Both the declared
+	// type and the type sniffed on commit must be allowed.
+	ContentTypes domain.ContentTypePolicy
+	// Scanner, when set, must find completed uploads clean before they are
+	// committed. Blobs wait in SCANNING until RunScanner gets to them.
+	Scanner          domain.Scanner
+	ScanPollInterval time.Duration
+	// Keys wraps the data keys of blobs uploaded with encryption requested.
+	// Without it such uploads fail with ErrEncryptionUnavailable.
+	Keys domain.KeyWrapper
+	// ReplicationPollInterval is how often RunReplication looks for blobs
+	// to copy when the storage is a domain.ReplicatedStorage.
+	ReplicationPollInterval time.Duration
+	// RetentionClients are the clients allowed to set retention periods and
+	// legal holds, on any blob.
+	RetentionClients []string
+	// Uploads, when set, counts the uploads that verify.
+	Uploads domain.UploadRecorder
 }
 
 type App struct {
@@ -25,66 +57,193 @@ func New(repo domain.BlobRepository, storage domain.ObjectStorage, cfg Config) *
 	return &App{repo: repo, storage: storage, cfg: cfg}
 }
 
+// PlanUpload tells the client whether to upload an object of sizeBytes with a
+// single PUT or as a multipart upload, and how to split it into parts.
+func (a *App) PlanUpload(sizeBytes int64) (*domain.UploadPlan, error) {
+	plan, err := domain.PlanUpload(sizeBytes, a.cfg.MultipartThresholdBytes)
+	if err != nil {
+		return nil, fmt.Errorf("PlanUpload: %w", err)
+	}
+	return plan, nil
+}
+
 type InitiateUploadResult struct {
 	AlreadyExists   bool
 	PresignedPutURL string
+	RequiredHeaders map[string]string
 	ExpiresAt       time.Time
 }
 
-func (a *App) InitiateUpload(ctx context.Context, id domain.BlobID, sizeBytes int64, contentType string) (*InitiateUploadResult, error) {
+// InitiateUpload presigns a PUT of the blob's content to an object of the
+// caller's own. Only callers that already reference the blob skip the upload,
+// so a reference is never granted for content the caller does not have.
+func (a *App) InitiateUpload(ctx context.Context, caller domain.Caller, id domain.BlobID, sizeBytes int64, contentType string, encrypt bool) (*InitiateUploadResult, error) {
 	if err := id.Validate(); err != nil {
 		return nil, err
 	}
 
-	existing, err := a.repo.FindByID(ctx, id)
-	if err != nil && !errors.Is(err, domain.ErrBlobNotFound) {
+	held, err := a.heldBlob(ctx, caller, id)
+	if err != nil {
 		return nil, fmt.Errorf("InitiateUpload: %w", err)
 	}
-
-	if existing != nil && existing.State == domain.StateCommitted {
+	if held != nil {
 		return &InitiateUploadResult{AlreadyExists: true}, nil
 	}
-
-	if existing == nil {
-		blob, err := domain.NewBlob(id, sizeBytes, contentType, time.Now().UTC())
-		if err != nil {
-			return nil, fmt.Errorf("InitiateUpload: %w", err)
-		}
-		if err := a.repo.Create(ctx, blob); err != nil {
-			return nil, fmt.Errorf("InitiateUpload: %w", err)
-		}
+	upload, err := a.openUpload(ctx, caller, id, sizeBytes, contentType, encrypt)
+	if err != nil {
+		return nil, fmt.Errorf("InitiateUpload: %w", err)
 	}
 
-	url, expiresAt, err := a.storage.PresignedPutURL(ctx, string(id), a.cfg.PresignPutTTL)
+	objects, err := a.objects(upload.Blob())
+	if err != nil {
+		return nil, fmt.Errorf("InitiateUpload: %w", err)
+	}
+	req, err := objects.PresignedPutURL(ctx, upload.ObjectKey, domain.PutConstraints{
+		SizeBytes:      upload.SizeBytes,
+		ContentType:    upload.ContentType,
+		ChecksumSHA256: string(upload.BlobID),
+	}, a.cfg.PresignPutTTL)
 	if err != nil {
 		return nil, fmt.Errorf("InitiateUpload: %w", err)
 	}
-	return &InitiateUploadResult{PresignedPutURL: url, ExpiresAt: expiresAt}, nil
+	return &InitiateUploadResult{
+		PresignedPutURL: req.URL,
+		RequiredHeaders: req.Headers,
+		ExpiresAt:       req.ExpiresAt,
+	}, nil
 }
 
+// CompleteUploadResult's CommittedAt is zero while the blob is SCANNING.
 type CompleteUploadResult struct {
 	BlobID      domain.BlobID
+	State       domain.UploadState
 	CommittedAt time.Time
 }
 
-func (a *App) CompleteUpload(ctx context.Context, id domain.BlobID) (*CompleteUploadResult, error) {
+func (a *App) CompleteUpload(ctx context.Context, caller domain.Caller, id domain.BlobID) (*CompleteUploadResult, error) {
 	if err := id.Validate(); err != nil {
 		return nil, err
 	}
 
-	blob, err := a.repo.FindByID(ctx, id)
+	upload, err := a.findUpload(ctx, caller, id)
 	if err != nil {
 		return nil, fmt.Errorf("CompleteUpload: %w", err)
 	}
-	if blob.State == domain.StateCommitted {
-		return nil, fmt.Errorf("CompleteUpload: %w", domain.ErrAlreadyCommitted)
+	blob, err := a.completeObject(ctx, caller, upload)
+	if err != nil {
+		return nil, fmt.Errorf("CompleteUpload: %w", err)
 	}
+	return &CompleteUploadResult{BlobID: id, State: blob.State, CommittedAt: committedAt(blob)}, nil
+}
 
-	now := time.Now().UTC()
-	if err := a.repo.MarkCommitted(ctx, id, now); err != nil {
-		return nil, fmt.Errorf("CompleteUpload: %w", err)
+// completeObject verifies and classifies the uploaded object and then
+// finishes the upload.
+func (a *App) completeObject(ctx context.Context, caller domain.Caller, upload *domain.Upload) (*domain.Blob, error) {
+	pending := upload.Blob()
+	if err := a.verifyObject(ctx, pending); err != nil {
+		return nil, err
+	}
+	if err := a.classifyObject(ctx, caller, pending); err != nil {
+		return nil, err
+	}
+	return a.finishUpload(ctx, upload, pending.DetectedContentType)
+}
+
+// finishUpload turns a verified upload into the caller's reference to the
+// blob, creating the blob from the upload if it does not exist yet.
+func (a *App) finishUpload(ctx context.Context, upload *domain.Upload, detectedContentType string) (*domain.Blob, error) {
+	if a.cfg.Uploads != nil {
+		a.cfg.Uploads.UploadVerified(upload.SizeBytes)
+	}
+	blob := upload.Blob()
+	blob.DetectedContentType = detectedContentType
+	if a.cfg.Scanner != nil {
+		blob.State = domain.StateScanning
+	} else {
+		now := time.Now().UTC()
+		blob.State, blob.CommittedAt = domain.StateCommitted, &now
+	}
+
+	stored, err := a.repo.CommitUpload(ctx, upload, blob, a.cfg.Quotas)
+	if err != nil {
+		return nil, err
+	}
+	if stored.R2Key != upload.ObjectKey {
+		if err := a.storage.DeleteObject(ctx, upload.ObjectKey); err != nil {
+			slog.Error("failed to delete duplicate upload", "blob_id", upload.BlobID, "key", upload.ObjectKey, "error", err)
+		}
+	}
+	return stored, nil
+}
+
+func committedAt(blob *domain.Blob) time.Time {
+	if blob.CommittedAt == nil {
+		return time.Time{}
 	}
-	return &CompleteUploadResult{BlobID: id, CommittedAt: now}, nil
+	return *blob.CommittedAt
+}
+
+// uploadClosed explains why a blob the caller holds has nothing to upload.
+func uploadClosed(blob *domain.Blob) error {
+	if blob.State == domain.StateQuarantined {
+		return domain.ErrBlobQuarantined
+	}
+	return domain.ErrAlreadyCommitted
+}
+
+// verifyObject checks the uploaded object's size and hash against the blob,
+// deleting it on mismatch so the client can upload again.
+func (a *App) verifyObject(ctx context.Context, blob *domain.Blob) error {
+	objects, err := a.objects(blob)
+	if err != nil {
+		return err
+	}
+	info, err := objects.StatObject(ctx, blob.R2Key)
+	if err != nil {
+		return err
+	}
+
+	mismatch := checkObject(blob, info.SizeBytes, info.ChecksumSHA256)
+	if mismatch == nil && info.ChecksumSHA256 == "" {
+		size, sum, err := hashObject(ctx, objects, blob.R2Key)
+		if err != nil {
+			return err
+		}
+		mismatch = checkObject(blob, size, sum)
+	}
+	if mismatch == nil {
+		return nil
+	}
+
+	if err := a.storage.DeleteObject(ctx, blob.R2Key); err != nil {
+		slog.Error("failed to delete mismatched object", "blob_id", blob.ID, "error", err)
+	}
+	return mismatch
+}
+
+func hashObject(ctx context.Context, objects domain.ObjectStorage, key string) (int64, string, error) {
+	body, err := objects.GetObject(ctx, key)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() { _ = body.Close() }()
+
+	h := sha256.New()
+	n, err := io.Copy(h, body)
+	if err != nil {
+		return 0, "", fmt.Errorf("hash object: %w", err)
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func checkObject(blob *domain.Blob, size int64, sum string) error {
+	if size != blob.SizeBytes {
+		return fmt.Errorf("%w: stored %d bytes, declared %d", domain.ErrContentMismatch, size, blob.SizeBytes)
+	}
+	if sum != "" && sum != string(blob.ID) {
+		return fmt.Errorf("%w: stored content hashes to %s", domain.ErrContentMismatch, sum)
+	}
+	return nil
 }
 
 type InitiateMultipartResult struct {
@@ -97,134 +256,574 @@ type InitiateMultipartResult struct {
 type PartUploadURL struct {
 	PartNumber      int32
 	PresignedPutURL string
+	RequiredHeaders map[string]string
 }
 
-func (a *App) InitiateMultipartUpload(ctx context.Context, id domain.BlobID, sizeBytes int64, contentType string, partCount int32) (*InitiateMultipartResult, error) {
+func (a *App) InitiateMultipartUpload(ctx context.Context, caller domain.Caller, id domain.BlobID, sizeBytes int64, contentType string, partCount int32, encrypt bool) (*InitiateMultipartResult, error) {
 	if err := id.Validate(); err != nil {
 		return nil, err
 	}
-	if partCount < 1 {
-		return nil, fmt.Errorf("part_count must be at least 1")
+	if err := domain.ValidatePartCount(sizeBytes, partCount); err != nil {
+		return nil, fmt.Errorf("InitiateMultipartUpload: %w", err)
 	}
 
-	existing, err := a.repo.FindByID(ctx, id)
-	if err != nil && !errors.Is(err, domain.ErrBlobNotFound) {
+	held, err := a.heldBlob(ctx, caller, id)
+	if err != nil {
 		return nil, fmt.Errorf("InitiateMultipartUpload: %w", err)
 	}
-
-	if existing != nil && existing.State == domain.StateCommitted {
+	if held != nil {
 		return &InitiateMultipartResult{AlreadyExists: true}, nil
 	}
-
-	if existing == nil {
-		blob, err := domain.NewBlob(id, sizeBytes, contentType, time.Now().UTC())
-		if err != nil {
-			return nil, fmt.Errorf("InitiateMultipartUpload: %w", err)
-		}
-		if err := a.repo.Create(ctx, blob); err != nil {
-			return nil, fmt.Errorf("InitiateMultipartUpload: %w", err)
-		}
+	upload, err := a.openUpload(ctx, caller, id, sizeBytes, contentType, encrypt)
+	if err != nil {
+		return nil, fmt.Errorf("InitiateMultipartUpload: %w", err)
 	}
 
-	uploadID, err := a.storage.CreateMultipartUpload(ctx, string(id), contentType)
+	session, err := a.openSession(ctx, upload, partCount, false)
 	if err != nil {
 		return nil, fmt.Errorf("InitiateMultipartUpload: %w", err)
 	}
 
-	parts := make([]PartUploadURL, partCount)
-	var expiresAt time.Time
-	for i := int32(0); i < partCount; i++ {
-		partNum := i + 1
-		url, exp, err := a.storage.PresignedPartURL(ctx, string(id), uploadID, partNum, a.cfg.PresignPutTTL)
-		if err != nil {
-			return nil, fmt.Errorf("InitiateMultipartUpload part %d: %w", partNum, err)
-		}
-		parts[i] = PartUploadURL{PartNumber: partNum, PresignedPutURL: url}
-		expiresAt = exp
+	partNumbers := make([]int32, partCount)
+	for i := range partNumbers {
+		partNumbers[i] = int32(i) + 1
+	}
+	parts, expiresAt, err := a.presignParts(ctx, upload.Blob(), session, partNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("InitiateMultipartUpload: %w", err)
 	}
 
 	return &InitiateMultipartResult{
-		UploadID:  uploadID,
+		UploadID:  session.UploadID,
 		Parts:     parts,
 		ExpiresAt: expiresAt,
 	}, nil
 }
 
+type ResumeMultipartResult struct {
+	AlreadyExists bool
+	UploadID      string
+	UploadedParts []domain.UploadedPart
+	MissingParts  []PartUploadURL
+	ExpiresAt     time.Time
+}
+
+// ResumeMultipartUpload reports the parts already stored for the caller's
+// multipart session and presigns fresh URLs for the ones still missing.
+func (a *App) ResumeMultipartUpload(ctx context.Context, caller domain.Caller, id domain.BlobID) (*ResumeMultipartResult, error) {
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+
+	upload, held, err := a.findUploadOrBlob(ctx, caller, id)
+	if err != nil {
+		return nil, fmt.Errorf("ResumeMultipartUpload: %w", err)
+	}
+	if held != nil {
+		return &ResumeMultipartResult{AlreadyExists: true}, nil
+	}
+
+	session, err := a.repo.FindMultipartUpload(ctx, id, caller.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("ResumeMultipartUpload: %w", err)
+	}
+	if session.Resumable {
+		return nil, fmt.Errorf("ResumeMultipartUpload: %w", domain.ErrSessionNotFound)
+	}
+
+	uploaded, err := a.storage.ListParts(ctx, upload.ObjectKey, session.UploadID)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		// The upload is gone from storage; forget it so the next
+		// InitiateMultipartUpload starts a fresh one.
+		if err := a.repo.DeleteMultipartUpload(ctx, session.UploadID); err != nil {
+			slog.Error("failed to delete stale multipart session", "blob_id", id, "error", err)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ResumeMultipartUpload: %w", err)
+	}
+
+	have := make(map[int32]bool, len(uploaded))
+	for _, p := range uploaded {
+		have[p.PartNumber] = true
+	}
+	var missing []int32
+	for n := int32(1); n <= session.PartCount; n++ {
+		if !have[n] {
+			missing = append(missing, n)
+		}
+	}
+
+	parts, expiresAt, err := a.presignParts(ctx, upload.Blob(), session, missing)
+	if err != nil {
+		return nil, fmt.Errorf("ResumeMultipartUpload: %w", err)
+	}
+	return &ResumeMultipartResult{
+		UploadID:      session.UploadID,
+		UploadedParts: uploaded,
+		MissingParts:  parts,
+		ExpiresAt:     expiresAt,
+	}, nil
+}
+
+// openSession returns the upload's multipart session, replacing one whose
+// parts no longer line up with the client's.
+func (a *App) openSession(ctx context.Context, upload *domain.Upload, partCount int32, resumable bool) (*domain.MultipartSession, error) {
+	id, key := upload.BlobID, upload.ObjectKey
+	session, err := a.repo.FindMultipartUpload(ctx, id, upload.Subject)
+	switch {
+	case err == nil && session.PartCount == partCount && session.Resumable == resumable:
+		return session, nil
+	case err == nil:
+		if err := a.storage.AbortMultipartUpload(ctx, key, session.UploadID); err != nil {
+			return nil, err
+		}
+		if err := a.repo.DeleteMultipartUpload(ctx, session.UploadID); err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, domain.ErrSessionNotFound):
+		return nil, err
+	}
+
+	objects, err := a.objects(upload.Blob())
+	if err != nil {
+		return nil, err
+	}
+	uploadID, err := objects.CreateMultipartUpload(ctx, key, upload.ContentType)
+	if err != nil {
+		return nil, err
+	}
+	session = &domain.MultipartSession{
+		BlobID:    id,
+		Subject:   upload.Subject,
+		UploadID:  uploadID,
+		PartCount: partCount,
+		CreatedAt: time.Now().UTC(),
+		Resumable: resumable,
+	}
+	err = a.repo.RecordMultipartUpload(ctx, session)
+	if errors.Is(err, domain.ErrSessionExists) {
+		// A concurrent call recorded its session first; use that one.
+		if err := a.storage.AbortMultipartUpload(ctx, key, uploadID); err != nil {
+			slog.Error("failed to abort duplicate multipart upload", "blob_id", id, "error", err)
+		}
+		return a.repo.FindMultipartUpload(ctx, id, upload.Subject)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// presignParts presigns the given parts of the session, each constrained to
+// its length in the SplitParts split of the blob.
+func (a *App) presignParts(ctx context.Context, blob *domain.Blob, session *domain.MultipartSession, partNumbers []int32) ([]PartUploadURL, time.Time, error) {
+	objects, err := a.objects(blob)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	split := domain.SplitParts(blob.SizeBytes, session.PartCount)
+	parts := make([]PartUploadURL, len(partNumbers))
+	var expiresAt time.Time
+	for i, partNum := range partNumbers {
+		req, err := objects.PresignedPartURL(ctx, blob.R2Key, session.UploadID, partNum, split[partNum-1].SizeBytes, a.cfg.PresignPutTTL)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("part %d: %w", partNum, err)
+		}
+		parts[i] = PartUploadURL{PartNumber: partNum, PresignedPutURL: req.URL, RequiredHeaders: req.Headers}
+		expiresAt = req.ExpiresAt
+	}
+	return parts, expiresAt, nil
+}
+
 type CompleteMultipartResult struct {
 	BlobID      domain.BlobID
+	State       domain.UploadState
 	CommittedAt time.Time
 }
 
-func (a *App) CompleteMultipartUpload(ctx context.Context, id domain.BlobID, uploadID string, parts []domain.CompletedPart) (*CompleteMultipartResult, error) {
+func (a *App) CompleteMultipartUpload(ctx context.Context, caller domain.Caller, id domain.BlobID, uploadID string, parts []domain.CompletedPart) (*CompleteMultipartResult, error) {
 	if err := id.Validate(); err != nil {
 		return nil, err
 	}
 
-	blob, err := a.repo.FindByID(ctx, id)
+	upload, err := a.findUpload(ctx, caller, id)
+	if err != nil {
+		return nil, fmt.Errorf("CompleteMultipartUpload: %w", err)
+	}
+	session, err := a.repo.FindMultipartUpload(ctx, id, caller.Subject)
 	if err != nil {
 		return nil, fmt.Errorf("CompleteMultipartUpload: %w", err)
 	}
-	if blob.State == domain.StateCommitted {
-		return nil, fmt.Errorf("CompleteMultipartUpload: %w", domain.ErrAlreadyCommitted)
+	if session.UploadID != uploadID {
+		return nil, fmt.Errorf("CompleteMultipartUpload: %w", domain.ErrSessionNotFound)
 	}
 
-	if err := a.storage.CompleteMultipartUpload(ctx, string(id), uploadID, parts); err != nil {
+	blob, err := a.completeMultipart(ctx, caller, upload, uploadID, parts)
+	if err != nil {
 		return nil, fmt.Errorf("CompleteMultipartUpload: %w", err)
 	}
+	return &CompleteMultipartResult{BlobID: id, State: blob.State, CommittedAt: committedAt(blob)}, nil
+}
 
-	now := time.Now().UTC()
-	if err := a.repo.MarkCommitted(ctx, id, now); err != nil {
-		return nil, fmt.Errorf("CompleteMultipartUpload: %w", err)
+// completeMultipart combines the parts of the upload's multipart session into
+// its object, then verifies and finishes it as CompleteUpload does.
+func (a *App) completeMultipart(ctx context.Context, caller domain.Caller, upload *domain.Upload, uploadID string, parts []domain.CompletedPart) (*domain.Blob, error) {
+	pending := upload.Blob()
+	objects, err := a.objects(pending)
+	if err != nil {
+		return nil, err
+	}
+	if err := objects.CompleteMultipartUpload(ctx, upload.ObjectKey, uploadID, parts); err != nil {
+		return nil, err
 	}
-	return &CompleteMultipartResult{BlobID: id, CommittedAt: now}, nil
+
+	if err := a.verifyObject(ctx, pending); err != nil {
+		return nil, err
+	}
+
+	if err := a.repo.DeleteMultipartUpload(ctx, uploadID); err != nil {
+		return nil, err
+	}
+	if err := a.classifyObject(ctx, caller, pending); err != nil {
+		return nil, err
+	}
+	return a.finishUpload(ctx, upload, pending.DetectedContentType)
 }
 
-func (a *App) AbortMultipartUpload(ctx context.Context, id domain.BlobID, uploadID string) error {
+// AbortMultipartUpload aborts the caller's own multipart session of the blob;
+// any other upload ID is reported as ErrSessionNotFound.
+func (a *App) AbortMultipartUpload(ctx context.Context, caller domain.Caller, id domain.BlobID, uploadID string) error {
 	if err := id.Validate(); err != nil {
 		return err
 	}
-	if err := a.storage.AbortMultipartUpload(ctx, string(id), uploadID); err != nil {
+	upload, err := a.findUpload(ctx, caller, id)
+	if err != nil {
 		return fmt.Errorf("AbortMultipartUpload: %w", err)
 	}
+	session, err := a.repo.FindMultipartUpload(ctx, id, caller.Subject)
+	if err != nil {
+		return fmt.Errorf("AbortMultipartUpload: %w", err)
+	}
+	if session.UploadID != uploadID {
+		return fmt.Errorf("AbortMultipartUpload: %w", domain.ErrSessionNotFound)
+	}
+	if err := a.storage.AbortMultipartUpload(ctx, upload.ObjectKey, uploadID); err != nil {
+		return fmt.Errorf("AbortMultipartUpload: %w", err)
+	}
+	if err := a.repo.DeleteMultipartUpload(ctx, uploadID); err != nil {
+		return fmt.Errorf("AbortMultipartUpload: %w", err)
+	}
+	a.dropStagedParts(ctx, upload.ObjectKey)
 	return nil
 }
 
 type GetDownloadURLResult struct {
 	PresignedGetURL string
+	RequiredHeaders map[string]string
 	ExpiresAt       time.Time
 }
 
-func (a *App) GetDownloadURL(ctx context.Context, id domain.BlobID, ttl time.Duration) (*GetDownloadURLResult, error) {
+// DownloadOptions are caller-supplied overrides for a download URL. A zero
+// Length reads to the end; a Filename alone implies attachment.
+type DownloadOptions struct {
+	Disposition  string
+	Filename     string
+	ContentType  string
+	CacheControl string
+	Offset       int64
+	Length       int64
+}
+
+func (a *App) GetDownloadURL(ctx context.Context, caller domain.Caller, id domain.BlobID, ttl time.Duration, opts DownloadOptions) (*GetDownloadURLResult, error) {
 	if err := id.Validate(); err != nil {
 		return nil, err
 	}
 
+	var (
+		get domain.GetOptions
+		err error
+	)
+	if get.ContentDisposition, err = domain.FormatContentDisposition(opts.Disposition, opts.Filename); err != nil {
+		return nil, err
+	}
+	if get.ContentType, err = domain.NormalizeContentType(opts.ContentType); err != nil {
+		return nil, err
+	}
+	if get.CacheControl, err = domain.NormalizeCacheControl(opts.CacheControl); err != nil {
+		return nil, err
+	}
+
 	if ttl <= 0 || ttl > a.cfg.PresignGetMaxTTL {
 		ttl = a.cfg.PresignGetMaxTTL
 	}
 
-	blob, err := a.repo.FindByID(ctx, id)
+	blob, err := a.findReadable(ctx, caller, id)
 	if err != nil {
 		return nil, fmt.Errorf("GetDownloadURL: %w", err)
 	}
-	if blob.State == domain.StatePending {
-		return nil, fmt.Errorf("GetDownloadURL: %w", domain.ErrBlobPending)
+	if get.ContentType == "" && !strings.HasPrefix(get.ContentDisposition, domain.DispositionAttachment) {
+		// Content a browser may render must not be served as a type the
+		// sniffer contradicts, e.g. HTML uploaded as an image.
+		if ct := blob.InlineContentType(); ct != blob.ContentType {
+			get.ContentType = ct
+		}
 	}
 
-	url, expiresAt, err := a.storage.PresignedGetURL(ctx, string(id), ttl)
+	if opts.Offset != 0 || opts.Length != 0 {
+		if err := checkRange(opts.Offset, opts.Length, blob.SizeBytes); err != nil {
+			return nil, fmt.Errorf("GetDownloadURL: %w", err)
+		}
+		get.Offset, get.Length = opts.Offset, opts.Length
+		if get.Length == 0 || get.Offset+get.Length > blob.SizeBytes {
+			get.Length = blob.SizeBytes - get.Offset
+		}
+	}
+
+	objects, err := a.downloadObjects(ctx, blob)
 	if err != nil {
 		return nil, fmt.Errorf("GetDownloadURL: %w", err)
 	}
-	return &GetDownloadURLResult{PresignedGetURL: url, ExpiresAt: expiresAt}, nil
+	req, err := objects.PresignedGetURL(ctx, blob.R2Key, get, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("GetDownloadURL: %w", err)
+	}
+	return &GetDownloadURLResult{
+		PresignedGetURL: req.URL,
+		RequiredHeaders: req.Headers,
+		ExpiresAt:       req.ExpiresAt,
+	}, nil
+}
+
+// checkRange rejects negative ranges and offsets at or past the end of the
+// blob, except offset 0 of an empty blob.
+func checkRange(offset, length, size int64) error {
+	if offset < 0 || length < 0 || (offset > 0 && offset >= size) {
+		return fmt.Errorf("%w: offset %d, length %d, size %d", domain.ErrInvalidRange, offset, length, size)
+	}
+	return nil
 }
 
-func (a *App) GetBlobInfo(ctx context.Context, id domain.BlobID) (*domain.Blob, error) {
+func (a *App) GetBlobInfo(ctx context.Context, caller domain.Caller, id domain.BlobID) (*domain.Blob, error) {
 	if err := id.Validate(); err != nil {
 		return nil, err
 	}
-	blob, err := a.repo.FindByID(ctx, id)
+	blob, err := a.findReferenced(ctx, caller, id)
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		// The caller's own upload of the blob reads as a PENDING blob.
+		var upload *domain.Upload
+		if upload, err = a.repo.FindUpload(ctx, id, caller.Subject); err == nil {
+			blob = upload.Blob()
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("GetBlobInfo: %w", err)
 	}
 	return blob, nil
 }
+
+// AddReference grants subject, or the caller if empty, a reference to a
+// blob the caller holds. The grant is charged to subject alone.
+func (a *App) AddReference(ctx context.Context, caller domain.Caller, id domain.BlobID, subject string) error {
+	if err := id.Validate(); err != nil {
+		return err
+	}
+	blob, err := a.findReadable(ctx, caller, id)
+	if err != nil {
+		return fmt.Errorf("AddReference: %w", err)
+	}
+	if subject == "" || subject == caller.Subject {
+		return nil
+	}
+	// The grant is made through the caller's client, which is charged for it.
+	grantee := domain.Caller{Subject: subject, ClientID: caller.ClientID}
+	held, err := a.repo.HasReference(ctx, id, subject)
+	if err != nil {
+		return fmt.Errorf("AddReference: %w", err)
+	}
+	if !held {
+		if err := a.checkQuota(ctx, grantee, blob.SizeBytes); err != nil {
+			return fmt.Errorf("AddReference: %w", err)
+		}
+	}
+	if err := a.repo.AddReference(ctx, id, grantee.Subject, grantee.ClientID, a.cfg.Quotas); err != nil {
+		return fmt.Errorf("AddReference: %w", err)
+	}
+	return nil
+}
+
+// ReleaseReference drops the caller's reference. Once no caller references a
+// blob it becomes eligible for garbage collection after the grace period.
+func (a *App) ReleaseReference(ctx context.Context, caller domain.Caller, id domain.BlobID) error {
+	if err := id.Validate(); err != nil {
+		return err
+	}
+	if err := a.repo.RemoveReference(ctx, id, caller.Subject, time.Now().UTC()); err != nil {
+		return fmt.Errorf("ReleaseReference: %w", err)
+	}
+	return nil
+}
+
+// heldBlob returns the blob if the caller holds a reference to it and it has
+// its content, and nil if the caller still has to upload it.
+func (a *App) heldBlob(ctx context.Context, caller domain.Caller, id domain.BlobID) (*domain.Blob, error) {
+	blob, err := a.findReferenced(ctx, caller, id)
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch blob.State {
+	case domain.StatePending, domain.StateMissing:
+		return nil, nil
+	case domain.StateQuarantined:
+		return nil, domain.ErrBlobQuarantined
+	}
+	return blob, nil
+}
+
+// openUpload returns the caller's upload of the blob, replacing one declared
+// with a different size, type or encryption.
+func (a *App) openUpload(ctx context.Context, caller domain.Caller, id domain.BlobID, sizeBytes int64, contentType string, encrypt bool) (*domain.Upload, error) {
+	if encrypt && a.cfg.Keys == nil {
+		return nil, domain.ErrEncryptionUnavailable
+	}
+	upload, err := domain.NewUpload(id, caller, sizeBytes, contentType, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if err := a.checkContentType(caller, upload.Blob()); err != nil {
+		return nil, err
+	}
+	held, err := a.repo.HasReference(ctx, id, caller.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if !held {
+		if err := a.checkQuota(ctx, caller, sizeBytes); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := a.repo.FindUpload(ctx, id, caller.Subject)
+	switch {
+	case err == nil && existing.SizeBytes == sizeBytes && existing.ContentType == contentType && (existing.KeyID != "") == encrypt:
+		return existing, nil
+	case err == nil:
+		if _, _, err := a.discardUpload(ctx, existing); err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, domain.ErrBlobNotFound):
+		return nil, err
+	}
+
+	if encrypt {
+		pending := upload.Blob()
+		if err := a.newDataKey(pending); err != nil {
+			return nil, err
+		}
+		upload.KeyID, upload.WrappedKey = pending.KeyID, pending.WrappedKey
+	}
+	err = a.repo.CreateUpload(ctx, upload)
+	if errors.Is(err, domain.ErrUploadExists) {
+		// A concurrent call started the upload first; use that one.
+		return a.repo.FindUpload(ctx, id, caller.Subject)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// discardUpload deletes the upload with its object, staged bytes and session.
+// An upload replaced since it was loaded is left alone.
+func (a *App) discardUpload(ctx context.Context, upload *domain.Upload) (deleted, aborted bool, err error) {
+	session, err := a.repo.FindMultipartUpload(ctx, upload.BlobID, upload.Subject)
+	if err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		return false, false, err
+	}
+	if deleted, err = a.repo.DeleteUpload(ctx, upload); err != nil || !deleted {
+		return false, false, err
+	}
+
+	if session != nil {
+		if err := a.storage.AbortMultipartUpload(ctx, upload.ObjectKey, session.UploadID); err != nil {
+			return true, false, err
+		}
+		aborted = true
+	}
+	if err := a.storage.DeleteObject(ctx, upload.ObjectKey); err != nil {
+		return true, aborted, err
+	}
+	a.dropStagedParts(ctx, upload.ObjectKey)
+	return true, aborted, nil
+}
+
+// findUpload loads the caller's upload of the blob. A caller with no upload
+// that holds a reference is told why there is nothing to upload.
+func (a *App) findUpload(ctx context.Context, caller domain.Caller, id domain.BlobID) (*domain.Upload, error) {
+	upload, blob, err := a.findUploadOrBlob(ctx, caller, id)
+	if err != nil {
+		return nil, err
+	}
+	if blob != nil {
+		return nil, uploadClosed(blob)
+	}
+	return upload, nil
+}
+
+// findUploadOrBlob returns the caller's upload of the blob or, failing that,
+// the blob if heldBlob would.
+func (a *App) findUploadOrBlob(ctx context.Context, caller domain.Caller, id domain.BlobID) (*domain.Upload, *domain.Blob, error) {
+	upload, err := a.repo.FindUpload(ctx, id, caller.Subject)
+	if !errors.Is(err, domain.ErrBlobNotFound) {
+		return upload, nil, err
+	}
+	blob, err := a.findReferenced(ctx, caller, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch blob.State {
+	case domain.StatePending:
+		return nil, nil, domain.ErrBlobPending
+	case domain.StateMissing:
+		return nil, nil, domain.ErrBlobMissing
+	}
+	return nil, blob, nil
+}
+
+// findReadable loads a blob the caller holds a reference to and that can be
+// read. A caller that is still uploading the blob gets ErrBlobPending.
+func (a *App) findReadable(ctx context.Context, caller domain.Caller, id domain.BlobID) (*domain.Blob, error) {
+	blob, err := a.findReferenced(ctx, caller, id)
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		if _, uerr := a.repo.FindUpload(ctx, id, caller.Subject); uerr == nil {
+			return nil, domain.ErrBlobPending
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := blob.Readable(); err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
+
+// findReferenced loads a blob the caller holds a reference to. Other blobs
+// are reported as not found so their existence is not disclosed.
+func (a *App) findReferenced(ctx context.Context, caller domain.Caller, id domain.BlobID) (*domain.Blob, error) {
+	blob, err := a.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := a.repo.HasReference(ctx, id, caller.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+	return blob, nil
+}
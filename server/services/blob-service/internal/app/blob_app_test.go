@@ -14,6 +14,11 @@ import (
 
 const validID = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
 
+var (
+	testCaller  = domain.Caller{Subject: "drive-service"}
+	otherCaller = domain.Caller{Subject: "chat-service"}
+)
+
 var testCfg = app.Config{
 	PresignPutTTL:           15 * time.Minute,
 	PresignGetMaxTTL:        time.Hour,
@@ -29,24 +34,40 @@ func TestInitiateUpload_NewBlob(t *testing.T) {
 	storage := &mockStorage{putURL: "https://r2.example.com/put"}
 	a := newApp(repo, storage)
 
-	result, err := a.InitiateUpload(context.Background(), domain.BlobID(validID), 1024, "image/png")
+	result, err := a.InitiateUpload(context.Background(), testCaller, domain.BlobID(validID), 1024, "image/png", false)
 	require.NoError(t, err)
 	assert.False(t, result.AlreadyExists)
 	assert.Equal(t, "https://r2.example.com/put", result.PresignedPutURL)
+	assert.Equal(t, "image/png", result.RequiredHeaders["Content-Type"])
+	assert.Equal(t, domain.PutConstraints{SizeBytes: 1024, ContentType: "image/png", ChecksumSHA256: validID}, storage.putConstraints)
 
-	_, err = repo.FindByID(context.Background(), domain.BlobID(validID))
+	upload, err := repo.FindUpload(context.Background(), domain.BlobID(validID), testCaller.Subject)
 	require.NoError(t, err)
+	assert.NotEqual(t, validID, upload.ObjectKey, "each upload gets its own object")
+	assert.NotContains(t, repo.blobs, domain.BlobID(validID), "the blob exists only once an upload verifies")
+}
+
+func TestInitiateUpload_SizesArePerUpload(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	repo.seed(blob)
+
+	a := newApp(repo, &mockStorage{})
+	_, err := a.InitiateUpload(context.Background(), otherCaller, domain.BlobID(validID), 2048, "image/png", false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024), repo.uploads[uploadKey{blob.ID, testCaller.Subject}].SizeBytes)
+	assert.Equal(t, int64(2048), repo.uploads[uploadKey{blob.ID, otherCaller.Subject}].SizeBytes)
 }
 
 func TestInitiateUpload_AlreadyCommitted(t *testing.T) {
 	repo := newMockRepo()
 	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
 	_ = blob.Commit(time.Now())
-	repo.blobs[domain.BlobID(validID)] = blob
+	repo.seed(blob)
 
 	a := newApp(repo, &mockStorage{})
 
-	result, err := a.InitiateUpload(context.Background(), domain.BlobID(validID), 1024, "image/png")
+	result, err := a.InitiateUpload(context.Background(), testCaller, domain.BlobID(validID), 1024, "image/png", false)
 	require.NoError(t, err)
 	assert.True(t, result.AlreadyExists)
 	assert.Empty(t, result.PresignedPutURL)
@@ -55,12 +76,12 @@ func TestInitiateUpload_AlreadyCommitted(t *testing.T) {
 func TestInitiateUpload_Pending_ReissuesURL(t *testing.T) {
 	repo := newMockRepo()
 	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
-	repo.blobs[domain.BlobID(validID)] = blob
+	repo.seed(blob)
 
 	storage := &mockStorage{putURL: "https://r2.example.com/put2"}
 	a := newApp(repo, storage)
 
-	result, err := a.InitiateUpload(context.Background(), domain.BlobID(validID), 1024, "image/png")
+	result, err := a.InitiateUpload(context.Background(), testCaller, domain.BlobID(validID), 1024, "image/png", false)
 	require.NoError(t, err)
 	assert.False(t, result.AlreadyExists)
 	assert.Equal(t, "https://r2.example.com/put2", result.PresignedPutURL)
@@ -68,28 +89,121 @@ func TestInitiateUpload_Pending_ReissuesURL(t *testing.T) {
 
 func TestInitiateUpload_InvalidID(t *testing.T) {
 	a := newApp(newMockRepo(), &mockStorage{})
-	_, err := a.InitiateUpload(context.Background(), "invalid", 1024, "image/png")
+	_, err := a.InitiateUpload(context.Background(), testCaller, "invalid", 1024, "image/png", false)
 	assert.ErrorIs(t, err, domain.ErrInvalidBlobID)
 }
 
 func TestCompleteUpload_HappyPath(t *testing.T) {
 	repo := newMockRepo()
-	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
-	repo.blobs[domain.BlobID(validID)] = blob
+	storage := &mockStorage{}
+	id := storage.put([]byte("hello, blob"))
+	blob, _ := domain.NewBlob(id, 11, "text/plain", time.Now())
+	repo.seed(blob)
 
-	a := newApp(repo, &mockStorage{})
-	result, err := a.CompleteUpload(context.Background(), domain.BlobID(validID))
+	a := newApp(repo, storage)
+	result, err := a.CompleteUpload(context.Background(), testCaller, id)
 	require.NoError(t, err)
-	assert.Equal(t, domain.BlobID(validID), result.BlobID)
+	assert.Equal(t, id, result.BlobID)
 	assert.False(t, result.CommittedAt.IsZero())
 
-	updated, _ := repo.FindByID(context.Background(), domain.BlobID(validID))
+	updated, _ := repo.FindByID(context.Background(), id)
 	assert.Equal(t, domain.StateCommitted, updated.State)
 }
 
+type uploadCounter struct{ bytes []int64 }
+
+func (c *uploadCounter) UploadVerified(sizeBytes int64) { c.bytes = append(c.bytes, sizeBytes) }
+
+func TestCompleteUpload_RecordsVerifiedUploads(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	id := storage.put([]byte("hello, blob"))
+	blob, _ := domain.NewBlob(id, 11, "text/plain", time.Now())
+	repo.seed(blob)
+	missing, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	repo.seed(missing)
+
+	counter := &uploadCounter{}
+	cfg := testCfg
+	cfg.Uploads = counter
+	a := app.New(repo, storage, cfg)
+
+	_, err := a.CompleteUpload(context.Background(), testCaller, id)
+	require.NoError(t, err)
+	_, err = a.CompleteUpload(context.Background(), testCaller, domain.BlobID(validID))
+	require.Error(t, err)
+	assert.Equal(t, []int64{11}, counter.bytes, "only verified uploads count")
+}
+
+func TestCompleteUpload_ObjectMissing(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	repo.seed(blob)
+
+	a := newApp(repo, &mockStorage{})
+	_, err := a.CompleteUpload(context.Background(), testCaller, domain.BlobID(validID))
+	assert.ErrorIs(t, err, domain.ErrObjectNotFound)
+	assert.Contains(t, repo.uploads, uploadKey{domain.BlobID(validID), testCaller.Subject})
+}
+
+func TestCompleteUpload_SizeMismatch(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	id := storage.put([]byte("hello, blob"))
+	blob, _ := domain.NewBlob(id, 1024, "text/plain", time.Now())
+	repo.seed(blob)
+
+	a := newApp(repo, storage)
+	_, err := a.CompleteUpload(context.Background(), testCaller, id)
+	assert.ErrorIs(t, err, domain.ErrContentMismatch)
+	assert.Contains(t, repo.uploads, uploadKey{id, testCaller.Subject})
+	assert.Equal(t, []string{string(id)}, storage.deleted)
+}
+
+func TestCompleteUpload_HashMismatch(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{objects: map[string][]byte{validID: []byte("not empty")}}
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 9, "text/plain", time.Now())
+	repo.seed(blob)
+
+	a := newApp(repo, storage)
+	_, err := a.CompleteUpload(context.Background(), testCaller, domain.BlobID(validID))
+	assert.ErrorIs(t, err, domain.ErrContentMismatch)
+	assert.Contains(t, repo.uploads, uploadKey{domain.BlobID(validID), testCaller.Subject})
+	assert.Equal(t, []string{validID}, storage.deleted)
+}
+
+func TestCompleteUpload_TrustsBackendChecksum(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{
+		objects:   map[string][]byte{validID: []byte("not empty")},
+		checksums: map[string]string{validID: validID},
+	}
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 9, "text/plain", time.Now())
+	repo.seed(blob)
+
+	a := newApp(repo, storage)
+	_, err := a.CompleteUpload(context.Background(), testCaller, domain.BlobID(validID))
+	require.NoError(t, err)
+	assert.Empty(t, storage.deleted)
+}
+
+func TestCompleteUpload_BackendChecksumMismatch(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	id := storage.put([]byte("hello, blob"))
+	storage.checksums = map[string]string{string(id): validID}
+	blob, _ := domain.NewBlob(id, 11, "text/plain", time.Now())
+	repo.seed(blob)
+
+	a := newApp(repo, storage)
+	_, err := a.CompleteUpload(context.Background(), testCaller, id)
+	assert.ErrorIs(t, err, domain.ErrContentMismatch)
+}
+
 func TestCompleteUpload_NotFound(t *testing.T) {
 	a := newApp(newMockRepo(), &mockStorage{})
-	_, err := a.CompleteUpload(context.Background(), domain.BlobID(validID))
+	_, err := a.CompleteUpload(context.Background(), testCaller, domain.BlobID(validID))
 	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
 }
 
@@ -97,10 +211,10 @@ func TestCompleteUpload_AlreadyCommitted(t *testing.T) {
 	repo := newMockRepo()
 	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
 	_ = blob.Commit(time.Now())
-	repo.blobs[domain.BlobID(validID)] = blob
+	repo.seed(blob)
 
 	a := newApp(repo, &mockStorage{})
-	_, err := a.CompleteUpload(context.Background(), domain.BlobID(validID))
+	_, err := a.CompleteUpload(context.Background(), testCaller, domain.BlobID(validID))
 	assert.ErrorIs(t, err, domain.ErrAlreadyCommitted)
 }
 
@@ -109,7 +223,7 @@ func TestInitiateMultipartUpload_NewBlob(t *testing.T) {
 	storage := &mockStorage{uploadID: "mpu-123", partURL: "https://r2.example.com/part"}
 	a := newApp(repo, storage)
 
-	result, err := a.InitiateMultipartUpload(context.Background(), domain.BlobID(validID), 1024*1024*50, "video/mp4", 3)
+	result, err := a.InitiateMultipartUpload(context.Background(), testCaller, domain.BlobID(validID), 1024*1024*50, "video/mp4", 3, false)
 	require.NoError(t, err)
 	assert.False(t, result.AlreadyExists)
 	assert.Equal(t, "mpu-123", result.UploadID)
@@ -118,62 +232,239 @@ func TestInitiateMultipartUpload_NewBlob(t *testing.T) {
 		assert.Equal(t, int32(i+1), p.PartNumber)
 		assert.Equal(t, "https://r2.example.com/part", p.PresignedPutURL)
 	}
+	for _, p := range domain.SplitParts(1024*1024*50, 3) {
+		assert.Equal(t, p.SizeBytes, storage.partSizes[p.PartNumber])
+	}
 }
 
 func TestInitiateMultipartUpload_AlreadyCommitted(t *testing.T) {
 	repo := newMockRepo()
 	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
 	_ = blob.Commit(time.Now())
-	repo.blobs[domain.BlobID(validID)] = blob
+	repo.seed(blob)
 
 	a := newApp(repo, &mockStorage{})
-	result, err := a.InitiateMultipartUpload(context.Background(), domain.BlobID(validID), 1024, "image/png", 1)
+	result, err := a.InitiateMultipartUpload(context.Background(), testCaller, domain.BlobID(validID), 1024, "image/png", 1, false)
 	require.NoError(t, err)
 	assert.True(t, result.AlreadyExists)
 }
 
 func TestInitiateMultipartUpload_ZeroPartCount(t *testing.T) {
 	a := newApp(newMockRepo(), &mockStorage{})
-	_, err := a.InitiateMultipartUpload(context.Background(), domain.BlobID(validID), 1024, "image/png", 0)
-	assert.Error(t, err)
+	_, err := a.InitiateMultipartUpload(context.Background(), testCaller, domain.BlobID(validID), 1024, "image/png", 0, false)
+	assert.ErrorIs(t, err, domain.ErrInvalidPartCount)
+}
+
+func TestInitiateMultipartUpload_PartsBelowMinimum(t *testing.T) {
+	repo := newMockRepo()
+	a := newApp(repo, &mockStorage{uploadID: "mpu-1"})
+	_, err := a.InitiateMultipartUpload(context.Background(), testCaller, domain.BlobID(validID), 12*1024*1024, "video/mp4", 3, false)
+	assert.ErrorIs(t, err, domain.ErrInvalidPartCount)
+	assert.Empty(t, repo.blobs)
+}
+
+func TestPlanUpload_UsesThreshold(t *testing.T) {
+	a := newApp(newMockRepo(), &mockStorage{})
+
+	small, err := a.PlanUpload(testCfg.MultipartThresholdBytes - 1)
+	require.NoError(t, err)
+	assert.False(t, small.Multipart)
+
+	large, err := a.PlanUpload(testCfg.MultipartThresholdBytes)
+	require.NoError(t, err)
+	assert.True(t, large.Multipart)
+	assert.NotEmpty(t, large.Parts)
 }
 
 func TestCompleteMultipartUpload_HappyPath(t *testing.T) {
 	repo := newMockRepo()
-	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
-	repo.blobs[domain.BlobID(validID)] = blob
+	storage := &mockStorage{}
+	id := storage.put([]byte("hello, blob"))
+	blob, _ := domain.NewBlob(id, 11, "text/plain", time.Now())
+	repo.seed(blob)
+	repo.sessions[uploadKey{id, testCaller.Subject}] = &domain.MultipartSession{BlobID: id, Subject: testCaller.Subject, UploadID: "mpu-123", PartCount: 1}
 
-	a := newApp(repo, &mockStorage{})
+	a := newApp(repo, storage)
 	parts := []domain.CompletedPart{{PartNumber: 1, ETag: `"abc123"`}}
-	result, err := a.CompleteMultipartUpload(context.Background(), domain.BlobID(validID), "mpu-123", parts)
+	result, err := a.CompleteMultipartUpload(context.Background(), testCaller, id, "mpu-123", parts)
 	require.NoError(t, err)
-	assert.Equal(t, domain.BlobID(validID), result.BlobID)
+	assert.Equal(t, id, result.BlobID)
 
-	updated, _ := repo.FindByID(context.Background(), domain.BlobID(validID))
+	updated, _ := repo.FindByID(context.Background(), id)
 	assert.Equal(t, domain.StateCommitted, updated.State)
 }
 
+func TestCompleteMultipartUpload_HashMismatch(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{objects: map[string][]byte{validID: []byte("not empty")}}
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 9, "text/plain", time.Now())
+	repo.seed(blob)
+	repo.sessions[uploadKey{blob.ID, testCaller.Subject}] = &domain.MultipartSession{BlobID: blob.ID, Subject: testCaller.Subject, UploadID: "mpu-123", PartCount: 1}
+
+	a := newApp(repo, storage)
+	parts := []domain.CompletedPart{{PartNumber: 1, ETag: `"abc123"`}}
+	_, err := a.CompleteMultipartUpload(context.Background(), testCaller, domain.BlobID(validID), "mpu-123", parts)
+	assert.ErrorIs(t, err, domain.ErrContentMismatch)
+	assert.Contains(t, repo.uploads, uploadKey{domain.BlobID(validID), testCaller.Subject})
+	assert.Equal(t, []string{validID}, storage.deleted)
+}
+
+func TestCompleteMultipartUpload_UnknownUploadID(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 0, "text/plain", time.Now())
+	repo.seed(blob)
+	repo.sessions[uploadKey{blob.ID, testCaller.Subject}] = &domain.MultipartSession{BlobID: blob.ID, Subject: testCaller.Subject, UploadID: "mpu-current", PartCount: 1}
+
+	a := newApp(repo, &mockStorage{})
+	parts := []domain.CompletedPart{{PartNumber: 1, ETag: `"abc123"`}}
+	_, err := a.CompleteMultipartUpload(context.Background(), testCaller, blob.ID, "mpu-orphaned", parts)
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+}
+
+func TestInitiateMultipartUpload_ReusesSession(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{uploadID: "mpu-1", partURL: "https://r2.example.com/part"}
+	a := newApp(repo, storage)
+
+	first, err := a.InitiateMultipartUpload(context.Background(), testCaller, domain.BlobID(validID), 1024*1024*50, "video/mp4", 3, false)
+	require.NoError(t, err)
+
+	storage.uploadID = "mpu-2"
+	second, err := a.InitiateMultipartUpload(context.Background(), testCaller, domain.BlobID(validID), 1024*1024*50, "video/mp4", 3, false)
+	require.NoError(t, err)
+	assert.Equal(t, first.UploadID, second.UploadID)
+	assert.Empty(t, storage.aborted)
+}
+
+func TestInitiateMultipartUpload_PartCountChangeReplacesSession(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{uploadID: "mpu-1", partURL: "https://r2.example.com/part"}
+	a := newApp(repo, storage)
+
+	_, err := a.InitiateMultipartUpload(context.Background(), testCaller, domain.BlobID(validID), 1024*1024*50, "video/mp4", 3, false)
+	require.NoError(t, err)
+
+	storage.uploadID = "mpu-2"
+	result, err := a.InitiateMultipartUpload(context.Background(), testCaller, domain.BlobID(validID), 1024*1024*50, "video/mp4", 5, false)
+	require.NoError(t, err)
+	assert.Equal(t, "mpu-2", result.UploadID)
+	assert.Len(t, result.Parts, 5)
+	assert.Equal(t, []string{"mpu-1"}, storage.aborted)
+	assert.Equal(t, int32(5), repo.sessions[uploadKey{domain.BlobID(validID), testCaller.Subject}].PartCount)
+}
+
+func TestResumeMultipartUpload_ReturnsMissingParts(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024*1024*50, "video/mp4", time.Now())
+	repo.seed(blob)
+	repo.sessions[uploadKey{blob.ID, testCaller.Subject}] = &domain.MultipartSession{BlobID: blob.ID, Subject: testCaller.Subject, UploadID: "mpu-1", PartCount: 4}
+
+	storage := &mockStorage{
+		partURL: "https://r2.example.com/part",
+		parts: map[string][]domain.UploadedPart{
+			"mpu-1": {
+				{PartNumber: 1, ETag: `"e1"`, SizeBytes: 16 * 1024 * 1024},
+				{PartNumber: 3, ETag: `"e3"`, SizeBytes: 16 * 1024 * 1024},
+			},
+		},
+	}
+	a := newApp(repo, storage)
+
+	result, err := a.ResumeMultipartUpload(context.Background(), testCaller, blob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "mpu-1", result.UploadID)
+	assert.Len(t, result.UploadedParts, 2)
+	require.Len(t, result.MissingParts, 2)
+	assert.Equal(t, int32(2), result.MissingParts[0].PartNumber)
+	assert.Equal(t, int32(4), result.MissingParts[1].PartNumber)
+}
+
+func TestResumeMultipartUpload_UploadGoneFromStorage(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024*1024*50, "video/mp4", time.Now())
+	repo.seed(blob)
+	repo.sessions[uploadKey{blob.ID, testCaller.Subject}] = &domain.MultipartSession{BlobID: blob.ID, Subject: testCaller.Subject, UploadID: "mpu-1", PartCount: 4}
+
+	a := newApp(repo, &mockStorage{})
+	_, err := a.ResumeMultipartUpload(context.Background(), testCaller, blob.ID)
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+	assert.Empty(t, repo.sessions, "stale session is forgotten")
+}
+
+func TestResumeMultipartUpload_OtherCaller(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024*1024*50, "video/mp4", time.Now())
+	repo.seed(blob)
+	repo.sessions[uploadKey{blob.ID, testCaller.Subject}] = &domain.MultipartSession{BlobID: blob.ID, Subject: testCaller.Subject, UploadID: "mpu-1", PartCount: 4}
+
+	a := newApp(repo, &mockStorage{})
+	_, err := a.ResumeMultipartUpload(context.Background(), otherCaller, blob.ID)
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+}
+
 func TestGetDownloadURL_Committed(t *testing.T) {
 	repo := newMockRepo()
 	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
 	_ = blob.Commit(time.Now())
-	repo.blobs[domain.BlobID(validID)] = blob
+	repo.seed(blob)
 
 	storage := &mockStorage{getURL: "https://r2.example.com/download"}
 	a := newApp(repo, storage)
 
-	result, err := a.GetDownloadURL(context.Background(), domain.BlobID(validID), 30*time.Minute)
+	result, err := a.GetDownloadURL(context.Background(), testCaller, domain.BlobID(validID), 30*time.Minute, app.DownloadOptions{})
 	require.NoError(t, err)
 	assert.Equal(t, "https://r2.example.com/download", result.PresignedGetURL)
 }
 
+func TestGetDownloadURL_Options(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+
+	storage := &mockStorage{getURL: "https://r2.example.com/download"}
+	a := newApp(repo, storage)
+
+	result, err := a.GetDownloadURL(context.Background(), testCaller, domain.BlobID(validID), time.Minute, app.DownloadOptions{
+		Filename:     "photo.png",
+		ContentType:  "image/png",
+		CacheControl: "private, max-age=60",
+		Offset:       1000,
+		Length:       100,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.GetOptions{
+		ContentDisposition: "attachment; filename=photo.png",
+		ContentType:        "image/png",
+		CacheControl:       "private, max-age=60",
+		Offset:             1000,
+		Length:             24,
+	}, storage.getOptions, "range is truncated to the blob")
+	assert.Equal(t, "bytes=1000-1023", result.RequiredHeaders["Range"])
+}
+
+func TestGetDownloadURL_InvalidOptions(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+
+	a := newApp(repo, &mockStorage{})
+
+	_, err := a.GetDownloadURL(context.Background(), testCaller, domain.BlobID(validID), time.Minute, app.DownloadOptions{CacheControl: "public\r\nSet-Cookie: a=b"})
+	assert.ErrorIs(t, err, domain.ErrInvalidDownloadOption)
+
+	_, err = a.GetDownloadURL(context.Background(), testCaller, domain.BlobID(validID), time.Minute, app.DownloadOptions{Offset: 1024})
+	assert.ErrorIs(t, err, domain.ErrInvalidRange)
+}
+
 func TestGetDownloadURL_Pending(t *testing.T) {
 	repo := newMockRepo()
 	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
-	repo.blobs[domain.BlobID(validID)] = blob
+	repo.seed(blob)
 
 	a := newApp(repo, &mockStorage{})
-	_, err := a.GetDownloadURL(context.Background(), domain.BlobID(validID), 30*time.Minute)
+	_, err := a.GetDownloadURL(context.Background(), testCaller, domain.BlobID(validID), 30*time.Minute, app.DownloadOptions{})
 	assert.ErrorIs(t, err, domain.ErrBlobPending)
 }
 
@@ -181,14 +472,14 @@ func TestGetDownloadURL_TTLCeiling(t *testing.T) {
 	repo := newMockRepo()
 	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
 	_ = blob.Commit(time.Now())
-	repo.blobs[domain.BlobID(validID)] = blob
+	repo.seed(blob)
 
 	storage := &mockStorage{getURL: "https://r2.example.com/download"}
 	cfg := app.Config{PresignPutTTL: 15 * time.Minute, PresignGetMaxTTL: time.Hour}
 	a := app.New(repo, storage, cfg)
 
 	before := time.Now()
-	result, err := a.GetDownloadURL(context.Background(), domain.BlobID(validID), 24*time.Hour)
+	result, err := a.GetDownloadURL(context.Background(), testCaller, domain.BlobID(validID), 24*time.Hour, app.DownloadOptions{})
 	require.NoError(t, err)
 	assert.True(t, result.ExpiresAt.Before(before.Add(time.Hour+time.Second)), "TTL should be capped at 1h")
 }
@@ -196,12 +487,81 @@ func TestGetDownloadURL_TTLCeiling(t *testing.T) {
 func TestGetBlobInfo(t *testing.T) {
 	repo := newMockRepo()
 	blob, _ := domain.NewBlob(domain.BlobID(validID), 2048, "application/pdf", time.Now())
-	repo.blobs[domain.BlobID(validID)] = blob
+	repo.seed(blob)
 
 	a := newApp(repo, &mockStorage{})
-	info, err := a.GetBlobInfo(context.Background(), domain.BlobID(validID))
+	info, err := a.GetBlobInfo(context.Background(), testCaller, domain.BlobID(validID))
 	require.NoError(t, err)
 	assert.Equal(t, domain.BlobID(validID), info.ID)
 	assert.Equal(t, int64(2048), info.SizeBytes)
 	assert.Equal(t, domain.StatePending, info.State)
 }
+
+func TestInitiateUpload_NoReferenceUntilVerified(t *testing.T) {
+	repo := newMockRepo()
+	a := newApp(repo, &mockStorage{putURL: "https://r2.example.com/put"})
+
+	_, err := a.InitiateUpload(context.Background(), testCaller, domain.BlobID(validID), 1024, "image/png", false)
+	require.NoError(t, err)
+	assert.False(t, repo.refs[domain.BlobID(validID)][testCaller.Subject])
+}
+
+func TestInitiateUpload_CommittedBlobRequiresUpload(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{putURL: "https://r2.example.com/put"}
+	content := []byte("hello, blob")
+	id := seedCommitted(repo, storage, content)
+	a := newApp(repo, storage)
+
+	result, err := a.InitiateUpload(context.Background(), otherCaller, id, int64(len(content)), "text/plain", false)
+	require.NoError(t, err)
+	assert.False(t, result.AlreadyExists, "existence is not disclosed to callers without a reference")
+	assert.Equal(t, "https://r2.example.com/put", result.PresignedPutURL)
+	assert.False(t, repo.refs[id][otherCaller.Subject])
+
+	_, err = a.CompleteUpload(context.Background(), otherCaller, id)
+	require.ErrorIs(t, err, domain.ErrObjectNotFound, "naming the hash is not proof of possession")
+	assert.False(t, repo.refs[id][otherCaller.Subject])
+
+	key := repo.uploads[uploadKey{id, otherCaller.Subject}].ObjectKey
+	storage.objects[key] = content
+	_, err = a.CompleteUpload(context.Background(), otherCaller, id)
+	require.NoError(t, err)
+	assert.True(t, repo.refs[id][otherCaller.Subject])
+	assert.Equal(t, []string{key}, storage.deleted, "the duplicate copy is dropped")
+	assert.Equal(t, string(id), repo.blobs[id].R2Key)
+}
+
+func TestGetBlobInfo_NotReferenced(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 2048, "application/pdf", time.Now())
+	repo.seed(blob)
+
+	a := newApp(repo, &mockStorage{})
+	_, err := a.GetBlobInfo(context.Background(), otherCaller, domain.BlobID(validID))
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+}
+
+func TestGetDownloadURL_NotReferenced(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+
+	a := newApp(repo, &mockStorage{getURL: "https://r2.example.com/download"})
+	_, err := a.GetDownloadURL(context.Background(), otherCaller, domain.BlobID(validID), time.Minute, app.DownloadOptions{})
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+}
+
+func TestCompleteUpload_NotReferenced(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	id := storage.put([]byte("hello, blob"))
+	blob, _ := domain.NewBlob(id, 11, "text/plain", time.Now())
+	repo.seed(blob)
+
+	a := newApp(repo, storage)
+	_, err := a.CompleteUpload(context.Background(), otherCaller, id)
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+	assert.Contains(t, repo.uploads, uploadKey{id, testCaller.Subject})
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+// sniffLen is the most content http.DetectContentType considers.
+const sniffLen = 512
+
+// detectContentType sniffs the type of a stored object from its first bytes.
+func (a *App) detectContentType(ctx context.Context, blob *domain.Blob) (string, error) {
+	if blob.SizeBytes == 0 {
+		return http.DetectContentType(nil), nil
+	}
+	objects, err := a.objects(blob)
+	if err != nil {
+		return "", err
+	}
+	body, err := objects.GetObjectRange(ctx, blob.R2Key, 0, min(blob.SizeBytes, sniffLen))
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = body.Close() }()
+
+	head, err := io.ReadAll(io.LimitReader(body, sniffLen))
+	if err != nil {
+		return "", fmt.Errorf("sniff object: %w", err)
+	}
+	return http.DetectContentType(head), nil
+}
+
+// classifyObject records the stored object's detected type on blob and
+// checks it against the caller's allowlist before the blob is committed.
+func (a *App) classifyObject(ctx context.Context, caller domain.Caller, blob *domain.Blob) error {
+	ct, err := a.detectContentType(ctx, blob)
+	if err != nil {
+		return err
+	}
+	blob.DetectedContentType = ct
+	return a.checkContentType(caller, blob)
+}
+
+// checkContentType checks the blob's declared and detected types against the
+// caller's client.
+func (a *App) checkContentType(caller domain.Caller, blob *domain.Blob) error {
+	types := []string{blob.ContentType}
+	if blob.DetectedContentType != "" {
+		types = append(types, blob.DetectedContentType)
+	}
+	for _, ct := range types {
+		if !a.cfg.ContentTypes.Allows(caller.ClientID, ct) {
+			return fmt.Errorf("%w: %q", domain.ErrContentTypeNotAllowed, ct)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+var (
+	pngHeader    = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	profileAgent = domain.Caller{Subject: "alice", ClientID: "profile-web"}
+)
+
+func newContentTypeApp(repo domain.BlobRepository, storage domain.ObjectStorage) *app.App {
+	cfg := testCfg
+	cfg.ContentTypes = domain.ContentTypePolicy{"profile-web": {"image/*"}}
+	return app.New(repo, storage, cfg)
+}
+
+func TestCompleteUpload_RecordsDetectedType(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	id := storage.put(pngHeader)
+	blob, _ := domain.NewBlob(id, int64(len(pngHeader)), "application/octet-stream", time.Now())
+	repo.seed(blob)
+
+	_, err := newApp(repo, storage).CompleteUpload(context.Background(), testCaller, id)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", repo.blobs[id].DetectedContentType)
+	assert.Equal(t, "application/octet-stream", repo.blobs[id].ContentType)
+}
+
+func TestCompleteUpload_DetectedTypeNotAllowed(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	a := newContentTypeApp(repo, storage)
+	content := []byte("<html><script>alert(1)</script></html>")
+	id := sha256Hex(content)
+
+	_, err := a.InitiateUpload(context.Background(), profileAgent, id, int64(len(content)), "image/png", false)
+	require.NoError(t, err)
+	storage.putUpload(repo, profileAgent, id, content)
+	_, err = a.CompleteUpload(context.Background(), profileAgent, id)
+	assert.ErrorIs(t, err, domain.ErrContentTypeNotAllowed)
+	assert.Contains(t, repo.uploads, uploadKey{id, profileAgent.Subject})
+}
+
+func TestCompleteUpload_AllowedType(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	a := newContentTypeApp(repo, storage)
+	id := sha256Hex(pngHeader)
+
+	_, err := a.InitiateUpload(context.Background(), profileAgent, id, int64(len(pngHeader)), "image/png", false)
+	require.NoError(t, err)
+	storage.putUpload(repo, profileAgent, id, pngHeader)
+	_, err = a.CompleteUpload(context.Background(), profileAgent, id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateCommitted, repo.blobs[id].State)
+}
+
+func TestInitiateUpload_DeclaredTypeNotAllowed(t *testing.T) {
+	repo := newMockRepo()
+	a := newContentTypeApp(repo, &mockStorage{})
+
+	_, err := a.InitiateUpload(context.Background(), profileAgent, domain.BlobID(validID), 10, "application/pdf", false)
+	assert.ErrorIs(t, err, domain.ErrContentTypeNotAllowed)
+	_, err = repo.FindByID(context.Background(), domain.BlobID(validID))
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+
+	_, err = a.InitiateUpload(context.Background(), testCaller, domain.BlobID(validID), 10, "application/pdf", false)
+	assert.NoError(t, err, "clients without an allowlist are unrestricted")
+}
+
+func TestCompleteUpload_DuplicateOfDisallowedType(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	content := []byte("<html><script>alert(1)</script></html>")
+	id := storage.put(content)
+	blob, _ := domain.NewBlob(id, int64(len(content)), "image/png", time.Now())
+	blob.DetectedContentType = "text/html; charset=utf-8"
+	blob.State = domain.StateScanning
+	repo.seed(blob)
+	require.NoError(t, repo.MarkCommitted(context.Background(), blob.ID, blob.DetectedContentType, time.Now()))
+
+	a := newContentTypeApp(repo, storage)
+	err := a.AddReference(context.Background(), profileAgent, blob.ID, "")
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+
+	_, err = a.InitiateUpload(context.Background(), profileAgent, blob.ID, blob.SizeBytes, "image/png", false)
+	require.NoError(t, err)
+	storage.putUpload(repo, profileAgent, blob.ID, content)
+	_, err = a.CompleteUpload(context.Background(), profileAgent, blob.ID)
+	assert.ErrorIs(t, err, domain.ErrContentTypeNotAllowed, "deduplication must not bypass the allowlist")
+	assert.False(t, repo.refs[blob.ID][profileAgent.Subject])
+}
+
+func TestUploadStream_DetectedTypeNotAllowed(t *testing.T) {
+	repo := newMockRepo()
+	a := newContentTypeApp(repo, &mockStorage{})
+
+	_, err := a.UploadStream(context.Background(), profileAgent, bytes.NewReader([]byte("%PDF-1.7\n")), "image/png", "", false)
+	assert.ErrorIs(t, err, domain.ErrContentTypeNotAllowed)
+	assert.Empty(t, repo.blobs)
+
+	result, err := a.UploadStream(context.Background(), profileAgent, bytes.NewReader(pngHeader), "image/png", "", false)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", repo.blobs[result.BlobID].DetectedContentType)
+}
+
+func TestGetDownloadURL_MismatchedTypeServedOpaque(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 10, "image/png", time.Now())
+	blob.State = domain.StateScanning
+	repo.seed(blob)
+	require.NoError(t, repo.MarkCommitted(context.Background(), blob.ID, "text/html; charset=utf-8", time.Now()))
+	a := newApp(repo, storage)
+
+	_, err := a.GetDownloadURL(context.Background(), testCaller, blob.ID, time.Minute, app.DownloadOptions{Disposition: domain.DispositionInline})
+	require.NoError(t, err)
+	assert.Equal(t, "application/octet-stream", storage.getOptions.ContentType)
+
+	_, err = a.GetDownloadURL(context.Background(), testCaller, blob.ID, time.Minute, app.DownloadOptions{Disposition: domain.DispositionAttachment})
+	require.NoError(t, err)
+	assert.Empty(t, storage.getOptions.ContentType, "attachments keep the declared type")
+}
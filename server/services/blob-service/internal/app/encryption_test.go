@@ -0,0 +1,134 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/keyring"
+)
+
+var (
+	masterKeyA = [32]byte{1}
+	masterKeyB = [32]byte{2}
+)
+
+func newEncryptingApp(t *testing.T, repo domain.BlobRepository, storage domain.ObjectStorage, current [32]byte, previous ...[32]byte) *app.App {
+	t.Helper()
+	keys, err := keyring.New(current, previous...)
+	require.NoError(t, err)
+	cfg := testCfg
+	cfg.Keys = keys
+	return app.New(repo, storage, cfg)
+}
+
+func TestUploadStream_EncryptedRoundTrip(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	a := newEncryptingApp(t, repo, storage, masterKeyA)
+	content := []byte("minutes of the residents' meeting")
+
+	result, err := a.UploadStream(context.Background(), testCaller, bytes.NewReader(content), "text/plain", "", true)
+	require.NoError(t, err)
+	blob := repo.blobs[result.BlobID]
+	require.True(t, blob.Encrypted())
+	assert.Equal(t, keyring.KeyID(masterKeyA), blob.KeyID)
+	assert.NotNil(t, storage.sealed[blob.R2Key], "content must be stored under the data key")
+
+	_, err = storage.GetObject(context.Background(), blob.R2Key)
+	assert.Error(t, err, "content cannot be read without the data key")
+
+	dl, err := a.DownloadStream(context.Background(), testCaller, result.BlobID, 15, 8)
+	require.NoError(t, err)
+	got, _ := io.ReadAll(dl.Body)
+	_ = dl.Body.Close()
+	assert.Equal(t, "resident", string(got))
+}
+
+func TestInitiateUpload_EncryptedPresignsCustomerKey(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{putURL: "https://r2.example.com/put", getURL: "https://r2.example.com/get"}
+	a := newEncryptingApp(t, repo, storage, masterKeyA)
+	content := []byte("encrypted through a presigned PUT")
+	id := sha256Hex(content)
+
+	result, err := a.InitiateUpload(context.Background(), testCaller, id, int64(len(content)), "text/plain", true)
+	require.NoError(t, err)
+	key, err := hex.DecodeString(result.RequiredHeaders[customerKeyHeader])
+	require.NoError(t, err)
+	require.Len(t, key, domain.DataKeySize)
+
+	// The client PUTs with the SSE-C headers it was given.
+	objectKey := repo.uploads[uploadKey{id, testCaller.Subject}].ObjectKey
+	require.NoError(t, storage.WithCustomerKey(key).PutObject(context.Background(), objectKey, bytes.NewReader(content), int64(len(content)), "text/plain", string(id)))
+
+	completed, err := a.CompleteUpload(context.Background(), testCaller, id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateCommitted, completed.State)
+	assert.Equal(t, "text/plain; charset=utf-8", repo.blobs[id].DetectedContentType)
+
+	dl, err := a.GetDownloadURL(context.Background(), testCaller, id, 0, app.DownloadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, result.RequiredHeaders[customerKeyHeader], dl.RequiredHeaders[customerKeyHeader])
+}
+
+func TestInitiateUpload_EncryptionUnavailable(t *testing.T) {
+	repo := newMockRepo()
+	a := newApp(repo, &mockStorage{})
+
+	_, err := a.InitiateUpload(context.Background(), testCaller, domain.BlobID(validID), 10, "text/plain", true)
+	assert.ErrorIs(t, err, domain.ErrEncryptionUnavailable)
+	assert.Empty(t, repo.blobs)
+}
+
+func TestInitiateUpload_ExistingBlobKeepsItsEncryption(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	a := newEncryptingApp(t, repo, storage, masterKeyA)
+
+	content := []byte("stored in the clear first")
+
+	first, err := a.UploadStream(context.Background(), testCaller, bytes.NewReader(content), "text/plain", "", false)
+	require.NoError(t, err)
+	_, err = a.UploadStream(context.Background(), otherCaller, bytes.NewReader(content), "text/plain", "", true)
+	require.NoError(t, err)
+	assert.False(t, repo.blobs[first.BlobID].Encrypted())
+	assert.True(t, repo.refs[first.BlobID][otherCaller.Subject])
+	assert.Len(t, storage.objects, 1, "the encrypted copy is dropped")
+}
+
+func TestRotateKeys_RewrapsWithoutTouchingContent(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	content := []byte("written under the old master key")
+	old := newEncryptingApp(t, repo, storage, masterKeyA)
+	uploaded, err := old.UploadStream(context.Background(), testCaller, bytes.NewReader(content), "text/plain", "", true)
+	require.NoError(t, err)
+	key := repo.blobs[uploaded.BlobID].R2Key
+	sealed := storage.sealed[key]
+
+	rotating := newEncryptingApp(t, repo, storage, masterKeyB, masterKeyA)
+	report, err := rotating.RotateKeys(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, app.RotateKeysReport{Rewrapped: 1}, report)
+	assert.Equal(t, keyring.KeyID(masterKeyB), repo.blobs[uploaded.BlobID].KeyID)
+	assert.Equal(t, sealed, storage.sealed[key], "the data key itself must not change")
+
+	report, err = rotating.RotateKeys(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, report.Rewrapped, "rotation is idempotent")
+
+	retired := newEncryptingApp(t, repo, storage, masterKeyB)
+	dl, err := retired.DownloadStream(context.Background(), testCaller, uploaded.BlobID, 0, 0)
+	require.NoError(t, err)
+	got, _ := io.ReadAll(dl.Body)
+	_ = dl.Body.Close()
+	assert.Equal(t, content, got)
+}
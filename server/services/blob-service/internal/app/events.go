@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+const (
+	eventBatchSize           = 500
+	defaultEventPollInterval = time.Second
+)
+
+// WatchEvents calls fn for every event after afterSeq in order, then polls
+// for more until ctx is done or fn fails.
+func (a *App) WatchEvents(ctx context.Context, afterSeq int64, fn func(*domain.BlobEvent) error) error {
+	interval := a.cfg.EventPollInterval
+	if interval <= 0 {
+		interval = defaultEventPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			events, err := a.repo.ListEvents(ctx, afterSeq, eventBatchSize)
+			if err != nil {
+				return fmt.Errorf("WatchEvents: %w", err)
+			}
+			for _, e := range events {
+				if err := fn(e); err != nil {
+					return err
+				}
+				afterSeq = e.Seq
+			}
+			if len(events) < eventBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// PruneEvents removes events older than EventRetention. Watchers resuming
+// from a pruned sequence continue at the oldest retained event.
+func (a *App) PruneEvents(ctx context.Context) (int64, error) {
+	n, err := a.repo.DeleteEventsBefore(ctx, time.Now().UTC().Add(-a.cfg.EventRetention))
+	if err != nil {
+		return 0, fmt.Errorf("PruneEvents: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,84 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+func TestMarkCommitted_RecordsEvent(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	a := newApp(repo, storage)
+
+	result, err := a.UploadStream(context.Background(), testCaller, bytes.NewReader([]byte("event me")), "text/plain", "", false)
+	require.NoError(t, err)
+	require.Len(t, repo.events, 1)
+	assert.Equal(t, domain.EventCommitted, repo.events[0].Kind)
+	assert.Equal(t, result.BlobID, repo.events[0].BlobID)
+}
+
+func TestWatchEvents_ResumesAfterCursor(t *testing.T) {
+	repo := newMockRepo()
+	for i := range 3 {
+		blob, _ := domain.NewBlob(sha256Hex([]byte{byte(i)}), int64(i), "text/plain", time.Now())
+		blob.State = domain.StateScanning
+		repo.seed(blob)
+		require.NoError(t, repo.MarkCommitted(context.Background(), blob.ID, "", time.Now()))
+	}
+
+	cfg := testCfg
+	cfg.EventPollInterval = time.Millisecond
+	a := app.New(repo, &mockStorage{}, cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var seen []int64
+	err := a.WatchEvents(ctx, 1, func(e *domain.BlobEvent) error {
+		seen = append(seen, e.Seq)
+		if len(seen) == 2 {
+			cancel()
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []int64{2, 3}, seen)
+}
+
+func TestWatchEvents_CallbackErrorStops(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 0, "text/plain", time.Now())
+	blob.State = domain.StateScanning
+	repo.seed(blob)
+	require.NoError(t, repo.MarkCommitted(context.Background(), blob.ID, "", time.Now()))
+
+	a := newApp(repo, &mockStorage{})
+	boom := errors.New("send failed")
+	err := a.WatchEvents(context.Background(), 0, func(*domain.BlobEvent) error { return boom })
+	assert.ErrorIs(t, err, boom)
+}
+
+func TestPruneEvents(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 0, "text/plain", time.Now())
+	blob.State = domain.StateScanning
+	repo.seed(blob)
+	require.NoError(t, repo.MarkCommitted(context.Background(), blob.ID, "", time.Now().Add(-30*24*time.Hour)))
+
+	cfg := testCfg
+	cfg.EventRetention = 24 * time.Hour
+	a := app.New(repo, &mockStorage{}, cfg)
+
+	n, err := a.PruneEvents(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.Empty(t, repo.events)
+}
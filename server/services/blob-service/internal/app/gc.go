@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+const gcBatchSize = 500
+
+type GCReport struct {
+	DryRun     bool
+	BlobIDs    []domain.BlobID
+	FreedBytes int64
+}
+
+// CollectGarbage deletes blobs released more than GCGracePeriod ago, the row
+// before the object. Re-uploads have keys of their own, so none loses content.
+func (a *App) CollectGarbage(ctx context.Context, dryRun bool) (*GCReport, error) {
+	cutoff := time.Now().UTC().Add(-a.cfg.GCGracePeriod)
+	report := &GCReport{DryRun: dryRun}
+
+	var after domain.BlobID
+	for {
+		blobs, err := a.repo.ListReleased(ctx, cutoff, after, gcBatchSize)
+		if err != nil {
+			return report, fmt.Errorf("CollectGarbage: %w", err)
+		}
+		for _, blob := range blobs {
+			after = blob.ID
+			if !dryRun {
+				key, deleted, err := a.repo.DeleteReleased(ctx, blob.ID, cutoff)
+				if err != nil {
+					return report, fmt.Errorf("CollectGarbage: %w", err)
+				}
+				if !deleted {
+					continue
+				}
+				if err := a.storage.DeleteObject(ctx, key); err != nil {
+					return report, fmt.Errorf("CollectGarbage: %w", err)
+				}
+			}
+			report.BlobIDs = append(report.BlobIDs, blob.ID)
+			report.FreedBytes += blob.SizeBytes
+		}
+		if len(blobs) < gcBatchSize {
+			return report, nil
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+func newGCApp(repo domain.BlobRepository, storage domain.ObjectStorage) *app.App {
+	cfg := testCfg
+	cfg.GCGracePeriod = time.Hour
+	return app.New(repo, storage, cfg)
+}
+
+func seedReleased(t *testing.T, repo *mockRepo, storage *mockStorage, content string, releasedAgo time.Duration) domain.BlobID {
+	t.Helper()
+	id := storage.put([]byte(content))
+	blob, err := domain.NewBlob(id, int64(len(content)), "text/plain", time.Now())
+	require.NoError(t, err)
+	require.NoError(t, blob.Commit(time.Now()))
+	released := time.Now().UTC().Add(-releasedAgo)
+	blob.ReleasedAt = &released
+	repo.blobs[id] = blob
+	return id
+}
+
+func TestReleaseReference_LastReferenceMarksReleased(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+	_ = repo.AddReference(context.Background(), blob.ID, otherCaller.Subject, "", domain.QuotaPolicy{})
+
+	a := newGCApp(repo, &mockStorage{})
+
+	require.NoError(t, a.ReleaseReference(context.Background(), testCaller, blob.ID))
+	assert.Nil(t, repo.blobs[blob.ID].ReleasedAt)
+
+	require.NoError(t, a.ReleaseReference(context.Background(), otherCaller, blob.ID))
+	assert.NotNil(t, repo.blobs[blob.ID].ReleasedAt)
+}
+
+func TestReleaseReference_NotHeld(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	repo.seed(blob)
+
+	a := newGCApp(repo, &mockStorage{})
+	err := a.ReleaseReference(context.Background(), otherCaller, blob.ID)
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+}
+
+func TestAddReference_Committed(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+
+	a := newGCApp(repo, &mockStorage{})
+	require.NoError(t, a.AddReference(context.Background(), testCaller, blob.ID, otherCaller.Subject))
+	assert.True(t, repo.refs[blob.ID][otherCaller.Subject])
+}
+
+func TestAddReference_ChargesCallersClient(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+	caller := domain.Caller{Subject: testCaller.Subject, ClientID: "drive-web"}
+
+	a := newGCApp(repo, &mockStorage{})
+	require.NoError(t, a.AddReference(context.Background(), caller, blob.ID, otherCaller.Subject))
+	assert.Equal(t, "drive-web", repo.clients[blob.ID][otherCaller.Subject])
+	usage, err := repo.GetUsage(context.Background(), domain.Owner{Kind: domain.OwnerClient, ID: "drive-web"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024), usage.Bytes)
+}
+
+func TestAddReference_RequiresHolder(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+
+	a := newGCApp(repo, &mockStorage{})
+	err := a.AddReference(context.Background(), otherCaller, blob.ID, "")
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound, "knowing the hash is not enough")
+	err = a.AddReference(context.Background(), otherCaller, blob.ID, "mallory")
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+	assert.Len(t, repo.refs[blob.ID], 1)
+}
+
+func TestAddReference_Scanning(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	blob.State = domain.StateScanning
+	repo.seed(blob)
+
+	a := newGCApp(repo, &mockStorage{})
+	err := a.AddReference(context.Background(), testCaller, blob.ID, otherCaller.Subject)
+	assert.ErrorIs(t, err, domain.ErrBlobScanning)
+}
+
+func TestCollectGarbage_DeletesExpiredReleasedBlobs(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	expired := seedReleased(t, repo, storage, "expired", 2*time.Hour)
+	recent := seedReleased(t, repo, storage, "recent", time.Minute)
+
+	a := newGCApp(repo, storage)
+	report, err := a.CollectGarbage(context.Background(), false)
+	require.NoError(t, err)
+
+	assert.False(t, report.DryRun)
+	assert.Equal(t, []domain.BlobID{expired}, report.BlobIDs)
+	assert.Equal(t, int64(len("expired")), report.FreedBytes)
+	assert.NotContains(t, repo.blobs, expired)
+	assert.Contains(t, repo.blobs, recent)
+	assert.Equal(t, []string{string(expired)}, storage.deleted)
+	require.Len(t, repo.events, 1)
+	assert.Equal(t, domain.EventDeleted, repo.events[0].Kind)
+	assert.Equal(t, expired, repo.events[0].BlobID)
+}
+
+func TestCollectGarbage_DryRun(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	expired := seedReleased(t, repo, storage, "expired", 2*time.Hour)
+
+	a := newGCApp(repo, storage)
+	report, err := a.CollectGarbage(context.Background(), true)
+	require.NoError(t, err)
+
+	assert.True(t, report.DryRun)
+	assert.Equal(t, []domain.BlobID{expired}, report.BlobIDs)
+	assert.Contains(t, repo.blobs, expired)
+	assert.Empty(t, storage.deleted)
+}
+
+func TestCollectGarbage_SkipsReferencedBlobs(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	id := seedReleased(t, repo, storage, "re-referenced", 2*time.Hour)
+	_ = repo.AddReference(context.Background(), id, testCaller.Subject, "", domain.QuotaPolicy{})
+
+	a := newGCApp(repo, storage)
+	report, err := a.CollectGarbage(context.Background(), false)
+	require.NoError(t, err)
+	assert.Empty(t, report.BlobIDs)
+	assert.Contains(t, repo.blobs, id)
+}
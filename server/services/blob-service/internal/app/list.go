@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+const (
+	MaxBatchBlobIDs     = 1000
+	DefaultListPageSize = 100
+	MaxListPageSize     = 1000
+)
+
+// BlobInfoResult is one entry of a BatchGetBlobInfo response. Exactly one of
+// Blob and Err is set.
+type BlobInfoResult struct {
+	BlobID domain.BlobID
+	Blob   *domain.Blob
+	Err    error
+}
+
+// BatchGetBlobInfo looks up blobs in the order of ids. An entry fails on its
+// own the way GetBlobInfo would fail for it.
+func (a *App) BatchGetBlobInfo(ctx context.Context, caller domain.Caller, ids []domain.BlobID) ([]BlobInfoResult, error) {
+	if len(ids) > MaxBatchBlobIDs {
+		return nil, fmt.Errorf("%w: at most %d per call", domain.ErrTooManyBlobIDs, MaxBatchBlobIDs)
+	}
+
+	results := make([]BlobInfoResult, len(ids))
+	valid := make([]domain.BlobID, 0, len(ids))
+	for i, id := range ids {
+		results[i].BlobID = id
+		if err := id.Validate(); err != nil {
+			results[i].Err = err
+			continue
+		}
+		valid = append(valid, id)
+	}
+
+	blobs, err := a.repo.FindReferenced(ctx, valid, caller.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("BatchGetBlobInfo: %w", err)
+	}
+	byID := make(map[domain.BlobID]*domain.Blob, len(blobs))
+	for _, b := range blobs {
+		byID[b.ID] = b
+	}
+	for i := range results {
+		if results[i].Err != nil {
+			continue
+		}
+		if b, ok := byID[results[i].BlobID]; ok {
+			results[i].Blob = b
+		} else {
+			results[i].Err = domain.ErrBlobNotFound
+		}
+	}
+	return results, nil
+}
+
+type ListBlobsResult struct {
+	Blobs         []*domain.Blob
+	NextPageToken string
+}
+
+// ListBlobs pages through the blobs the caller holds a reference to.
+func (a *App) ListBlobs(ctx context.Context, caller domain.Caller, f domain.BlobFilter, pageSize int, pageToken string) (*ListBlobsResult, error) {
+	if pageSize <= 0 {
+		pageSize = DefaultListPageSize
+	}
+	pageSize = min(pageSize, MaxListPageSize)
+
+	var after domain.BlobID
+	if pageToken != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(pageToken)
+		after = domain.BlobID(raw)
+		if err != nil || after.Validate() != nil {
+			return nil, domain.ErrInvalidPageToken
+		}
+	}
+
+	f.Subject = caller.Subject
+	blobs, err := a.repo.ListBlobs(ctx, f, after, pageSize+1)
+	if err != nil {
+		return nil, fmt.Errorf("ListBlobs: %w", err)
+	}
+
+	result := &ListBlobsResult{Blobs: blobs}
+	if len(blobs) > pageSize {
+		result.Blobs = blobs[:pageSize]
+		last := result.Blobs[pageSize-1].ID
+		result.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(last))
+	}
+	return result, nil
+}
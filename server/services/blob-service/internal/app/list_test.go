@@ -0,0 +1,112 @@
+package app_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+func seedBlobs(t *testing.T, repo *mockRepo, n int, contentType string, created time.Time) []domain.BlobID {
+	t.Helper()
+	ids := make([]domain.BlobID, n)
+	for i := range ids {
+		ids[i] = sha256Hex([]byte(fmt.Sprintf("%s-%d", contentType, i)))
+		blob, err := domain.NewBlob(ids[i], int64(i), contentType, created)
+		require.NoError(t, err)
+		_ = blob.Commit(created)
+		repo.seed(blob)
+	}
+	return ids
+}
+
+func TestBatchGetBlobInfo_PartialResults(t *testing.T) {
+	repo := newMockRepo()
+	ids := seedBlobs(t, repo, 2, "text/plain", time.Now())
+
+	foreign, _ := domain.NewBlob(sha256Hex([]byte("foreign")), 1, "text/plain", time.Now())
+	repo.blobs[foreign.ID] = foreign
+	_ = repo.AddReference(context.Background(), foreign.ID, otherCaller.Subject, "", domain.QuotaPolicy{})
+
+	a := newApp(repo, &mockStorage{})
+	results, err := a.BatchGetBlobInfo(context.Background(), testCaller, []domain.BlobID{ids[1], "bogus", foreign.ID, ids[0]})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	assert.Equal(t, ids[1], results[0].Blob.ID)
+	assert.ErrorIs(t, results[1].Err, domain.ErrInvalidBlobID)
+	assert.ErrorIs(t, results[2].Err, domain.ErrBlobNotFound, "blobs the caller does not reference are hidden")
+	assert.Equal(t, ids[0], results[3].Blob.ID)
+}
+
+func TestBatchGetBlobInfo_TooMany(t *testing.T) {
+	a := newApp(newMockRepo(), &mockStorage{})
+	_, err := a.BatchGetBlobInfo(context.Background(), testCaller, make([]domain.BlobID, app.MaxBatchBlobIDs+1))
+	assert.ErrorIs(t, err, domain.ErrTooManyBlobIDs)
+}
+
+func TestListBlobs_Paginates(t *testing.T) {
+	repo := newMockRepo()
+	seedBlobs(t, repo, 5, "text/plain", time.Now())
+
+	a := newApp(repo, &mockStorage{})
+	var (
+		seen  []domain.BlobID
+		token string
+	)
+	for {
+		result, err := a.ListBlobs(context.Background(), testCaller, domain.BlobFilter{}, 2, token)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(result.Blobs), 2)
+		for _, b := range result.Blobs {
+			seen = append(seen, b.ID)
+		}
+		if result.NextPageToken == "" {
+			break
+		}
+		token = result.NextPageToken
+	}
+	assert.Len(t, seen, 5)
+	assert.IsIncreasing(t, seen)
+
+	result, err := a.ListBlobs(context.Background(), otherCaller, domain.BlobFilter{}, 0, "")
+	require.NoError(t, err)
+	assert.Empty(t, result.Blobs, "only the caller's references are listed")
+}
+
+func TestListBlobs_Filters(t *testing.T) {
+	repo := newMockRepo()
+	old := time.Now().Add(-48 * time.Hour)
+	images := seedBlobs(t, repo, 2, "image/png", time.Now())
+	seedBlobs(t, repo, 2, "video/mp4", old)
+	scanning, _ := domain.NewBlob(sha256Hex([]byte("scanning")), 1, "image/jpeg", time.Now())
+	scanning.State = domain.StateScanning
+	repo.seed(scanning)
+
+	a := newApp(repo, &mockStorage{})
+	list := func(f domain.BlobFilter) []*domain.Blob {
+		t.Helper()
+		result, err := a.ListBlobs(context.Background(), testCaller, f, 0, "")
+		require.NoError(t, err)
+		return result.Blobs
+	}
+
+	assert.Len(t, list(domain.BlobFilter{ContentType: "image/*"}), 3)
+	assert.Len(t, list(domain.BlobFilter{ContentType: "image/*", State: domain.StateCommitted}), 2)
+	assert.Len(t, list(domain.BlobFilter{CreatedBefore: time.Now().Add(-24 * time.Hour)}), 2)
+	got := list(domain.BlobFilter{ContentType: "image/png", CommittedAfter: time.Now().Add(-time.Hour)})
+	require.Len(t, got, 2)
+	assert.ElementsMatch(t, images, []domain.BlobID{got[0].ID, got[1].ID})
+}
+
+func TestListBlobs_InvalidPageToken(t *testing.T) {
+	a := newApp(newMockRepo(), &mockStorage{})
+	_, err := a.ListBlobs(context.Background(), testCaller, domain.BlobFilter{}, 10, "not-a-token")
+	assert.ErrorIs(t, err, domain.ErrInvalidPageToken)
+}
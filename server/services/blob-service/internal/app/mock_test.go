@@ -1,7 +1,16 @@
 package app_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
@@ -9,12 +18,65 @@ import (
 
 type mockRepo struct {
 	blobs     map[domain.BlobID]*domain.Blob
+	refs      map[domain.BlobID]map[string]bool
+	clients   map[domain.BlobID]map[string]string
+	usage     map[domain.Owner]domain.Usage
+	links     map[domain.BlobID]map[string]domain.BlobID
+	uploads   map[uploadKey]*domain.Upload
+	sessions  map[uploadKey]*domain.MultipartSession
+	claimed   map[claimKey]int32
+	cursors   map[string]int64
+	events    []*domain.BlobEvent
+	audit     []domain.AuditAction
 	createErr error
 	commitErr error
 }
 
 func newMockRepo() *mockRepo {
-	return &mockRepo{blobs: make(map[domain.BlobID]*domain.Blob)}
+	return &mockRepo{
+		blobs:    make(map[domain.BlobID]*domain.Blob),
+		refs:     make(map[domain.BlobID]map[string]bool),
+		clients:  make(map[domain.BlobID]map[string]string),
+		usage:    make(map[domain.Owner]domain.Usage),
+		links:    make(map[domain.BlobID]map[string]domain.BlobID),
+		uploads:  make(map[uploadKey]*domain.Upload),
+		sessions: make(map[uploadKey]*domain.MultipartSession),
+		claimed:  make(map[claimKey]int32),
+		cursors:  make(map[string]int64),
+	}
+}
+
+// uploadKey identifies a subject's upload of a blob.
+type uploadKey struct {
+	id      domain.BlobID
+	subject string
+}
+
+// claimKey identifies the part or staging numbers of a resumable session.
+type claimKey struct {
+	uploadID string
+	part     bool
+}
+
+// seed stores b with a reference held by testCaller.
+// seed stores b referenced by testCaller. A PENDING blob is seeded as
+// testCaller's upload of the object stored under its ID instead.
+func (m *mockRepo) seed(b *domain.Blob) {
+	if b.State == domain.StatePending {
+		m.uploads[uploadKey{b.ID, testCaller.Subject}] = &domain.Upload{
+			BlobID:      b.ID,
+			Subject:     testCaller.Subject,
+			ObjectKey:   string(b.ID),
+			SizeBytes:   b.SizeBytes,
+			ContentType: b.ContentType,
+			KeyID:       b.KeyID,
+			WrappedKey:  b.WrappedKey,
+			CreatedAt:   b.CreatedAt,
+		}
+		return
+	}
+	m.blobs[b.ID] = b
+	_ = m.AddReference(context.Background(), b.ID, testCaller.Subject, "", domain.QuotaPolicy{})
 }
 
 func (m *mockRepo) FindByID(_ context.Context, id domain.BlobID) (*domain.Blob, error) {
@@ -34,7 +96,7 @@ func (m *mockRepo) Create(_ context.Context, b *domain.Blob) error {
 	return nil
 }
 
-func (m *mockRepo) MarkCommitted(_ context.Context, id domain.BlobID, at time.Time) error {
+func (m *mockRepo) MarkCommitted(_ context.Context, id domain.BlobID, detectedContentType string, at time.Time) error {
 	if m.commitErr != nil {
 		return m.commitErr
 	}
@@ -42,11 +104,532 @@ func (m *mockRepo) MarkCommitted(_ context.Context, id domain.BlobID, at time.Ti
 	if !ok {
 		return domain.ErrBlobNotFound
 	}
+	if b.State != domain.StatePending && b.State != domain.StateScanning {
+		return domain.ErrAlreadyCommitted
+	}
 	b.State = domain.StateCommitted
+	b.DetectedContentType = detectedContentType
 	b.CommittedAt = &at
+	for subject := range m.refs[id] {
+		m.charge(b, subject, m.clients[id][subject], 1)
+	}
+	m.recordEvent(domain.EventCommitted, b, at)
+	return nil
+}
+
+func (m *mockRepo) MarkQuarantined(_ context.Context, id domain.BlobID, reason string) error {
+	b, ok := m.blobs[id]
+	if !ok || b.State != domain.StateScanning {
+		return domain.ErrAlreadyCommitted
+	}
+	b.State = domain.StateQuarantined
+	b.QuarantineReason = reason
+	return nil
+}
+
+func (m *mockRepo) MarkMissing(_ context.Context, id domain.BlobID) error {
+	b, ok := m.blobs[id]
+	if !ok || (b.State != domain.StateCommitted && b.State != domain.StateScanning) {
+		return domain.ErrAlreadyCommitted
+	}
+	if b.State == domain.StateCommitted {
+		for subject := range m.refs[id] {
+			m.charge(b, subject, m.clients[id][subject], -1)
+		}
+	}
+	b.State = domain.StateMissing
+	b.CommittedAt = nil
+	b.ReplicatedAt = nil
+	return nil
+}
+
+func (m *mockRepo) ListAll(_ context.Context, afterID domain.BlobID, limit int) ([]*domain.Blob, error) {
+	var out []*domain.Blob
+	for id, b := range m.blobs {
+		if id > afterID {
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (m *mockRepo) FindByIDs(_ context.Context, ids []domain.BlobID) ([]*domain.Blob, error) {
+	var out []*domain.Blob
+	for _, id := range ids {
+		if b, ok := m.blobs[id]; ok {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepo) ListScanning(_ context.Context, afterID domain.BlobID, limit int) ([]*domain.Blob, error) {
+	var out []*domain.Blob
+	for id, b := range m.blobs {
+		if b.State == domain.StateScanning && id > afterID {
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (m *mockRepo) ListStaleKeys(_ context.Context, keyID string, afterID domain.BlobID, limit int) ([]*domain.Blob, error) {
+	var out []*domain.Blob
+	for id, b := range m.blobs {
+		if b.KeyID != "" && b.KeyID != keyID && id > afterID {
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (m *mockRepo) RewrapKey(_ context.Context, id domain.BlobID, oldKeyID, keyID string, wrapped []byte) (bool, error) {
+	b, ok := m.blobs[id]
+	if !ok || b.KeyID != oldKeyID {
+		return false, nil
+	}
+	b.KeyID, b.WrappedKey = keyID, wrapped
+	return true, nil
+}
+
+func (m *mockRepo) ListUnreplicated(_ context.Context, afterID domain.BlobID, limit int) ([]*domain.Blob, error) {
+	var out []*domain.Blob
+	for id, b := range m.blobs {
+		if b.State == domain.StateCommitted && b.ReplicatedAt == nil && id > afterID {
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (m *mockRepo) MarkReplicated(_ context.Context, id domain.BlobID, at time.Time) error {
+	if b, ok := m.blobs[id]; ok {
+		b.ReplicatedAt = &at
+	}
+	return nil
+}
+
+func (m *mockRepo) SetRetention(_ context.Context, id domain.BlobID, until time.Time, _ domain.Caller) error {
+	b, ok := m.blobs[id]
+	if !ok {
+		return domain.ErrBlobNotFound
+	}
+	if b.RetainUntil != nil && b.RetainUntil.After(until) {
+		return domain.ErrRetentionShortened
+	}
+	b.RetainUntil = &until
+	m.audit = append(m.audit, domain.AuditRetentionSet)
+	return nil
+}
+
+func (m *mockRepo) SetLegalHold(_ context.Context, id domain.BlobID, hold bool, _ string, _ domain.Caller) error {
+	b, ok := m.blobs[id]
+	if !ok {
+		return domain.ErrBlobNotFound
+	}
+	b.LegalHold = hold
+	if hold {
+		m.audit = append(m.audit, domain.AuditLegalHoldSet)
+	} else {
+		m.audit = append(m.audit, domain.AuditLegalHoldReleased)
+	}
+	return nil
+}
+
+func (m *mockRepo) charge(b *domain.Blob, subject, clientID string, sign int64) {
+	caller := domain.Caller{Subject: subject, ClientID: clientID}
+	for _, o := range caller.Owners() {
+		u := m.usage[o]
+		u.Bytes += sign * b.SizeBytes
+		u.Objects += sign
+		m.usage[o] = u
+	}
+}
+
+// admit mirrors the repository's quota check on charging a new reference.
+func (m *mockRepo) admit(b *domain.Blob, subject, clientID string, quotas domain.QuotaPolicy) error {
+	if b.State != domain.StateCommitted || m.refs[b.ID][subject] {
+		return nil
+	}
+	caller := domain.Caller{Subject: subject, ClientID: clientID}
+	for _, o := range caller.Owners() {
+		if err := quotas.LimitsFor(o).Admit(m.usage[o], b.SizeBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockRepo) GetUsage(_ context.Context, o domain.Owner) (*domain.Usage, error) {
+	u := m.usage[o]
+	return &u, nil
+}
+
+func (m *mockRepo) recordEvent(kind domain.EventKind, b *domain.Blob, at time.Time) {
+	m.events = append(m.events, &domain.BlobEvent{
+		Seq:         int64(len(m.events) + 1),
+		Kind:        kind,
+		BlobID:      b.ID,
+		SizeBytes:   b.SizeBytes,
+		ContentType: b.ContentType,
+		OccurredAt:  at,
+	})
+}
+
+func (m *mockRepo) FindReferenced(_ context.Context, ids []domain.BlobID, subject string) ([]*domain.Blob, error) {
+	var out []*domain.Blob
+	for _, id := range ids {
+		if b, ok := m.blobs[id]; ok && m.refs[id][subject] {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepo) ListBlobs(_ context.Context, f domain.BlobFilter, afterID domain.BlobID, limit int) ([]*domain.Blob, error) {
+	var out []*domain.Blob
+	for id, b := range m.blobs {
+		if id > afterID && (f.Subject == "" || m.refs[id][f.Subject]) && matchesFilter(b, f) {
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func matchesFilter(b *domain.Blob, f domain.BlobFilter) bool {
+	if f.State != "" && b.State != f.State {
+		return false
+	}
+	if major, ok := strings.CutSuffix(f.ContentType, "/*"); ok {
+		if !strings.HasPrefix(b.ContentType, major+"/") {
+			return false
+		}
+	} else if f.ContentType != "" && b.ContentType != f.ContentType {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && b.CreatedAt.Before(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !b.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	if !f.CommittedAfter.IsZero() && (b.CommittedAt == nil || b.CommittedAt.Before(f.CommittedAfter)) {
+		return false
+	}
+	if !f.CommittedBefore.IsZero() && (b.CommittedAt == nil || !b.CommittedAt.Before(f.CommittedBefore)) {
+		return false
+	}
+	return true
+}
+
+func (m *mockRepo) AddReference(_ context.Context, id domain.BlobID, subject, clientID string, quotas domain.QuotaPolicy) error {
+	b, ok := m.blobs[id]
+	if !ok {
+		return domain.ErrBlobNotFound
+	}
+	if m.refs[id][subject] {
+		return nil
+	}
+	if err := m.admit(b, subject, clientID, quotas); err != nil {
+		return err
+	}
+	if m.refs[id] == nil {
+		m.refs[id] = make(map[string]bool)
+		m.clients[id] = make(map[string]string)
+	}
+	m.refs[id][subject] = true
+	m.clients[id][subject] = clientID
+	b.ReleasedAt = nil
+	if b.State == domain.StateCommitted {
+		m.charge(b, subject, clientID, 1)
+	}
+	return nil
+}
+
+func (m *mockRepo) HasReference(_ context.Context, id domain.BlobID, subject string) (bool, error) {
+	return m.refs[id][subject], nil
+}
+
+func (m *mockRepo) RemoveReference(_ context.Context, id domain.BlobID, subject string, at time.Time) error {
+	if !m.refs[id][subject] {
+		return domain.ErrBlobNotFound
+	}
+	b := m.blobs[id]
+	if b.State == domain.StateCommitted {
+		m.charge(b, subject, m.clients[id][subject], -1)
+	}
+	delete(m.refs[id], subject)
+	delete(m.clients[id], subject)
+	if len(m.refs[id]) == 0 {
+		b.ReleasedAt = &at
+	}
+	return nil
+}
+
+func (m *mockRepo) ListReleased(_ context.Context, before time.Time, afterID domain.BlobID, limit int) ([]*domain.Blob, error) {
+	var out []*domain.Blob
+	for id, b := range m.blobs {
+		if b.State == domain.StateCommitted && b.ReleasedAt != nil && b.ReleasedAt.Before(before) &&
+			len(m.refs[id]) == 0 && !b.Held(time.Now()) && id > afterID {
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (m *mockRepo) DeleteReleased(_ context.Context, id domain.BlobID, before time.Time) (string, bool, error) {
+	b, ok := m.blobs[id]
+	if !ok || b.ReleasedAt == nil || !b.ReleasedAt.Before(before) || len(m.refs[id]) > 0 || b.Held(time.Now()) {
+		return "", false, nil
+	}
+	delete(m.blobs, id)
+	m.recordEvent(domain.EventDeleted, b, time.Now())
+	return b.R2Key, true, nil
+}
+
+func (m *mockRepo) RecordMultipartUpload(_ context.Context, s *domain.MultipartSession) error {
+	k := uploadKey{s.BlobID, s.Subject}
+	if _, ok := m.uploads[k]; !ok {
+		return domain.ErrBlobNotFound
+	}
+	if _, ok := m.sessions[k]; ok {
+		return domain.ErrSessionExists
+	}
+	cp := *s
+	m.sessions[k] = &cp
+	return nil
+}
+
+func (m *mockRepo) FindMultipartUpload(_ context.Context, id domain.BlobID, subject string) (*domain.MultipartSession, error) {
+	s, ok := m.sessions[uploadKey{id, subject}]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+	cp := *s
+	cp.Parts, cp.Staged = slices.Clone(s.Parts), slices.Clone(s.Staged)
+	return &cp, nil
+}
+
+func (m *mockRepo) DeleteMultipartUpload(_ context.Context, uploadID string) error {
+	for k, s := range m.sessions {
+		if s.UploadID == uploadID {
+			delete(m.sessions, k)
+		}
+	}
 	return nil
 }
 
+func (m *mockRepo) HasMultipartUpload(_ context.Context, uploadID string) (bool, error) {
+	for _, s := range m.sessions {
+		if s.UploadID == uploadID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockRepo) resumableSession(uploadID string, offset int64) (*domain.MultipartSession, error) {
+	for _, s := range m.sessions {
+		if s.UploadID == uploadID && s.Resumable && s.ReceivedBytes == offset {
+			return s, nil
+		}
+	}
+	return nil, domain.ErrOffsetMismatch
+}
+
+func (m *mockRepo) ClaimResumableNumber(_ context.Context, uploadID string, offset int64, part bool) (int32, error) {
+	if _, err := m.resumableSession(uploadID, offset); err != nil {
+		return 0, err
+	}
+	k := claimKey{uploadID, part}
+	m.claimed[k]++
+	return m.claimed[k], nil
+}
+
+func (m *mockRepo) AdvanceResumableUpload(_ context.Context, s *domain.MultipartSession, offset int64) error {
+	stored, err := m.resumableSession(s.UploadID, offset)
+	if err != nil {
+		return err
+	}
+	stored.ReceivedBytes, stored.StagedOffset = s.ReceivedBytes, s.StagedOffset
+	stored.Parts, stored.Staged = slices.Clone(s.Parts), slices.Clone(s.Staged)
+	return nil
+}
+
+func (m *mockRepo) CreateUpload(_ context.Context, u *domain.Upload) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	k := uploadKey{u.BlobID, u.Subject}
+	if _, ok := m.uploads[k]; ok {
+		return domain.ErrUploadExists
+	}
+	cp := *u
+	m.uploads[k] = &cp
+	return nil
+}
+
+func (m *mockRepo) FindUpload(_ context.Context, id domain.BlobID, subject string) (*domain.Upload, error) {
+	u, ok := m.uploads[uploadKey{id, subject}]
+	if !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (m *mockRepo) FindUploads(_ context.Context, ids []domain.BlobID) ([]*domain.Upload, error) {
+	var out []*domain.Upload
+	for _, u := range m.uploads {
+		if slices.Contains(ids, u.BlobID) {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepo) DeleteUpload(_ context.Context, u *domain.Upload) (bool, error) {
+	k := uploadKey{u.BlobID, u.Subject}
+	if cur, ok := m.uploads[k]; !ok || cur.ObjectKey != u.ObjectKey {
+		return false, nil
+	}
+	delete(m.uploads, k)
+	delete(m.sessions, k)
+	return true, nil
+}
+
+func (m *mockRepo) ListExpiredUploads(_ context.Context, before time.Time, afterKey string, limit int) ([]*domain.Upload, error) {
+	var out []*domain.Upload
+	for _, u := range m.uploads {
+		if u.CreatedAt.Before(before) && u.ObjectKey > afterKey {
+			out = append(out, u)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ObjectKey < out[j].ObjectKey })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (m *mockRepo) CommitUpload(ctx context.Context, u *domain.Upload, b *domain.Blob, quotas domain.QuotaPolicy) (*domain.Blob, error) {
+	if m.commitErr != nil {
+		return nil, m.commitErr
+	}
+	stored, ok := m.blobs[b.ID]
+	written := !ok || stored.State == domain.StatePending || stored.State == domain.StateMissing
+	charged := stored
+	if written {
+		charged = b
+	}
+	if err := m.admit(charged, u.Subject, u.ClientID, quotas); err != nil {
+		return nil, err
+	}
+	if ok, _ := m.DeleteUpload(ctx, u); !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+	if written {
+		cp := *b
+		if ok {
+			cp.RetainUntil, cp.LegalHold = stored.RetainUntil, stored.LegalHold
+		}
+		stored = &cp
+		m.blobs[b.ID] = stored
+	}
+	if written && stored.State == domain.StateCommitted {
+		m.recordEvent(domain.EventCommitted, stored, *stored.CommittedAt)
+		for subject := range m.refs[b.ID] {
+			m.charge(stored, subject, m.clients[b.ID][subject], 1)
+		}
+	}
+	if err := m.AddReference(ctx, b.ID, u.Subject, u.ClientID, quotas); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+func (m *mockRepo) ListEvents(_ context.Context, afterSeq int64, limit int) ([]*domain.BlobEvent, error) {
+	var out []*domain.BlobEvent
+	for _, e := range m.events {
+		if e.Seq > afterSeq && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepo) DeleteEventsBefore(_ context.Context, before time.Time) (int64, error) {
+	var kept []*domain.BlobEvent
+	for _, e := range m.events {
+		if !e.OccurredAt.Before(before) {
+			kept = append(kept, e)
+		}
+	}
+	n := int64(len(m.events) - len(kept))
+	m.events = kept
+	return n, nil
+}
+
+func (m *mockRepo) FindEventCursor(_ context.Context, consumer string) (int64, error) {
+	return m.cursors[consumer], nil
+}
+
+func (m *mockRepo) SaveEventCursor(_ context.Context, consumer string, seq int64) error {
+	m.cursors[consumer] = max(m.cursors[consumer], seq)
+	return nil
+}
+
+func (m *mockRepo) LinkRendition(_ context.Context, source domain.BlobID, spec string, rendition domain.BlobID) error {
+	if m.links[source] == nil {
+		m.links[source] = make(map[string]domain.BlobID)
+	}
+	m.links[source][spec] = rendition
+	return nil
+}
+
+func (m *mockRepo) FindRendition(_ context.Context, source domain.BlobID, spec string) (*domain.Blob, error) {
+	b, ok := m.blobs[m.links[source][spec]]
+	if !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+	return b, nil
+}
+
+func (m *mockRepo) IsRendition(_ context.Context, id domain.BlobID) (bool, error) {
+	for _, specs := range m.links {
+		for _, rid := range specs {
+			if rid == id {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
 type mockStorage struct {
 	putURL      string
 	getURL      string
@@ -54,28 +637,311 @@ type mockStorage struct {
 	partURL     string
 	completeErr error
 	abortErr    error
+
+	objects   map[string][]byte
+	checksums map[string]string
+	deleted   []string
+	uploads   []domain.MultipartUpload
+	aborted   []string
+	parts     map[string][]domain.UploadedPart
+	// partData holds the bodies of parts stored by UploadPart, which
+	// CompleteMultipartUpload combines into the object.
+	partData map[string]map[int32][]byte
+
+	putConstraints domain.PutConstraints
+	partSizes      map[int32]int64
+	getOptions     domain.GetOptions
+
+	// sealed records the customer key each object was written with.
+	sealed map[string][]byte
+}
+
+// putUpload stores content as caller's upload of id, as a client would
+// with the presigned PUT.
+func (m *mockStorage) putUpload(repo *mockRepo, caller domain.Caller, id domain.BlobID, content []byte) {
+	if m.objects == nil {
+		m.objects = make(map[string][]byte)
+	}
+	m.objects[repo.uploads[uploadKey{id, caller.Subject}].ObjectKey] = content
 }
 
-func (m *mockStorage) PresignedPutURL(_ context.Context, _ string, _ time.Duration) (string, time.Time, error) {
-	return m.putURL, time.Now().Add(time.Minute), nil
+func (m *mockStorage) put(content []byte) domain.BlobID {
+	sum := sha256.Sum256(content)
+	id := hex.EncodeToString(sum[:])
+	if m.objects == nil {
+		m.objects = make(map[string][]byte)
+	}
+	m.objects[id] = content
+	return domain.BlobID(id)
 }
 
-func (m *mockStorage) PresignedGetURL(_ context.Context, _ string, ttl time.Duration) (string, time.Time, error) {
-	return m.getURL, time.Now().Add(ttl), nil
+func (m *mockStorage) PresignedPutURL(_ context.Context, _ string, c domain.PutConstraints, _ time.Duration) (*domain.PresignedRequest, error) {
+	m.putConstraints = c
+	return &domain.PresignedRequest{
+		URL:       m.putURL,
+		Headers:   map[string]string{"Content-Type": c.ContentType},
+		ExpiresAt: time.Now().Add(time.Minute),
+	}, nil
+}
+
+func (m *mockStorage) PresignedGetURL(_ context.Context, _ string, o domain.GetOptions, ttl time.Duration) (*domain.PresignedRequest, error) {
+	m.getOptions = o
+	headers := map[string]string{}
+	if rng := o.Range(); rng != "" {
+		headers["Range"] = rng
+	}
+	return &domain.PresignedRequest{URL: m.getURL, Headers: headers, ExpiresAt: time.Now().Add(ttl)}, nil
 }
 
 func (m *mockStorage) CreateMultipartUpload(_ context.Context, _, _ string) (string, error) {
+	if m.parts == nil {
+		m.parts = make(map[string][]domain.UploadedPart)
+	}
+	if _, ok := m.parts[m.uploadID]; !ok {
+		m.parts[m.uploadID] = nil
+	}
 	return m.uploadID, nil
 }
 
-func (m *mockStorage) PresignedPartURL(_ context.Context, _, _ string, _ int32, _ time.Duration) (string, time.Time, error) {
-	return m.partURL, time.Now().Add(time.Minute), nil
+func (m *mockStorage) PresignedPartURL(_ context.Context, _, _ string, partNumber int32, sizeBytes int64, _ time.Duration) (*domain.PresignedRequest, error) {
+	if m.partSizes == nil {
+		m.partSizes = make(map[int32]int64)
+	}
+	m.partSizes[partNumber] = sizeBytes
+	return &domain.PresignedRequest{URL: m.partURL, ExpiresAt: time.Now().Add(time.Minute)}, nil
 }
 
-func (m *mockStorage) CompleteMultipartUpload(_ context.Context, _, _ string, _ []domain.CompletedPart) error {
-	return m.completeErr
+func (m *mockStorage) UploadPart(_ context.Context, _, uploadID string, partNumber int32, body io.Reader, _ int64) (string, error) {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	if m.partData == nil {
+		m.partData = make(map[string]map[int32][]byte)
+	}
+	if m.parts == nil {
+		m.parts = make(map[string][]domain.UploadedPart)
+	}
+	if m.partData[uploadID] == nil {
+		m.partData[uploadID] = make(map[int32][]byte)
+	}
+	m.partData[uploadID][partNumber] = content
+	etag := fmt.Sprintf("etag-%d", partNumber)
+	parts := m.parts[uploadID]
+	for i, p := range parts {
+		if p.PartNumber == partNumber {
+			parts = append(parts[:i], parts[i+1:]...)
+			break
+		}
+	}
+	m.parts[uploadID] = append(parts, domain.UploadedPart{PartNumber: partNumber, ETag: etag, SizeBytes: int64(len(content))})
+	return etag, nil
 }
 
-func (m *mockStorage) AbortMultipartUpload(_ context.Context, _, _ string) error {
-	return m.abortErr
+func (m *mockStorage) CompleteMultipartUpload(_ context.Context, key, uploadID string, parts []domain.CompletedPart) error {
+	return m.completeMultipart(key, uploadID, nil, parts)
+}
+
+// completeMultipart combines the parts stored by UploadPart into the object.
+// Uploads whose parts went through presigned URLs have nothing to combine.
+func (m *mockStorage) completeMultipart(key, uploadID string, customerKey []byte, parts []domain.CompletedPart) error {
+	if m.completeErr != nil {
+		return m.completeErr
+	}
+	data, ok := m.partData[uploadID]
+	if !ok {
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, p := range parts {
+		buf.Write(data[p.PartNumber])
+	}
+	delete(m.partData, uploadID)
+	delete(m.parts, uploadID)
+	return m.putObject(key, customerKey, &buf)
+}
+
+func (m *mockStorage) AbortMultipartUpload(_ context.Context, _, uploadID string) error {
+	if m.abortErr != nil {
+		return m.abortErr
+	}
+	m.aborted = append(m.aborted, uploadID)
+	return nil
+}
+
+func (m *mockStorage) ListMultipartUploads(context.Context) ([]domain.MultipartUpload, error) {
+	return m.uploads, nil
+}
+
+func (m *mockStorage) ListParts(_ context.Context, _, uploadID string) ([]domain.UploadedPart, error) {
+	parts, ok := m.parts[uploadID]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+	return parts, nil
+}
+
+func (m *mockStorage) StatObject(_ context.Context, key string) (*domain.ObjectInfo, error) {
+	return m.stat(key, nil)
+}
+
+func (m *mockStorage) stat(key string, customerKey []byte) (*domain.ObjectInfo, error) {
+	obj, err := m.object(key, customerKey)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ObjectInfo{SizeBytes: int64(len(obj)), ChecksumSHA256: m.checksums[key]}, nil
+}
+
+func (m *mockStorage) GetObject(_ context.Context, key string) (io.ReadCloser, error) {
+	return m.getRange(key, nil, 0, -1)
+}
+
+func (m *mockStorage) GetObjectRange(_ context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	return m.getRange(key, nil, offset, length)
+}
+
+func (m *mockStorage) getRange(key string, customerKey []byte, offset, length int64) (io.ReadCloser, error) {
+	obj, err := m.object(key, customerKey)
+	if err != nil {
+		return nil, err
+	}
+	end := int64(len(obj))
+	if length >= 0 {
+		end = min(end, offset+length)
+	}
+	return io.NopCloser(bytes.NewReader(obj[offset:end])), nil
+}
+
+func (m *mockStorage) PutObject(_ context.Context, key string, body io.Reader, _ int64, _, _ string) error {
+	return m.putObject(key, nil, body)
+}
+
+func (m *mockStorage) putObject(key string, customerKey []byte, body io.Reader) error {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if m.objects == nil {
+		m.objects = make(map[string][]byte)
+	}
+	if m.sealed == nil {
+		m.sealed = make(map[string][]byte)
+	}
+	m.objects[key] = content
+	m.sealed[key] = customerKey
+	return nil
+}
+
+// object returns the object under key if it was written with customerKey,
+// which is nil for objects written without one.
+func (m *mockStorage) object(key string, customerKey []byte) ([]byte, error) {
+	obj, ok := m.objects[key]
+	if !ok {
+		return nil, domain.ErrObjectNotFound
+	}
+	if !bytes.Equal(m.sealed[key], customerKey) {
+		return nil, errWrongCustomerKey
+	}
+	return obj, nil
+}
+
+func (m *mockStorage) DeleteObject(_ context.Context, key string) error {
+	delete(m.objects, key)
+	delete(m.sealed, key)
+	m.deleted = append(m.deleted, key)
+	return nil
+}
+
+func (m *mockStorage) ListObjects(_ context.Context, afterKey string, limit int) ([]domain.StoredObject, error) {
+	var out []domain.StoredObject
+	for key, obj := range m.objects {
+		if key > afterKey {
+			out = append(out, domain.StoredObject{Key: key, SizeBytes: int64(len(obj))})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (m *mockStorage) WithCustomerKey(key []byte) domain.ObjectStorage {
+	return &keyedStorage{mockStorage: m, key: key}
+}
+
+var errWrongCustomerKey = errors.New("object requires a different customer key")
+
+// keyedStorage is a mockStorage view that, like SSE-C, only reads objects
+// written with its key and puts the key in presigned request headers.
+type keyedStorage struct {
+	*mockStorage
+	key []byte
+}
+
+const customerKeyHeader = "X-Amz-Server-Side-Encryption-Customer-Key"
+
+func (k *keyedStorage) withKey(req *domain.PresignedRequest, err error) (*domain.PresignedRequest, error) {
+	if err != nil {
+		return nil, err
+	}
+	if req.Headers == nil {
+		req.Headers = map[string]string{}
+	}
+	req.Headers[customerKeyHeader] = hex.EncodeToString(k.key)
+	return req, nil
+}
+
+func (k *keyedStorage) PresignedPutURL(ctx context.Context, key string, c domain.PutConstraints, ttl time.Duration) (*domain.PresignedRequest, error) {
+	return k.withKey(k.mockStorage.PresignedPutURL(ctx, key, c, ttl))
+}
+
+func (k *keyedStorage) PresignedGetURL(ctx context.Context, key string, o domain.GetOptions, ttl time.Duration) (*domain.PresignedRequest, error) {
+	return k.withKey(k.mockStorage.PresignedGetURL(ctx, key, o, ttl))
+}
+
+func (k *keyedStorage) PresignedPartURL(ctx context.Context, key, uploadID string, partNumber int32, sizeBytes int64, ttl time.Duration) (*domain.PresignedRequest, error) {
+	return k.withKey(k.mockStorage.PresignedPartURL(ctx, key, uploadID, partNumber, sizeBytes, ttl))
+}
+
+func (k *keyedStorage) CompleteMultipartUpload(_ context.Context, key, uploadID string, parts []domain.CompletedPart) error {
+	return k.completeMultipart(key, uploadID, k.key, parts)
+}
+
+func (k *keyedStorage) StatObject(_ context.Context, key string) (*domain.ObjectInfo, error) {
+	return k.stat(key, k.key)
+}
+
+func (k *keyedStorage) GetObject(_ context.Context, key string) (io.ReadCloser, error) {
+	return k.getRange(key, k.key, 0, -1)
+}
+
+func (k *keyedStorage) GetObjectRange(_ context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	return k.getRange(key, k.key, offset, length)
+}
+
+func (k *keyedStorage) PutObject(_ context.Context, key string, body io.Reader, _ int64, _, _ string) error {
+	return k.putObject(key, k.key, body)
+}
+
+// mockScanner reports content containing "EICAR" as infected.
+type mockScanner struct {
+	err     error
+	scanned int
+}
+
+func (m *mockScanner) Scan(_ context.Context, r io.Reader) (*domain.ScanResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.scanned++
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(content, []byte("EICAR")) {
+		return &domain.ScanResult{Signature: "Eicar-Test-Signature"}, nil
+	}
+	return &domain.ScanResult{Clean: true}, nil
 }
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+// OwnerUsage is an owner's current usage together with the limits that
+// apply to it.
+type OwnerUsage struct {
+	Owner  domain.Owner
+	Usage  domain.Usage
+	Limits domain.Limits
+}
+
+// GetUsage reports the usage of each owner the caller's references are
+// charged to: its subject first, then its client if the token named one.
+func (a *App) GetUsage(ctx context.Context, caller domain.Caller) ([]OwnerUsage, error) {
+	owners := caller.Owners()
+	result := make([]OwnerUsage, 0, len(owners))
+	for _, o := range owners {
+		u, err := a.repo.GetUsage(ctx, o)
+		if err != nil {
+			return nil, fmt.Errorf("GetUsage: %w", err)
+		}
+		result = append(result, OwnerUsage{Owner: o, Usage: *u, Limits: a.cfg.Quotas.LimitsFor(o)})
+	}
+	return result, nil
+}
+
+// checkQuota only rejects early: the repository enforces the limits again
+// when it charges the reference.
+func (a *App) checkQuota(ctx context.Context, caller domain.Caller, sizeBytes int64) error {
+	for _, o := range caller.Owners() {
+		limits := a.cfg.Quotas.LimitsFor(o)
+		if limits == (domain.Limits{}) {
+			continue
+		}
+		u, err := a.repo.GetUsage(ctx, o)
+		if err != nil {
+			return err
+		}
+		if err := limits.Admit(*u, sizeBytes); err != nil {
+			return fmt.Errorf("%s: %w", o, err)
+		}
+	}
+	return nil
+}
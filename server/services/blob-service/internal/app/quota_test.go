@@ -0,0 +1,143 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+func newQuotaApp(repo domain.BlobRepository, quotas domain.QuotaPolicy) *app.App {
+	cfg := testCfg
+	cfg.Quotas = quotas
+	return app.New(repo, &mockStorage{}, cfg)
+}
+
+func TestInitiateUpload_QuotaExceeded(t *testing.T) {
+	repo := newMockRepo()
+	seedBlobs(t, repo, 1, "text/plain", time.Now())
+	subject := domain.Owner{Kind: domain.OwnerSubject, ID: testCaller.Subject}
+	repo.usage[subject] = domain.Usage{Bytes: 900, Objects: 1}
+
+	for name, limits := range map[string]domain.Limits{
+		"bytes":       {MaxBytes: 1000},
+		"objects":     {MaxObjects: 1},
+		"object size": {MaxObjectBytes: 100},
+	} {
+		t.Run(name, func(t *testing.T) {
+			a := newQuotaApp(repo, domain.QuotaPolicy{Subject: limits})
+			_, err := a.InitiateUpload(context.Background(), testCaller, domain.BlobID(validID), 200, "text/plain", false)
+			assert.ErrorIs(t, err, domain.ErrQuotaExceeded)
+			_, err = repo.FindByID(context.Background(), domain.BlobID(validID))
+			assert.ErrorIs(t, err, domain.ErrBlobNotFound, "no blob is created over quota")
+		})
+	}
+}
+
+func TestInitiateUpload_QuotaOverride(t *testing.T) {
+	repo := newMockRepo()
+	a := newQuotaApp(repo, domain.QuotaPolicy{
+		Subject:   domain.Limits{MaxBytes: 100},
+		Overrides: map[string]domain.Limits{"subject:" + testCaller.Subject: {MaxBytes: 1000}},
+	})
+
+	_, err := a.InitiateUpload(context.Background(), testCaller, domain.BlobID(validID), 200, "text/plain", false)
+	require.NoError(t, err)
+	_, err = a.InitiateUpload(context.Background(), otherCaller, domain.BlobID(validID), 200, "text/plain", false)
+	assert.ErrorIs(t, err, domain.ErrQuotaExceeded)
+}
+
+func TestInitiateUpload_ClientQuota(t *testing.T) {
+	repo := newMockRepo()
+	caller := domain.Caller{Subject: "alice", ClientID: "web"}
+	repo.usage[domain.Owner{Kind: domain.OwnerClient, ID: "web"}] = domain.Usage{Objects: 5}
+	a := newQuotaApp(repo, domain.QuotaPolicy{Client: domain.Limits{MaxObjects: 5}})
+
+	_, err := a.InitiateUpload(context.Background(), caller, domain.BlobID(validID), 10, "text/plain", false)
+	assert.ErrorIs(t, err, domain.ErrQuotaExceeded)
+	_, err = a.InitiateUpload(context.Background(), testCaller, domain.BlobID(validID), 10, "text/plain", false)
+	assert.NoError(t, err, "callers without a client ID are only held to subject limits")
+}
+
+func TestInitiateUpload_HeldReferenceNotRecharged(t *testing.T) {
+	repo := newMockRepo()
+	ids := seedBlobs(t, repo, 2, "text/plain", time.Now())
+	a := newQuotaApp(repo, domain.QuotaPolicy{Subject: domain.Limits{MaxObjects: 2}})
+
+	blob := repo.blobs[ids[1]]
+	_, err := a.InitiateUpload(context.Background(), testCaller, blob.ID, blob.SizeBytes, blob.ContentType, false)
+	assert.NoError(t, err, "a blob the caller already references costs nothing more")
+
+	_, err = a.InitiateUpload(context.Background(), testCaller, domain.BlobID(validID), 1, "text/plain", false)
+	assert.ErrorIs(t, err, domain.ErrQuotaExceeded)
+}
+
+func TestCompleteUpload_QuotaEnforcedOnCommit(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	cfg := testCfg
+	cfg.Quotas = domain.QuotaPolicy{Subject: domain.Limits{MaxObjects: 1}}
+	a := app.New(repo, storage, cfg)
+	first, second := []byte("first"), []byte("second")
+
+	for _, content := range [][]byte{first, second} {
+		_, err := a.InitiateUpload(context.Background(), testCaller, sha256Hex(content), int64(len(content)), "text/plain", false)
+		require.NoError(t, err, "neither upload is charged yet")
+		storage.putUpload(repo, testCaller, sha256Hex(content), content)
+	}
+
+	_, err := a.CompleteUpload(context.Background(), testCaller, sha256Hex(first))
+	require.NoError(t, err)
+	_, err = a.CompleteUpload(context.Background(), testCaller, sha256Hex(second))
+	assert.ErrorIs(t, err, domain.ErrQuotaExceeded, "the limit is enforced again when the reference is charged")
+	assert.False(t, repo.refs[sha256Hex(second)][testCaller.Subject])
+	assert.Contains(t, repo.uploads, uploadKey{sha256Hex(second), testCaller.Subject}, "the upload survives the failed commit")
+}
+
+func TestUsage_ChargedOnCommitAndRelease(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	a := app.New(repo, storage, testCfg)
+	caller := domain.Caller{Subject: "alice", ClientID: "web"}
+	content := []byte("hello quotas")
+	id := sha256Hex(content)
+
+	_, err := a.InitiateUpload(context.Background(), caller, id, int64(len(content)), "text/plain", false)
+	require.NoError(t, err)
+	storage.putUpload(repo, caller, id, content)
+	usage, err := a.GetUsage(context.Background(), caller)
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+	assert.Zero(t, usage[0].Usage, "pending uploads are not charged")
+
+	_, err = a.CompleteUpload(context.Background(), caller, id)
+	require.NoError(t, err)
+	usage, err = a.GetUsage(context.Background(), caller)
+	require.NoError(t, err)
+	want := domain.Usage{Bytes: int64(len(content)), Objects: 1}
+	assert.Equal(t, domain.Owner{Kind: domain.OwnerSubject, ID: "alice"}, usage[0].Owner)
+	assert.Equal(t, want, usage[0].Usage)
+	assert.Equal(t, domain.Owner{Kind: domain.OwnerClient, ID: "web"}, usage[1].Owner)
+	assert.Equal(t, want, usage[1].Usage)
+
+	require.NoError(t, a.ReleaseReference(context.Background(), caller, id))
+	usage, err = a.GetUsage(context.Background(), caller)
+	require.NoError(t, err)
+	assert.Zero(t, usage[0].Usage)
+	assert.Zero(t, usage[1].Usage)
+}
+
+func TestAddReference_QuotaExceeded(t *testing.T) {
+	repo := newMockRepo()
+	ids := seedBlobs(t, repo, 1, "text/plain", time.Now())
+	a := newQuotaApp(repo, domain.QuotaPolicy{Subject: domain.Limits{MaxObjects: 1}})
+	repo.usage[domain.Owner{Kind: domain.OwnerSubject, ID: otherCaller.Subject}] = domain.Usage{Objects: 1}
+
+	assert.ErrorIs(t, a.AddReference(context.Background(), testCaller, ids[0], otherCaller.Subject), domain.ErrQuotaExceeded)
+	assert.NoError(t, a.AddReference(context.Background(), testCaller, ids[0], testCaller.Subject), "re-adding a held reference is not charged")
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const reaperBatchSize = 500
+
+type ExpiryReport struct {
+	ExpiredUploads int
+	AbortedUploads int
+}
+
+// ExpirePending removes uploads older than PendingTTL with their objects and
+// sessions, then aborts untracked bucket uploads of ours past the same cutoff.
+func (a *App) ExpirePending(ctx context.Context) (*ExpiryReport, error) {
+	cutoff := time.Now().UTC().Add(-a.cfg.PendingTTL)
+	report := &ExpiryReport{}
+	var errs []error
+
+	var afterKey string
+	for {
+		expired, err := a.repo.ListExpiredUploads(ctx, cutoff, afterKey, reaperBatchSize)
+		if err != nil {
+			return report, fmt.Errorf("ExpirePending: %w", err)
+		}
+		for _, upload := range expired {
+			afterKey = upload.ObjectKey
+			deleted, aborted, err := a.discardUpload(ctx, upload)
+			if deleted {
+				report.ExpiredUploads++
+			}
+			if aborted {
+				report.AbortedUploads++
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("upload %s: %w", upload.ObjectKey, err))
+			}
+		}
+		if len(expired) < reaperBatchSize {
+			break
+		}
+	}
+
+	uploads, err := a.storage.ListMultipartUploads(ctx)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, u := range uploads {
+		if _, ours := blobOfKey(u.Key); !ours || !u.InitiatedAt.Before(cutoff) {
+			continue
+		}
+		tracked, err := a.repo.HasMultipartUpload(ctx, u.UploadID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("upload %s: %w", u.UploadID, err))
+			continue
+		}
+		if tracked {
+			continue
+		}
+		if err := a.storage.AbortMultipartUpload(ctx, u.Key, u.UploadID); err != nil {
+			errs = append(errs, fmt.Errorf("upload %s: %w", u.UploadID, err))
+			continue
+		}
+		report.AbortedUploads++
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return report, fmt.Errorf("ExpirePending: %w", err)
+	}
+	return report, nil
+}
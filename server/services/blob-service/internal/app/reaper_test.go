@@ -0,0 +1,86 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+func newReaperApp(repo domain.BlobRepository, storage domain.ObjectStorage) *app.App {
+	cfg := testCfg
+	cfg.PendingTTL = time.Hour
+	return app.New(repo, storage, cfg)
+}
+
+func TestInitiateMultipartUpload_TracksUploadID(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{uploadID: "mpu-123", partURL: "https://r2.example.com/part"}
+	a := newApp(repo, storage)
+
+	_, err := a.InitiateMultipartUpload(context.Background(), testCaller, domain.BlobID(validID), 1024*1024*50, "video/mp4", 3, false)
+	require.NoError(t, err)
+	assert.Equal(t, "mpu-123", repo.sessions[uploadKey{domain.BlobID(validID), testCaller.Subject}].UploadID)
+
+	err = a.AbortMultipartUpload(context.Background(), testCaller, domain.BlobID(validID), "mpu-456")
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound, "only the caller's own session can be aborted")
+	assert.Empty(t, storage.aborted)
+
+	require.NoError(t, a.AbortMultipartUpload(context.Background(), testCaller, domain.BlobID(validID), "mpu-123"))
+	assert.Empty(t, repo.sessions)
+}
+
+func TestExpirePending_RemovesAbandonedUploads(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+
+	stale, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now().Add(-2*time.Hour))
+	repo.seed(stale)
+	repo.sessions[uploadKey{stale.ID, testCaller.Subject}] = &domain.MultipartSession{BlobID: stale.ID, Subject: testCaller.Subject, UploadID: "mpu-stale", PartCount: 1}
+
+	freshID := storage.put([]byte("fresh"))
+	fresh, _ := domain.NewBlob(freshID, 5, "text/plain", time.Now())
+	repo.seed(fresh)
+
+	committedID := storage.put([]byte("committed"))
+	committed, _ := domain.NewBlob(committedID, 9, "text/plain", time.Now().Add(-2*time.Hour))
+	_ = committed.Commit(time.Now())
+	repo.seed(committed)
+
+	a := newReaperApp(repo, storage)
+	report, err := a.ExpirePending(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.ExpiredUploads)
+	assert.Equal(t, 1, report.AbortedUploads)
+	assert.NotContains(t, repo.uploads, uploadKey{stale.ID, testCaller.Subject})
+	assert.Contains(t, repo.uploads, uploadKey{freshID, testCaller.Subject})
+	assert.Contains(t, repo.blobs, committedID)
+	assert.Equal(t, []string{"mpu-stale"}, storage.aborted)
+	assert.Equal(t, []string{validID}, storage.deleted)
+}
+
+func TestExpirePending_AbortsStrayUploads(t *testing.T) {
+	repo := newMockRepo()
+	live := domain.NewObjectKey(domain.BlobID(validID))
+	repo.sessions[uploadKey{domain.BlobID(validID), testCaller.Subject}] = &domain.MultipartSession{BlobID: domain.BlobID(validID), Subject: testCaller.Subject, UploadID: "live", PartCount: 1}
+	storage := &mockStorage{uploads: []domain.MultipartUpload{
+		{Key: validID, UploadID: "stray-old", InitiatedAt: time.Now().Add(-2 * time.Hour)},
+		{Key: validID, UploadID: "stray-new", InitiatedAt: time.Now()},
+		{Key: live, UploadID: "live", InitiatedAt: time.Now().Add(-2 * time.Hour)},
+		{Key: "backups/db.tar", UploadID: "foreign", InitiatedAt: time.Now().Add(-2 * time.Hour)},
+	}}
+
+	a := newReaperApp(repo, storage)
+	report, err := a.ExpirePending(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, report.ExpiredUploads)
+	assert.Equal(t, 1, report.AbortedUploads)
+	assert.Equal(t, []string{"stray-old"}, storage.aborted)
+}
@@ -0,0 +1,215 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/rendition"
+)
+
+const defaultRenditionMaxSourceBytes = 50 << 20
+
+type GetRenditionResult struct {
+	Blob            *domain.Blob
+	PresignedGetURL string
+	RequiredHeaders map[string]string
+	ExpiresAt       time.Time
+}
+
+// GetRendition returns a download URL for a rendition of a blob the caller
+// references, or ErrRenditionPending until RunRenditions has made it.
+func (a *App) GetRendition(ctx context.Context, caller domain.Caller, id domain.BlobID, spec domain.RenditionSpec, ttl time.Duration) (*GetRenditionResult, error) {
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(a.cfg.Renditions, spec) {
+		return nil, fmt.Errorf("GetRendition: %w: %s is not configured", domain.ErrUnknownRendition, spec)
+	}
+	if ttl <= 0 || ttl > a.cfg.PresignGetMaxTTL {
+		ttl = a.cfg.PresignGetMaxTTL
+	}
+
+	source, err := a.findReadable(ctx, caller, id)
+	if err != nil {
+		return nil, fmt.Errorf("GetRendition: %w", err)
+	}
+	if !rendition.CanRender(source) {
+		return nil, fmt.Errorf("GetRendition: %w: content type %q", domain.ErrNotRenderable, source.ContentType)
+	}
+
+	blob, err := a.repo.FindRendition(ctx, id, spec.String())
+	switch {
+	case errors.Is(err, domain.ErrBlobNotFound):
+		return nil, fmt.Errorf("GetRendition: %w: %s", domain.ErrRenditionPending, spec)
+	case err != nil:
+		return nil, fmt.Errorf("GetRendition: %w", err)
+	case blob.State != domain.StateCommitted:
+		return nil, fmt.Errorf("GetRendition: %w: %s", domain.ErrRenditionPending, spec)
+	}
+
+	objects, err := a.downloadObjects(ctx, blob)
+	if err != nil {
+		return nil, fmt.Errorf("GetRendition: %w", err)
+	}
+	req, err := objects.PresignedGetURL(ctx, blob.R2Key, domain.GetOptions{}, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("GetRendition: %w", err)
+	}
+	return &GetRenditionResult{
+		Blob:            blob,
+		PresignedGetURL: req.URL,
+		RequiredHeaders: req.Headers,
+		ExpiresAt:       req.ExpiresAt,
+	}, nil
+}
+
+// GenerateRenditions renders the configured specs a committed image lacks.
+func (a *App) GenerateRenditions(ctx context.Context, id domain.BlobID) error {
+	if len(a.cfg.Renditions) == 0 {
+		return nil
+	}
+	source, err := a.repo.FindByID(ctx, id)
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("GenerateRenditions: %w", err)
+	}
+	if source.State != domain.StateCommitted || !rendition.CanRender(source) {
+		return nil
+	}
+	derived, err := a.repo.IsRendition(ctx, id)
+	if err != nil {
+		return fmt.Errorf("GenerateRenditions: %w", err)
+	}
+	if derived {
+		return nil
+	}
+
+	for _, spec := range a.cfg.Renditions {
+		existing, err := a.repo.FindRendition(ctx, id, spec.String())
+		if err != nil && !errors.Is(err, domain.ErrBlobNotFound) {
+			return fmt.Errorf("GenerateRenditions: %w", err)
+		}
+		if existing != nil && existing.State == domain.StateCommitted {
+			continue
+		}
+		if _, err := a.renderBlob(ctx, source, spec); err != nil {
+			if errors.Is(err, domain.ErrNotRenderable) {
+				// A corrupt or oversized image fails every spec the same way.
+				slog.Warn("skipping renditions", "blob_id", id, "error", err)
+				return nil
+			}
+			return fmt.Errorf("GenerateRenditions: %w", err)
+		}
+	}
+	return nil
+}
+
+// renditionConsumer names the rendition worker's event cursor.
+const renditionConsumer = "renditions"
+
+// RunRenditions generates renditions as commits appear in the event log,
+// until ctx is done. It resumes after the last commit it handled.
+func (a *App) RunRenditions(ctx context.Context) error {
+	cursor, err := a.repo.FindEventCursor(ctx, renditionConsumer)
+	if err != nil {
+		return fmt.Errorf("RunRenditions: %w", err)
+	}
+	return a.WatchEvents(ctx, cursor, func(e *domain.BlobEvent) error {
+		if e.Kind != domain.EventCommitted {
+			return nil
+		}
+		if err := a.GenerateRenditions(ctx, e.BlobID); err != nil {
+			slog.Error("rendition generation failed", "blob_id", e.BlobID, "error", err)
+		}
+		if err := a.repo.SaveEventCursor(ctx, renditionConsumer, e.Seq); err != nil {
+			return fmt.Errorf("RunRenditions: %w", err)
+		}
+		return nil
+	})
+}
+
+// renderBlob stores source rendered by spec as a blob linked to source. The
+// link is written first, so the commit event is known to be a rendition's.
+func (a *App) renderBlob(ctx context.Context, source *domain.Blob, spec domain.RenditionSpec) (*domain.Blob, error) {
+	maxBytes := a.cfg.RenditionMaxSourceBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultRenditionMaxSourceBytes
+	}
+	if source.SizeBytes > maxBytes {
+		return nil, fmt.Errorf("%w: source is larger than %d bytes", domain.ErrNotRenderable, maxBytes)
+	}
+
+	sourceObjects, err := a.objects(source)
+	if err != nil {
+		return nil, err
+	}
+	body, err := sourceObjects.GetObject(ctx, source.R2Key)
+	if err != nil {
+		return nil, err
+	}
+	src, err := io.ReadAll(io.LimitReader(body, maxBytes))
+	_ = body.Close()
+	if err != nil {
+		return nil, err
+	}
+	out, err := rendition.Render(src, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(out)
+	id := domain.BlobID(hex.EncodeToString(sum[:]))
+	blob, err := a.repo.FindByID(ctx, id)
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		if blob, err = domain.NewBlob(id, int64(len(out)), spec.ContentType(), time.Now().UTC()); err != nil {
+			return nil, err
+		}
+		blob.R2Key = domain.NewObjectKey(id)
+		if source.Encrypted() {
+			if err := a.newDataKey(blob); err != nil {
+				return nil, err
+			}
+		}
+		if err := a.repo.Create(ctx, blob); errors.Is(err, domain.ErrAlreadyCommitted) {
+			// Rendered concurrently by another worker.
+			if blob, err = a.repo.FindByID(ctx, id); err != nil {
+				return nil, err
+			}
+		} else if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := a.repo.LinkRendition(ctx, source.ID, spec.String(), id); err != nil {
+		return nil, err
+	}
+	if blob.State == domain.StateCommitted {
+		return blob, nil
+	}
+
+	objects, err := a.objects(blob)
+	if err != nil {
+		return nil, err
+	}
+	if err := objects.PutObject(ctx, blob.R2Key, bytes.NewReader(out), int64(len(out)), blob.ContentType, string(id)); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if err := a.repo.MarkCommitted(ctx, id, spec.ContentType(), now); err != nil && !errors.Is(err, domain.ErrAlreadyCommitted) {
+		return nil, err
+	}
+	return a.repo.FindByID(ctx, id)
+}
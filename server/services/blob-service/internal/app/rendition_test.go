@@ -0,0 +1,148 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+var (
+	thumbJPEG = domain.RenditionSpec{Format: domain.RenditionJPEG, MaxDimension: 16}
+	thumbWebP = domain.RenditionSpec{Format: domain.RenditionWebP, MaxDimension: 16}
+)
+
+func newRenditionApp(repo *mockRepo, storage *mockStorage) *app.App {
+	cfg := testCfg
+	cfg.Renditions = []domain.RenditionSpec{thumbJPEG, thumbWebP}
+	cfg.EventPollInterval = time.Millisecond
+	return app.New(repo, storage, cfg)
+}
+
+// seedImage stores a committed PNG referenced by testCaller.
+func seedImage(t *testing.T, repo *mockRepo, storage *mockStorage) *domain.Blob {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 64, 32))))
+	id := storage.put(buf.Bytes())
+	blob, err := domain.NewBlob(id, int64(buf.Len()), "image/png", time.Now())
+	require.NoError(t, err)
+	require.NoError(t, blob.Commit(time.Now()))
+	repo.seed(blob)
+	return blob
+}
+
+func TestGenerateRenditions(t *testing.T) {
+	repo, storage := newMockRepo(), &mockStorage{}
+	source := seedImage(t, repo, storage)
+	a := newRenditionApp(repo, storage)
+
+	require.NoError(t, a.GenerateRenditions(context.Background(), source.ID))
+	require.Len(t, repo.links[source.ID], 2)
+	for _, spec := range []domain.RenditionSpec{thumbJPEG, thumbWebP} {
+		r, err := repo.FindRendition(context.Background(), source.ID, spec.String())
+		require.NoError(t, err)
+		assert.Equal(t, domain.StateCommitted, r.State)
+		assert.Equal(t, spec.ContentType(), r.ContentType)
+		assert.Equal(t, r.ID, sha256Hex(storage.objects[r.R2Key]), "renditions are content-addressed")
+		assert.Empty(t, repo.refs[r.ID], "renditions are not charged to anyone")
+
+		require.NoError(t, a.GenerateRenditions(context.Background(), r.ID))
+		assert.Empty(t, repo.links[r.ID], "renditions are not rendered again")
+	}
+}
+
+func TestGenerateRenditions_SkipsNonImages(t *testing.T) {
+	repo, storage := newMockRepo(), &mockStorage{}
+	id := storage.put([]byte("%PDF-1.7"))
+	blob, _ := domain.NewBlob(id, 8, "application/pdf", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+
+	a := newRenditionApp(repo, storage)
+	require.NoError(t, a.GenerateRenditions(context.Background(), id))
+	assert.Empty(t, repo.links)
+}
+
+func TestGenerateRenditions_UsesSniffedType(t *testing.T) {
+	repo, storage := newMockRepo(), &mockStorage{}
+	id := storage.put([]byte("<html><body>not a picture</body></html>"))
+	blob, _ := domain.NewBlob(id, 39, "image/png", time.Now())
+	blob.DetectedContentType = "text/html; charset=utf-8"
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+
+	a := newRenditionApp(repo, storage)
+	require.NoError(t, a.GenerateRenditions(context.Background(), id))
+	assert.Empty(t, repo.links, "the declared type is not trusted")
+	_, err := a.GetRendition(context.Background(), testCaller, id, thumbJPEG, 0)
+	assert.ErrorIs(t, err, domain.ErrNotRenderable)
+}
+
+func TestGetRendition_ServesGenerated(t *testing.T) {
+	repo, storage := newMockRepo(), &mockStorage{getURL: "https://r2.example.com/get"}
+	source := seedImage(t, repo, storage)
+	a := newRenditionApp(repo, storage)
+
+	_, err := a.GetRendition(context.Background(), testCaller, source.ID, thumbWebP, time.Minute)
+	assert.ErrorIs(t, err, domain.ErrRenditionPending)
+	assert.Empty(t, repo.links, "requests do not render")
+
+	require.NoError(t, a.GenerateRenditions(context.Background(), source.ID))
+	result, err := a.GetRendition(context.Background(), testCaller, source.ID, thumbWebP, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "https://r2.example.com/get", result.PresignedGetURL)
+	assert.Equal(t, "image/webp", result.Blob.ContentType)
+	assert.Equal(t, result.Blob.ID, repo.links[source.ID][thumbWebP.String()])
+}
+
+func TestGetRendition_Errors(t *testing.T) {
+	repo, storage := newMockRepo(), &mockStorage{}
+	source := seedImage(t, repo, storage)
+	text := storage.put([]byte("hello"))
+	blob, _ := domain.NewBlob(text, 5, "text/plain", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+	a := newRenditionApp(repo, storage)
+
+	_, err := a.GetRendition(context.Background(), testCaller, source.ID, domain.RenditionSpec{Format: domain.RenditionJPEG, MaxDimension: 17}, 0)
+	assert.ErrorIs(t, err, domain.ErrUnknownRendition)
+	_, err = a.GetRendition(context.Background(), otherCaller, source.ID, thumbJPEG, 0)
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+	_, err = a.GetRendition(context.Background(), testCaller, text, thumbJPEG, 0)
+	assert.ErrorIs(t, err, domain.ErrNotRenderable)
+}
+
+func TestRunRenditions_RendersCommittedImages(t *testing.T) {
+	repo, storage := newMockRepo(), &mockStorage{}
+	source := seedImage(t, repo, storage)
+	repo.recordEvent(domain.EventCommitted, source, time.Now())
+	a := newRenditionApp(repo, storage)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, a.RunRenditions(ctx), context.DeadlineExceeded)
+	assert.Len(t, repo.links[source.ID], 2)
+	assert.Equal(t, repo.events[len(repo.events)-1].Seq, repo.cursors["renditions"])
+}
+
+func TestRunRenditions_ResumesFromCursor(t *testing.T) {
+	repo, storage := newMockRepo(), &mockStorage{}
+	source := seedImage(t, repo, storage)
+	repo.recordEvent(domain.EventCommitted, source, time.Now())
+	repo.cursors["renditions"] = repo.events[len(repo.events)-1].Seq
+	a := newRenditionApp(repo, storage)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, a.RunRenditions(ctx), context.DeadlineExceeded)
+	assert.Empty(t, repo.links[source.ID], "commits before the cursor are not replayed")
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+const (
+	replicateBatchSize             = 100
+	defaultReplicationPollInterval = 30 * time.Second
+)
+
+// downloadObjects returns the secondary store for replicated blobs while the
+// primary is failing, and otherwise the store objects would.
+func (a *App) downloadObjects(ctx context.Context, blob *domain.Blob) (domain.ObjectStorage, error) {
+	objects, err := a.objects(blob)
+	if err != nil {
+		return nil, err
+	}
+	replicas, ok := objects.(domain.ReplicatedStorage)
+	if !ok || blob.ReplicatedAt == nil {
+		return objects, nil
+	}
+	if secondary := replicas.Failover(ctx); secondary != nil {
+		return secondary, nil
+	}
+	return objects, nil
+}
+
+// ReplicateBlob copies a committed blob to the secondary store once.
+func (a *App) ReplicateBlob(ctx context.Context, id domain.BlobID) error {
+	if _, ok := a.storage.(domain.ReplicatedStorage); !ok {
+		return nil
+	}
+	blob, err := a.repo.FindByID(ctx, id)
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ReplicateBlob: %w", err)
+	}
+	if blob.State != domain.StateCommitted || blob.ReplicatedAt != nil {
+		return nil
+	}
+
+	objects, err := a.objects(blob)
+	if err != nil {
+		return fmt.Errorf("ReplicateBlob: %w", err)
+	}
+	replicas := objects.(domain.ReplicatedStorage)
+	if err := replicas.Replicate(ctx, blob.R2Key, blob.SizeBytes, blob.ContentType, string(blob.ID)); err != nil {
+		return fmt.Errorf("ReplicateBlob: %w", err)
+	}
+	if err := a.repo.MarkReplicated(ctx, id, time.Now().UTC()); err != nil {
+		return fmt.Errorf("ReplicateBlob: %w", err)
+	}
+	return nil
+}
+
+// RunReplication replicates committed blobs until ctx is done, retrying
+// failures on the next pass.
+func (a *App) RunReplication(ctx context.Context) error {
+	interval := a.cfg.ReplicationPollInterval
+	if interval <= 0 {
+		interval = defaultReplicationPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var afterID domain.BlobID
+		for {
+			blobs, err := a.repo.ListUnreplicated(ctx, afterID, replicateBatchSize)
+			if err != nil {
+				return fmt.Errorf("RunReplication: %w", err)
+			}
+			for _, b := range blobs {
+				if err := a.ReplicateBlob(ctx, b.ID); err != nil {
+					slog.Error("blob replication failed", "blob_id", b.ID, "error", err)
+				}
+				afterID = b.ID
+			}
+			if len(blobs) < replicateBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
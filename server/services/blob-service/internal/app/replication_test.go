@@ -0,0 +1,123 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+	replicatedstorage "github.com/barn0w1/hss-science/server/services/blob-service/internal/storage/replicated"
+)
+
+// pingingStorage is a primary store whose health check fails while down is
+// set.
+type pingingStorage struct {
+	*mockStorage
+	down bool
+}
+
+func (p *pingingStorage) Ping(context.Context) error {
+	if p.down {
+		return errors.New("primary unreachable")
+	}
+	return nil
+}
+
+func newReplicatedApp(repo domain.BlobRepository, primary *pingingStorage, secondary *mockStorage) *app.App {
+	cfg := testCfg
+	cfg.ReplicationPollInterval = 10 * time.Millisecond
+	return app.New(repo, replicatedstorage.New(primary, secondary, time.Nanosecond), cfg)
+}
+
+func seedCommitted(repo *mockRepo, storage *mockStorage, content []byte) domain.BlobID {
+	id := storage.put(content)
+	blob, _ := domain.NewBlob(id, int64(len(content)), "text/plain", time.Now())
+	blob.State = domain.StateCommitted
+	now := time.Now()
+	blob.CommittedAt = &now
+	repo.seed(blob)
+	return id
+}
+
+func TestReplicateBlob(t *testing.T) {
+	repo := newMockRepo()
+	primary := &pingingStorage{mockStorage: &mockStorage{}}
+	secondary := &mockStorage{}
+	a := newReplicatedApp(repo, primary, secondary)
+	content := []byte("copied to the replica")
+	id := seedCommitted(repo, primary.mockStorage, content)
+
+	require.NoError(t, a.ReplicateBlob(context.Background(), id))
+	assert.Equal(t, content, secondary.objects[string(id)])
+	assert.NotNil(t, repo.blobs[id].ReplicatedAt)
+}
+
+func TestReplicateBlob_SkipsUncommitted(t *testing.T) {
+	repo := newMockRepo()
+	primary := &pingingStorage{mockStorage: &mockStorage{}}
+	secondary := &mockStorage{}
+	a := newReplicatedApp(repo, primary, secondary)
+	id := primary.put([]byte("still uploading"))
+	blob, _ := domain.NewBlob(id, 15, "text/plain", time.Now())
+	blob.State = domain.StateScanning
+	repo.seed(blob)
+
+	require.NoError(t, a.ReplicateBlob(context.Background(), id))
+	assert.Empty(t, secondary.objects)
+	assert.Nil(t, repo.blobs[id].ReplicatedAt)
+}
+
+func TestReplicateBlob_NotReplicatedStorage(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	a := newApp(repo, storage)
+	id := seedCommitted(repo, storage, []byte("single bucket"))
+
+	require.NoError(t, a.ReplicateBlob(context.Background(), id))
+	assert.Nil(t, repo.blobs[id].ReplicatedAt)
+}
+
+func TestRunReplication(t *testing.T) {
+	repo := newMockRepo()
+	primary := &pingingStorage{mockStorage: &mockStorage{}}
+	secondary := &mockStorage{}
+	a := newReplicatedApp(repo, primary, secondary)
+	first := seedCommitted(repo, primary.mockStorage, []byte("first"))
+	second := seedCommitted(repo, primary.mockStorage, []byte("second"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, a.RunReplication(ctx), context.DeadlineExceeded)
+
+	assert.NotNil(t, repo.blobs[first].ReplicatedAt)
+	assert.NotNil(t, repo.blobs[second].ReplicatedAt)
+	assert.Len(t, secondary.objects, 2)
+}
+
+func TestGetDownloadURL_FailsOverToReplica(t *testing.T) {
+	repo := newMockRepo()
+	primary := &pingingStorage{mockStorage: &mockStorage{getURL: "https://primary.example.com/get"}}
+	secondary := &mockStorage{getURL: "https://replica.example.com/get"}
+	a := newReplicatedApp(repo, primary, secondary)
+	replicated := seedCommitted(repo, primary.mockStorage, []byte("on both buckets"))
+	require.NoError(t, a.ReplicateBlob(context.Background(), replicated))
+	unreplicated := seedCommitted(repo, primary.mockStorage, []byte("on the primary only"))
+
+	dl, err := a.GetDownloadURL(context.Background(), testCaller, replicated, time.Minute, app.DownloadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://primary.example.com/get", dl.PresignedGetURL)
+
+	primary.down = true
+	dl, err = a.GetDownloadURL(context.Background(), testCaller, replicated, time.Minute, app.DownloadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://replica.example.com/get", dl.PresignedGetURL)
+
+	dl, err = a.GetDownloadURL(context.Background(), testCaller, unreplicated, time.Minute, app.DownloadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://primary.example.com/get", dl.PresignedGetURL, "a blob not yet copied has no replica to fail over to")
+}
@@ -0,0 +1,362 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+// ResumableUpload is the progress of an upload whose bytes are sent through
+// the service in order. Offset is the number of bytes received so far.
+type ResumableUpload struct {
+	BlobID    domain.BlobID
+	SizeBytes int64
+	Offset    int64
+	State     domain.UploadState
+}
+
+const stagedListBatchSize = 100
+
+// Bytes too few for a part of a resumable upload are kept in staging objects
+// until enough arrive, each chunk under a number of its own.
+func stagedPartKey(key string, n int32) string {
+	return fmt.Sprintf("%s_part%d", key, n)
+}
+
+// CreateResumableUpload starts, or picks up, the caller's resumable upload of
+// the blob.
+func (a *App) CreateResumableUpload(ctx context.Context, caller domain.Caller, id domain.BlobID, sizeBytes int64, contentType string, encrypt bool) (*ResumableUpload, error) {
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := domain.ResumablePartSize(sizeBytes); err != nil {
+		return nil, fmt.Errorf("CreateResumableUpload: %w", err)
+	}
+
+	held, err := a.heldBlob(ctx, caller, id)
+	if err != nil {
+		return nil, fmt.Errorf("CreateResumableUpload: %w", err)
+	}
+	if held != nil {
+		return receivedAll(held), nil
+	}
+	upload, err := a.openUpload(ctx, caller, id, sizeBytes, contentType, encrypt)
+	if err != nil {
+		return nil, fmt.Errorf("CreateResumableUpload: %w", err)
+	}
+	if sizeBytes == 0 {
+		progress, err := a.completeEmpty(ctx, caller, upload)
+		if err != nil {
+			return nil, fmt.Errorf("CreateResumableUpload: %w", err)
+		}
+		return progress, nil
+	}
+
+	session, err := a.openSession(ctx, upload, domain.MaxPartCount, true)
+	if err != nil {
+		return nil, fmt.Errorf("CreateResumableUpload: %w", err)
+	}
+	return resumableProgress(upload.Blob(), session), nil
+}
+
+// GetResumableUpload reports how much of the caller's resumable upload has
+// been received. Blobs the caller already holds report every byte received.
+func (a *App) GetResumableUpload(ctx context.Context, caller domain.Caller, id domain.BlobID) (*ResumableUpload, error) {
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+	upload, held, err := a.findUploadOrBlob(ctx, caller, id)
+	if err != nil {
+		return nil, fmt.Errorf("GetResumableUpload: %w", err)
+	}
+	if held != nil {
+		return receivedAll(held), nil
+	}
+	session, err := a.resumableSession(ctx, id, caller.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("GetResumableUpload: %w", err)
+	}
+	progress := resumableProgress(upload.Blob(), session)
+	if progress.Offset < progress.SizeBytes {
+		return progress, nil
+	}
+	// Every byte arrived but completing the upload failed; a client told
+	// so would never send another chunk, so it is completed here instead.
+	if progress, err = a.completeResumable(ctx, caller, upload, session); err != nil {
+		return nil, fmt.Errorf("GetResumableUpload: %w", err)
+	}
+	return progress, nil
+}
+
+func receivedAll(blob *domain.Blob) *ResumableUpload {
+	return &ResumableUpload{BlobID: blob.ID, SizeBytes: blob.SizeBytes, Offset: blob.SizeBytes, State: blob.State}
+}
+
+func resumableProgress(blob *domain.Blob, session *domain.MultipartSession) *ResumableUpload {
+	return &ResumableUpload{BlobID: blob.ID, SizeBytes: blob.SizeBytes, Offset: session.ReceivedBytes, State: blob.State}
+}
+
+// resumableSession loads the subject's resumable session of the blob. A
+// session opened for presigned parts is reported as ErrSessionNotFound.
+func (a *App) resumableSession(ctx context.Context, id domain.BlobID, subject string) (*domain.MultipartSession, error) {
+	session, err := a.repo.FindMultipartUpload(ctx, id, subject)
+	if err != nil {
+		return nil, err
+	}
+	if !session.Resumable {
+		return nil, fmt.Errorf("%w: the upload was started for presigned parts", domain.ErrSessionNotFound)
+	}
+	return session, nil
+}
+
+// AppendResumableUpload appends chunk at offset, taking only as many bytes as
+// fit in one part; the returned Offset says where to go on from.
+func (a *App) AppendResumableUpload(ctx context.Context, caller domain.Caller, id domain.BlobID, offset int64, chunk io.ReaderAt, size int64) (*ResumableUpload, error) {
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+	upload, err := a.findUpload(ctx, caller, id)
+	if err != nil {
+		return nil, fmt.Errorf("AppendResumableUpload: %w", err)
+	}
+	blob := upload.Blob()
+	session, err := a.resumableSession(ctx, id, caller.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("AppendResumableUpload: %w", err)
+	}
+	if offset != session.ReceivedBytes {
+		return nil, fmt.Errorf("AppendResumableUpload: %w: received %d bytes, chunk starts at %d", domain.ErrOffsetMismatch, session.ReceivedBytes, offset)
+	}
+	if offset+size > blob.SizeBytes {
+		return nil, fmt.Errorf("AppendResumableUpload: %w: chunk ends past the declared %d bytes", domain.ErrContentMismatch, blob.SizeBytes)
+	}
+	size = min(size, domain.MaxPartSize-(offset-session.StagedOffset))
+
+	combined, err := a.appendChunk(ctx, blob, session, chunk, size)
+	if err != nil {
+		return nil, fmt.Errorf("AppendResumableUpload: %w", err)
+	}
+	if err := a.repo.AdvanceResumableUpload(ctx, session, offset); err != nil {
+		return nil, fmt.Errorf("AppendResumableUpload: %w", err)
+	}
+	for _, n := range combined {
+		if err := a.storage.DeleteObject(ctx, stagedPartKey(blob.R2Key, n)); err != nil {
+			slog.Error("failed to delete staged part", "blob_id", id, "error", err)
+		}
+	}
+	progress := resumableProgress(blob, session)
+	if progress.Offset < blob.SizeBytes {
+		return progress, nil
+	}
+	if progress, err = a.completeResumable(ctx, caller, upload, session); err != nil {
+		return nil, fmt.Errorf("AppendResumableUpload: %w", err)
+	}
+	return progress, nil
+}
+
+// completeResumable completes a session that has received every byte.
+func (a *App) completeResumable(ctx context.Context, caller domain.Caller, upload *domain.Upload, session *domain.MultipartSession) (*ResumableUpload, error) {
+	blob := upload.Blob()
+	parts, err := a.resumableParts(ctx, blob, session)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := a.completeMultipart(ctx, caller, upload, session.UploadID, parts)
+	if err != nil {
+		return nil, err
+	}
+	a.dropStagedParts(ctx, upload.ObjectKey)
+	progress := resumableProgress(blob, session)
+	progress.State = stored.State
+	return progress, nil
+}
+
+// appendChunk stages chunk or uploads it, with the bytes staged before it, as
+// the next part, returning the staging objects it used up. Parts and staging
+// objects are claimed under fresh numbers, so a concurrent chunk for the same
+// offset cannot overwrite them.
+func (a *App) appendChunk(ctx context.Context, blob *domain.Blob, session *domain.MultipartSession, chunk io.ReaderAt, size int64) ([]int32, error) {
+	objects, err := a.objects(blob)
+	if err != nil {
+		return nil, err
+	}
+	partSize, err := domain.ResumablePartSize(blob.SizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	offset := session.ReceivedBytes
+	staged := offset - session.StagedOffset
+	section := io.NewSectionReader(chunk, 0, size)
+	session.ReceivedBytes += size
+
+	if staged+size < partSize && session.ReceivedBytes < blob.SizeBytes {
+		n, err := a.repo.ClaimResumableNumber(ctx, session.UploadID, offset, false)
+		if err != nil {
+			return nil, err
+		}
+		if err := stageChunk(ctx, objects, stagedPartKey(blob.R2Key, n), section); err != nil {
+			return nil, err
+		}
+		session.Staged = append(session.Staged, n)
+		return nil, nil
+	}
+
+	n, err := a.repo.ClaimResumableNumber(ctx, session.UploadID, offset, true)
+	if err != nil {
+		return nil, err
+	}
+	if n > session.PartCount {
+		return nil, fmt.Errorf("%w: the upload needs more than %d parts", domain.ErrInvalidPartCount, session.PartCount)
+	}
+	body := &stagedReader{ctx: ctx, objects: objects, key: blob.R2Key, staged: session.Staged}
+	_, err = objects.UploadPart(ctx, blob.R2Key, session.UploadID, n, io.MultiReader(body, section), staged+size)
+	_ = body.Close()
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		// The upload is gone from storage; forget it so the upload can be
+		// created again.
+		if err := a.repo.DeleteMultipartUpload(ctx, session.UploadID); err != nil {
+			slog.Error("failed to delete stale multipart session", "blob_id", blob.ID, "error", err)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("part %d: %w", n, err)
+	}
+	combined := session.Staged
+	session.Parts = append(session.Parts, n)
+	session.Staged = nil
+	session.StagedOffset = session.ReceivedBytes
+	return combined, nil
+}
+
+// stageChunk stores chunk under key. The chunk is read twice, since the
+// store needs its checksum up front.
+func stageChunk(ctx context.Context, objects domain.ObjectStorage, key string, chunk *io.SectionReader) error {
+	h := sha256.New()
+	if _, err := io.Copy(h, chunk); err != nil {
+		return fmt.Errorf("hash staged part: %w", err)
+	}
+	if _, err := chunk.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return objects.PutObject(ctx, key, chunk, chunk.Size(), "application/octet-stream", hex.EncodeToString(h.Sum(nil)))
+}
+
+// stagedReader reads the staging objects of a resumable upload one after
+// another, opening each only once the one before it is used up.
+type stagedReader struct {
+	ctx     context.Context
+	objects domain.ObjectStorage
+	key     string
+	staged  []int32
+	cur     io.ReadCloser
+}
+
+func (r *stagedReader) Read(p []byte) (int, error) {
+	for {
+		if r.cur == nil {
+			if len(r.staged) == 0 {
+				return 0, io.EOF
+			}
+			body, err := r.objects.GetObject(r.ctx, stagedPartKey(r.key, r.staged[0]))
+			if err != nil {
+				return 0, err
+			}
+			r.cur, r.staged = body, r.staged[1:]
+		}
+		n, err := r.cur.Read(p)
+		if err == io.EOF {
+			_ = r.cur.Close()
+			r.cur = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *stagedReader) Close() error {
+	if r.cur == nil {
+		return nil
+	}
+	return r.cur.Close()
+}
+
+// resumableParts lists the session's parts for completing it. Parts
+// uploaded for chunks that lost a race to a concurrent one are left out.
+func (a *App) resumableParts(ctx context.Context, blob *domain.Blob, session *domain.MultipartSession) ([]domain.CompletedPart, error) {
+	uploaded, err := a.storage.ListParts(ctx, blob.R2Key, session.UploadID)
+	if err != nil {
+		return nil, err
+	}
+	etags := make(map[int32]string, len(uploaded))
+	for _, p := range uploaded {
+		etags[p.PartNumber] = p.ETag
+	}
+	parts := make([]domain.CompletedPart, len(session.Parts))
+	for i, n := range session.Parts {
+		etag, ok := etags[n]
+		if !ok {
+			return nil, fmt.Errorf("%w: part %d is missing from storage", domain.ErrObjectNotFound, n)
+		}
+		parts[i] = domain.CompletedPart{PartNumber: n, ETag: etag}
+	}
+	return parts, nil
+}
+
+// completeEmpty stores and commits the object of an empty upload, which has
+// no parts to upload.
+func (a *App) completeEmpty(ctx context.Context, caller domain.Caller, upload *domain.Upload) (*ResumableUpload, error) {
+	sum := sha256.Sum256(nil)
+	if string(upload.BlobID) != hex.EncodeToString(sum[:]) {
+		return nil, fmt.Errorf("%w: empty content hashes to %x", domain.ErrContentMismatch, sum)
+	}
+	objects, err := a.objects(upload.Blob())
+	if err != nil {
+		return nil, err
+	}
+	if err := objects.PutObject(ctx, upload.ObjectKey, bytes.NewReader(nil), 0, upload.ContentType, string(upload.BlobID)); err != nil {
+		return nil, err
+	}
+	blob, err := a.completeObject(ctx, caller, upload)
+	if err != nil {
+		return nil, err
+	}
+	return &ResumableUpload{BlobID: upload.BlobID, State: blob.State}, nil
+}
+
+// dropStagedParts deletes any staging objects left for a resumable upload
+// to key. They sort directly after their common prefix, so the listing stops
+// at the first key without it.
+func (a *App) dropStagedParts(ctx context.Context, key string) {
+	prefix := key + "_part"
+	afterKey := prefix
+	for {
+		objects, err := a.storage.ListObjects(ctx, afterKey, stagedListBatchSize)
+		if err != nil {
+			slog.Error("failed to list staged parts", "key", key, "error", err)
+			return
+		}
+		for _, o := range objects {
+			if !strings.HasPrefix(o.Key, prefix) {
+				return
+			}
+			afterKey = o.Key
+			if err := a.storage.DeleteObject(ctx, o.Key); err != nil {
+				slog.Error("failed to delete staged part", "key", o.Key, "error", err)
+			}
+		}
+		if len(objects) < stagedListBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+// resumableContent is large enough for several parts of the 5 MiB minimum.
+func resumableContent() ([]byte, domain.BlobID) {
+	content := make([]byte, 20<<20)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+	sum := sha256.Sum256(content)
+	return content, domain.BlobID(hex.EncodeToString(sum[:]))
+}
+
+func TestResumableUpload_ChunksAcrossParts(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{uploadID: "mpu-1"}
+	a := newApp(repo, storage)
+	ctx := context.Background()
+	content, id := resumableContent()
+
+	upload, err := a.CreateResumableUpload(ctx, testCaller, id, int64(len(content)), "video/mp4", false)
+	require.NoError(t, err)
+	assert.Zero(t, upload.Offset)
+	assert.True(t, repo.sessions[uploadKey{id, testCaller.Subject}].Resumable)
+	key := repo.uploads[uploadKey{id, testCaller.Subject}].ObjectKey
+
+	// 3 MiB and then 1 MiB are too few for a part, so each is staged.
+	upload, err = a.AppendResumableUpload(ctx, testCaller, id, 0, bytes.NewReader(content[:3<<20]), 3<<20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3<<20), upload.Offset)
+	assert.Len(t, storage.objects[key+"_part1"], 3<<20)
+
+	_, err = a.AppendResumableUpload(ctx, testCaller, id, 0, bytes.NewReader(content[:1]), 1)
+	assert.ErrorIs(t, err, domain.ErrOffsetMismatch)
+
+	_, err = a.AppendResumableUpload(ctx, testCaller, id, 3<<20, bytes.NewReader(content[3<<20:4<<20]), 1<<20)
+	require.NoError(t, err)
+	assert.Len(t, storage.objects[key+"_part2"], 1<<20)
+	assert.Empty(t, storage.parts["mpu-1"])
+
+	// 8 MiB is uploaded as part 1 together with the 4 MiB staged.
+	upload, err = a.AppendResumableUpload(ctx, testCaller, id, 4<<20, bytes.NewReader(content[4<<20:12<<20]), 8<<20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12<<20), upload.Offset)
+	assert.NotContains(t, storage.objects, key+"_part1")
+	assert.NotContains(t, storage.objects, key+"_part2")
+	require.Len(t, storage.parts["mpu-1"], 1)
+	assert.Equal(t, int64(12<<20), storage.parts["mpu-1"][0].SizeBytes)
+
+	got, err := a.GetResumableUpload(ctx, testCaller, id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12<<20), got.Offset)
+
+	// 6 MiB is a part of its own, and the last 2 MiB completes the upload.
+	_, err = a.AppendResumableUpload(ctx, testCaller, id, 12<<20, bytes.NewReader(content[12<<20:18<<20]), 6<<20)
+	require.NoError(t, err)
+	require.Len(t, storage.parts["mpu-1"], 2)
+	upload, err = a.AppendResumableUpload(ctx, testCaller, id, 18<<20, bytes.NewReader(content[18<<20:]), 2<<20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), upload.Offset)
+	assert.Equal(t, domain.StateCommitted, upload.State)
+	assert.Equal(t, domain.StateCommitted, repo.blobs[id].State)
+	assert.Equal(t, key, repo.blobs[id].R2Key)
+	assert.Equal(t, content, storage.objects[key])
+	assert.Empty(t, repo.sessions)
+
+	got, err = a.GetResumableUpload(ctx, testCaller, id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), got.Offset, "a committed blob reports every byte received")
+}
+
+// staleSessionRepo hands out the session as it was when stale was taken, as
+// a replica that loaded it before another one appended a chunk would see it.
+type staleSessionRepo struct {
+	*mockRepo
+	stale *domain.MultipartSession
+}
+
+func (r *staleSessionRepo) FindMultipartUpload(context.Context, domain.BlobID, string) (*domain.MultipartSession, error) {
+	cp := *r.stale
+	return &cp, nil
+}
+
+func TestResumableUpload_ConcurrentChunkLoses(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{uploadID: "mpu-1"}
+	a := newApp(repo, storage)
+	ctx := context.Background()
+	content, id := resumableContent()
+
+	_, err := a.CreateResumableUpload(ctx, testCaller, id, int64(len(content)), "video/mp4", false)
+	require.NoError(t, err)
+	stale, err := repo.FindMultipartUpload(ctx, id, testCaller.Subject)
+	require.NoError(t, err)
+
+	_, err = a.AppendResumableUpload(ctx, testCaller, id, 0, bytes.NewReader(content[:6<<20]), 6<<20)
+	require.NoError(t, err)
+
+	other := newApp(&staleSessionRepo{mockRepo: repo, stale: stale}, storage)
+	_, err = other.AppendResumableUpload(ctx, testCaller, id, 0, bytes.NewReader(content[:6<<20]), 6<<20)
+	assert.ErrorIs(t, err, domain.ErrOffsetMismatch)
+	assert.Len(t, storage.parts["mpu-1"], 1, "the losing chunk claims no part number")
+
+	upload, err := a.AppendResumableUpload(ctx, testCaller, id, 6<<20, bytes.NewReader(content[6<<20:]), int64(len(content))-6<<20)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateCommitted, upload.State)
+	assert.Equal(t, content, storage.objects[repo.blobs[id].R2Key])
+}
+
+func TestResumableUpload_RetriesFailedCompletion(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{uploadID: "mpu-1"}
+	a := newApp(repo, storage)
+	ctx := context.Background()
+	content, id := resumableContent()
+
+	_, err := a.CreateResumableUpload(ctx, testCaller, id, int64(len(content)), "video/mp4", false)
+	require.NoError(t, err)
+	_, err = a.AppendResumableUpload(ctx, testCaller, id, 0, bytes.NewReader(content[:12<<20]), 12<<20)
+	require.NoError(t, err)
+
+	storage.completeErr = assert.AnError
+	_, err = a.AppendResumableUpload(ctx, testCaller, id, 12<<20, bytes.NewReader(content[12<<20:]), 8<<20)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, domain.StatePending, repo.uploads[uploadKey{id, testCaller.Subject}].Blob().State)
+
+	_, err = a.GetResumableUpload(ctx, testCaller, id)
+	assert.ErrorIs(t, err, assert.AnError, "a check-in after every byte arrived retries completion")
+
+	storage.completeErr = nil
+	upload, err := a.GetResumableUpload(ctx, testCaller, id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), upload.Offset)
+	assert.Equal(t, domain.StateCommitted, upload.State)
+	assert.Equal(t, content, storage.objects[repo.blobs[id].R2Key])
+	assert.Empty(t, repo.sessions)
+}
+
+func TestResumableUpload_ContentMismatch(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{uploadID: "mpu-1"}
+	a := newApp(repo, storage)
+	ctx := context.Background()
+	content := []byte("not what the blob ID says")
+
+	_, err := a.CreateResumableUpload(ctx, testCaller, domain.BlobID(validID), int64(len(content)), "text/plain", false)
+	require.NoError(t, err)
+	key := repo.uploads[uploadKey{domain.BlobID(validID), testCaller.Subject}].ObjectKey
+	_, err = a.AppendResumableUpload(ctx, testCaller, domain.BlobID(validID), 0, bytes.NewReader(content), int64(len(content))+1)
+	assert.ErrorIs(t, err, domain.ErrContentMismatch, "a chunk past the declared size is rejected")
+
+	_, err = a.AppendResumableUpload(ctx, testCaller, domain.BlobID(validID), 0, bytes.NewReader(content), int64(len(content)))
+	assert.ErrorIs(t, err, domain.ErrContentMismatch)
+	assert.Contains(t, repo.uploads, uploadKey{domain.BlobID(validID), testCaller.Subject})
+	assert.NotContains(t, storage.objects, key)
+}
+
+func TestResumableUpload_Encrypted(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{uploadID: "mpu-1"}
+	a := newEncryptingApp(t, repo, storage, masterKeyA)
+	ctx := context.Background()
+	content := []byte("sealed at rest, staged bytes included")
+	id := domain.BlobID(sha256Hex(content))
+
+	_, err := a.CreateResumableUpload(ctx, testCaller, id, int64(len(content)), "text/plain", true)
+	require.NoError(t, err)
+	key := repo.uploads[uploadKey{id, testCaller.Subject}].ObjectKey
+	_, err = a.AppendResumableUpload(ctx, testCaller, id, 0, bytes.NewReader(content[:10]), 10)
+	require.NoError(t, err)
+	assert.NotNil(t, storage.sealed[key+"_part1"])
+
+	upload, err := a.AppendResumableUpload(ctx, testCaller, id, 10, bytes.NewReader(content[10:]), int64(len(content)-10))
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateCommitted, upload.State)
+	assert.NotNil(t, storage.sealed[key])
+}
+
+func TestCreateResumableUpload_Empty(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	a := newApp(repo, storage)
+	id := domain.BlobID(sha256Hex(nil))
+
+	upload, err := a.CreateResumableUpload(context.Background(), testCaller, id, 0, "text/plain", false)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateCommitted, upload.State)
+	assert.Contains(t, storage.objects, repo.blobs[id].R2Key)
+	assert.Empty(t, repo.sessions)
+}
+
+func TestExpirePending_DropsStagedParts(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{uploadID: "mpu-1"}
+	a := newReaperApp(repo, storage)
+	ctx := context.Background()
+	content := []byte("abandoned halfway")
+	id := domain.BlobID(sha256Hex(content))
+
+	_, err := a.CreateResumableUpload(ctx, testCaller, id, int64(len(content)), "text/plain", false)
+	require.NoError(t, err)
+	_, err = a.AppendResumableUpload(ctx, testCaller, id, 0, bytes.NewReader(content[:8]), 8)
+	require.NoError(t, err)
+	repo.uploads[uploadKey{id, testCaller.Subject}].CreatedAt = time.Now().Add(-2 * time.Hour)
+
+	report, err := a.ExpirePending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.ExpiredUploads)
+	assert.Empty(t, storage.objects)
+}
+
+func TestAbortMultipartUpload_DropsEveryStagedPart(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{uploadID: "mpu-1"}
+	a := newApp(repo, storage)
+	ctx := context.Background()
+	content, id := resumableContent()
+
+	_, err := a.CreateResumableUpload(ctx, testCaller, id, int64(len(content)), "video/mp4", false)
+	require.NoError(t, err)
+	key := repo.uploads[uploadKey{id, testCaller.Subject}].ObjectKey
+	if storage.objects == nil {
+		storage.objects = make(map[string][]byte)
+	}
+	for n := 1; n <= 250; n++ {
+		storage.objects[fmt.Sprintf("%s_part%d", key, n)] = []byte{1}
+	}
+	storage.objects[key+"~other"] = []byte{1}
+
+	require.NoError(t, a.AbortMultipartUpload(ctx, testCaller, id, "mpu-1"))
+	assert.Equal(t, []string{key + "~other"}, slices.Collect(maps.Keys(storage.objects)), "staged parts are deleted past the first page")
+}
@@ -0,0 +1,107 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+var recordsCaller = domain.Caller{Subject: "records-officer", ClientID: "records-admin"}
+
+func newRetentionApp(repo domain.BlobRepository, storage domain.ObjectStorage) *app.App {
+	cfg := testCfg
+	cfg.GCGracePeriod = time.Hour
+	cfg.PendingTTL = time.Hour
+	cfg.RetentionClients = []string{"records-admin"}
+	return app.New(repo, storage, cfg)
+}
+
+func TestSetRetention_RequiresRetentionClient(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+	a := newRetentionApp(repo, &mockStorage{})
+	until := time.Now().Add(time.Hour)
+
+	// Holding a reference does not make the caller a retention client.
+	err := a.SetRetention(context.Background(), testCaller, blob.ID, until)
+	assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+	err = a.SetLegalHold(context.Background(), domain.Caller{Subject: "x", ClientID: "drive"}, blob.ID, true, "")
+	assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+	assert.Nil(t, repo.blobs[blob.ID].RetainUntil)
+	assert.False(t, repo.blobs[blob.ID].LegalHold)
+	assert.Empty(t, repo.audit)
+}
+
+func TestSetRetention_ExtendOnly(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+	a := newRetentionApp(repo, &mockStorage{})
+	ctx := context.Background()
+	until := time.Now().Add(24 * time.Hour)
+
+	require.NoError(t, a.SetRetention(ctx, recordsCaller, blob.ID, until))
+	require.NoError(t, a.SetRetention(ctx, recordsCaller, blob.ID, until.Add(time.Hour)))
+	assert.True(t, until.Add(time.Hour).Equal(*repo.blobs[blob.ID].RetainUntil))
+
+	err := a.SetRetention(ctx, recordsCaller, blob.ID, until)
+	assert.ErrorIs(t, err, domain.ErrRetentionShortened)
+	err = a.SetRetention(ctx, recordsCaller, blob.ID, time.Now().Add(-time.Minute))
+	assert.ErrorIs(t, err, domain.ErrInvalidRetention)
+	err = a.SetRetention(ctx, recordsCaller, "invalid", until)
+	assert.ErrorIs(t, err, domain.ErrInvalidBlobID)
+	assert.Equal(t, []domain.AuditAction{domain.AuditRetentionSet, domain.AuditRetentionSet}, repo.audit)
+}
+
+func TestCollectGarbage_SkipsHeldBlobs(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	retained := seedReleased(t, repo, storage, "retained", 2*time.Hour)
+	onHold := seedReleased(t, repo, storage, "on hold", 2*time.Hour)
+	a := newRetentionApp(repo, storage)
+	ctx := context.Background()
+
+	require.NoError(t, a.SetRetention(ctx, recordsCaller, retained, time.Now().Add(time.Hour)))
+	require.NoError(t, a.SetLegalHold(ctx, recordsCaller, onHold, true, "litigation"))
+
+	report, err := a.CollectGarbage(ctx, false)
+	require.NoError(t, err)
+	assert.Empty(t, report.BlobIDs)
+	assert.Empty(t, storage.deleted)
+
+	require.NoError(t, a.SetLegalHold(ctx, recordsCaller, onHold, false, "case closed"))
+	report, err = a.CollectGarbage(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.BlobID{onHold}, report.BlobIDs)
+	assert.Contains(t, repo.blobs, retained)
+	assert.Equal(t, []domain.AuditAction{
+		domain.AuditRetentionSet, domain.AuditLegalHoldSet, domain.AuditLegalHoldReleased,
+	}, repo.audit)
+}
+
+func TestExpirePending_LeavesHeldBlobs(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	held, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now().Add(-2*time.Hour))
+	_ = held.Commit(time.Now())
+	repo.seed(held)
+	stale := &domain.Upload{BlobID: held.ID, Subject: otherCaller.Subject, ObjectKey: domain.NewObjectKey(held.ID), SizeBytes: 1024, CreatedAt: time.Now().Add(-2 * time.Hour)}
+	require.NoError(t, repo.CreateUpload(context.Background(), stale))
+	a := newRetentionApp(repo, storage)
+
+	require.NoError(t, a.SetLegalHold(context.Background(), recordsCaller, held.ID, true, ""))
+	report, err := a.ExpirePending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.ExpiredUploads)
+	assert.Contains(t, repo.blobs, held.ID)
+	assert.Equal(t, []string{stale.ObjectKey}, storage.deleted, "only the stale upload's object is deleted")
+}
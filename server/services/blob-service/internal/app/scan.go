@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+const (
+	scanBatchSize           = 100
+	defaultScanPollInterval = 5 * time.Second
+)
+
+// ScanBlob commits or quarantines a SCANNING blob. A scanner error leaves it
+// SCANNING so it is retried.
+func (a *App) ScanBlob(ctx context.Context, id domain.BlobID) error {
+	if a.cfg.Scanner == nil {
+		return nil
+	}
+	blob, err := a.repo.FindByID(ctx, id)
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ScanBlob: %w", err)
+	}
+	if blob.State != domain.StateScanning {
+		return nil
+	}
+
+	objects, err := a.objects(blob)
+	if err != nil {
+		return fmt.Errorf("ScanBlob: %w", err)
+	}
+	body, err := objects.GetObject(ctx, blob.R2Key)
+	if err != nil {
+		return fmt.Errorf("ScanBlob: %w", err)
+	}
+	result, err := a.cfg.Scanner.Scan(ctx, body)
+	_ = body.Close()
+	if err != nil {
+		return fmt.Errorf("ScanBlob: %w", err)
+	}
+
+	if result.Clean {
+		err = a.repo.MarkCommitted(ctx, id, blob.DetectedContentType, time.Now().UTC())
+	} else {
+		slog.Warn("quarantining blob", "blob_id", id, "signature", result.Signature)
+		err = a.repo.MarkQuarantined(ctx, id, result.Signature)
+	}
+	if err != nil && !errors.Is(err, domain.ErrAlreadyCommitted) {
+		return fmt.Errorf("ScanBlob: %w", err)
+	}
+	return nil
+}
+
+// RunScanner scans SCANNING blobs until ctx is done, retrying failures on the
+// next pass.
+func (a *App) RunScanner(ctx context.Context) error {
+	interval := a.cfg.ScanPollInterval
+	if interval <= 0 {
+		interval = defaultScanPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var afterID domain.BlobID
+		for {
+			blobs, err := a.repo.ListScanning(ctx, afterID, scanBatchSize)
+			if err != nil {
+				return fmt.Errorf("RunScanner: %w", err)
+			}
+			for _, b := range blobs {
+				if err := a.ScanBlob(ctx, b.ID); err != nil {
+					slog.Error("blob scan failed", "blob_id", b.ID, "error", err)
+				}
+				afterID = b.ID
+			}
+			if len(blobs) < scanBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
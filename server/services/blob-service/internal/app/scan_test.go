@@ -0,0 +1,132 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+func newScanApp(repo domain.BlobRepository, storage domain.ObjectStorage, scanner domain.Scanner) *app.App {
+	cfg := testCfg
+	cfg.Scanner = scanner
+	cfg.ScanPollInterval = 10 * time.Millisecond
+	return app.New(repo, storage, cfg)
+}
+
+func uploadForScan(t *testing.T, a *app.App, repo *mockRepo, storage *mockStorage, content []byte) domain.BlobID {
+	t.Helper()
+	id := storage.put(content)
+	blob, _ := domain.NewBlob(id, int64(len(content)), "text/plain", time.Now())
+	repo.seed(blob)
+
+	result, err := a.CompleteUpload(context.Background(), testCaller, id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateScanning, result.State)
+	assert.True(t, result.CommittedAt.IsZero())
+	return id
+}
+
+func TestScanBlob_Clean(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	a := newScanApp(repo, storage, &mockScanner{})
+	id := uploadForScan(t, a, repo, storage, []byte("meeting notes"))
+
+	_, err := a.GetDownloadURL(context.Background(), testCaller, id, time.Minute, app.DownloadOptions{})
+	assert.ErrorIs(t, err, domain.ErrBlobScanning)
+	assert.Empty(t, repo.events, "nothing is announced before the scan")
+
+	require.NoError(t, a.ScanBlob(context.Background(), id))
+	assert.Equal(t, domain.StateCommitted, repo.blobs[id].State)
+	assert.Equal(t, "text/plain; charset=utf-8", repo.blobs[id].DetectedContentType)
+	require.Len(t, repo.events, 1)
+	assert.Equal(t, domain.EventCommitted, repo.events[0].Kind)
+
+	_, err = a.GetDownloadURL(context.Background(), testCaller, id, time.Minute, app.DownloadOptions{})
+	assert.NoError(t, err)
+}
+
+func TestScanBlob_Infected(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	a := newScanApp(repo, storage, &mockScanner{})
+	id := uploadForScan(t, a, repo, storage, []byte("X5O!P%@AP EICAR"))
+
+	require.NoError(t, a.ScanBlob(context.Background(), id))
+	assert.Equal(t, domain.StateQuarantined, repo.blobs[id].State)
+	assert.Equal(t, "Eicar-Test-Signature", repo.blobs[id].QuarantineReason)
+
+	_, err := a.GetDownloadURL(context.Background(), testCaller, id, time.Minute, app.DownloadOptions{})
+	assert.ErrorIs(t, err, domain.ErrBlobQuarantined)
+	_, err = a.DownloadStream(context.Background(), testCaller, id, 0, 0)
+	assert.ErrorIs(t, err, domain.ErrBlobQuarantined)
+	_, err = a.InitiateUpload(context.Background(), testCaller, id, repo.blobs[id].SizeBytes, "text/plain", false)
+	assert.ErrorIs(t, err, domain.ErrBlobQuarantined)
+	result, err := a.InitiateUpload(context.Background(), otherCaller, id, repo.blobs[id].SizeBytes, "text/plain", false)
+	require.NoError(t, err)
+	assert.False(t, result.AlreadyExists, "quarantined content cannot be claimed by hash")
+	_, err = a.CompleteUpload(context.Background(), testCaller, id)
+	assert.ErrorIs(t, err, domain.ErrBlobQuarantined)
+}
+
+func TestScanBlob_ScannerErrorLeavesBlobScanning(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	a := newScanApp(repo, storage, &mockScanner{err: errors.New("clamd: dial: connection refused")})
+	id := uploadForScan(t, a, repo, storage, []byte("meeting notes"))
+
+	assert.Error(t, a.ScanBlob(context.Background(), id))
+	assert.Equal(t, domain.StateScanning, repo.blobs[id].State)
+}
+
+func TestInitiateUpload_ScanningBlobAlreadyExists(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	a := newScanApp(repo, storage, &mockScanner{})
+	id := uploadForScan(t, a, repo, storage, []byte("meeting notes"))
+
+	result, err := a.InitiateUpload(context.Background(), testCaller, id, repo.blobs[id].SizeBytes, "text/plain", false)
+	require.NoError(t, err)
+	assert.True(t, result.AlreadyExists)
+	assert.Empty(t, result.PresignedPutURL, "scanned content must not be overwritten")
+}
+
+func TestUploadStream_Scanning(t *testing.T) {
+	repo := newMockRepo()
+	a := newScanApp(repo, &mockStorage{}, &mockScanner{})
+
+	result, err := a.UploadStream(context.Background(), testCaller, bytes.NewReader([]byte("meeting notes")), "text/plain", "", false)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateScanning, result.State)
+	assert.True(t, result.CommittedAt.IsZero())
+
+	again, err := a.UploadStream(context.Background(), otherCaller, bytes.NewReader([]byte("meeting notes")), "text/plain", "", false)
+	require.NoError(t, err)
+	assert.True(t, again.AlreadyExists)
+	assert.Equal(t, domain.StateScanning, again.State)
+}
+
+func TestRunScanner(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	scanner := &mockScanner{}
+	a := newScanApp(repo, storage, scanner)
+	clean := uploadForScan(t, a, repo, storage, []byte("meeting notes"))
+	infected := uploadForScan(t, a, repo, storage, []byte("EICAR"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, a.RunScanner(ctx), context.DeadlineExceeded)
+
+	assert.Equal(t, domain.StateCommitted, repo.blobs[clean].State)
+	assert.Equal(t, domain.StateQuarantined, repo.blobs[infected].State)
+	assert.Equal(t, 2, scanner.scanned, "finished blobs are not scanned again")
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+// UploadStreamResult reports the blob's state as CompleteUploadResult does.
+type UploadStreamResult struct {
+	BlobID        domain.BlobID
+	SizeBytes     int64
+	AlreadyExists bool
+	State         domain.UploadState
+	CommittedAt   time.Time
+}
+
+// UploadStream stores and commits the content read from r. It is spooled to
+// a temporary file, since the blob ID is only known after the last byte.
+func (a *App) UploadStream(ctx context.Context, caller domain.Caller, r io.Reader, contentType string, expected domain.BlobID, encrypt bool) (*UploadStreamResult, error) {
+	if expected != "" {
+		if err := expected.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	spool, err := os.CreateTemp("", "blob-upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("UploadStream: %w", err)
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(spool, h), io.LimitReader(r, domain.MaxPartSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("UploadStream: %w", err)
+	}
+	if size > domain.MaxPartSize {
+		return nil, fmt.Errorf("UploadStream: %w: streamed uploads are limited to %d bytes", domain.ErrInvalidSize, domain.MaxPartSize)
+	}
+	id := domain.BlobID(hex.EncodeToString(h.Sum(nil)))
+	if expected != "" && id != expected {
+		return nil, fmt.Errorf("UploadStream: %w: content hashes to %s", domain.ErrContentMismatch, id)
+	}
+
+	head := make([]byte, min(size, sniffLen))
+	if _, err := spool.ReadAt(head, 0); err != nil {
+		return nil, fmt.Errorf("UploadStream: %w", err)
+	}
+	detected := http.DetectContentType(head)
+	if !a.cfg.ContentTypes.Allows(caller.ClientID, detected) {
+		return nil, fmt.Errorf("UploadStream: %w: %q", domain.ErrContentTypeNotAllowed, detected)
+	}
+
+	held, err := a.heldBlob(ctx, caller, id)
+	if err != nil {
+		return nil, fmt.Errorf("UploadStream: %w", err)
+	}
+	if held != nil {
+		return existingUpload(held), nil
+	}
+	upload, err := a.openUpload(ctx, caller, id, size, contentType, encrypt)
+	if err != nil {
+		return nil, fmt.Errorf("UploadStream: %w", err)
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("UploadStream: %w", err)
+	}
+	objects, err := a.objects(upload.Blob())
+	if err != nil {
+		return nil, fmt.Errorf("UploadStream: %w", err)
+	}
+	if err := objects.PutObject(ctx, upload.ObjectKey, spool, size, upload.ContentType, string(id)); err != nil {
+		return nil, fmt.Errorf("UploadStream: %w", err)
+	}
+
+	blob, err := a.finishUpload(ctx, upload, detected)
+	if err != nil {
+		return nil, fmt.Errorf("UploadStream: %w", err)
+	}
+	if blob.R2Key != upload.ObjectKey {
+		// Another caller had already stored the content.
+		return existingUpload(blob), nil
+	}
+	return &UploadStreamResult{BlobID: id, SizeBytes: size, State: blob.State, CommittedAt: committedAt(blob)}, nil
+}
+
+func existingUpload(blob *domain.Blob) *UploadStreamResult {
+	return &UploadStreamResult{BlobID: blob.ID, SizeBytes: blob.SizeBytes, AlreadyExists: true, State: blob.State, CommittedAt: committedAt(blob)}
+}
+
+type DownloadStreamResult struct {
+	Blob   *domain.Blob
+	Offset int64
+	Length int64
+	Body   io.ReadCloser
+}
+
+// DownloadStream opens a committed blob for reading. A zero length reads to
+// the end. The caller must close Body.
+func (a *App) DownloadStream(ctx context.Context, caller domain.Caller, id domain.BlobID, offset, length int64) (*DownloadStreamResult, error) {
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+
+	blob, err := a.findReadable(ctx, caller, id)
+	if err != nil {
+		return nil, fmt.Errorf("DownloadStream: %w", err)
+	}
+
+	if err := checkRange(offset, length, blob.SizeBytes); err != nil {
+		return nil, fmt.Errorf("DownloadStream: %w", err)
+	}
+	if length == 0 || offset+length > blob.SizeBytes {
+		length = blob.SizeBytes - offset
+	}
+
+	result := &DownloadStreamResult{Blob: blob, Offset: offset, Length: length}
+	if length == 0 {
+		result.Body = io.NopCloser(bytes.NewReader(nil))
+		return result, nil
+	}
+	objects, err := a.objects(blob)
+	if err != nil {
+		return nil, fmt.Errorf("DownloadStream: %w", err)
+	}
+	body, err := objects.GetObjectRange(ctx, blob.R2Key, offset, length)
+	if err != nil {
+		if errors.Is(err, domain.ErrObjectNotFound) {
+			slog.Error("committed blob missing from storage", "blob_id", id)
+		}
+		return nil, fmt.Errorf("DownloadStream: %w", err)
+	}
+	result.Body = body
+	return result, nil
+}
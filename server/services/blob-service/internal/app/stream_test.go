@@ -0,0 +1,149 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+func sha256Hex(b []byte) domain.BlobID {
+	sum := sha256.Sum256(b)
+	return domain.BlobID(hex.EncodeToString(sum[:]))
+}
+
+func TestUploadStream_CommitsUnderComputedID(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	a := newApp(repo, storage)
+
+	content := []byte("streamed content")
+	result, err := a.UploadStream(context.Background(), testCaller, bytes.NewReader(content), "text/plain", "", false)
+	require.NoError(t, err)
+
+	id := sha256Hex(content)
+	assert.Equal(t, id, result.BlobID)
+	assert.Equal(t, int64(len(content)), result.SizeBytes)
+	assert.False(t, result.AlreadyExists)
+	assert.Equal(t, content, storage.objects[repo.blobs[id].R2Key])
+	assert.Equal(t, domain.StateCommitted, repo.blobs[id].State)
+	assert.True(t, repo.refs[id][testCaller.Subject])
+}
+
+func TestUploadStream_ExistingBlobGrantsReference(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	content := []byte("already here")
+	id := storage.put(content)
+	blob, _ := domain.NewBlob(id, int64(len(content)), "text/plain", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+
+	a := newApp(repo, storage)
+	result, err := a.UploadStream(context.Background(), otherCaller, bytes.NewReader(content), "text/plain", "", false)
+	require.NoError(t, err)
+	assert.True(t, result.AlreadyExists)
+	assert.True(t, repo.refs[id][otherCaller.Subject])
+}
+
+func TestUploadStream_ExpectedIDMismatch(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	a := newApp(repo, storage)
+
+	_, err := a.UploadStream(context.Background(), testCaller, bytes.NewReader([]byte("x")), "text/plain", domain.BlobID(validID), false)
+	assert.ErrorIs(t, err, domain.ErrContentMismatch)
+	assert.Empty(t, repo.blobs)
+	assert.Empty(t, storage.objects)
+}
+
+func TestDownloadStream_Range(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	content := []byte("0123456789")
+	id := storage.put(content)
+	blob, _ := domain.NewBlob(id, int64(len(content)), "text/plain", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+
+	a := newApp(repo, storage)
+
+	tests := []struct {
+		name           string
+		offset, length int64
+		want           string
+	}{
+		{"whole blob", 0, 0, "0123456789"},
+		{"middle", 2, 3, "234"},
+		{"to end", 7, 0, "789"},
+		{"past end is truncated", 8, 10, "89"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := a.DownloadStream(context.Background(), testCaller, id, tc.offset, tc.length)
+			require.NoError(t, err)
+			defer func() { _ = result.Body.Close() }()
+			got, _ := io.ReadAll(result.Body)
+			assert.Equal(t, tc.want, string(got))
+			assert.Equal(t, int64(len(tc.want)), result.Length)
+		})
+	}
+}
+
+func TestDownloadStream_InvalidRange(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	id := storage.put([]byte("abc"))
+	blob, _ := domain.NewBlob(id, 3, "text/plain", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+
+	a := newApp(repo, storage)
+	_, err := a.DownloadStream(context.Background(), testCaller, id, 4, 0)
+	assert.ErrorIs(t, err, domain.ErrInvalidRange)
+
+	_, err = a.DownloadStream(context.Background(), testCaller, id, 3, 0)
+	assert.ErrorIs(t, err, domain.ErrInvalidRange, "an offset at the end is past the last byte")
+
+	_, err = a.DownloadStream(context.Background(), testCaller, id, -1, 0)
+	assert.ErrorIs(t, err, domain.ErrInvalidRange)
+}
+
+func TestDownloadStream_EmptyBlob(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	id := storage.put(nil)
+	blob, _ := domain.NewBlob(id, 0, "text/plain", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+
+	a := newApp(repo, storage)
+	result, err := a.DownloadStream(context.Background(), testCaller, id, 0, 0)
+	require.NoError(t, err)
+	_ = result.Body.Close()
+	assert.Zero(t, result.Length)
+
+	_, err = a.GetDownloadURL(context.Background(), testCaller, id, time.Minute, app.DownloadOptions{Length: 10})
+	require.NoError(t, err)
+	_, err = a.DownloadStream(context.Background(), testCaller, id, 1, 0)
+	assert.ErrorIs(t, err, domain.ErrInvalidRange)
+}
+
+func TestDownloadStream_Pending(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 0, "text/plain", time.Now())
+	repo.seed(blob)
+
+	a := newApp(repo, &mockStorage{})
+	_, err := a.DownloadStream(context.Background(), testCaller, blob.ID, 0, 0)
+	assert.ErrorIs(t, err, domain.ErrBlobPending)
+}
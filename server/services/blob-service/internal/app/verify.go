@@ -0,0 +1,214 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+const verifyBatchSize = 500
+
+type DiscrepancyKind string
+
+const (
+	// DiscrepancyMissingObject is a blob past PENDING with no stored object.
+	DiscrepancyMissingObject DiscrepancyKind = "missing_object"
+	// DiscrepancySizeMismatch is a blob whose stored object has a different
+	// size than the row records.
+	DiscrepancySizeMismatch DiscrepancyKind = "size_mismatch"
+	// DiscrepancyOrphanedObject is a stored object that neither a blob nor an
+	// upload is stored under.
+	DiscrepancyOrphanedObject DiscrepancyKind = "orphaned_object"
+)
+
+// Discrepancy is one difference between the blobs table and the bucket.
+// State is empty for orphaned objects.
+type Discrepancy struct {
+	Kind          DiscrepancyKind
+	Key           string
+	State         domain.UploadState
+	ExpectedBytes int64
+	ActualBytes   int64
+	Repaired      bool
+}
+
+type VerifyReport struct {
+	Repair         bool
+	CheckedBlobs   int
+	CheckedObjects int
+	Discrepancies  []Discrepancy
+	// Failed counts blobs and objects that could not be checked or
+	// repaired; the errors are logged.
+	Failed int
+}
+
+// Verify reports blobs whose object is missing or the wrong size, and objects
+// no blob or upload is stored under. With repair set, such blobs are marked
+// MISSING, keeping their references, and orphaned objects are deleted.
+func (a *App) Verify(ctx context.Context, repair bool) (*VerifyReport, error) {
+	report := &VerifyReport{Repair: repair}
+	if err := a.verifyBlobs(ctx, report); err != nil {
+		return report, fmt.Errorf("Verify: %w", err)
+	}
+	if err := a.verifyObjects(ctx, report); err != nil {
+		return report, fmt.Errorf("Verify: %w", err)
+	}
+	return report, nil
+}
+
+func (a *App) verifyBlobs(ctx context.Context, report *VerifyReport) error {
+	var afterID domain.BlobID
+	for {
+		blobs, err := a.repo.ListAll(ctx, afterID, verifyBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, blob := range blobs {
+			afterID = blob.ID
+			if blob.State == domain.StatePending || blob.State == domain.StateMissing {
+				continue
+			}
+			report.CheckedBlobs++
+			d, err := a.checkBlob(ctx, blob)
+			if err != nil {
+				slog.Error("failed to verify blob", "blob_id", blob.ID, "error", err)
+				report.Failed++
+				continue
+			}
+			if d == nil {
+				continue
+			}
+			if report.Repair && (blob.State == domain.StateCommitted || blob.State == domain.StateScanning) {
+				err := a.repo.MarkMissing(ctx, blob.ID)
+				switch {
+				case err == nil:
+					d.Repaired = true
+				case !errors.Is(err, domain.ErrAlreadyCommitted):
+					slog.Error("failed to repair blob", "blob_id", blob.ID, "error", err)
+					report.Failed++
+				}
+			}
+			report.Discrepancies = append(report.Discrepancies, *d)
+		}
+		if len(blobs) < verifyBatchSize {
+			return nil
+		}
+	}
+}
+
+// checkBlob returns the discrepancy between the blob and its object, or nil
+// if there is none.
+func (a *App) checkBlob(ctx context.Context, blob *domain.Blob) (*Discrepancy, error) {
+	objects, err := a.objects(blob)
+	if err != nil {
+		return nil, err
+	}
+	d := &Discrepancy{Key: blob.R2Key, State: blob.State, ExpectedBytes: blob.SizeBytes}
+	info, err := objects.StatObject(ctx, blob.R2Key)
+	if errors.Is(err, domain.ErrObjectNotFound) {
+		d.Kind = DiscrepancyMissingObject
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.SizeBytes != blob.SizeBytes {
+		d.Kind, d.ActualBytes = DiscrepancySizeMismatch, info.SizeBytes
+		return d, nil
+	}
+	return nil, nil
+}
+
+func (a *App) verifyObjects(ctx context.Context, report *VerifyReport) error {
+	var afterKey string
+	for {
+		objects, err := a.storage.ListObjects(ctx, afterKey, verifyBatchSize)
+		if err != nil {
+			return err
+		}
+		var ids []domain.BlobID
+		sizes := make(map[string]int64, len(objects))
+		for _, o := range objects {
+			afterKey = o.Key
+			id, ok := blobOfKey(o.Key)
+			if !ok {
+				continue
+			}
+			ids = append(ids, id)
+			sizes[o.Key] = o.SizeBytes
+		}
+		report.CheckedObjects += len(sizes)
+
+		used, err := a.usedKeys(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, o := range objects {
+			size, ok := sizes[o.Key]
+			if !ok || used[o.Key] {
+				continue
+			}
+			d := Discrepancy{Kind: DiscrepancyOrphanedObject, Key: o.Key, ActualBytes: size}
+			if report.Repair {
+				deleted, err := a.deleteOrphan(ctx, o.Key)
+				if err != nil {
+					slog.Error("failed to delete orphaned object", "key", o.Key, "error", err)
+					report.Failed++
+				}
+				d.Repaired = deleted
+			}
+			report.Discrepancies = append(report.Discrepancies, d)
+		}
+		if len(objects) < verifyBatchSize {
+			return nil
+		}
+	}
+}
+
+// blobOfKey returns the blob a blob or upload key belongs to.
+func blobOfKey(key string) (domain.BlobID, bool) {
+	id, suffix, _ := strings.Cut(key, ".")
+	if domain.BlobID(id).Validate() != nil || strings.Contains(suffix, "_") {
+		return "", false
+	}
+	return domain.BlobID(id), true
+}
+
+// usedKeys returns the keys the blobs and their uploads are stored under. The
+// uploads are read first, so a key moving to its blob is seen in one place.
+func (a *App) usedKeys(ctx context.Context, ids []domain.BlobID) (map[string]bool, error) {
+	used := make(map[string]bool)
+	uploads, err := a.repo.FindUploads(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range uploads {
+		used[u.ObjectKey] = true
+	}
+	blobs, err := a.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blobs {
+		used[b.R2Key] = true
+	}
+	return used, nil
+}
+
+// deleteOrphan deletes the object unless something was stored under key since
+// the bucket was listed. New content always gets a fresh key.
+func (a *App) deleteOrphan(ctx context.Context, key string) (bool, error) {
+	id, _ := blobOfKey(key)
+	used, err := a.usedKeys(ctx, []domain.BlobID{id})
+	if err != nil || used[key] {
+		return false, err
+	}
+	if err := a.storage.DeleteObject(ctx, key); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,124 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+type driftedBlobs struct {
+	healthy, missing, mismatched, pending, orphan domain.BlobID
+}
+
+// seedDrift stores one blob of each kind the checker looks for, plus a key
+// that is not a blob ID.
+func seedDrift(repo *mockRepo, storage *mockStorage) driftedBlobs {
+	var d driftedBlobs
+	d.healthy = seedCommitted(repo, storage, []byte("intact"))
+
+	d.missing = seedCommitted(repo, storage, []byte("lost from the bucket"))
+	delete(storage.objects, string(d.missing))
+
+	d.mismatched = seedCommitted(repo, storage, []byte("truncated"))
+	storage.objects[string(d.mismatched)] = []byte("trunc")
+
+	pending, _ := domain.NewBlob(sha256Hex([]byte("not uploaded yet")), 16, "text/plain", time.Now())
+	repo.seed(pending)
+	d.pending = pending.ID
+
+	d.orphan = storage.put([]byte("left behind"))
+	storage.objects["notes.txt"] = []byte("not a blob")
+	return d
+}
+
+func TestVerify_ReportsDrift(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	d := seedDrift(repo, storage)
+	a := newApp(repo, storage)
+
+	report, err := a.Verify(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.CheckedBlobs, "PENDING blobs are not checked")
+	assert.Equal(t, 3, report.CheckedObjects, "keys that are not blob IDs are ignored")
+	assert.Zero(t, report.Failed)
+	assert.ElementsMatch(t, []app.Discrepancy{
+		{Kind: app.DiscrepancyMissingObject, Key: string(d.missing), State: domain.StateCommitted, ExpectedBytes: 20},
+		{Kind: app.DiscrepancySizeMismatch, Key: string(d.mismatched), State: domain.StateCommitted, ExpectedBytes: 9, ActualBytes: 5},
+		{Kind: app.DiscrepancyOrphanedObject, Key: string(d.orphan), ActualBytes: 11},
+	}, report.Discrepancies)
+
+	assert.Equal(t, domain.StateCommitted, repo.blobs[d.missing].State, "nothing is repaired unless asked")
+	assert.Contains(t, storage.objects, string(d.orphan))
+}
+
+func TestVerify_Repair(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	d := seedDrift(repo, storage)
+	owner := domain.Owner{Kind: domain.OwnerSubject, ID: testCaller.Subject}
+	a := newApp(repo, storage)
+
+	report, err := a.Verify(context.Background(), true)
+	require.NoError(t, err)
+	require.Len(t, report.Discrepancies, 3)
+	for _, disc := range report.Discrepancies {
+		assert.True(t, disc.Repaired, disc.Kind)
+	}
+
+	assert.Equal(t, domain.StateCommitted, repo.blobs[d.healthy].State)
+	assert.Equal(t, domain.StateMissing, repo.blobs[d.missing].State)
+	assert.Nil(t, repo.blobs[d.missing].CommittedAt)
+	assert.True(t, repo.refs[d.missing][testCaller.Subject], "references survive the repair")
+	assert.Equal(t, domain.StateMissing, repo.blobs[d.mismatched].State)
+	assert.NotContains(t, storage.objects, string(d.orphan))
+	assert.Contains(t, storage.objects, "notes.txt")
+	assert.Equal(t, domain.Usage{Bytes: 6, Objects: 1}, repo.usage[owner], "missing blobs are refunded")
+
+	report, err = a.Verify(context.Background(), true)
+	require.NoError(t, err)
+	assert.Empty(t, report.Discrepancies)
+}
+
+func TestVerify_RepairedBlobCanBeUploadedAgain(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	content := []byte("lost from the bucket")
+	id := seedCommitted(repo, storage, content)
+	delete(storage.objects, string(id))
+	a := newApp(repo, storage)
+
+	_, err := a.Verify(context.Background(), true)
+	require.NoError(t, err)
+	_, err = a.GetDownloadURL(context.Background(), testCaller, id, time.Minute, app.DownloadOptions{})
+	assert.ErrorIs(t, err, domain.ErrBlobMissing)
+
+	_, err = a.InitiateUpload(context.Background(), testCaller, id, int64(len(content)), "text/plain", false)
+	require.NoError(t, err)
+	storage.putUpload(repo, testCaller, id, content)
+	result, err := a.CompleteUpload(context.Background(), testCaller, id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateCommitted, result.State)
+	assert.Equal(t, domain.StateCommitted, repo.blobs[id].State)
+}
+
+func TestVerify_EncryptedBlob(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	a := newEncryptingApp(t, repo, storage, masterKeyA)
+	_, err := a.UploadStream(context.Background(), testCaller, bytes.NewReader([]byte("sealed content")), "text/plain", "", true)
+	require.NoError(t, err)
+
+	report, err := a.Verify(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.CheckedBlobs)
+	assert.Empty(t, report.Discrepancies)
+	assert.Zero(t, report.Failed)
+}
@@ -19,25 +19,55 @@ func (id BlobID) Validate() error {
 
 type UploadState string
 
+// A blob is PENDING until its upload completes. When a scanner is
+// configured it is then SCANNING until the scan finds it clean and it is
+// COMMITTED, or finds malware and it is QUARANTINED. A blob whose stored
+// content is found missing or damaged is MISSING, keeping its references,
+// until a holder uploads it again. Only COMMITTED blobs can be downloaded.
 const (
-	StatePending   UploadState = "PENDING"
-	StateCommitted UploadState = "COMMITTED"
+	StatePending     UploadState = "PENDING"
+	StateScanning    UploadState = "SCANNING"
+	StateCommitted   UploadState = "COMMITTED"
+	StateQuarantined UploadState = "QUARANTINED"
+	StateMissing     UploadState = "MISSING"
 )
 
 type Blob struct {
 	ID          BlobID
 	SizeBytes   int64
 	ContentType string
-	R2Key       string
-	State       UploadState
-	CreatedAt   time.Time
-	CommittedAt *time.Time
+	// DetectedContentType is sniffed from the stored content when the blob
+	// is committed. It is empty until then, and for blobs committed before
+	// sniffing was introduced.
+	DetectedContentType string
+	R2Key               string
+	State               UploadState
+	CreatedAt           time.Time
+	CommittedAt         *time.Time
+	// ReleasedAt is set when the last reference to the blob was released
+	// and cleared when a new reference is added.
+	ReleasedAt *time.Time
+	// QuarantineReason names what the scanner found in a QUARANTINED blob.
+	QuarantineReason string
+	// KeyID names the master key WrappedKey is wrapped under. Both are empty
+	// for blobs stored without encryption.
+	KeyID      string
+	WrappedKey []byte
+	// ReplicatedAt is set once the blob's content has been copied to the
+	// secondary store, and is nil until then or when replication is off.
+	ReplicatedAt *time.Time
+	// RetainUntil and LegalHold keep the blob from being deleted; see Held.
+	RetainUntil *time.Time
+	LegalHold   bool
 }
 
 func NewBlob(id BlobID, sizeBytes int64, contentType string, now time.Time) (*Blob, error) {
 	if err := id.Validate(); err != nil {
 		return nil, err
 	}
+	if err := validateSize(sizeBytes); err != nil {
+		return nil, err
+	}
 	return &Blob{
 		ID:          id,
 		SizeBytes:   sizeBytes,
@@ -48,6 +78,23 @@ func NewBlob(id BlobID, sizeBytes int64, contentType string, now time.Time) (*Bl
 	}, nil
 }
 
+// Readable returns nil if the blob's content can be served, and otherwise
+// the error explaining why not.
+func (b *Blob) Readable() error {
+	switch b.State {
+	case StateCommitted:
+		return nil
+	case StateScanning:
+		return ErrBlobScanning
+	case StateQuarantined:
+		return ErrBlobQuarantined
+	case StateMissing:
+		return ErrBlobMissing
+	default:
+		return ErrBlobPending
+	}
+}
+
 func (b *Blob) Commit(at time.Time) error {
 	if b.State == StateCommitted {
 		return fmt.Errorf("%w: blob %s", ErrAlreadyCommitted, b.ID)
@@ -56,6 +56,14 @@ func TestNewBlob_InvalidID(t *testing.T) {
 	assert.ErrorIs(t, err, domain.ErrInvalidBlobID)
 }
 
+func TestNewBlob_InvalidSize(t *testing.T) {
+	_, err := domain.NewBlob(domain.BlobID(validID), -1, "image/png", time.Now())
+	assert.ErrorIs(t, err, domain.ErrInvalidSize)
+
+	_, err = domain.NewBlob(domain.BlobID(validID), domain.MaxObjectSize+1, "image/png", time.Now())
+	assert.ErrorIs(t, err, domain.ErrInvalidSize)
+}
+
 func TestBlob_Commit(t *testing.T) {
 	blob, err := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
 	require.NoError(t, err)
@@ -76,3 +84,30 @@ func TestBlob_Commit_AlreadyCommitted(t *testing.T) {
 	err = blob.Commit(time.Now())
 	assert.ErrorIs(t, err, domain.ErrAlreadyCommitted)
 }
+
+func TestBlob_Readable(t *testing.T) {
+	for state, want := range map[domain.UploadState]error{
+		domain.StatePending:     domain.ErrBlobPending,
+		domain.StateScanning:    domain.ErrBlobScanning,
+		domain.StateCommitted:   nil,
+		domain.StateQuarantined: domain.ErrBlobQuarantined,
+	} {
+		blob := &domain.Blob{State: state}
+		if want == nil {
+			assert.NoError(t, blob.Readable(), state)
+		} else {
+			assert.ErrorIs(t, blob.Readable(), want, state)
+		}
+	}
+}
+
+func TestBlob_Held(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	assert.False(t, (&domain.Blob{}).Held(now))
+	assert.True(t, (&domain.Blob{LegalHold: true}).Held(now))
+	assert.True(t, (&domain.Blob{RetainUntil: &future}).Held(now))
+	assert.False(t, (&domain.Blob{RetainUntil: &past}).Held(now), "an ended retention period no longer holds")
+	assert.True(t, (&domain.Blob{RetainUntil: &past, LegalHold: true}).Held(now))
+}
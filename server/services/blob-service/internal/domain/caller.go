@@ -0,0 +1,9 @@
+package domain
+
+// Caller identifies the authenticated principal an operation runs on behalf
+// of. Subject is the token's sub claim, e.g. "drive-service". ClientID is the
+// OAuth client the token was issued to (azp, or client_id), and may be empty.
+type Caller struct {
+	Subject  string
+	ClientID string
+}
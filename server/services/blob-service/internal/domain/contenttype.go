@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"mime"
+	"strings"
+)
+
+// ContentTypePolicy lists the content types each client may store, keyed by
+// client ID. Entries are media types, "type/*" for a whole major type, or
+// "*/*". Clients without an entry may store anything.
+type ContentTypePolicy map[string][]string
+
+// Allows reports whether clientID may store blobs of contentType.
+func (p ContentTypePolicy) Allows(clientID, contentType string) bool {
+	patterns, ok := p[clientID]
+	if !ok {
+		return true
+	}
+	for _, pattern := range patterns {
+		if MatchContentType(pattern, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchContentType reports whether contentType matches pattern, ignoring
+// parameters and case. A pattern ending in "/*" matches its major type.
+func MatchContentType(pattern, contentType string) bool {
+	pattern, ct := mediaType(pattern), mediaType(contentType)
+	if pattern == "*/*" {
+		return ct != ""
+	}
+	if major, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(ct, major+"/")
+	}
+	return ct != "" && ct == pattern
+}
+
+// InlineContentType is the type a download a browser may render should be
+// served as. It is the declared type, unless the sniffed type contradicts it,
+// in which case the content is served as opaque bytes rather than as either
+// claim.
+func (b *Blob) InlineContentType() string {
+	if b.DetectedContentType == "" || mediaType(b.ContentType) == mediaType(b.DetectedContentType) {
+		return b.ContentType
+	}
+	return "application/octet-stream"
+}
+
+func mediaType(ct string) string {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return ""
+	}
+	return mt
+}
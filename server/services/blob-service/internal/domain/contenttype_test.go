@@ -0,0 +1,46 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+func TestMatchContentType(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, ct string
+		want        bool
+	}{
+		{"image/png", "image/png", true},
+		{"image/png", "Image/PNG; q=1", true},
+		{"image/png", "image/jpeg", false},
+		{"image/*", "image/webp", true},
+		{"image/*", "imagex/webp", false},
+		{"text/*", "text/plain; charset=utf-8", true},
+		{"*/*", "application/pdf", true},
+		{"*/*", "", false},
+		{"image/png", "not a type;;", false},
+	} {
+		assert.Equal(t, tc.want, domain.MatchContentType(tc.pattern, tc.ct), "%s ~ %s", tc.pattern, tc.ct)
+	}
+}
+
+func TestContentTypePolicy_Allows(t *testing.T) {
+	p := domain.ContentTypePolicy{"profile-web": {"image/jpeg", "image/png"}, "locked": {}}
+	assert.True(t, p.Allows("profile-web", "image/png"))
+	assert.False(t, p.Allows("profile-web", "application/pdf"))
+	assert.False(t, p.Allows("locked", "image/png"))
+	assert.True(t, p.Allows("drive-web", "application/pdf"))
+	assert.True(t, p.Allows("", "application/pdf"))
+}
+
+func TestBlob_InlineContentType(t *testing.T) {
+	b := &domain.Blob{ContentType: "text/plain"}
+	assert.Equal(t, "text/plain", b.InlineContentType())
+	b.DetectedContentType = "text/plain; charset=utf-8"
+	assert.Equal(t, "text/plain", b.InlineContentType())
+	b.ContentType = "image/png"
+	assert.Equal(t, "application/octet-stream", b.InlineContentType())
+}
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	DispositionInline     = "inline"
+	DispositionAttachment = "attachment"
+
+	maxFilenameBytes = 255
+)
+
+// GetOptions are response overrides and a byte range signed into a presigned
+// download URL. Empty overrides leave the stored metadata in place and a zero
+// Length selects the whole object. Values must come from the Format* and
+// Normalize* helpers below, which reject anything that could smuggle extra
+// headers into the response.
+type GetOptions struct {
+	ContentDisposition string
+	ContentType        string
+	CacheControl       string
+	Offset             int64
+	Length             int64
+}
+
+// Range returns the HTTP Range header value for the options, or "" when the
+// whole object is requested.
+func (o GetOptions) Range() string {
+	if o.Length == 0 {
+		return ""
+	}
+	return fmt.Sprintf("bytes=%d-%d", o.Offset, o.Offset+o.Length-1)
+}
+
+// FormatContentDisposition builds a Content-Disposition value. A filename
+// without a disposition implies attachment; non-ASCII names are encoded per
+// RFC 2231.
+func FormatContentDisposition(disposition, filename string) (string, error) {
+	if disposition == "" {
+		if filename == "" {
+			return "", nil
+		}
+		disposition = DispositionAttachment
+	}
+	if disposition != DispositionInline && disposition != DispositionAttachment {
+		return "", fmt.Errorf("%w: unknown disposition %q", ErrInvalidDownloadOption, disposition)
+	}
+	if filename == "" {
+		return disposition, nil
+	}
+	if err := validateFilename(filename); err != nil {
+		return "", err
+	}
+	v := mime.FormatMediaType(disposition, map[string]string{"filename": filename})
+	if v == "" {
+		return "", fmt.Errorf("%w: filename cannot be encoded", ErrInvalidDownloadOption)
+	}
+	return v, nil
+}
+
+func validateFilename(name string) error {
+	if len(name) > maxFilenameBytes || !utf8.ValidString(name) {
+		return fmt.Errorf("%w: filename must be valid UTF-8 of at most %d bytes", ErrInvalidDownloadOption, maxFilenameBytes)
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("%w: filename must not contain a path", ErrInvalidDownloadOption)
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%w: filename contains control characters", ErrInvalidDownloadOption)
+		}
+	}
+	return nil
+}
+
+// NormalizeContentType parses a media type and returns it in canonical form.
+func NormalizeContentType(ct string) (string, error) {
+	if ct == "" {
+		return "", nil
+	}
+	mt, params, err := mime.ParseMediaType(ct)
+	if err != nil || !strings.Contains(mt, "/") {
+		return "", fmt.Errorf("%w: invalid content type %q", ErrInvalidDownloadOption, ct)
+	}
+	v := mime.FormatMediaType(mt, params)
+	if v == "" {
+		return "", fmt.Errorf("%w: invalid content type %q", ErrInvalidDownloadOption, ct)
+	}
+	return v, nil
+}
+
+var (
+	cacheControlFlags = map[string]bool{
+		"public": true, "private": true, "no-cache": true, "no-store": true,
+		"no-transform": true, "must-revalidate": true, "proxy-revalidate": true,
+		"immutable": true,
+	}
+	cacheControlSeconds = map[string]bool{
+		"max-age": true, "s-maxage": true, "stale-while-revalidate": true, "stale-if-error": true,
+	}
+)
+
+// NormalizeCacheControl accepts a comma-separated list of well-known response
+// directives and returns it in canonical form.
+func NormalizeCacheControl(cc string) (string, error) {
+	if cc == "" {
+		return "", nil
+	}
+	var out []string
+	for d := range strings.SplitSeq(cc, ",") {
+		d = strings.ToLower(strings.TrimSpace(d))
+		name, value, hasValue := strings.Cut(d, "=")
+		switch {
+		case cacheControlFlags[name] && !hasValue:
+			out = append(out, name)
+		case cacheControlSeconds[name] && hasValue:
+			n, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return "", fmt.Errorf("%w: invalid %s value %q", ErrInvalidDownloadOption, name, value)
+			}
+			out = append(out, name+"="+strconv.FormatUint(n, 10))
+		default:
+			return "", fmt.Errorf("%w: unsupported cache-control directive %q", ErrInvalidDownloadOption, d)
+		}
+	}
+	return strings.Join(out, ", "), nil
+}
@@ -0,0 +1,67 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+func TestFormatContentDisposition(t *testing.T) {
+	tests := []struct {
+		name        string
+		disposition string
+		filename    string
+		want        string
+		wantErr     bool
+	}{
+		{"none", "", "", "", false},
+		{"inline", "inline", "", "inline", false},
+		{"filename implies attachment", "", "report.pdf", `attachment; filename=report.pdf`, false},
+		{"quoted", "inline", "my report.pdf", `inline; filename="my report.pdf"`, false},
+		{"non-ascii", "attachment", "résumé.pdf", `attachment; filename*=utf-8''r%C3%A9sum%C3%A9.pdf`, false},
+		{"header injection", "attachment", "a.txt\r\nSet-Cookie: x=1", "", true},
+		{"path", "attachment", "../etc/passwd", "", true},
+		{"unknown disposition", "form-data", "", "", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := domain.FormatContentDisposition(tc.disposition, tc.filename)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, domain.ErrInvalidDownloadOption)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNormalizeContentType(t *testing.T) {
+	got, err := domain.NormalizeContentType("Text/HTML; Charset=UTF-8")
+	require.NoError(t, err)
+	assert.Equal(t, "text/html; charset=UTF-8", got)
+
+	for _, bad := range []string{"text", "text/plain\r\nX-Evil: 1", "text/plain; charset"} {
+		_, err := domain.NormalizeContentType(bad)
+		assert.ErrorIs(t, err, domain.ErrInvalidDownloadOption, bad)
+	}
+}
+
+func TestNormalizeCacheControl(t *testing.T) {
+	got, err := domain.NormalizeCacheControl(" Public ,max-age=3600, immutable")
+	require.NoError(t, err)
+	assert.Equal(t, "public, max-age=3600, immutable", got)
+
+	for _, bad := range []string{"max-age", "max-age=-1", "public=1", "no-cache\r\nX-Evil: 1", "community"} {
+		_, err := domain.NormalizeCacheControl(bad)
+		assert.ErrorIs(t, err, domain.ErrInvalidDownloadOption, bad)
+	}
+}
+
+func TestGetOptions_Range(t *testing.T) {
+	assert.Empty(t, domain.GetOptions{}.Range())
+	assert.Equal(t, "bytes=10-19", domain.GetOptions{Offset: 10, Length: 10}.Range())
+}
@@ -0,0 +1,22 @@
+package domain
+
+// DataKeySize is the length of a blob's data key, which the object store
+// uses as an SSE-C customer key (AES-256).
+const DataKeySize = 32
+
+// KeyWrapper encrypts blob data keys under a master key so that only the
+// wrapped form is stored. Wrapping binds the key to the blob ID; unwrapping
+// it under a different ID fails.
+type KeyWrapper interface {
+	// ActiveKeyID names the master key Wrap uses.
+	ActiveKeyID() string
+	Wrap(id BlobID, dataKey []byte) (keyID string, wrapped []byte, err error)
+	// Unwrap returns ErrUnknownMasterKey if keyID names no configured key.
+	Unwrap(id BlobID, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Encrypted reports whether the blob's content is stored under its own data
+// key.
+func (b *Blob) Encrypted() bool {
+	return b.KeyID != ""
+}
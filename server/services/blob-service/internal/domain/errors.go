@@ -7,4 +7,30 @@ var (
 	ErrAlreadyCommitted = errors.New("blob already committed")
 	ErrInvalidBlobID    = errors.New("invalid blob_id: must be 64-char lowercase hex")
 	ErrBlobPending      = errors.New("blob is in PENDING state")
+	ErrBlobScanning     = errors.New("blob is being scanned")
+	ErrBlobQuarantined  = errors.New("blob is quarantined")
+	ErrBlobMissing      = errors.New("blob content is missing from storage")
+	ErrObjectNotFound   = errors.New("object not found in storage")
+	ErrContentMismatch  = errors.New("uploaded content does not match blob_id and size_bytes")
+	ErrSessionNotFound  = errors.New("multipart upload session not found")
+	ErrSessionExists    = errors.New("multipart upload session already exists")
+	ErrUploadExists     = errors.New("upload already exists")
+	ErrInvalidSize      = errors.New("invalid size_bytes")
+	ErrInvalidPartCount = errors.New("invalid part_count")
+	ErrInvalidRange     = errors.New("requested range is outside the blob")
+
+	ErrInvalidDownloadOption = errors.New("invalid download option")
+	ErrInvalidPageToken      = errors.New("invalid page_token")
+	ErrTooManyBlobIDs        = errors.New("too many blob_ids")
+	ErrQuotaExceeded         = errors.New("storage quota exceeded")
+	ErrUnknownRendition      = errors.New("unknown rendition spec")
+	ErrNotRenderable         = errors.New("blob cannot be rendered")
+	ErrRenditionPending      = errors.New("rendition has not been generated yet")
+	ErrContentTypeNotAllowed = errors.New("content type not allowed for this client")
+	ErrEncryptionUnavailable = errors.New("encryption at rest is not configured")
+	ErrUnknownMasterKey      = errors.New("data key is wrapped under an unknown master key")
+	ErrPermissionDenied      = errors.New("caller is not permitted to perform this operation")
+	ErrInvalidRetention      = errors.New("retain_until must be in the future")
+	ErrRetentionShortened    = errors.New("retention period cannot be shortened")
+	ErrOffsetMismatch        = errors.New("upload offset does not match the bytes received")
 )
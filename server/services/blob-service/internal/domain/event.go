@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+type EventKind string
+
+const (
+	EventCommitted EventKind = "COMMITTED"
+	EventDeleted   EventKind = "DELETED"
+)
+
+// BlobEvent is an entry in the blob event outbox. Seq increases
+// monotonically and serves as the resume cursor for watchers.
+type BlobEvent struct {
+	Seq         int64
+	Kind        EventKind
+	BlobID      BlobID
+	SizeBytes   int64
+	ContentType string
+	OccurredAt  time.Time
+}
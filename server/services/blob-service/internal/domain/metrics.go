@@ -0,0 +1,7 @@
+package domain
+
+// UploadRecorder is told about every upload that verifies against its blob
+// ID, before any scan. It must be safe for concurrent use.
+type UploadRecorder interface {
+	UploadVerified(sizeBytes int64)
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// MultipartSession is the multipart upload currently in progress for a
+// subject's upload of a blob. An upload has at most one session at a time.
+type MultipartSession struct {
+	BlobID    BlobID
+	Subject   string
+	UploadID  string
+	PartCount int32
+	CreatedAt time.Time
+
+	// Resumable sessions take their bytes in order through the service
+	// rather than through presigned part URLs, so their parts vary in size.
+	// Of the ReceivedBytes bytes received, those before StagedOffset are in
+	// Parts and the rest in the Staged staging objects.
+	Resumable     bool
+	ReceivedBytes int64
+	StagedOffset  int64
+	Parts         []int32
+	Staged        []int32
+}
@@ -0,0 +1,104 @@
+package domain
+
+import "fmt"
+
+// Limits imposed by the S3 multipart upload API.
+const (
+	MinPartSize  int64 = 5 << 20
+	MaxPartSize  int64 = 5 << 30
+	MaxPartCount int32 = 10000
+
+	// MaxObjectSize is the largest object S3 accepts.
+	MaxObjectSize int64 = 5 << 40
+)
+
+// DefaultPartSize is the approximate part size planned for multipart uploads
+// unless the object is too large to fit in MaxPartCount parts of this size.
+const DefaultPartSize int64 = 16 << 20
+
+// PartRange is the byte range [Offset, Offset+SizeBytes) of the object that
+// a client uploads as the given part.
+type PartRange struct {
+	PartNumber int32
+	Offset     int64
+	SizeBytes  int64
+}
+
+type UploadPlan struct {
+	Multipart bool
+	PartSize  int64
+	Parts     []PartRange
+}
+
+// PlanUpload decides how an object of sizeBytes should be uploaded. Objects
+// smaller than threshold use a single PUT; larger ones are split into as
+// many parts as DefaultPartSize calls for, capped at MaxPartCount, and the
+// bytes are then divided evenly as described by SplitParts.
+func PlanUpload(sizeBytes, threshold int64) (*UploadPlan, error) {
+	if err := validateSize(sizeBytes); err != nil {
+		return nil, err
+	}
+	if sizeBytes < threshold && sizeBytes <= MaxPartSize {
+		return &UploadPlan{PartSize: sizeBytes}, nil
+	}
+
+	count := int32(min(int64(MaxPartCount), max(1, ceilDiv(sizeBytes, DefaultPartSize))))
+	parts := SplitParts(sizeBytes, count)
+	return &UploadPlan{Multipart: true, PartSize: parts[0].SizeBytes, Parts: parts}, nil
+}
+
+// SplitParts divides an object of sizeBytes into partCount parts of equal
+// size, rounded up, with the remainder in the last part. This is the split
+// that part sizes are signed into presigned part URLs with.
+func SplitParts(sizeBytes int64, partCount int32) []PartRange {
+	partSize := ceilDiv(sizeBytes, int64(partCount))
+	parts := make([]PartRange, partCount)
+	for i := range parts {
+		offset := min(int64(i)*partSize, sizeBytes)
+		parts[i] = PartRange{
+			PartNumber: int32(i) + 1,
+			Offset:     offset,
+			SizeBytes:  min(partSize, sizeBytes-offset),
+		}
+	}
+	return parts
+}
+
+// ResumablePartSize is the least a resumable upload of sizeBytes gathers
+// into each part but its last, so that it fits in MaxPartCount parts.
+func ResumablePartSize(sizeBytes int64) (int64, error) {
+	if err := validateSize(sizeBytes); err != nil {
+		return 0, err
+	}
+	return max(MinPartSize, ceilDiv(sizeBytes, int64(MaxPartCount))), nil
+}
+
+// ValidatePartCount checks that an object of sizeBytes split into partCount
+// parts by SplitParts satisfies the S3 part limits.
+func ValidatePartCount(sizeBytes int64, partCount int32) error {
+	if err := validateSize(sizeBytes); err != nil {
+		return err
+	}
+	if partCount < 1 || partCount > MaxPartCount {
+		return fmt.Errorf("%w: must be between 1 and %d", ErrInvalidPartCount, MaxPartCount)
+	}
+	partSize := ceilDiv(sizeBytes, int64(partCount))
+	if partCount > 1 && partSize < MinPartSize {
+		return fmt.Errorf("%w: %d parts of %d bytes are below the %d byte minimum", ErrInvalidPartCount, partCount, partSize, MinPartSize)
+	}
+	if partSize > MaxPartSize {
+		return fmt.Errorf("%w: %d parts of %d bytes exceed the %d byte maximum", ErrInvalidPartCount, partCount, partSize, MaxPartSize)
+	}
+	return nil
+}
+
+func validateSize(sizeBytes int64) error {
+	if sizeBytes < 0 || sizeBytes > MaxObjectSize {
+		return fmt.Errorf("%w: must be between 0 and %d", ErrInvalidSize, MaxObjectSize)
+	}
+	return nil
+}
+
+func ceilDiv(a, b int64) int64 {
+	return (a + b - 1) / b
+}
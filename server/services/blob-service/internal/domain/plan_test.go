@@ -0,0 +1,103 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+const (
+	mib = int64(1 << 20)
+	gib = int64(1 << 30)
+)
+
+func TestPlanUpload_BelowThreshold(t *testing.T) {
+	plan, err := domain.PlanUpload(4*mib, 10*mib)
+	require.NoError(t, err)
+	assert.False(t, plan.Multipart)
+	assert.Empty(t, plan.Parts)
+}
+
+func TestPlanUpload_Multipart(t *testing.T) {
+	size := 40*mib + 123
+	plan, err := domain.PlanUpload(size, 10*mib)
+	require.NoError(t, err)
+	assert.True(t, plan.Multipart)
+	require.Len(t, plan.Parts, 3)
+	assert.Equal(t, plan.Parts[0].SizeBytes, plan.PartSize)
+
+	var next int64
+	for i, p := range plan.Parts {
+		assert.Equal(t, int32(i+1), p.PartNumber)
+		assert.Equal(t, next, p.Offset)
+		next += p.SizeBytes
+	}
+	assert.Equal(t, size, next)
+	assert.Equal(t, domain.SplitParts(size, 3), plan.Parts)
+	require.NoError(t, domain.ValidatePartCount(size, int32(len(plan.Parts))))
+}
+
+func TestPlanUpload_GrowsPartSizeToFitPartLimit(t *testing.T) {
+	size := 1000 * gib
+	plan, err := domain.PlanUpload(size, 10*mib)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(plan.Parts), int(domain.MaxPartCount))
+	require.NoError(t, domain.ValidatePartCount(size, int32(len(plan.Parts))))
+}
+
+func TestPlanUpload_ForcesMultipartAboveSinglePutLimit(t *testing.T) {
+	plan, err := domain.PlanUpload(6*gib, 10*gib)
+	require.NoError(t, err)
+	assert.True(t, plan.Multipart)
+}
+
+func TestPlanUpload_InvalidSize(t *testing.T) {
+	_, err := domain.PlanUpload(-1, 10*mib)
+	assert.ErrorIs(t, err, domain.ErrInvalidSize)
+
+	_, err = domain.PlanUpload(domain.MaxObjectSize+1, 10*mib)
+	assert.ErrorIs(t, err, domain.ErrInvalidSize)
+}
+
+func TestSplitParts(t *testing.T) {
+	parts := domain.SplitParts(10, 3)
+	assert.Equal(t, []domain.PartRange{
+		{PartNumber: 1, Offset: 0, SizeBytes: 4},
+		{PartNumber: 2, Offset: 4, SizeBytes: 4},
+		{PartNumber: 3, Offset: 8, SizeBytes: 2},
+	}, parts)
+
+	empty := domain.SplitParts(0, 1)
+	assert.Equal(t, []domain.PartRange{{PartNumber: 1}}, empty)
+}
+
+func TestValidatePartCount(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int64
+		partCount int32
+		wantErr   bool
+	}{
+		{"single small part", 1024, 1, false},
+		{"empty object", 0, 1, false},
+		{"zero parts", 1024, 0, true},
+		{"too many parts", 100 * gib, domain.MaxPartCount + 1, true},
+		{"parts below minimum", 12 * mib, 3, true},
+		{"parts at minimum", 10 * mib, 2, false},
+		{"part above maximum", 6 * gib, 1, true},
+		{"large object split", 6 * gib, 2, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := domain.ValidatePartCount(tc.size, tc.partCount)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, domain.ErrInvalidPartCount)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package domain
+
+import "fmt"
+
+type OwnerKind string
+
+const (
+	OwnerSubject OwnerKind = "subject"
+	OwnerClient  OwnerKind = "client"
+)
+
+// Owner is a principal that usage is accounted to. Every reference is
+// charged to its subject, and to its client when the caller's token named
+// one.
+type Owner struct {
+	Kind OwnerKind
+	ID   string
+}
+
+func (o Owner) String() string {
+	return string(o.Kind) + ":" + o.ID
+}
+
+// Owners returns the owners a reference held by c is charged to.
+func (c Caller) Owners() []Owner {
+	owners := []Owner{{Kind: OwnerSubject, ID: c.Subject}}
+	if c.ClientID != "" {
+		owners = append(owners, Owner{Kind: OwnerClient, ID: c.ClientID})
+	}
+	return owners
+}
+
+// Usage is the storage charged to an owner: the size and number of the
+// committed blobs it holds references to.
+type Usage struct {
+	Bytes   int64
+	Objects int64
+}
+
+// Limits caps an owner's usage. Zero fields are unlimited.
+type Limits struct {
+	MaxBytes       int64
+	MaxObjects     int64
+	MaxObjectBytes int64
+}
+
+// Admit reports ErrQuotaExceeded if charging one more object of sizeBytes to
+// an owner with usage u would exceed l.
+func (l Limits) Admit(u Usage, sizeBytes int64) error {
+	switch {
+	case l.MaxObjectBytes > 0 && sizeBytes > l.MaxObjectBytes:
+		return fmt.Errorf("%w: object of %d bytes exceeds the %d byte limit", ErrQuotaExceeded, sizeBytes, l.MaxObjectBytes)
+	case l.MaxBytes > 0 && u.Bytes+sizeBytes > l.MaxBytes:
+		return fmt.Errorf("%w: %d of %d bytes used", ErrQuotaExceeded, u.Bytes, l.MaxBytes)
+	case l.MaxObjects > 0 && u.Objects+1 > l.MaxObjects:
+		return fmt.Errorf("%w: %d of %d objects used", ErrQuotaExceeded, u.Objects, l.MaxObjects)
+	}
+	return nil
+}
+
+// QuotaPolicy holds the default limits for each kind of owner and
+// per-owner overrides keyed by Owner.String().
+type QuotaPolicy struct {
+	Subject   Limits
+	Client    Limits
+	Overrides map[string]Limits
+}
+
+func (p QuotaPolicy) LimitsFor(o Owner) Limits {
+	if l, ok := p.Overrides[o.String()]; ok {
+		return l
+	}
+	if o.Kind == OwnerClient {
+		return p.Client
+	}
+	return p.Subject
+}
@@ -0,0 +1,54 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+func TestLimits_Admit(t *testing.T) {
+	limits := domain.Limits{MaxBytes: 100, MaxObjects: 3, MaxObjectBytes: 50}
+
+	tests := []struct {
+		name    string
+		usage   domain.Usage
+		size    int64
+		wantErr bool
+	}{
+		{"within limits", domain.Usage{Bytes: 40, Objects: 1}, 50, false},
+		{"exactly full", domain.Usage{Bytes: 50, Objects: 2}, 50, false},
+		{"object too large", domain.Usage{}, 51, true},
+		{"bytes exhausted", domain.Usage{Bytes: 60, Objects: 1}, 41, true},
+		{"objects exhausted", domain.Usage{Bytes: 0, Objects: 3}, 1, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := limits.Admit(tc.usage, tc.size)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, domain.ErrQuotaExceeded)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, domain.Limits{}.Admit(domain.Usage{Bytes: 1 << 62, Objects: 1 << 40}, 1<<40), "zero limits are unlimited")
+}
+
+func TestQuotaPolicy_LimitsFor(t *testing.T) {
+	p := domain.QuotaPolicy{
+		Subject:   domain.Limits{MaxBytes: 1},
+		Client:    domain.Limits{MaxBytes: 2},
+		Overrides: map[string]domain.Limits{"subject:drive-service": {MaxBytes: 3}},
+	}
+	assert.Equal(t, int64(1), p.LimitsFor(domain.Owner{Kind: domain.OwnerSubject, ID: "chat-service"}).MaxBytes)
+	assert.Equal(t, int64(2), p.LimitsFor(domain.Owner{Kind: domain.OwnerClient, ID: "drive-service"}).MaxBytes)
+	assert.Equal(t, int64(3), p.LimitsFor(domain.Owner{Kind: domain.OwnerSubject, ID: "drive-service"}).MaxBytes)
+}
+
+func TestCaller_Owners(t *testing.T) {
+	assert.Equal(t, []domain.Owner{{Kind: domain.OwnerSubject, ID: "alice"}}, domain.Caller{Subject: "alice"}.Owners())
+	assert.Len(t, domain.Caller{Subject: "alice", ClientID: "drive-web"}.Owners(), 2)
+}
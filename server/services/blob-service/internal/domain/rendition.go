@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type RenditionFormat string
+
+const (
+	RenditionJPEG RenditionFormat = "jpeg"
+	RenditionWebP RenditionFormat = "webp"
+
+	MaxRenditionDimension = 4096
+)
+
+// RenditionSpec describes a derived image: the source scaled down, keeping
+// its aspect ratio, to fit a MaxDimension square and re-encoded as Format.
+// Sources that already fit are re-encoded at their own size.
+type RenditionSpec struct {
+	Format       RenditionFormat
+	MaxDimension int
+}
+
+// String is the spec's canonical name, e.g. "jpeg-256", under which its
+// renditions are recorded.
+func (s RenditionSpec) String() string {
+	return string(s.Format) + "-" + strconv.Itoa(s.MaxDimension)
+}
+
+func (s RenditionSpec) ContentType() string {
+	return "image/" + string(s.Format)
+}
+
+func (s RenditionSpec) Validate() error {
+	if s.Format != RenditionJPEG && s.Format != RenditionWebP {
+		return fmt.Errorf("%w: unknown format %q", ErrUnknownRendition, s.Format)
+	}
+	if s.MaxDimension < 1 || s.MaxDimension > MaxRenditionDimension {
+		return fmt.Errorf("%w: max dimension must be between 1 and %d, got %d", ErrUnknownRendition, MaxRenditionDimension, s.MaxDimension)
+	}
+	return nil
+}
+
+// ParseRenditionSpec parses the form produced by RenditionSpec.String.
+func ParseRenditionSpec(v string) (RenditionSpec, error) {
+	format, dim, ok := strings.Cut(v, "-")
+	if !ok {
+		return RenditionSpec{}, fmt.Errorf("%w: %q is not of the form <format>-<max dimension>", ErrUnknownRendition, v)
+	}
+	n, err := strconv.Atoi(dim)
+	if err != nil {
+		return RenditionSpec{}, fmt.Errorf("%w: %q is not of the form <format>-<max dimension>", ErrUnknownRendition, v)
+	}
+	s := RenditionSpec{Format: RenditionFormat(format), MaxDimension: n}
+	return s, s.Validate()
+}
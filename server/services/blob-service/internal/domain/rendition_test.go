@@ -0,0 +1,27 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+func TestParseRenditionSpec(t *testing.T) {
+	spec, err := domain.ParseRenditionSpec("jpeg-512")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RenditionSpec{Format: domain.RenditionJPEG, MaxDimension: 512}, spec)
+	assert.Equal(t, "jpeg-512", spec.String())
+	assert.Equal(t, "image/jpeg", spec.ContentType())
+
+	spec, err = domain.ParseRenditionSpec("webp-256")
+	require.NoError(t, err)
+	assert.Equal(t, "image/webp", spec.ContentType())
+
+	for _, bad := range []string{"", "jpeg", "png-256", "jpeg-0", "jpeg-x", "jpeg-99999"} {
+		_, err := domain.ParseRenditionSpec(bad)
+		assert.ErrorIs(t, err, domain.ErrUnknownRendition, bad)
+	}
+}
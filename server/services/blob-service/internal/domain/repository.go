@@ -5,8 +5,163 @@ import (
 	"time"
 )
 
+// BlobFilter selects blobs for ListBlobs. A non-empty Subject matches the
+// blobs it holds a reference to; it and every other zero-valued field match
+// all blobs. ContentType matches exactly, or by
+// major type when it ends in "/*". The After bounds are inclusive and the
+// Before bounds exclusive.
+type BlobFilter struct {
+	Subject         string
+	State           UploadState
+	ContentType     string
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
+	CommittedAfter  time.Time
+	CommittedBefore time.Time
+}
+
 type BlobRepository interface {
 	FindByID(ctx context.Context, id BlobID) (*Blob, error)
 	Create(ctx context.Context, b *Blob) error
-	MarkCommitted(ctx context.Context, id BlobID, at time.Time) error
+	// MarkCommitted commits a PENDING or SCANNING blob. It stores the
+	// sniffed content type, records an EventCommitted and charges the blob to
+	// the usage of every reference already held, in one transaction.
+	MarkCommitted(ctx context.Context, id BlobID, detectedContentType string, at time.Time) error
+	// MarkQuarantined quarantines a SCANNING blob. It returns
+	// ErrAlreadyCommitted if the blob is in any other state.
+	MarkQuarantined(ctx context.Context, id BlobID, reason string) error
+	// MarkMissing moves a SCANNING or COMMITTED blob to MISSING so its
+	// content can be uploaded again, refunding any usage charged for it. It
+	// returns ErrAlreadyCommitted if the blob is in any other state.
+	MarkMissing(ctx context.Context, id BlobID) error
+	// ListScanning returns SCANNING blobs ordered by ID and starting after
+	// afterID.
+	ListScanning(ctx context.Context, afterID BlobID, limit int) ([]*Blob, error)
+
+	// ListAll returns every blob, ordered by ID and starting after afterID.
+	ListAll(ctx context.Context, afterID BlobID, limit int) ([]*Blob, error)
+	// FindByIDs returns the blobs among ids that exist, in no particular
+	// order.
+	FindByIDs(ctx context.Context, ids []BlobID) ([]*Blob, error)
+	// FindReferenced returns the blobs among ids that subject holds a
+	// reference to, in no particular order.
+	FindReferenced(ctx context.Context, ids []BlobID, subject string) ([]*Blob, error)
+	// ListBlobs returns blobs matching f, ordered by ID and starting after
+	// afterID.
+	ListBlobs(ctx context.Context, f BlobFilter, afterID BlobID, limit int) ([]*Blob, error)
+
+	// AddReference records that subject, acting through clientID, holds a
+	// reference to the blob. Adding an existing reference is a no-op. A new
+	// reference to a committed blob is charged to the subject's and client's
+	// usage, failing with ErrQuotaExceeded if that takes either over its
+	// limits under quotas.
+	AddReference(ctx context.Context, id BlobID, subject, clientID string, quotas QuotaPolicy) error
+	HasReference(ctx context.Context, id BlobID, subject string) (bool, error)
+	// RemoveReference returns ErrBlobNotFound if subject holds no reference.
+	// Removing the last reference marks the blob released at the given time.
+	// The reference's charge to usage, if any, is refunded.
+	RemoveReference(ctx context.Context, id BlobID, subject string, at time.Time) error
+
+	// ListReleased returns committed, quarantined or missing, unreferenced
+	// blobs released before the cutoff, ordered by ID and starting after
+	// afterID.
+	// Blobs linked as a rendition count as referenced, and held blobs are
+	// never listed.
+	ListReleased(ctx context.Context, before time.Time, afterID BlobID, limit int) ([]*Blob, error)
+	// DeleteReleased deletes the blob row only if it is still unreferenced,
+	// not held and was released before the cutoff, reporting whether it was
+	// deleted and the object key it had.
+	// A deletion records an EventDeleted and releases the blob's renditions
+	// that nothing else holds, in one transaction.
+	DeleteReleased(ctx context.Context, id BlobID, before time.Time) (key string, deleted bool, err error)
+
+	// CreateUpload returns ErrUploadExists if the subject already has an
+	// upload of the blob.
+	CreateUpload(ctx context.Context, u *Upload) error
+	// FindUpload returns ErrBlobNotFound if subject has no upload of the blob.
+	FindUpload(ctx context.Context, id BlobID, subject string) (*Upload, error)
+	// FindUploads returns the uploads of the given blobs, in no particular
+	// order.
+	FindUploads(ctx context.Context, ids []BlobID) ([]*Upload, error)
+	// DeleteUpload deletes the upload and its multipart session only if it is
+	// still stored under u.ObjectKey, reporting whether it was deleted.
+	DeleteUpload(ctx context.Context, u *Upload) (bool, error)
+	// ListExpiredUploads returns uploads created before the cutoff, ordered
+	// by object key and starting after afterKey.
+	ListExpiredUploads(ctx context.Context, before time.Time, afterKey string, limit int) ([]*Upload, error)
+	// CommitUpload turns a verified upload into a reference, in one
+	// transaction. It deletes the upload, returning ErrBlobNotFound if it is
+	// gone or was replaced. If the blob does not exist it is created as b,
+	// which is stored under the upload's key; a PENDING or MISSING blob left
+	// without content is restored from b the same way, and any other blob is
+	// kept as it is. The subject is then given a reference, and the stored
+	// blob returned. Creating or restoring a COMMITTED blob records an
+	// EventCommitted. The new reference is charged as by AddReference.
+	CommitUpload(ctx context.Context, u *Upload, b *Blob, quotas QuotaPolicy) (*Blob, error)
+
+	// RecordMultipartUpload returns ErrSessionExists if the upload already
+	// has a session, and ErrBlobNotFound if the upload is gone.
+	RecordMultipartUpload(ctx context.Context, s *MultipartSession) error
+	// FindMultipartUpload returns ErrSessionNotFound if the subject's upload
+	// of the blob has no session.
+	FindMultipartUpload(ctx context.Context, id BlobID, subject string) (*MultipartSession, error)
+	DeleteMultipartUpload(ctx context.Context, uploadID string) error
+	// HasMultipartUpload reports whether a session tracks the upload ID.
+	HasMultipartUpload(ctx context.Context, uploadID string) (bool, error)
+	// ClaimResumableNumber reserves the next part number of a resumable
+	// session, or the next staging object number if part is false, for a
+	// chunk starting at offset. It returns ErrOffsetMismatch unless the
+	// session has received exactly offset bytes.
+	ClaimResumableNumber(ctx context.Context, uploadID string, offset int64, part bool) (int32, error)
+	// AdvanceResumableUpload stores the progress of s, returning
+	// ErrOffsetMismatch unless the stored session has received exactly
+	// offset bytes.
+	AdvanceResumableUpload(ctx context.Context, s *MultipartSession, offset int64) error
+
+	// ListEvents returns events with a sequence greater than afterSeq, oldest
+	// first.
+	ListEvents(ctx context.Context, afterSeq int64, limit int) ([]*BlobEvent, error)
+	// DeleteEventsBefore removes events that occurred before the cutoff and
+	// returns how many were removed.
+	DeleteEventsBefore(ctx context.Context, before time.Time) (int64, error)
+	// FindEventCursor returns the last sequence the consumer recorded, or 0.
+	FindEventCursor(ctx context.Context, consumer string) (int64, error)
+	// SaveEventCursor records seq for the consumer. A cursor never moves
+	// back.
+	SaveEventCursor(ctx context.Context, consumer string, seq int64) error
+
+	// LinkRendition records rendition as the blob derived from source by the
+	// named spec, replacing any earlier link. FindRendition returns
+	// ErrBlobNotFound if there is no link.
+	LinkRendition(ctx context.Context, source BlobID, spec string, rendition BlobID) error
+	FindRendition(ctx context.Context, source BlobID, spec string) (*Blob, error)
+	// IsRendition reports whether the blob is linked as any blob's rendition.
+	IsRendition(ctx context.Context, id BlobID) (bool, error)
+
+	// ListStaleKeys returns encrypted blobs whose data key is wrapped under a
+	// master key other than keyID, ordered by ID and starting after afterID.
+	ListStaleKeys(ctx context.Context, keyID string, afterID BlobID, limit int) ([]*Blob, error)
+	// RewrapKey replaces the blob's wrapped data key only if it is still
+	// wrapped under oldKeyID, reporting whether it was replaced.
+	RewrapKey(ctx context.Context, id BlobID, oldKeyID, keyID string, wrapped []byte) (bool, error)
+
+	// ListUnreplicated returns committed blobs not yet copied to the
+	// secondary store, ordered by ID and starting after afterID.
+	ListUnreplicated(ctx context.Context, afterID BlobID, limit int) ([]*Blob, error)
+	// MarkReplicated records that the blob's content was copied to the
+	// secondary store at the given time.
+	MarkReplicated(ctx context.Context, id BlobID, at time.Time) error
+
+	// SetRetention extends the blob's retention period to until and records
+	// it in the audit log as done by caller, in one transaction. It returns
+	// ErrRetentionShortened if the blob is already retained past until.
+	SetRetention(ctx context.Context, id BlobID, until time.Time, by Caller) error
+	// SetLegalHold places or releases the blob's legal hold and records it
+	// in the audit log with the reason, in one transaction. Both return
+	// ErrBlobNotFound if there is no such blob.
+	SetLegalHold(ctx context.Context, id BlobID, hold bool, reason string, by Caller) error
+
+	// GetUsage returns the owner's usage, which is zero if nothing was ever
+	// charged to it.
+	GetUsage(ctx context.Context, o Owner) (*Usage, error)
 }
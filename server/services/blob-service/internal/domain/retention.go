@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// AuditAction names a change recorded in the blob audit log.
+type AuditAction string
+
+const (
+	AuditRetentionSet      AuditAction = "RETENTION_SET"
+	AuditLegalHoldSet      AuditAction = "LEGAL_HOLD_SET"
+	AuditLegalHoldReleased AuditAction = "LEGAL_HOLD_RELEASED"
+)
+
+// Held reports whether the blob is under a legal hold or a retention period
+// that has not ended by now. A held blob is never deleted, even once it is
+// unreferenced.
+func (b *Blob) Held(now time.Time) bool {
+	return b.LegalHold || (b.RetainUntil != nil && b.RetainUntil.After(now))
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"context"
+	"io"
+)
+
+type ScanResult struct {
+	Clean bool
+	// Signature names the malware found when the content is not clean.
+	Signature string
+}
+
+// Scanner inspects blob content for malware.
+type Scanner interface {
+	// Scan reads r to the end and returns the verdict. An error means no
+	// verdict was reached and the scan should be retried.
+	Scan(ctx context.Context, r io.Reader) (*ScanResult, error)
+}
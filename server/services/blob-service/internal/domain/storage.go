@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"io"
 	"time"
 )
 
@@ -10,11 +11,105 @@ type CompletedPart struct {
 	ETag       string
 }
 
+// UploadedPart is a part already stored for an in-progress multipart upload.
+type UploadedPart struct {
+	PartNumber int32
+	ETag       string
+	SizeBytes  int64
+}
+
+// PutConstraints are signed into a presigned upload URL so that the object
+// store itself rejects a body of a different size, type or content.
+// ChecksumSHA256 is lowercase hex; ContentType and ChecksumSHA256 are not
+// enforced when empty.
+type PutConstraints struct {
+	SizeBytes      int64
+	ContentType    string
+	ChecksumSHA256 string
+}
+
+// PresignedRequest is a URL a client can use without credentials. Headers
+// were signed into the URL and must be sent with the request unchanged.
+type PresignedRequest struct {
+	URL       string
+	Headers   map[string]string
+	ExpiresAt time.Time
+}
+
+// ObjectInfo describes a stored object as reported by the backend.
+// ChecksumSHA256 is the lowercase hex digest of the full object when the
+// backend recorded one on upload, and empty otherwise.
+type ObjectInfo struct {
+	SizeBytes      int64
+	ChecksumSHA256 string
+}
+
+// StoredObject is an object as listed by the backend.
+type StoredObject struct {
+	Key       string
+	SizeBytes int64
+}
+
+// MultipartUpload is an in-progress multipart upload as listed by the backend.
+type MultipartUpload struct {
+	Key         string
+	UploadID    string
+	InitiatedAt time.Time
+}
+
 type ObjectStorage interface {
-	PresignedPutURL(ctx context.Context, key string, ttl time.Duration) (url string, expiresAt time.Time, err error)
-	PresignedGetURL(ctx context.Context, key string, ttl time.Duration) (url string, expiresAt time.Time, err error)
+	PresignedPutURL(ctx context.Context, key string, c PutConstraints, ttl time.Duration) (*PresignedRequest, error)
+	PresignedGetURL(ctx context.Context, key string, o GetOptions, ttl time.Duration) (*PresignedRequest, error)
 	CreateMultipartUpload(ctx context.Context, key, contentType string) (uploadID string, err error)
-	PresignedPartURL(ctx context.Context, key, uploadID string, partNumber int32, ttl time.Duration) (url string, expiresAt time.Time, err error)
+	// PresignedPartURL signs sizeBytes into the URL as the part's required length.
+	PresignedPartURL(ctx context.Context, key, uploadID string, partNumber int32, sizeBytes int64, ttl time.Duration) (*PresignedRequest, error)
+	// UploadPart stores sizeBytes bytes read from body as the given part and
+	// returns its ETag. It returns ErrSessionNotFound if the upload no longer
+	// exists.
+	UploadPart(ctx context.Context, key, uploadID string, partNumber int32, body io.Reader, sizeBytes int64) (etag string, err error)
 	CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error
+	// AbortMultipartUpload is a no-op if the upload no longer exists.
 	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
+	ListMultipartUploads(ctx context.Context) ([]MultipartUpload, error)
+	// ListParts returns ErrSessionNotFound if the upload no longer exists.
+	ListParts(ctx context.Context, key, uploadID string) ([]UploadedPart, error)
+
+	// StatObject returns ErrObjectNotFound if no object exists under key.
+	StatObject(ctx context.Context, key string) (*ObjectInfo, error)
+	// GetObject returns ErrObjectNotFound if no object exists under key.
+	GetObject(ctx context.Context, key string) (io.ReadCloser, error)
+	// GetObjectRange reads length bytes starting at offset, or to the end of
+	// the object when length is negative. It returns ErrObjectNotFound if no
+	// object exists under key.
+	GetObjectRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	// PutObject stores sizeBytes bytes read from body under key. The backend
+	// rejects the body if it does not hash to checksumSHA256 (lowercase hex).
+	PutObject(ctx context.Context, key string, body io.Reader, sizeBytes int64, contentType, checksumSHA256 string) error
+	// DeleteObject is a no-op if no object exists under key.
+	DeleteObject(ctx context.Context, key string) error
+	// ListObjects returns stored objects ordered by key and starting after
+	// afterKey.
+	ListObjects(ctx context.Context, afterKey string, limit int) ([]StoredObject, error)
+
+	// WithCustomerKey returns a view of the store that encrypts the objects
+	// it writes, and decrypts the objects it reads, with a customer-provided
+	// key (SSE-C). Presigned requests from the view carry the key in their
+	// required headers. Objects written through a view can only be read
+	// through a view with the same key.
+	WithCustomerKey(key []byte) ObjectStorage
+}
+
+// ReplicatedStorage is an ObjectStorage that keeps copies of committed
+// objects in a secondary store. Writes and presigned requests go to the
+// primary store; reads fall back to the secondary when the primary fails,
+// and deletes remove both copies. Its WithCustomerKey views are
+// ReplicatedStorage as well.
+type ReplicatedStorage interface {
+	ObjectStorage
+	// Replicate copies the object under key from the primary store to the
+	// secondary, which rejects it unless it hashes to checksumSHA256.
+	Replicate(ctx context.Context, key string, sizeBytes int64, contentType, checksumSHA256 string) error
+	// Failover returns the secondary store while the primary is failing its
+	// health check, and nil while the primary is healthy.
+	Failover(ctx context.Context) ObjectStorage
 }
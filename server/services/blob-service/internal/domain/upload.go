@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"crypto/rand"
+	"strings"
+	"time"
+)
+
+// Upload is a caller's upload of a blob's content that has not verified yet.
+// Each upload is stored under an object key of its own, so uploads of the
+// same content by different callers never share bytes, and a caller only
+// gets a reference to the blob once its own upload hashes to the blob ID.
+type Upload struct {
+	BlobID      BlobID
+	Subject     string
+	ClientID    string
+	ObjectKey   string
+	SizeBytes   int64
+	ContentType string
+	// KeyID and WrappedKey are the data key the upload is encrypted under,
+	// and become the blob's if the upload creates it.
+	KeyID      string
+	WrappedKey []byte
+	CreatedAt  time.Time
+}
+
+func NewUpload(id BlobID, caller Caller, sizeBytes int64, contentType string, now time.Time) (*Upload, error) {
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validateSize(sizeBytes); err != nil {
+		return nil, err
+	}
+	return &Upload{
+		BlobID:      id,
+		Subject:     caller.Subject,
+		ClientID:    caller.ClientID,
+		ObjectKey:   NewObjectKey(id),
+		SizeBytes:   sizeBytes,
+		ContentType: contentType,
+		CreatedAt:   now,
+	}, nil
+}
+
+// NewObjectKey returns a key to store content of the blob under that no
+// other object has been or will be stored under.
+func NewObjectKey(id BlobID) string {
+	return string(id) + "." + strings.ToLower(rand.Text())
+}
+
+// Blob returns the PENDING blob the upload stands for, stored under the
+// upload's key.
+func (u *Upload) Blob() *Blob {
+	return &Blob{
+		ID:          u.BlobID,
+		SizeBytes:   u.SizeBytes,
+		ContentType: u.ContentType,
+		R2Key:       u.ObjectKey,
+		State:       StatePending,
+		CreatedAt:   u.CreatedAt,
+		KeyID:       u.KeyID,
+		WrappedKey:  u.WrappedKey,
+	}
+}
@@ -0,0 +1,77 @@
+// Package keyring wraps blob data keys under AES-256-GCM master keys.
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+// Keyring wraps new data keys under its current master key and unwraps data
+// keys wrapped under the current or any previous one. A master key's ID is
+// derived from the key itself, so configuration only lists the keys.
+type Keyring struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+var _ domain.KeyWrapper = (*Keyring)(nil)
+
+func New(current [32]byte, previous ...[32]byte) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]cipher.AEAD, 1+len(previous))}
+	for i, key := range append([][32]byte{current}, previous...) {
+		block, err := aes.NewCipher(key[:])
+		if err != nil {
+			return nil, fmt.Errorf("aes cipher: %w", err)
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("gcm: %w", err)
+		}
+		id := KeyID(key)
+		if i == 0 {
+			k.active = id
+		}
+		k.keys[id] = gcm
+	}
+	return k, nil
+}
+
+// KeyID identifies a master key without revealing it.
+func KeyID(key [32]byte) string {
+	sum := sha256.Sum256(key[:])
+	return hex.EncodeToString(sum[:8])
+}
+
+func (k *Keyring) ActiveKeyID() string { return k.active }
+
+func (k *Keyring) Wrap(id domain.BlobID, dataKey []byte) (string, []byte, error) {
+	gcm := k.keys[k.active]
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, fmt.Errorf("nonce: %w", err)
+	}
+	return k.active, gcm.Seal(nonce, nonce, dataKey, []byte(id)), nil
+}
+
+func (k *Keyring) Unwrap(id domain.BlobID, keyID string, wrapped []byte) ([]byte, error) {
+	gcm, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnknownMasterKey, keyID)
+	}
+	nonceSize := gcm.NonceSize()
+	if len(wrapped) < nonceSize {
+		return nil, fmt.Errorf("wrapped key too short")
+	}
+	nonce, ciphertext := wrapped[:nonceSize], wrapped[nonceSize:]
+	dataKey, err := gcm.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("unwrap: %w", err)
+	}
+	return dataKey, nil
+}
@@ -0,0 +1,84 @@
+package keyring_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/keyring"
+)
+
+var (
+	blobA = domain.BlobID(strings.Repeat("a", 64))
+	blobB = domain.BlobID(strings.Repeat("b", 64))
+)
+
+func testKey(t *testing.T) [32]byte {
+	t.Helper()
+	var key [32]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestWrapUnwrap_RoundTrip(t *testing.T) {
+	k, err := keyring.New(testKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataKey := bytes.Repeat([]byte{7}, domain.DataKeySize)
+
+	keyID, wrapped, err := k.Wrap(blobA, dataKey)
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	if keyID != k.ActiveKeyID() {
+		t.Errorf("expected key ID %s, got %s", k.ActiveKeyID(), keyID)
+	}
+	if bytes.Contains(wrapped, dataKey) {
+		t.Error("wrapped key contains the data key")
+	}
+
+	got, err := k.Unwrap(blobA, keyID, wrapped)
+	if err != nil {
+		t.Fatalf("Unwrap: %v", err)
+	}
+	if !bytes.Equal(got, dataKey) {
+		t.Errorf("expected %x, got %x", dataKey, got)
+	}
+}
+
+func TestUnwrap_BoundToBlob(t *testing.T) {
+	k, _ := keyring.New(testKey(t))
+	keyID, wrapped, _ := k.Wrap(blobA, make([]byte, domain.DataKeySize))
+
+	if _, err := k.Unwrap(blobB, keyID, wrapped); err == nil {
+		t.Fatal("expected a key wrapped for one blob not to unwrap for another")
+	}
+}
+
+func TestUnwrap_PreviousKey(t *testing.T) {
+	oldKey, newKey := testKey(t), testKey(t)
+	old, _ := keyring.New(oldKey)
+	keyID, wrapped, _ := old.Wrap(blobA, make([]byte, domain.DataKeySize))
+
+	rotated, err := keyring.New(newKey, oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.ActiveKeyID() == keyID {
+		t.Fatal("expected the current key to be active")
+	}
+	if _, err := rotated.Unwrap(blobA, keyID, wrapped); err != nil {
+		t.Errorf("expected a previous key to unwrap: %v", err)
+	}
+
+	retired, _ := keyring.New(newKey)
+	if _, err := retired.Unwrap(blobA, keyID, wrapped); !errors.Is(err, domain.ErrUnknownMasterKey) {
+		t.Errorf("expected ErrUnknownMasterKey, got %v", err)
+	}
+}
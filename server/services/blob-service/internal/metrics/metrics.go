@@ -0,0 +1,108 @@
+// Package metrics collects blob-service's Prometheus metrics and serves them
+// in the text exposition format.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/codes"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+// Metrics holds the service's collectors, registered on a registry of its
+// own alongside the Go runtime and process collectors.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	rpcRequests     *prometheus.CounterVec
+	rpcDuration     *prometheus.HistogramVec
+	uploadBytes     prometheus.Counter
+	uploads         prometheus.Counter
+	presigned       *prometheus.CounterVec
+	storageDuration *prometheus.HistogramVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		rpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "blob_grpc_requests_total",
+			Help: "gRPC requests handled, by method and status code.",
+		}, []string{"service", "method", "code"}),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "blob_grpc_request_duration_seconds",
+			Help:    "Time taken to handle gRPC requests. Streams are timed until they end.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"service", "method"}),
+		uploadBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "blob_upload_bytes_total",
+			Help: "Bytes of uploads verified against their blob ID.",
+		}),
+		uploads: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "blob_uploads_total",
+			Help: "Uploads verified against their blob ID.",
+		}),
+		presigned: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "blob_presigned_urls_total",
+			Help: "Presigned URLs issued, by operation.",
+		}, []string{"operation"}),
+		storageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "blob_storage_call_duration_seconds",
+			Help:    "Time taken by object storage calls, by store, operation and outcome.",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"store", "operation", "outcome"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.rpcRequests, m.rpcDuration,
+		m.uploadBytes, m.uploads,
+		m.presigned, m.storageDuration,
+	)
+	return m
+}
+
+// Handler serves the metrics for scraping.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRPC records a finished gRPC call to fullMethod, e.g.
+// "/blob.v1.BlobService/GetBlobInfo".
+func (m *Metrics) ObserveRPC(fullMethod string, code codes.Code, d time.Duration) {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	m.rpcRequests.WithLabelValues(service, method, code.String()).Inc()
+	m.rpcDuration.WithLabelValues(service, method).Observe(d.Seconds())
+}
+
+// UploadVerified records an upload of sizeBytes that hashed to its blob ID.
+func (m *Metrics) UploadVerified(sizeBytes int64) {
+	m.uploads.Inc()
+	m.uploadBytes.Add(float64(sizeBytes))
+}
+
+// Presigned records a presigned URL issued for operation.
+func (m *Metrics) Presigned(operation string) {
+	m.presigned.WithLabelValues(operation).Inc()
+}
+
+// ObserveStorage records a call to the named store. Objects and uploads
+// found missing are an answer rather than a failure, and are recorded as
+// not_found.
+func (m *Metrics) ObserveStorage(store, operation string, d time.Duration, err error) {
+	outcome := "ok"
+	switch {
+	case errors.Is(err, domain.ErrObjectNotFound), errors.Is(err, domain.ErrSessionNotFound):
+		outcome = "not_found"
+	case err != nil:
+		outcome = "error"
+	}
+	m.storageDuration.WithLabelValues(store, operation, outcome).Observe(d.Seconds())
+}
@@ -0,0 +1,47 @@
+package metrics_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/metrics"
+)
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_Exposition(t *testing.T) {
+	m := metrics.New()
+	m.ObserveRPC("/blob.v1.BlobService/GetBlobInfo", codes.NotFound, 20*time.Millisecond)
+	m.UploadVerified(1024)
+	m.UploadVerified(76)
+	m.Presigned("put")
+	m.ObserveStorage("primary", "stat_object", time.Millisecond, domain.ErrObjectNotFound)
+	m.ObserveStorage("primary", "put_object", time.Millisecond, errors.New("connection reset"))
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `blob_grpc_requests_total{code="NotFound",method="GetBlobInfo",service="blob.v1.BlobService"} 1`)
+	assert.Contains(t, body, `blob_grpc_request_duration_seconds_count{method="GetBlobInfo",service="blob.v1.BlobService"} 1`)
+	assert.Contains(t, body, "blob_upload_bytes_total 1100")
+	assert.Contains(t, body, "blob_uploads_total 2")
+	assert.Contains(t, body, `blob_presigned_urls_total{operation="put"} 1`)
+	assert.Contains(t, body, `blob_storage_call_duration_seconds_count{operation="stat_object",outcome="not_found",store="primary"} 1`)
+	assert.Contains(t, body, `blob_storage_call_duration_seconds_count{operation="put_object",outcome="error",store="primary"} 1`)
+	assert.Contains(t, body, "go_goroutines")
+}
@@ -0,0 +1,98 @@
+// Package rendition derives resized images from image blobs using only the
+// standard library and golang.org/x/image.
+package rendition
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+const (
+	// MaxSourcePixels bounds the decoded size of a source image, so that a
+	// small, highly compressed file cannot exhaust memory when decoded.
+	MaxSourcePixels = 50_000_000
+
+	jpegQuality = 85
+)
+
+var sourceTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// CanRender reports whether the blob can be rendered, judging by its sniffed
+// content type. Blobs committed before sniffing fall back to their declared
+// one.
+func CanRender(b *domain.Blob) bool {
+	if b.DetectedContentType != "" {
+		return sourceTypes[b.DetectedContentType]
+	}
+	return sourceTypes[b.ContentType]
+}
+
+// Render decodes src, scales it to fit spec and encodes it in the spec's
+// format. Images with transparency are flattened onto white for JPEG. It
+// returns ErrNotRenderable if src is not an image it can decode.
+func Render(src []byte, spec domain.RenditionSpec) ([]byte, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrNotRenderable, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > MaxSourcePixels {
+		return nil, fmt.Errorf("%w: %dx%d image exceeds %d pixels", domain.ErrNotRenderable, cfg.Width, cfg.Height, MaxSourcePixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrNotRenderable, err)
+	}
+
+	w, h := fit(img.Bounds().Dx(), img.Bounds().Dy(), spec.MaxDimension)
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	if w == img.Bounds().Dx() && h == img.Bounds().Dy() {
+		draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Src)
+	} else {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+	}
+
+	var buf bytes.Buffer
+	switch spec.Format {
+	case domain.RenditionJPEG:
+		flat := image.NewRGBA(dst.Bounds())
+		draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(flat, flat.Bounds(), dst, image.Point{}, draw.Over)
+		err = jpeg.Encode(&buf, flat, &jpeg.Options{Quality: jpegQuality})
+	case domain.RenditionWebP:
+		err = encodeWebP(&buf, dst)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fit scales w×h down, keeping the aspect ratio, until neither side exceeds
+// limit. Images that already fit are left at their size.
+func fit(w, h, limit int) (int, int) {
+	if w <= limit && h <= limit {
+		return w, h
+	}
+	if w >= h {
+		return limit, max(1, (h*limit+w/2)/w)
+	}
+	return max(1, (w*limit+h/2)/h), limit
+}
@@ -0,0 +1,140 @@
+package rendition_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math/bits"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/rendition"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func testImages() map[string]*image.NRGBA {
+	rng := rand.New(rand.NewPCG(1, 2))
+	noise := image.NewNRGBA(image.Rect(0, 0, 61, 37))
+	for i := range noise.Pix {
+		noise.Pix[i] = uint8(rng.UintN(256))
+	}
+
+	gradient := image.NewNRGBA(image.Rect(0, 0, 200, 50))
+	for y := range 50 {
+		for x := range 200 {
+			gradient.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y * 5), B: uint8(x + y), A: 255})
+		}
+	}
+
+	flat := image.NewNRGBA(image.Rect(0, 0, 300, 7))
+	for y := range 7 {
+		for x := range 300 {
+			c := color.NRGBA{R: 10, G: 200, B: 30, A: 255}
+			if x > 150 {
+				c = color.NRGBA{A: 0}
+			}
+			flat.SetNRGBA(x, y, c)
+		}
+	}
+
+	// Geometrically distributed values push the optimal Huffman code past
+	// the format's 15-bit limit.
+	skewed := image.NewNRGBA(image.Rect(0, 0, 256, 256))
+	for i := range skewed.Pix {
+		skewed.Pix[i] = uint8(bits.TrailingZeros32(rng.Uint32()|1<<24) * 9)
+	}
+
+	single := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	single.SetNRGBA(0, 0, color.NRGBA{R: 1, G: 2, B: 3, A: 4})
+
+	return map[string]*image.NRGBA{"noise": noise, "gradient": gradient, "flat": flat, "skewed": skewed, "single": single}
+}
+
+func TestRender_WebPIsLossless(t *testing.T) {
+	spec := domain.RenditionSpec{Format: domain.RenditionWebP, MaxDimension: domain.MaxRenditionDimension}
+	for name, img := range testImages() {
+		t.Run(name, func(t *testing.T) {
+			out, err := rendition.Render(encodePNG(t, img), spec)
+			require.NoError(t, err)
+
+			decoded, err := webp.Decode(bytes.NewReader(out))
+			require.NoError(t, err)
+			require.Equal(t, img.Bounds(), decoded.Bounds())
+			for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+				for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+					want := img.NRGBAAt(x, y)
+					got := color.NRGBAModel.Convert(decoded.At(x, y)).(color.NRGBA)
+					if want.A == 0 {
+						assert.Zero(t, got.A)
+						continue
+					}
+					require.Equal(t, want, got, "pixel (%d, %d)", x, y)
+				}
+			}
+		})
+	}
+}
+
+func TestRender_ScalesToFit(t *testing.T) {
+	src := encodePNG(t, testImages()["gradient"])
+
+	out, err := rendition.Render(src, domain.RenditionSpec{Format: domain.RenditionJPEG, MaxDimension: 64})
+	require.NoError(t, err)
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 64, 16), img.Bounds())
+
+	out, err = rendition.Render(src, domain.RenditionSpec{Format: domain.RenditionWebP, MaxDimension: 20})
+	require.NoError(t, err)
+	cfg, err := webp.DecodeConfig(bytes.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, 20, cfg.Width)
+	assert.Equal(t, 5, cfg.Height)
+}
+
+func TestRender_FlattensTransparency(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+
+	out, err := rendition.Render(encodePNG(t, src), domain.RenditionSpec{Format: domain.RenditionJPEG, MaxDimension: 64})
+	require.NoError(t, err)
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	require.NoError(t, err)
+	r, g, b, _ := img.At(4, 4).RGBA()
+	assert.Greater(t, min(r, g, b), uint32(0xf000), "transparent pixels come out white")
+}
+
+func TestRender_NotAnImage(t *testing.T) {
+	_, err := rendition.Render([]byte("%PDF-1.7"), domain.RenditionSpec{Format: domain.RenditionJPEG, MaxDimension: 64})
+	assert.ErrorIs(t, err, domain.ErrNotRenderable)
+}
+
+func TestRender_RejectsUnknownFormat(t *testing.T) {
+	_, err := rendition.Render([]byte("%PDF-1.7"), domain.RenditionSpec{Format: "png", MaxDimension: 64})
+	assert.ErrorIs(t, err, domain.ErrUnknownRendition, "the format is checked before the source is decoded")
+}
+
+func TestRender_RejectsHugeImages(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 10_000, 10_000))))
+	_, err := rendition.Render(buf.Bytes(), domain.RenditionSpec{Format: domain.RenditionJPEG, MaxDimension: 64})
+	assert.ErrorIs(t, err, domain.ErrNotRenderable)
+}
+
+func TestCanRender_UsesSniffedType(t *testing.T) {
+	assert.True(t, rendition.CanRender(&domain.Blob{ContentType: "image/png"}), "blobs without a sniffed type fall back to the declared one")
+	assert.False(t, rendition.CanRender(&domain.Blob{ContentType: "image/png", DetectedContentType: "text/html; charset=utf-8"}))
+	assert.True(t, rendition.CanRender(&domain.Blob{ContentType: "application/octet-stream", DetectedContentType: "image/gif"}))
+}
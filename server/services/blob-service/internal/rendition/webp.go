@@ -0,0 +1,434 @@
+package rendition
+
+import (
+	"encoding/binary"
+	"image"
+	"io"
+	"math/bits"
+	"sort"
+)
+
+// encodeWebP writes img as a lossless (VP8L) WebP. The encoder is
+// deliberately small: it applies the subtract-green transform, codes runs
+// that repeat the pixel to the left or the row above as backward
+// references, and Huffman-codes everything else as literals. That is enough
+// for thumbnails without pulling in a cgo dependency on libwebp.
+func encodeWebP(w io.Writer, img *image.NRGBA) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+
+	argb := make([]uint32, 0, width*height)
+	opaque := true
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(b.Min.X, y):]
+		for x := 0; x < width; x++ {
+			r, g, bl, a := row[4*x], row[4*x+1], row[4*x+2], row[4*x+3]
+			if a != 0xff {
+				opaque = false
+			}
+			// Subtract green: red and blue are coded relative to green.
+			argb = append(argb, uint32(a)<<24|uint32(r-g)<<16|uint32(g)<<8|uint32(bl-g))
+		}
+	}
+
+	tokens := tokenize(argb, width)
+	var freq [5][]uint32
+	for i, size := range alphabetSizes {
+		freq[i] = make([]uint32, size)
+	}
+	for _, t := range tokens {
+		if t.length == 0 {
+			freq[huffGreen][t.argb>>8&0xff]++
+			freq[huffRed][t.argb>>16&0xff]++
+			freq[huffBlue][t.argb&0xff]++
+			freq[huffAlpha][t.argb>>24]++
+			continue
+		}
+		lp, _, _ := prefixEncode(t.length)
+		dp, _, _ := prefixEncode(t.distCode)
+		freq[huffGreen][256+lp]++
+		freq[huffDistance][dp]++
+	}
+
+	var bw bitWriter
+	bw.write(vp8lSignature, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if opaque {
+		bw.write(0, 1)
+	} else {
+		bw.write(1, 1)
+	}
+	bw.write(0, 3) // version
+	bw.write(1, 1) // a transform follows
+	bw.write(transformSubtractGreen, 2)
+	bw.write(0, 1) // no more transforms
+	bw.write(0, 1) // no color cache
+	bw.write(0, 1) // a single set of prefix codes for the whole image
+
+	var codes [5]prefixCode
+	for i := range codes {
+		codes[i] = writePrefixCode(&bw, freq[i])
+	}
+	for _, t := range tokens {
+		if t.length == 0 {
+			codes[huffGreen].write(&bw, t.argb>>8&0xff)
+			codes[huffRed].write(&bw, t.argb>>16&0xff)
+			codes[huffBlue].write(&bw, t.argb&0xff)
+			codes[huffAlpha].write(&bw, t.argb>>24)
+			continue
+		}
+		lp, ln, lx := prefixEncode(t.length)
+		codes[huffGreen].write(&bw, 256+lp)
+		bw.write(lx, uint(ln))
+		dp, dn, dx := prefixEncode(t.distCode)
+		codes[huffDistance].write(&bw, dp)
+		bw.write(dx, uint(dn))
+	}
+	data := bw.flush()
+
+	pad := len(data) & 1
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(12+len(data)+pad))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(data)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if pad == 1 {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
+
+const (
+	vp8lSignature          = 0x2f
+	transformSubtractGreen = 2
+
+	huffGreen    = 0
+	huffRed      = 1
+	huffBlue     = 2
+	huffAlpha    = 3
+	huffDistance = 4
+
+	maxCodeLength       = 15
+	maxCodeLengthLength = 7
+	minBackRef          = 3
+	maxBackRef          = 4096
+
+	// Distance codes for the two neighbours a backward reference may copy
+	// from, from the spec's 2D distance table.
+	distCodeUp   = 1
+	distCodeLeft = 2
+)
+
+// alphabetSizes are the sizes of the green (literals plus 24 length
+// prefixes), red, blue, alpha and distance alphabets.
+var alphabetSizes = [5]int{256 + 24, 256, 256, 256, 40}
+
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// token is either a literal pixel (length 0) or a backward reference of
+// length pixels.
+type token struct {
+	argb     uint32
+	length   uint32
+	distCode uint32
+}
+
+func tokenize(argb []uint32, width int) []token {
+	tokens := make([]token, 0, len(argb)/2)
+	for i := 0; i < len(argb); {
+		var left, up int
+		if i > 0 {
+			for left < maxBackRef && i+left < len(argb) && argb[i+left] == argb[i-1] {
+				left++
+			}
+		}
+		if i >= width {
+			for up < maxBackRef && i+up < len(argb) && argb[i+up] == argb[i+up-width] {
+				up++
+			}
+		}
+		switch {
+		case left >= up && left >= minBackRef:
+			tokens = append(tokens, token{length: uint32(left), distCode: distCodeLeft})
+			i += left
+		case up >= minBackRef:
+			tokens = append(tokens, token{length: uint32(up), distCode: distCodeUp})
+			i += up
+		default:
+			tokens = append(tokens, token{argb: argb[i]})
+			i++
+		}
+	}
+	return tokens
+}
+
+// prefixEncode splits a length or distance code into the prefix symbol and
+// the extra bits that follow it.
+func prefixEncode(v uint32) (prefix, nbits, extra uint32) {
+	if v <= 4 {
+		return v - 1, 0, 0
+	}
+	d := v - 1
+	h := uint32(bits.Len32(d)) - 1
+	second := d >> (h - 1) & 1
+	return 2*h + second, h - 1, d & (1<<(h-1) - 1)
+}
+
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32 // bit-reversed, ready to be written LSB first
+}
+
+func (c *prefixCode) write(bw *bitWriter, symbol uint32) {
+	bw.write(c.codes[symbol], uint(c.lengths[symbol]))
+}
+
+// writePrefixCode writes the prefix code for freq and returns it. Alphabets
+// with at most two symbols below 256 use the spec's simple code; the rest
+// are written as code lengths, themselves Huffman-coded.
+func writePrefixCode(bw *bitWriter, freq []uint32) prefixCode {
+	var used []uint32
+	for s, f := range freq {
+		if f > 0 {
+			used = append(used, uint32(s))
+		}
+	}
+	if len(used) == 0 {
+		used = []uint32{0}
+	}
+
+	code := prefixCode{lengths: make([]uint8, len(freq)), codes: make([]uint32, len(freq))}
+	if len(used) <= 2 && used[len(used)-1] < 256 {
+		bw.write(1, 1)
+		bw.write(uint32(len(used)-1), 1)
+		if used[0] < 2 {
+			bw.write(0, 1)
+			bw.write(used[0], 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(used[0], 8)
+		}
+		if len(used) == 2 {
+			bw.write(used[1], 8)
+			code.lengths[used[0]], code.lengths[used[1]] = 1, 1
+			code.codes[used[1]] = 1
+		}
+		return code
+	}
+
+	lengths := huffmanLengths(freq, maxCodeLength)
+	code.lengths = lengths
+	code.codes = canonicalCodes(lengths)
+
+	type clToken struct{ symbol, nbits, extra uint32 }
+	var (
+		cls    []clToken
+		clFreq = make([]uint32, 19)
+	)
+	emit := func(symbol, nbits, extra uint32) {
+		cls = append(cls, clToken{symbol, nbits, extra})
+		clFreq[symbol]++
+	}
+	for i := 0; i < len(lengths); {
+		l := lengths[i]
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == l {
+			run++
+		}
+		i += run
+		if l == 0 {
+			for run > 0 {
+				switch {
+				case run >= 11:
+					n := min(run, 138)
+					emit(18, 7, uint32(n-11))
+					run -= n
+				case run >= 3:
+					emit(17, 3, uint32(run-3))
+					run = 0
+				default:
+					emit(0, 0, 0)
+					run--
+				}
+			}
+			continue
+		}
+		emit(uint32(l), 0, 0)
+		for run--; run > 0; {
+			if run < 3 {
+				emit(uint32(l), 0, 0)
+				run--
+				continue
+			}
+			n := min(run, 6)
+			emit(16, 2, uint32(n-3))
+			run -= n
+		}
+	}
+
+	// A one-symbol code has no bits to read, so make sure the code length
+	// code has two.
+	if nonzero(clFreq) == 1 {
+		if clFreq[0] == 0 {
+			clFreq[0] = 1
+		} else {
+			clFreq[1] = 1
+		}
+	}
+	clLengths := huffmanLengths(clFreq, maxCodeLengthLength)
+	clCodes := canonicalCodes(clLengths)
+
+	n := len(codeLengthCodeOrder)
+	for n > 4 && clLengths[codeLengthCodeOrder[n-1]] == 0 {
+		n--
+	}
+	bw.write(0, 1) // normal code
+	bw.write(uint32(n-4), 4)
+	for _, s := range codeLengthCodeOrder[:n] {
+		bw.write(uint32(clLengths[s]), 3)
+	}
+	bw.write(0, 1) // code lengths cover the whole alphabet
+	for _, t := range cls {
+		bw.write(clCodes[t.symbol], uint(clLengths[t.symbol]))
+		bw.write(t.extra, uint(t.nbits))
+	}
+	return code
+}
+
+func nonzero(freq []uint32) int {
+	n := 0
+	for _, f := range freq {
+		if f > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// huffmanLengths returns Huffman code lengths for freq no longer than limit.
+// When the optimal code is too deep the frequencies are flattened and the
+// code rebuilt, which costs a little compression on pathological inputs.
+func huffmanLengths(freq []uint32, limit int) []uint8 {
+	weights := append([]uint32(nil), freq...)
+	for {
+		lengths, depth := huffmanTree(weights)
+		if depth <= limit {
+			return lengths
+		}
+		for i, w := range weights {
+			if w > 0 {
+				weights[i] = (w + 1) / 2
+			}
+		}
+	}
+}
+
+func huffmanTree(weights []uint32) ([]uint8, int) {
+	lengths := make([]uint8, len(weights))
+	var leaves []int
+	for s, w := range weights {
+		if w > 0 {
+			leaves = append(leaves, s)
+		}
+	}
+	if len(leaves) < 2 {
+		for _, s := range leaves {
+			lengths[s] = 1
+		}
+		return lengths, 1
+	}
+	sort.SliceStable(leaves, func(i, j int) bool { return weights[leaves[i]] < weights[leaves[j]] })
+
+	// Two-queue construction: leaves sorted by weight, followed by internal
+	// nodes, which are created in non-decreasing weight order.
+	type node struct {
+		weight uint64
+		parent int
+	}
+	n := len(leaves)
+	nodes := make([]node, n, 2*n-1)
+	for i, s := range leaves {
+		nodes[i] = node{weight: uint64(weights[s])}
+	}
+	nextLeaf, nextInternal := 0, n
+	pick := func() int {
+		if nextLeaf < n && (nextInternal >= len(nodes) || nodes[nextLeaf].weight <= nodes[nextInternal].weight) {
+			nextLeaf++
+			return nextLeaf - 1
+		}
+		nextInternal++
+		return nextInternal - 1
+	}
+	for len(nodes) < 2*n-1 {
+		a, b := pick(), pick()
+		nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight})
+		nodes[a].parent, nodes[b].parent = len(nodes)-1, len(nodes)-1
+	}
+
+	depths := make([]int, len(nodes))
+	maxDepth := 0
+	for i := len(nodes) - 2; i >= 0; i-- {
+		depths[i] = depths[nodes[i].parent] + 1
+		if i < n {
+			lengths[leaves[i]] = uint8(depths[i])
+			maxDepth = max(maxDepth, depths[i])
+		}
+	}
+	return lengths, maxDepth
+}
+
+// canonicalCodes assigns canonical codes to lengths and returns them
+// bit-reversed, since the bit stream is read LSB first.
+func canonicalCodes(lengths []uint8) []uint32 {
+	var count [maxCodeLength + 1]uint32
+	for _, l := range lengths {
+		count[l]++
+	}
+	count[0] = 0
+	var next [maxCodeLength + 1]uint32
+	code := uint32(0)
+	for l := 1; l <= maxCodeLength; l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+	}
+	codes := make([]uint32, len(lengths))
+	for s, l := range lengths {
+		if l > 0 {
+			codes[s] = bits.Reverse32(next[l]) >> (32 - uint(l))
+			next[l]++
+		}
+	}
+	return codes
+}
+
+type bitWriter struct {
+	buf  []byte
+	acc  uint64
+	nacc uint
+}
+
+func (w *bitWriter) write(v uint32, n uint) {
+	w.acc |= uint64(v) << w.nacc
+	w.nacc += n
+	for w.nacc >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nacc -= 8
+	}
+}
+
+func (w *bitWriter) flush() []byte {
+	if w.nacc > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nacc = 0, 0
+	}
+	return w.buf
+}
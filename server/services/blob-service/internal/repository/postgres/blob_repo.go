@@ -1,10 +1,13 @@
 package postgres
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -22,20 +25,29 @@ func New(db *sqlx.DB) *BlobRepo {
 }
 
 type blobRow struct {
-	ID          string       `db:"id"`
-	SizeBytes   int64        `db:"size_bytes"`
-	ContentType string       `db:"content_type"`
-	R2Key       string       `db:"r2_key"`
-	State       string       `db:"state"`
-	CreatedAt   time.Time    `db:"created_at"`
-	CommittedAt sql.NullTime `db:"committed_at"`
+	ID                  string       `db:"id"`
+	SizeBytes           int64        `db:"size_bytes"`
+	ContentType         string       `db:"content_type"`
+	DetectedContentType string       `db:"detected_content_type"`
+	R2Key               string       `db:"r2_key"`
+	State               string       `db:"state"`
+	CreatedAt           time.Time    `db:"created_at"`
+	CommittedAt         sql.NullTime `db:"committed_at"`
+	ReleasedAt          sql.NullTime `db:"released_at"`
+	QuarantineReason    string       `db:"quarantine_reason"`
+	KeyID               string       `db:"key_id"`
+	WrappedKey          []byte       `db:"wrapped_key"`
+	ReplicatedAt        sql.NullTime `db:"replicated_at"`
+	RetainUntil         sql.NullTime `db:"retain_until"`
+	LegalHold           bool         `db:"legal_hold"`
 }
 
+const blobColumns = `id, size_bytes, content_type, detected_content_type, r2_key, state, created_at, committed_at, released_at, quarantine_reason, key_id, wrapped_key, replicated_at, retain_until, legal_hold`
+
 func (r *BlobRepo) FindByID(ctx context.Context, id domain.BlobID) (*domain.Blob, error) {
 	var row blobRow
 	err := r.db.GetContext(ctx, &row,
-		`SELECT id, size_bytes, content_type, r2_key, state, created_at, committed_at
-		 FROM blobs WHERE id = $1`, string(id))
+		`SELECT `+blobColumns+` FROM blobs WHERE id = $1`, string(id))
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, domain.ErrBlobNotFound
 	}
@@ -47,9 +59,9 @@ func (r *BlobRepo) FindByID(ctx context.Context, id domain.BlobID) (*domain.Blob
 
 func (r *BlobRepo) Create(ctx context.Context, b *domain.Blob) error {
 	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO blobs (id, size_bytes, content_type, r2_key, state, created_at)
-		 VALUES ($1, $2, $3, $4, $5, $6)`,
-		string(b.ID), b.SizeBytes, b.ContentType, b.R2Key, string(b.State), b.CreatedAt,
+		`INSERT INTO blobs (id, size_bytes, content_type, r2_key, state, created_at, key_id, wrapped_key)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		string(b.ID), b.SizeBytes, b.ContentType, b.R2Key, string(b.State), b.CreatedAt, b.KeyID, b.WrappedKey,
 	)
 	if err != nil {
 		var pqErr *pq.Error
@@ -61,37 +73,1211 @@ func (r *BlobRepo) Create(ctx context.Context, b *domain.Blob) error {
 	return nil
 }
 
-func (r *BlobRepo) MarkCommitted(ctx context.Context, id domain.BlobID, at time.Time) error {
+func (r *BlobRepo) MarkCommitted(ctx context.Context, id domain.BlobID, detectedContentType string, at time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("blobs.MarkCommitted begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var row eventRow
+	err = tx.GetContext(ctx, &row,
+		`UPDATE blobs SET state = 'COMMITTED', committed_at = $2, detected_content_type = $3
+		 WHERE id = $1 AND state IN ('PENDING', 'SCANNING')
+		 RETURNING size_bytes, content_type`,
+		string(id), at, detectedContentType,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("blobs.MarkCommitted: %w", domain.ErrAlreadyCommitted)
+	}
+	if err != nil {
+		return fmt.Errorf("blobs.MarkCommitted: %w", err)
+	}
+
+	// References taken while the blob was pending are charged now. The
+	// UPDATE above holds the row lock that AddReference and RemoveReference
+	// wait on, so none of them can race this charge.
+	if err := chargeReferences(ctx, tx, id, 1); err != nil {
+		return err
+	}
+	if err := recordEvent(ctx, tx, domain.EventCommitted, id, row.SizeBytes, row.ContentType); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("blobs.MarkCommitted commit: %w", err)
+	}
+	return nil
+}
+
+func (r *BlobRepo) MarkQuarantined(ctx context.Context, id domain.BlobID, reason string) error {
 	res, err := r.db.ExecContext(ctx,
-		`UPDATE blobs SET state = 'COMMITTED', committed_at = $2
-		 WHERE id = $1 AND state = 'PENDING'`,
+		`UPDATE blobs SET state = 'QUARANTINED', quarantine_reason = $2
+		 WHERE id = $1 AND state = 'SCANNING'`,
+		string(id), reason,
+	)
+	return checkTransition(res, err, "blobs.MarkQuarantined")
+}
+
+func (r *BlobRepo) MarkMissing(ctx context.Context, id domain.BlobID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("blobs.MarkMissing begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var state domain.UploadState
+	err = tx.GetContext(ctx, &state,
+		`SELECT state FROM blobs WHERE id = $1 FOR UPDATE`, string(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("blobs.MarkMissing: %w", domain.ErrAlreadyCommitted)
+	}
+	if err != nil {
+		return fmt.Errorf("blobs.MarkMissing: %w", err)
+	}
+	if state != domain.StateCommitted && state != domain.StateScanning {
+		return fmt.Errorf("blobs.MarkMissing: %w", domain.ErrAlreadyCommitted)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE blobs SET state = 'MISSING', committed_at = NULL, replicated_at = NULL
+		 WHERE id = $1`,
+		string(id),
+	); err != nil {
+		return fmt.Errorf("blobs.MarkMissing: %w", err)
+	}
+
+	// Usage is charged for references to committed blobs only, so a blob
+	// that leaves COMMITTED gives the charge back. The row lock taken above
+	// keeps AddReference and RemoveReference from racing the refund.
+	if state == domain.StateCommitted {
+		if err := chargeReferences(ctx, tx, id, -1); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("blobs.MarkMissing commit: %w", err)
+	}
+	return nil
+}
+
+func checkTransition(res sql.Result, err error, op string) error {
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s rows: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, domain.ErrAlreadyCommitted)
+	}
+	return nil
+}
+
+func (r *BlobRepo) ListScanning(ctx context.Context, afterID domain.BlobID, limit int) ([]*domain.Blob, error) {
+	var rows []blobRow
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT `+blobColumns+` FROM blobs
+		 WHERE state = 'SCANNING' AND id > $1
+		 ORDER BY id
+		 LIMIT $2`,
+		string(afterID), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("blobs.ListScanning: %w", err)
+	}
+	return rowsToBlobs(rows), nil
+}
+
+func (r *BlobRepo) ListAll(ctx context.Context, afterID domain.BlobID, limit int) ([]*domain.Blob, error) {
+	var rows []blobRow
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT `+blobColumns+` FROM blobs
+		 WHERE id > $1
+		 ORDER BY id
+		 LIMIT $2`,
+		string(afterID), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("blobs.ListAll: %w", err)
+	}
+	return rowsToBlobs(rows), nil
+}
+
+func (r *BlobRepo) FindByIDs(ctx context.Context, ids []domain.BlobID) ([]*domain.Blob, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = string(id)
+	}
+	var rows []blobRow
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT `+blobColumns+` FROM blobs WHERE id = ANY($1::char(64)[])`,
+		pq.Array(keys),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("blobs.FindByIDs: %w", err)
+	}
+	return rowsToBlobs(rows), nil
+}
+
+func (r *BlobRepo) FindReferenced(ctx context.Context, ids []domain.BlobID, subject string) ([]*domain.Blob, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = string(id)
+	}
+	var rows []blobRow
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT `+blobColumns+` FROM blobs
+		 WHERE id = ANY($1::char(64)[])
+		   AND id IN (SELECT blob_id FROM blob_refs WHERE subject = $2)`,
+		pq.Array(keys), subject,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("blobs.FindReferenced: %w", err)
+	}
+	return rowsToBlobs(rows), nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *BlobRepo) ListBlobs(ctx context.Context, f domain.BlobFilter, afterID domain.BlobID, limit int) ([]*domain.Blob, error) {
+	where := []string{"id > $1"}
+	args := []any{string(afterID)}
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.Subject != "" {
+		add("id IN (SELECT blob_id FROM blob_refs WHERE subject = $%d)", f.Subject)
+	}
+	if f.State != "" {
+		add("state = $%d", string(f.State))
+	}
+	if major, ok := strings.CutSuffix(f.ContentType, "/*"); ok {
+		add("content_type LIKE $%d", likeEscaper.Replace(major)+"/%")
+	} else if f.ContentType != "" {
+		add("content_type = $%d", f.ContentType)
+	}
+	if !f.CreatedAfter.IsZero() {
+		add("created_at >= $%d", f.CreatedAfter)
+	}
+	if !f.CreatedBefore.IsZero() {
+		add("created_at < $%d", f.CreatedBefore)
+	}
+	if !f.CommittedAfter.IsZero() {
+		add("committed_at >= $%d", f.CommittedAfter)
+	}
+	if !f.CommittedBefore.IsZero() {
+		add("committed_at < $%d", f.CommittedBefore)
+	}
+	args = append(args, limit)
+
+	var rows []blobRow
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT `+blobColumns+` FROM blobs
+		 WHERE `+strings.Join(where, " AND ")+`
+		 ORDER BY id
+		 LIMIT $`+fmt.Sprint(len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("blobs.ListBlobs: %w", err)
+	}
+	return rowsToBlobs(rows), nil
+}
+
+func (r *BlobRepo) AddReference(ctx context.Context, id domain.BlobID, subject, clientID string, quotas domain.QuotaPolicy) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("blob_refs.Add begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	state, err := lockBlobState(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("blob_refs.Add: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO blob_refs (blob_id, subject, client_id) VALUES ($1, $2, $3)
+		 ON CONFLICT (blob_id, subject) DO NOTHING`,
+		string(id), subject, clientID,
+	)
+	if err != nil {
+		return fmt.Errorf("blob_refs.Add: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("blob_refs.Add rows: %w", err)
+	}
+	if n == 0 {
+		return tx.Commit()
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE blobs SET released_at = NULL WHERE id = $1 AND released_at IS NOT NULL`,
+		string(id),
+	); err != nil {
+		return fmt.Errorf("blobs.Unrelease: %w", err)
+	}
+	if state == domain.StateCommitted {
+		if err := reserveUsage(ctx, tx, id, subject, clientID, quotas); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("blob_refs.Add commit: %w", err)
+	}
+	return nil
+}
+
+func (r *BlobRepo) HasReference(ctx context.Context, id domain.BlobID, subject string) (bool, error) {
+	var ok bool
+	err := r.db.GetContext(ctx, &ok,
+		`SELECT EXISTS (SELECT 1 FROM blob_refs WHERE blob_id = $1 AND subject = $2)`,
+		string(id), subject,
+	)
+	if err != nil {
+		return false, fmt.Errorf("blob_refs.Has: %w", err)
+	}
+	return ok, nil
+}
+
+func (r *BlobRepo) RemoveReference(ctx context.Context, id domain.BlobID, subject string, at time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("blob_refs.Remove begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	state, err := lockBlobState(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("blob_refs.Remove: %w", err)
+	}
+
+	var clientID string
+	err = tx.GetContext(ctx, &clientID,
+		`DELETE FROM blob_refs WHERE blob_id = $1 AND subject = $2 RETURNING client_id`,
+		string(id), subject,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("blob_refs.Remove: %w", domain.ErrBlobNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("blob_refs.Remove: %w", err)
+	}
+	if state == domain.StateCommitted {
+		if err := chargeUsage(ctx, tx, id, subject, clientID, -1); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE blobs SET released_at = $2
+		 WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM blob_refs WHERE blob_id = $1)`,
 		string(id), at,
+	); err != nil {
+		return fmt.Errorf("blobs.Release: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("blob_refs.Remove commit: %w", err)
+	}
+	return nil
+}
+
+// notHeld matches blobs under neither a legal hold nor an unexpired
+// retention period, the only ones any deletion path may remove.
+const notHeld = `NOT legal_hold AND (retain_until IS NULL OR retain_until <= now())`
+
+func (r *BlobRepo) ListReleased(ctx context.Context, before time.Time, afterID domain.BlobID, limit int) ([]*domain.Blob, error) {
+	var rows []blobRow
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT `+blobColumns+` FROM blobs b
+		 WHERE state IN ('COMMITTED', 'QUARANTINED', 'MISSING') AND released_at < $1 AND id > $2
+		   AND NOT EXISTS (SELECT 1 FROM blob_refs WHERE blob_id = b.id)
+		   AND NOT EXISTS (SELECT 1 FROM blob_renditions WHERE rendition_id = b.id)
+		   AND `+notHeld+`
+		 ORDER BY id
+		 LIMIT $3`,
+		before, string(afterID), limit,
 	)
 	if err != nil {
-		return fmt.Errorf("blobs.MarkCommitted: %w", err)
+		return nil, fmt.Errorf("blobs.ListReleased: %w", err)
+	}
+	return rowsToBlobs(rows), nil
+}
+
+func (r *BlobRepo) DeleteReleased(ctx context.Context, id domain.BlobID, before time.Time) (string, bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", false, fmt.Errorf("blobs.DeleteReleased begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Lock the row first so that the checks below see every reference and
+	// rendition link committed before the lock was granted.
+	var key string
+	err = tx.GetContext(ctx, &key, `SELECT r2_key FROM blobs WHERE id = $1 FOR UPDATE`, string(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("blobs.DeleteReleased lock: %w", err)
+	}
+
+	var renditions []string
+	if err := tx.SelectContext(ctx, &renditions,
+		`SELECT rendition_id FROM blob_renditions WHERE source_id = $1`, string(id),
+	); err != nil {
+		return "", false, fmt.Errorf("blob_renditions.List: %w", err)
+	}
+
+	var row eventRow
+	err = tx.GetContext(ctx, &row,
+		`DELETE FROM blobs b
+		 WHERE id = $1 AND state IN ('COMMITTED', 'QUARANTINED', 'MISSING') AND released_at < $2
+		   AND NOT EXISTS (SELECT 1 FROM blob_refs WHERE blob_id = b.id)
+		   AND NOT EXISTS (SELECT 1 FROM blob_renditions WHERE rendition_id = b.id)
+		   AND `+notHeld+`
+		 RETURNING size_bytes, content_type`,
+		string(id), before,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("blobs.DeleteReleased: %w", err)
+	}
+
+	// The source's links went with it; renditions nothing else holds on to
+	// are now released like any other unreferenced blob.
+	if len(renditions) > 0 {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE blobs b SET released_at = now()
+			 WHERE id = ANY($1::char(64)[]) AND released_at IS NULL
+			   AND NOT EXISTS (SELECT 1 FROM blob_refs WHERE blob_id = b.id)
+			   AND NOT EXISTS (SELECT 1 FROM blob_renditions WHERE rendition_id = b.id)`,
+			pq.Array(renditions),
+		); err != nil {
+			return "", false, fmt.Errorf("blobs.ReleaseRenditions: %w", err)
+		}
+	}
+	if err := recordEvent(ctx, tx, domain.EventDeleted, id, row.SizeBytes, row.ContentType); err != nil {
+		return "", false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", false, fmt.Errorf("blobs.DeleteReleased commit: %w", err)
+	}
+	return key, true, nil
+}
+
+func (r *BlobRepo) LinkRendition(ctx context.Context, source domain.BlobID, spec string, rendition domain.BlobID) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO blob_renditions (source_id, spec, rendition_id) VALUES ($1, $2, $3)
+		 ON CONFLICT (source_id, spec) DO UPDATE SET rendition_id = EXCLUDED.rendition_id, created_at = now()`,
+		string(source), spec, string(rendition),
+	)
+	if err != nil {
+		return fmt.Errorf("blob_renditions.Link: %w", err)
+	}
+	return nil
+}
+
+func (r *BlobRepo) FindRendition(ctx context.Context, source domain.BlobID, spec string) (*domain.Blob, error) {
+	var row blobRow
+	err := r.db.GetContext(ctx, &row,
+		`SELECT `+blobColumns+` FROM blobs
+		 WHERE id = (SELECT rendition_id FROM blob_renditions WHERE source_id = $1 AND spec = $2)`,
+		string(source), spec,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("blob_renditions.Find: %w", err)
+	}
+	return rowToBlob(row), nil
+}
+
+func (r *BlobRepo) IsRendition(ctx context.Context, id domain.BlobID) (bool, error) {
+	var ok bool
+	err := r.db.GetContext(ctx, &ok,
+		`SELECT EXISTS (SELECT 1 FROM blob_renditions WHERE rendition_id = $1)`, string(id))
+	if err != nil {
+		return false, fmt.Errorf("blob_renditions.IsRendition: %w", err)
+	}
+	return ok, nil
+}
+
+// lockBlobState share-locks the blob row for the rest of the transaction so
+// that MarkCommitted cannot change its state while references are charged.
+func lockBlobState(ctx context.Context, tx *sqlx.Tx, id domain.BlobID) (domain.UploadState, error) {
+	var state string
+	err := tx.GetContext(ctx, &state,
+		`SELECT state FROM blobs WHERE id = $1 FOR SHARE`, string(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", domain.ErrBlobNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return domain.UploadState(state), nil
+}
+
+// Statements that charge several usage rows lock them in the same order,
+// by kind and then ID compared bytewise, so concurrent charges cannot
+// deadlock. usageLockOrder and compareOwners must agree.
+const usageLockOrder = `o.kind COLLATE "C", o.id COLLATE "C"`
+
+func compareOwners(a, b domain.Owner) int {
+	return cmp.Or(strings.Compare(string(a.Kind), string(b.Kind)), strings.Compare(a.ID, b.ID))
+}
+
+// chargeUsage adds sign times the blob's size and one object to the usage of
+// the reference's subject and, if set, its client.
+func chargeUsage(ctx context.Context, tx *sqlx.Tx, id domain.BlobID, subject, clientID string, sign int64) error {
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO blob_usage (owner_kind, owner_id, bytes, objects)
+		 SELECT o.kind, o.id, b.size_bytes * $4, $4
+		 FROM blobs b
+		 CROSS JOIN (VALUES ('subject', $2::text), ('client', $3::text)) AS o (kind, id)
+		 WHERE b.id = $1 AND o.id <> ''
+		 ORDER BY `+usageLockOrder+`
+		 ON CONFLICT (owner_kind, owner_id) DO UPDATE
+		 SET bytes = blob_usage.bytes + EXCLUDED.bytes, objects = blob_usage.objects + EXCLUDED.objects`,
+		string(id), subject, clientID, sign,
+	); err != nil {
+		return fmt.Errorf("blob_usage.Charge: %w", err)
+	}
+	return nil
+}
+
+// reserveUsage charges a new reference to the blob as chargeUsage does,
+// failing with ErrQuotaExceeded if that takes the subject or client over its
+// limits. Each limit is checked by the UPDATE that charges it, under the
+// usage row's lock, so concurrent reservations cannot overshoot it.
+func reserveUsage(ctx context.Context, tx *sqlx.Tx, id domain.BlobID, subject, clientID string, quotas domain.QuotaPolicy) error {
+	var size int64
+	if err := tx.GetContext(ctx, &size, `SELECT size_bytes FROM blobs WHERE id = $1`, string(id)); err != nil {
+		return fmt.Errorf("blob_usage.Reserve: %w", err)
+	}
+	caller := domain.Caller{Subject: subject, ClientID: clientID}
+	owners := caller.Owners()
+	slices.SortFunc(owners, compareOwners)
+	for _, o := range owners {
+		limits := quotas.LimitsFor(o)
+		if err := limits.Admit(domain.Usage{}, size); err != nil {
+			return fmt.Errorf("%s: %w", o, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO blob_usage (owner_kind, owner_id) VALUES ($1, $2)
+			 ON CONFLICT (owner_kind, owner_id) DO NOTHING`,
+			string(o.Kind), o.ID,
+		); err != nil {
+			return fmt.Errorf("blob_usage.Reserve: %w", err)
+		}
+		res, err := tx.ExecContext(ctx,
+			`UPDATE blob_usage SET bytes = bytes + $3, objects = objects + 1
+			 WHERE owner_kind = $1 AND owner_id = $2
+			   AND ($4::bigint = 0 OR bytes + $3 <= $4) AND ($5::bigint = 0 OR objects + 1 <= $5)`,
+			string(o.Kind), o.ID, size, limits.MaxBytes, limits.MaxObjects,
+		)
+		if err != nil {
+			return fmt.Errorf("blob_usage.Reserve: %w", err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return fmt.Errorf("blob_usage.Reserve rows: %w", err)
+		} else if n == 0 {
+			return fmt.Errorf("blob_usage.Reserve: %s: %w", o, domain.ErrQuotaExceeded)
+		}
+	}
+	return nil
+}
+
+// chargeReferences adds sign times the blob's size and one object to the
+// usage of every reference held on it, as chargeUsage does for one.
+func chargeReferences(ctx context.Context, tx *sqlx.Tx, id domain.BlobID, sign int64) error {
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO blob_usage (owner_kind, owner_id, bytes, objects)
+		 SELECT o.kind, o.id, SUM(b.size_bytes) * $2, COUNT(*) * $2
+		 FROM blob_refs r
+		 JOIN blobs b ON b.id = r.blob_id
+		 CROSS JOIN LATERAL (VALUES ('subject', r.subject), ('client', r.client_id)) AS o (kind, id)
+		 WHERE r.blob_id = $1 AND o.id <> ''
+		 GROUP BY o.kind, o.id
+		 ORDER BY `+usageLockOrder+`
+		 ON CONFLICT (owner_kind, owner_id) DO UPDATE
+		 SET bytes = blob_usage.bytes + EXCLUDED.bytes, objects = blob_usage.objects + EXCLUDED.objects`,
+		string(id), sign,
+	); err != nil {
+		return fmt.Errorf("blob_usage.Charge: %w", err)
+	}
+	return nil
+}
+
+type usageRow struct {
+	Bytes   int64 `db:"bytes"`
+	Objects int64 `db:"objects"`
+}
+
+func (r *BlobRepo) ListStaleKeys(ctx context.Context, keyID string, afterID domain.BlobID, limit int) ([]*domain.Blob, error) {
+	var rows []blobRow
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT `+blobColumns+` FROM blobs
+		 WHERE key_id <> '' AND key_id <> $1 AND id > $2
+		 ORDER BY id
+		 LIMIT $3`,
+		keyID, string(afterID), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("blobs.ListStaleKeys: %w", err)
+	}
+	return rowsToBlobs(rows), nil
+}
+
+func (r *BlobRepo) RewrapKey(ctx context.Context, id domain.BlobID, oldKeyID, keyID string, wrapped []byte) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE blobs SET key_id = $3, wrapped_key = $4
+		 WHERE id = $1 AND key_id = $2`,
+		string(id), oldKeyID, keyID, wrapped,
+	)
+	if err != nil {
+		return false, fmt.Errorf("blobs.RewrapKey: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("blobs.RewrapKey rows: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *BlobRepo) ListUnreplicated(ctx context.Context, afterID domain.BlobID, limit int) ([]*domain.Blob, error) {
+	var rows []blobRow
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT `+blobColumns+` FROM blobs
+		 WHERE state = 'COMMITTED' AND replicated_at IS NULL AND id > $1
+		 ORDER BY id
+		 LIMIT $2`,
+		string(afterID), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("blobs.ListUnreplicated: %w", err)
+	}
+	return rowsToBlobs(rows), nil
+}
+
+func (r *BlobRepo) MarkReplicated(ctx context.Context, id domain.BlobID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE blobs SET replicated_at = $2 WHERE id = $1`,
+		string(id), at,
+	)
+	if err != nil {
+		return fmt.Errorf("blobs.MarkReplicated: %w", err)
+	}
+	return nil
+}
+
+func (r *BlobRepo) SetRetention(ctx context.Context, id domain.BlobID, until time.Time, by domain.Caller) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("blobs.SetRetention begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var current sql.NullTime
+	err = tx.GetContext(ctx, &current,
+		`SELECT retain_until FROM blobs WHERE id = $1 FOR UPDATE`, string(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("blobs.SetRetention: %w", domain.ErrBlobNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("blobs.SetRetention: %w", err)
+	}
+	if current.Valid && current.Time.After(until) {
+		return fmt.Errorf("blobs.SetRetention: %w", domain.ErrRetentionShortened)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE blobs SET retain_until = $2 WHERE id = $1`, string(id), until,
+	); err != nil {
+		return fmt.Errorf("blobs.SetRetention: %w", err)
+	}
+	if err := audit(ctx, tx, id, domain.AuditRetentionSet, by, &until, ""); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("blobs.SetRetention commit: %w", err)
+	}
+	return nil
+}
+
+func (r *BlobRepo) SetLegalHold(ctx context.Context, id domain.BlobID, hold bool, reason string, by domain.Caller) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("blobs.SetLegalHold begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE blobs SET legal_hold = $2 WHERE id = $1`, string(id), hold,
+	)
+	if err != nil {
+		return fmt.Errorf("blobs.SetLegalHold: %w", err)
 	}
 	n, err := res.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("blobs.MarkCommitted rows: %w", err)
+		return fmt.Errorf("blobs.SetLegalHold rows: %w", err)
 	}
 	if n == 0 {
-		return fmt.Errorf("blobs.MarkCommitted: %w", domain.ErrAlreadyCommitted)
+		return fmt.Errorf("blobs.SetLegalHold: %w", domain.ErrBlobNotFound)
+	}
+	action := domain.AuditLegalHoldSet
+	if !hold {
+		action = domain.AuditLegalHoldReleased
+	}
+	if err := audit(ctx, tx, id, action, by, nil, reason); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("blobs.SetLegalHold commit: %w", err)
 	}
 	return nil
 }
 
-func rowToBlob(row blobRow) *domain.Blob {
-	b := &domain.Blob{
-		ID:          domain.BlobID(row.ID),
+func audit(ctx context.Context, tx *sqlx.Tx, id domain.BlobID, action domain.AuditAction, by domain.Caller, retainUntil *time.Time, reason string) error {
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO blob_audit_log (blob_id, action, subject, client_id, retain_until, reason)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		string(id), string(action), by.Subject, by.ClientID, retainUntil, reason,
+	); err != nil {
+		return fmt.Errorf("blob_audit_log.Insert: %w", err)
+	}
+	return nil
+}
+
+func (r *BlobRepo) GetUsage(ctx context.Context, o domain.Owner) (*domain.Usage, error) {
+	var row usageRow
+	err := r.db.GetContext(ctx, &row,
+		`SELECT bytes, objects FROM blob_usage WHERE owner_kind = $1 AND owner_id = $2`,
+		string(o.Kind), o.ID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &domain.Usage{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("blob_usage.Get: %w", err)
+	}
+	return &domain.Usage{Bytes: row.Bytes, Objects: row.Objects}, nil
+}
+
+type multipartRow struct {
+	UploadID      string        `db:"upload_id"`
+	BlobID        string        `db:"blob_id"`
+	Subject       string        `db:"subject"`
+	PartCount     int32         `db:"part_count"`
+	CreatedAt     time.Time     `db:"created_at"`
+	Resumable     bool          `db:"resumable"`
+	ReceivedBytes int64         `db:"received_bytes"`
+	StagedOffset  int64         `db:"staged_offset"`
+	Parts         pq.Int32Array `db:"parts"`
+	Staged        pq.Int32Array `db:"staged"`
+}
+
+func (r *BlobRepo) RecordMultipartUpload(ctx context.Context, s *domain.MultipartSession) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO multipart_uploads (upload_id, blob_id, subject, part_count, created_at, resumable) VALUES ($1, $2, $3, $4, $5, $6)`,
+		s.UploadID, string(s.BlobID), s.Subject, s.PartCount, s.CreatedAt, s.Resumable,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			switch pqErr.Code {
+			case "23505":
+				return fmt.Errorf("multipart_uploads.Record: %w", domain.ErrSessionExists)
+			case "23503":
+				return fmt.Errorf("multipart_uploads.Record: %w", domain.ErrBlobNotFound)
+			}
+		}
+		return fmt.Errorf("multipart_uploads.Record: %w", err)
+	}
+	return nil
+}
+
+func (r *BlobRepo) FindMultipartUpload(ctx context.Context, id domain.BlobID, subject string) (*domain.MultipartSession, error) {
+	var row multipartRow
+	err := r.db.GetContext(ctx, &row,
+		`SELECT upload_id, blob_id, subject, part_count, created_at,
+		        resumable, received_bytes, staged_offset, parts, staged
+		 FROM multipart_uploads WHERE blob_id = $1 AND subject = $2`,
+		string(id), subject,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("multipart_uploads.Find: %w", err)
+	}
+	return &domain.MultipartSession{
+		BlobID:        domain.BlobID(row.BlobID),
+		Subject:       row.Subject,
+		UploadID:      row.UploadID,
+		PartCount:     row.PartCount,
+		CreatedAt:     row.CreatedAt,
+		Resumable:     row.Resumable,
+		ReceivedBytes: row.ReceivedBytes,
+		StagedOffset:  row.StagedOffset,
+		Parts:         row.Parts,
+		Staged:        row.Staged,
+	}, nil
+}
+
+func (r *BlobRepo) DeleteMultipartUpload(ctx context.Context, uploadID string) error {
+	if _, err := r.db.ExecContext(ctx,
+		`DELETE FROM multipart_uploads WHERE upload_id = $1`, uploadID,
+	); err != nil {
+		return fmt.Errorf("multipart_uploads.Delete: %w", err)
+	}
+	return nil
+}
+
+func (r *BlobRepo) HasMultipartUpload(ctx context.Context, uploadID string) (bool, error) {
+	var ok bool
+	err := r.db.GetContext(ctx, &ok,
+		`SELECT EXISTS (SELECT 1 FROM multipart_uploads WHERE upload_id = $1)`, uploadID)
+	if err != nil {
+		return false, fmt.Errorf("multipart_uploads.Has: %w", err)
+	}
+	return ok, nil
+}
+
+// ClaimResumableNumber and AdvanceResumableUpload only touch a session that
+// is still at offset, so of two chunks sent for the same offset by
+// different replicas, only the first to advance the session is kept.
+func (r *BlobRepo) ClaimResumableNumber(ctx context.Context, uploadID string, offset int64, part bool) (int32, error) {
+	column := "next_stage"
+	if part {
+		column = "next_part"
+	}
+	var n int32
+	err := r.db.GetContext(ctx, &n,
+		`UPDATE multipart_uploads SET `+column+` = `+column+` + 1
+		 WHERE upload_id = $1 AND resumable AND received_bytes = $2
+		 RETURNING `+column+` - 1`,
+		uploadID, offset,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("multipart_uploads.Claim: %w", domain.ErrOffsetMismatch)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("multipart_uploads.Claim: %w", err)
+	}
+	return n, nil
+}
+
+func (r *BlobRepo) AdvanceResumableUpload(ctx context.Context, s *domain.MultipartSession, offset int64) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE multipart_uploads
+		 SET received_bytes = $3, staged_offset = $4, parts = $5, staged = $6
+		 WHERE upload_id = $1 AND resumable AND received_bytes = $2`,
+		s.UploadID, offset, s.ReceivedBytes, s.StagedOffset, pq.Array(s.Parts), pq.Array(s.Staged),
+	)
+	if err != nil {
+		return fmt.Errorf("multipart_uploads.Advance: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("multipart_uploads.Advance rows: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("multipart_uploads.Advance: %w", domain.ErrOffsetMismatch)
+	}
+	return nil
+}
+
+type uploadRow struct {
+	BlobID      string    `db:"blob_id"`
+	Subject     string    `db:"subject"`
+	ClientID    string    `db:"client_id"`
+	ObjectKey   string    `db:"object_key"`
+	SizeBytes   int64     `db:"size_bytes"`
+	ContentType string    `db:"content_type"`
+	KeyID       string    `db:"key_id"`
+	WrappedKey  []byte    `db:"wrapped_key"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+const uploadColumns = `blob_id, subject, client_id, object_key, size_bytes, content_type, key_id, wrapped_key, created_at`
+
+func (r *BlobRepo) CreateUpload(ctx context.Context, u *domain.Upload) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO blob_uploads (`+uploadColumns+`)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		string(u.BlobID), u.Subject, u.ClientID, u.ObjectKey, u.SizeBytes, u.ContentType, u.KeyID, u.WrappedKey, u.CreatedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return fmt.Errorf("blob_uploads.Create: %w", domain.ErrUploadExists)
+		}
+		return fmt.Errorf("blob_uploads.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *BlobRepo) FindUpload(ctx context.Context, id domain.BlobID, subject string) (*domain.Upload, error) {
+	var row uploadRow
+	err := r.db.GetContext(ctx, &row,
+		`SELECT `+uploadColumns+` FROM blob_uploads WHERE blob_id = $1 AND subject = $2`,
+		string(id), subject,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("blob_uploads.Find: %w", err)
+	}
+	return rowToUpload(row), nil
+}
+
+func (r *BlobRepo) FindUploads(ctx context.Context, ids []domain.BlobID) ([]*domain.Upload, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = string(id)
+	}
+	var rows []uploadRow
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT `+uploadColumns+` FROM blob_uploads WHERE blob_id = ANY($1::char(64)[])`,
+		pq.Array(keys),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("blob_uploads.FindMany: %w", err)
+	}
+	return rowsToUploads(rows), nil
+}
+
+func (r *BlobRepo) DeleteUpload(ctx context.Context, u *domain.Upload) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM blob_uploads WHERE blob_id = $1 AND subject = $2 AND object_key = $3`,
+		string(u.BlobID), u.Subject, u.ObjectKey,
+	)
+	if err != nil {
+		return false, fmt.Errorf("blob_uploads.Delete: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("blob_uploads.Delete rows: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *BlobRepo) ListExpiredUploads(ctx context.Context, before time.Time, afterKey string, limit int) ([]*domain.Upload, error) {
+	var rows []uploadRow
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT `+uploadColumns+` FROM blob_uploads
+		 WHERE created_at < $1 AND object_key > $2
+		 ORDER BY object_key
+		 LIMIT $3`,
+		before, afterKey, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("blob_uploads.ListExpired: %w", err)
+	}
+	return rowsToUploads(rows), nil
+}
+
+func (r *BlobRepo) CommitUpload(ctx context.Context, u *domain.Upload, b *domain.Blob, quotas domain.QuotaPolicy) (*domain.Blob, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("blob_uploads.Commit begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx,
+		`DELETE FROM blob_uploads WHERE blob_id = $1 AND subject = $2 AND object_key = $3`,
+		string(u.BlobID), u.Subject, u.ObjectKey,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("blob_uploads.Commit: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("blob_uploads.Commit rows: %w", err)
+	} else if n == 0 {
+		return nil, fmt.Errorf("blob_uploads.Commit: %w", domain.ErrBlobNotFound)
+	}
+
+	stored, written, err := storeUploadedBlob(ctx, tx, b)
+	if err != nil {
+		return nil, err
+	}
+	// A blob that became COMMITTED here is charged to the references it
+	// already had, before the caller's is added and charged below.
+	committed := written && stored.State == domain.StateCommitted
+	if committed {
+		if err := chargeReferences(ctx, tx, b.ID, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err = tx.ExecContext(ctx,
+		`INSERT INTO blob_refs (blob_id, subject, client_id) VALUES ($1, $2, $3)
+		 ON CONFLICT (blob_id, subject) DO NOTHING`,
+		string(b.ID), u.Subject, u.ClientID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("blob_refs.Add: %w", err)
+	}
+	added, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("blob_refs.Add rows: %w", err)
+	}
+	if added > 0 {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE blobs SET released_at = NULL WHERE id = $1 AND released_at IS NOT NULL`,
+			string(b.ID),
+		); err != nil {
+			return nil, fmt.Errorf("blobs.Unrelease: %w", err)
+		}
+	}
+
+	if added > 0 && stored.State == domain.StateCommitted {
+		if err := reserveUsage(ctx, tx, b.ID, u.Subject, u.ClientID, quotas); err != nil {
+			return nil, err
+		}
+	}
+	if committed {
+		if err := recordEvent(ctx, tx, domain.EventCommitted, b.ID, stored.SizeBytes, stored.ContentType); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("blob_uploads.Commit commit: %w", err)
+	}
+	return stored, nil
+}
+
+// storeUploadedBlob creates b, or restores the PENDING or MISSING blob with
+// its ID from b, and returns the blob as stored and whether b was written.
+// Any other blob is row-locked and returned unchanged.
+func storeUploadedBlob(ctx context.Context, tx *sqlx.Tx, b *domain.Blob) (*domain.Blob, bool, error) {
+	for {
+		res, err := tx.ExecContext(ctx,
+			`INSERT INTO blobs (id, size_bytes, content_type, detected_content_type, r2_key, state, created_at, committed_at, key_id, wrapped_key)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			 ON CONFLICT (id) DO NOTHING`,
+			string(b.ID), b.SizeBytes, b.ContentType, b.DetectedContentType, b.R2Key, string(b.State), b.CreatedAt, b.CommittedAt, b.KeyID, b.WrappedKey,
+		)
+		if err != nil {
+			return nil, false, fmt.Errorf("blobs.Create: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, false, fmt.Errorf("blobs.Create rows: %w", err)
+		}
+		if n > 0 {
+			cp := *b
+			return &cp, true, nil
+		}
+
+		var row blobRow
+		err = tx.GetContext(ctx, &row,
+			`SELECT `+blobColumns+` FROM blobs WHERE id = $1 FOR UPDATE`, string(b.ID))
+		if errors.Is(err, sql.ErrNoRows) {
+			// Deleted since the insert conflicted with it; try again.
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("blobs.FindForUpdate: %w", err)
+		}
+		stored := rowToBlob(row)
+		if stored.State != domain.StatePending && stored.State != domain.StateMissing {
+			return stored, false, nil
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE blobs SET size_bytes = $2, content_type = $3, detected_content_type = $4, r2_key = $5,
+			     state = $6, committed_at = $7, key_id = $8, wrapped_key = $9, replicated_at = NULL
+			 WHERE id = $1`,
+			string(b.ID), b.SizeBytes, b.ContentType, b.DetectedContentType, b.R2Key, string(b.State), b.CommittedAt, b.KeyID, b.WrappedKey,
+		); err != nil {
+			return nil, false, fmt.Errorf("blobs.Restore: %w", err)
+		}
+		stored.SizeBytes, stored.ContentType, stored.DetectedContentType = b.SizeBytes, b.ContentType, b.DetectedContentType
+		stored.R2Key, stored.State, stored.CommittedAt = b.R2Key, b.State, b.CommittedAt
+		stored.KeyID, stored.WrappedKey, stored.ReplicatedAt = b.KeyID, b.WrappedKey, nil
+		return stored, true, nil
+	}
+}
+
+// eventsLock serializes the transactions that record events, so sequence
+// values become visible in the order they are assigned.
+const eventsLock = 0x626c6f62
+
+// recordEvent records an event in the transaction. It holds eventsLock until
+// commit, so callers make it their last statement.
+func recordEvent(ctx context.Context, tx *sqlx.Tx, kind domain.EventKind, id domain.BlobID, size int64, contentType string) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, eventsLock); err != nil {
+		return fmt.Errorf("blob_events.Lock: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO blob_events (kind, blob_id, size_bytes, content_type) VALUES ($1, $2, $3, $4)`,
+		string(kind), string(id), size, contentType,
+	); err != nil {
+		return fmt.Errorf("blob_events.Insert: %w", err)
+	}
+	return nil
+}
+
+func rowsToUploads(rows []uploadRow) []*domain.Upload {
+	uploads := make([]*domain.Upload, len(rows))
+	for i, row := range rows {
+		uploads[i] = rowToUpload(row)
+	}
+	return uploads
+}
+
+func rowToUpload(row uploadRow) *domain.Upload {
+	return &domain.Upload{
+		BlobID:      domain.BlobID(row.BlobID),
+		Subject:     row.Subject,
+		ClientID:    row.ClientID,
+		ObjectKey:   row.ObjectKey,
 		SizeBytes:   row.SizeBytes,
 		ContentType: row.ContentType,
-		R2Key:       row.R2Key,
-		State:       domain.UploadState(row.State),
+		KeyID:       row.KeyID,
+		WrappedKey:  row.WrappedKey,
 		CreatedAt:   row.CreatedAt,
 	}
+}
+
+type eventRow struct {
+	Seq         int64     `db:"seq"`
+	Kind        string    `db:"kind"`
+	BlobID      string    `db:"blob_id"`
+	SizeBytes   int64     `db:"size_bytes"`
+	ContentType string    `db:"content_type"`
+	OccurredAt  time.Time `db:"occurred_at"`
+}
+
+func (r *BlobRepo) ListEvents(ctx context.Context, afterSeq int64, limit int) ([]*domain.BlobEvent, error) {
+	var rows []eventRow
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT seq, kind, blob_id, size_bytes, content_type, occurred_at FROM blob_events
+		 WHERE seq > $1
+		 ORDER BY seq
+		 LIMIT $2`,
+		afterSeq, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("blob_events.List: %w", err)
+	}
+	events := make([]*domain.BlobEvent, len(rows))
+	for i, row := range rows {
+		events[i] = &domain.BlobEvent{
+			Seq:         row.Seq,
+			Kind:        domain.EventKind(row.Kind),
+			BlobID:      domain.BlobID(row.BlobID),
+			SizeBytes:   row.SizeBytes,
+			ContentType: row.ContentType,
+			OccurredAt:  row.OccurredAt,
+		}
+	}
+	return events, nil
+}
+
+func (r *BlobRepo) DeleteEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM blob_events WHERE occurred_at < $1`, before,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("blob_events.DeleteBefore: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("blob_events.DeleteBefore rows: %w", err)
+	}
+	return n, nil
+}
+
+func (r *BlobRepo) FindEventCursor(ctx context.Context, consumer string) (int64, error) {
+	var seq int64
+	err := r.db.GetContext(ctx, &seq,
+		`SELECT seq FROM event_cursors WHERE consumer = $1`, consumer)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("event_cursors.Find: %w", err)
+	}
+	return seq, nil
+}
+
+func (r *BlobRepo) SaveEventCursor(ctx context.Context, consumer string, seq int64) error {
+	if _, err := r.db.ExecContext(ctx,
+		`INSERT INTO event_cursors (consumer, seq) VALUES ($1, $2)
+		 ON CONFLICT (consumer) DO UPDATE
+		 SET seq = GREATEST(event_cursors.seq, EXCLUDED.seq), updated_at = now()`,
+		consumer, seq,
+	); err != nil {
+		return fmt.Errorf("event_cursors.Save: %w", err)
+	}
+	return nil
+}
+
+func rowsToBlobs(rows []blobRow) []*domain.Blob {
+	blobs := make([]*domain.Blob, len(rows))
+	for i, row := range rows {
+		blobs[i] = rowToBlob(row)
+	}
+	return blobs
+}
+
+func rowToBlob(row blobRow) *domain.Blob {
+	b := &domain.Blob{
+		ID:                  domain.BlobID(row.ID),
+		SizeBytes:           row.SizeBytes,
+		ContentType:         row.ContentType,
+		DetectedContentType: row.DetectedContentType,
+		R2Key:               row.R2Key,
+		State:               domain.UploadState(row.State),
+		CreatedAt:           row.CreatedAt,
+		QuarantineReason:    row.QuarantineReason,
+		KeyID:               row.KeyID,
+		WrappedKey:          row.WrappedKey,
+		LegalHold:           row.LegalHold,
+	}
 	if row.CommittedAt.Valid {
 		t := row.CommittedAt.Time
 		b.CommittedAt = &t
 	}
+	if row.ReleasedAt.Valid {
+		t := row.ReleasedAt.Time
+		b.ReleasedAt = &t
+	}
+	if row.ReplicatedAt.Valid {
+		t := row.ReplicatedAt.Time
+		b.ReplicatedAt = &t
+	}
+	if row.RetainUntil.Valid {
+		t := row.RetainUntil.Time
+		b.RetainUntil = &t
+	}
 	return b
 }
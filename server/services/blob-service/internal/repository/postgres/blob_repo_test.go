@@ -2,6 +2,7 @@ package postgres_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -50,11 +51,12 @@ func TestMarkCommitted(t *testing.T) {
 	require.NoError(t, repo.Create(context.Background(), blob))
 
 	at := time.Now().UTC().Truncate(time.Microsecond)
-	require.NoError(t, repo.MarkCommitted(context.Background(), domain.BlobID(validID), at))
+	require.NoError(t, repo.MarkCommitted(context.Background(), domain.BlobID(validID), "image/png", at))
 
 	got, err := repo.FindByID(context.Background(), domain.BlobID(validID))
 	require.NoError(t, err)
 	assert.Equal(t, domain.StateCommitted, got.State)
+	assert.Equal(t, "image/png", got.DetectedContentType)
 	require.NotNil(t, got.CommittedAt)
 	assert.Equal(t, at, got.CommittedAt.UTC().Truncate(time.Microsecond))
 }
@@ -65,9 +67,9 @@ func TestMarkCommitted_AlreadyCommitted(t *testing.T) {
 
 	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now().UTC())
 	require.NoError(t, repo.Create(context.Background(), blob))
-	require.NoError(t, repo.MarkCommitted(context.Background(), domain.BlobID(validID), time.Now()))
+	require.NoError(t, repo.MarkCommitted(context.Background(), domain.BlobID(validID), "", time.Now()))
 
-	err := repo.MarkCommitted(context.Background(), domain.BlobID(validID), time.Now())
+	err := repo.MarkCommitted(context.Background(), domain.BlobID(validID), "", time.Now())
 	assert.ErrorIs(t, err, domain.ErrAlreadyCommitted)
 }
 
@@ -75,7 +77,7 @@ func TestMarkCommitted_NotFound(t *testing.T) {
 	db := testhelper.NewTestDB(t)
 	repo := postgres.New(db)
 
-	err := repo.MarkCommitted(context.Background(), domain.BlobID(validID), time.Now())
+	err := repo.MarkCommitted(context.Background(), domain.BlobID(validID), "", time.Now())
 	assert.ErrorIs(t, err, domain.ErrAlreadyCommitted)
 }
 
@@ -89,3 +91,724 @@ func TestCreate_DuplicateKey(t *testing.T) {
 	err := repo.Create(context.Background(), blob)
 	assert.Error(t, err)
 }
+
+func TestAddReference_and_HasReference(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now().UTC())
+	require.NoError(t, repo.Create(ctx, blob))
+
+	ok, err := repo.HasReference(ctx, domain.BlobID(validID), "drive-service")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, repo.AddReference(ctx, domain.BlobID(validID), "drive-service", "", domain.QuotaPolicy{}))
+	require.NoError(t, repo.AddReference(ctx, domain.BlobID(validID), "drive-service", "", domain.QuotaPolicy{}), "re-adding is a no-op")
+
+	ok, err = repo.HasReference(ctx, domain.BlobID(validID), "drive-service")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = repo.HasReference(ctx, domain.BlobID(validID), "chat-service")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestAddReference_BlobNotFound(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+
+	err := repo.AddReference(context.Background(), domain.BlobID(validID), "drive-service", "", domain.QuotaPolicy{})
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+}
+
+func TestRemoveReference_LastReferenceReleases(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	id := domain.BlobID(validID)
+
+	blob, _ := domain.NewBlob(id, 1024, "image/png", time.Now().UTC())
+	require.NoError(t, repo.Create(ctx, blob))
+	require.NoError(t, repo.AddReference(ctx, id, "drive-service", "", domain.QuotaPolicy{}))
+	require.NoError(t, repo.AddReference(ctx, id, "chat-service", "", domain.QuotaPolicy{}))
+
+	at := time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, repo.RemoveReference(ctx, id, "drive-service", at))
+	got, err := repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	assert.Nil(t, got.ReleasedAt)
+
+	require.NoError(t, repo.RemoveReference(ctx, id, "chat-service", at))
+	got, err = repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, got.ReleasedAt)
+	assert.Equal(t, at, got.ReleasedAt.UTC().Truncate(time.Microsecond))
+
+	require.NoError(t, repo.AddReference(ctx, id, "drive-service", "", domain.QuotaPolicy{}))
+	got, err = repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	assert.Nil(t, got.ReleasedAt, "adding a reference clears released_at")
+}
+
+func TestRemoveReference_NotHeld(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now().UTC())
+	require.NoError(t, repo.Create(ctx, blob))
+
+	err := repo.RemoveReference(ctx, domain.BlobID(validID), "drive-service", time.Now())
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+}
+
+func TestFindReferenced(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	mine := domain.BlobID(validID)
+	theirs := domain.BlobID(strings.Repeat("a", 64))
+
+	for _, id := range []domain.BlobID{mine, theirs} {
+		blob, _ := domain.NewBlob(id, 1, "text/plain", time.Now().UTC())
+		require.NoError(t, repo.Create(ctx, blob))
+	}
+	require.NoError(t, repo.AddReference(ctx, mine, "drive-service", "", domain.QuotaPolicy{}))
+	require.NoError(t, repo.AddReference(ctx, theirs, "chat-service", "", domain.QuotaPolicy{}))
+
+	found, err := repo.FindReferenced(ctx, []domain.BlobID{mine, theirs, domain.BlobID(strings.Repeat("b", 64))}, "drive-service")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, mine, found[0].ID)
+}
+
+func TestListBlobs(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	blobs := []struct {
+		id          domain.BlobID
+		contentType string
+		commit      bool
+	}{
+		{domain.BlobID(strings.Repeat("1", 64)), "image/png", true},
+		{domain.BlobID(strings.Repeat("2", 64)), "image/jpeg", false},
+		{domain.BlobID(strings.Repeat("3", 64)), "video/mp4", true},
+		{domain.BlobID(strings.Repeat("4", 64)), "imagex/weird", true},
+	}
+	for _, b := range blobs {
+		blob, _ := domain.NewBlob(b.id, 1, b.contentType, now.Add(-time.Hour))
+		require.NoError(t, repo.Create(ctx, blob))
+		if b.commit {
+			require.NoError(t, repo.MarkCommitted(ctx, b.id, "", now))
+		}
+		require.NoError(t, repo.AddReference(ctx, b.id, "drive-service", "", domain.QuotaPolicy{}))
+	}
+
+	ids := func(f domain.BlobFilter, after domain.BlobID, limit int) []domain.BlobID {
+		t.Helper()
+		f.Subject = "drive-service"
+		got, err := repo.ListBlobs(ctx, f, after, limit)
+		require.NoError(t, err)
+		out := make([]domain.BlobID, len(got))
+		for i, b := range got {
+			out[i] = b.ID
+		}
+		return out
+	}
+
+	assert.Equal(t, []domain.BlobID{blobs[0].id, blobs[1].id}, ids(domain.BlobFilter{}, "", 2))
+	assert.Equal(t, []domain.BlobID{blobs[2].id, blobs[3].id}, ids(domain.BlobFilter{}, blobs[1].id, 10))
+	assert.Equal(t, []domain.BlobID{blobs[0].id, blobs[1].id}, ids(domain.BlobFilter{ContentType: "image/*"}, "", 10))
+	assert.Equal(t, []domain.BlobID{blobs[0].id}, ids(domain.BlobFilter{ContentType: "image/*", State: domain.StateCommitted}, "", 10))
+	assert.Equal(t, []domain.BlobID{blobs[2].id}, ids(domain.BlobFilter{ContentType: "video/mp4"}, "", 10))
+	assert.Len(t, ids(domain.BlobFilter{CommittedAfter: now.Add(-time.Minute)}, "", 10), 3)
+	assert.Empty(t, ids(domain.BlobFilter{CreatedAfter: now}, "", 10))
+
+	other, err := repo.ListBlobs(ctx, domain.BlobFilter{Subject: "chat-service"}, "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, other)
+
+	pending, err := repo.ListBlobs(ctx, domain.BlobFilter{State: domain.StatePending}, "", 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1, "without a subject every blob matches")
+	assert.Equal(t, blobs[1].id, pending[0].ID)
+}
+
+func TestListReleased_and_DeleteReleased(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	id := domain.BlobID(validID)
+
+	blob, _ := domain.NewBlob(id, 1024, "image/png", time.Now().UTC())
+	require.NoError(t, repo.Create(ctx, blob))
+	require.NoError(t, repo.MarkCommitted(ctx, id, "", time.Now().UTC()))
+	require.NoError(t, repo.AddReference(ctx, id, "drive-service", "", domain.QuotaPolicy{}))
+	require.NoError(t, repo.RemoveReference(ctx, id, "drive-service", time.Now().UTC().Add(-2*time.Hour)))
+
+	cutoff := time.Now().UTC().Add(-time.Hour)
+	released, err := repo.ListReleased(ctx, cutoff, "", 10)
+	require.NoError(t, err)
+	require.Len(t, released, 1)
+	assert.Equal(t, id, released[0].ID)
+
+	released, err = repo.ListReleased(ctx, cutoff.Add(-2*time.Hour), "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, released)
+
+	key, deleted, err := repo.DeleteReleased(ctx, id, cutoff)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	assert.Equal(t, blob.R2Key, key)
+
+	_, err = repo.FindByID(ctx, id)
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+}
+
+func TestMultipartUploadTracking(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	id := domain.BlobID(validID)
+
+	upload, _ := domain.NewUpload(id, domain.Caller{Subject: "drive-service"}, 50*1024*1024, "video/mp4", time.Now().UTC())
+	require.NoError(t, repo.CreateUpload(ctx, upload))
+
+	_, err := repo.FindMultipartUpload(ctx, id, "drive-service")
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+
+	session := &domain.MultipartSession{BlobID: id, Subject: "drive-service", UploadID: "mpu-1", PartCount: 10, CreatedAt: time.Now().UTC()}
+	require.NoError(t, repo.RecordMultipartUpload(ctx, session))
+
+	err = repo.RecordMultipartUpload(ctx, &domain.MultipartSession{BlobID: id, Subject: "drive-service", UploadID: "mpu-2", PartCount: 10, CreatedAt: time.Now().UTC()})
+	assert.ErrorIs(t, err, domain.ErrSessionExists)
+	err = repo.RecordMultipartUpload(ctx, &domain.MultipartSession{BlobID: id, Subject: "mail-service", UploadID: "mpu-3", PartCount: 10, CreatedAt: time.Now().UTC()})
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound, "a session needs an upload to belong to")
+
+	found, err := repo.FindMultipartUpload(ctx, id, "drive-service")
+	require.NoError(t, err)
+	assert.Equal(t, "mpu-1", found.UploadID)
+	assert.Equal(t, int32(10), found.PartCount)
+	tracked, err := repo.HasMultipartUpload(ctx, "mpu-1")
+	require.NoError(t, err)
+	assert.True(t, tracked)
+
+	require.NoError(t, repo.DeleteMultipartUpload(ctx, "mpu-1"))
+	_, err = repo.FindMultipartUpload(ctx, id, "drive-service")
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+	tracked, err = repo.HasMultipartUpload(ctx, "mpu-1")
+	require.NoError(t, err)
+	assert.False(t, tracked)
+}
+
+func TestResumableUploadProgress(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	id := domain.BlobID(validID)
+
+	upload, _ := domain.NewUpload(id, domain.Caller{Subject: "drive-service"}, 50*1024*1024, "video/mp4", time.Now().UTC())
+	require.NoError(t, repo.CreateUpload(ctx, upload))
+	session := &domain.MultipartSession{BlobID: id, Subject: "drive-service", UploadID: "mpu-1", PartCount: domain.MaxPartCount, CreatedAt: time.Now().UTC(), Resumable: true}
+	require.NoError(t, repo.RecordMultipartUpload(ctx, session))
+
+	n, err := repo.ClaimResumableNumber(ctx, "mpu-1", 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), n)
+	n, err = repo.ClaimResumableNumber(ctx, "mpu-1", 0, true)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), n, "parts and staging objects are numbered apart")
+	n, err = repo.ClaimResumableNumber(ctx, "mpu-1", 0, true)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), n)
+
+	session.ReceivedBytes, session.StagedOffset, session.Parts = 6<<20, 6<<20, []int32{2}
+	require.NoError(t, repo.AdvanceResumableUpload(ctx, session, 0))
+	err = repo.AdvanceResumableUpload(ctx, session, 0)
+	assert.ErrorIs(t, err, domain.ErrOffsetMismatch, "a session is only advanced from the offset it is at")
+	_, err = repo.ClaimResumableNumber(ctx, "mpu-1", 0, true)
+	assert.ErrorIs(t, err, domain.ErrOffsetMismatch)
+
+	found, err := repo.FindMultipartUpload(ctx, id, "drive-service")
+	require.NoError(t, err)
+	assert.True(t, found.Resumable)
+	assert.Equal(t, int64(6<<20), found.ReceivedBytes)
+	assert.Equal(t, int64(6<<20), found.StagedOffset)
+	assert.Equal(t, []int32{2}, found.Parts)
+	assert.Empty(t, found.Staged)
+}
+
+func TestListExpiredUploads_and_DeleteUpload(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	id := domain.BlobID(validID)
+
+	old, _ := domain.NewUpload(id, domain.Caller{Subject: "drive-service"}, 1024, "image/png", time.Now().UTC().Add(-2*time.Hour))
+	fresh, _ := domain.NewUpload(id, domain.Caller{Subject: "mail-service"}, 1024, "image/png", time.Now().UTC())
+	require.NoError(t, repo.CreateUpload(ctx, old))
+	require.NoError(t, repo.CreateUpload(ctx, fresh))
+	require.NoError(t, repo.RecordMultipartUpload(ctx, &domain.MultipartSession{BlobID: id, Subject: "drive-service", UploadID: "mpu-1", PartCount: 2, CreatedAt: time.Now().UTC()}))
+
+	again, _ := domain.NewUpload(id, domain.Caller{Subject: "drive-service"}, 1024, "image/png", time.Now().UTC())
+	assert.ErrorIs(t, repo.CreateUpload(ctx, again), domain.ErrUploadExists)
+
+	cutoff := time.Now().UTC().Add(-time.Hour)
+	expired, err := repo.ListExpiredUploads(ctx, cutoff, "", 10)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, old.ObjectKey, expired[0].ObjectKey)
+
+	deleted, err := repo.DeleteUpload(ctx, again)
+	require.NoError(t, err)
+	assert.False(t, deleted, "an upload is only deleted under its own key")
+	deleted, err = repo.DeleteUpload(ctx, old)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	_, err = repo.FindMultipartUpload(ctx, id, "drive-service")
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound, "tracked uploads cascade with the upload")
+	_, err = repo.FindUpload(ctx, id, "drive-service")
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+	found, err := repo.FindUpload(ctx, id, "mail-service")
+	require.NoError(t, err)
+	assert.Equal(t, fresh.ObjectKey, found.ObjectKey)
+}
+
+func TestCommitUpload(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	id := domain.BlobID(validID)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	commit := func(subject string) (*domain.Upload, *domain.Blob, error) {
+		upload, _ := domain.NewUpload(id, domain.Caller{Subject: subject, ClientID: "web"}, 1024, "image/png", now)
+		require.NoError(t, repo.CreateUpload(ctx, upload))
+		blob := upload.Blob()
+		blob.State, blob.CommittedAt, blob.DetectedContentType = domain.StateCommitted, &now, "image/png"
+		stored, err := repo.CommitUpload(ctx, upload, blob, domain.QuotaPolicy{})
+		return upload, stored, err
+	}
+
+	first, stored, err := commit("drive-service")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateCommitted, stored.State)
+	assert.Equal(t, first.ObjectKey, stored.R2Key, "the first upload's object becomes the blob's")
+
+	second, stored, err := commit("mail-service")
+	require.NoError(t, err)
+	assert.Equal(t, first.ObjectKey, stored.R2Key, "later uploads leave the blob as it is")
+	assert.NotEqual(t, second.ObjectKey, stored.R2Key)
+
+	for _, subject := range []string{"drive-service", "mail-service"} {
+		ok, err := repo.HasReference(ctx, id, subject)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		usage, err := repo.GetUsage(ctx, domain.Owner{Kind: domain.OwnerSubject, ID: subject})
+		require.NoError(t, err)
+		assert.Equal(t, domain.Usage{Bytes: 1024, Objects: 1}, *usage)
+	}
+	usage, err := repo.GetUsage(ctx, domain.Owner{Kind: domain.OwnerClient, ID: "web"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.Usage{Bytes: 2048, Objects: 2}, *usage)
+
+	_, err = repo.CommitUpload(ctx, second, second.Blob(), domain.QuotaPolicy{})
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound, "an upload is consumed by its commit")
+
+	var commits int
+	require.NoError(t, db.GetContext(ctx, &commits, `SELECT COUNT(*) FROM blob_events WHERE kind = 'COMMITTED'`))
+	assert.Equal(t, 1, commits, "only the upload that created the blob commits it")
+}
+
+func TestEventCursors(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+
+	seq, err := repo.FindEventCursor(ctx, "renditions")
+	require.NoError(t, err)
+	assert.Zero(t, seq)
+
+	require.NoError(t, repo.SaveEventCursor(ctx, "renditions", 7))
+	require.NoError(t, repo.SaveEventCursor(ctx, "renditions", 5))
+	seq, err = repo.FindEventCursor(ctx, "renditions")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), seq, "a cursor never moves back")
+}
+
+func TestBlobEvents_Outbox(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	id := domain.BlobID(validID)
+
+	blob, _ := domain.NewBlob(id, 1024, "image/png", time.Now().UTC())
+	require.NoError(t, repo.Create(ctx, blob))
+	require.NoError(t, repo.MarkCommitted(ctx, id, "", time.Now().UTC()))
+	assert.ErrorIs(t, repo.MarkCommitted(ctx, id, "", time.Now().UTC()), domain.ErrAlreadyCommitted)
+
+	require.NoError(t, repo.AddReference(ctx, id, "drive-service", "", domain.QuotaPolicy{}))
+	require.NoError(t, repo.RemoveReference(ctx, id, "drive-service", time.Now().UTC().Add(-2*time.Hour)))
+	_, deleted, err := repo.DeleteReleased(ctx, id, time.Now().UTC().Add(-time.Hour))
+	require.NoError(t, err)
+	require.True(t, deleted)
+
+	events, err := repo.ListEvents(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, domain.EventCommitted, events[0].Kind)
+	assert.Equal(t, domain.EventDeleted, events[1].Kind)
+	assert.Equal(t, id, events[1].BlobID)
+	assert.Equal(t, int64(1024), events[1].SizeBytes)
+	assert.Less(t, events[0].Seq, events[1].Seq)
+
+	events, err = repo.ListEvents(ctx, events[0].Seq, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	n, err := repo.DeleteEventsBefore(ctx, time.Now().UTC().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+}
+
+func TestUsage_ChargedOnCommitAndRelease(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	id := domain.BlobID(validID)
+	alice := domain.Owner{Kind: domain.OwnerSubject, ID: "alice"}
+	bob := domain.Owner{Kind: domain.OwnerSubject, ID: "bob"}
+	web := domain.Owner{Kind: domain.OwnerClient, ID: "web"}
+
+	blob, _ := domain.NewBlob(id, 1024, "image/png", time.Now().UTC())
+	require.NoError(t, repo.Create(ctx, blob))
+	require.NoError(t, repo.AddReference(ctx, id, "alice", "web", domain.QuotaPolicy{}))
+
+	u, err := repo.GetUsage(ctx, alice)
+	require.NoError(t, err)
+	assert.Zero(t, *u, "pending references are not charged")
+
+	require.NoError(t, repo.MarkCommitted(ctx, id, "", time.Now().UTC()))
+	require.NoError(t, repo.AddReference(ctx, id, "bob", "", domain.QuotaPolicy{}))
+	require.NoError(t, repo.AddReference(ctx, id, "bob", "", domain.QuotaPolicy{}), "re-adding is not charged twice")
+
+	for _, o := range []domain.Owner{alice, bob, web} {
+		u, err := repo.GetUsage(ctx, o)
+		require.NoError(t, err)
+		assert.Equal(t, domain.Usage{Bytes: 1024, Objects: 1}, *u, o.String())
+	}
+
+	require.NoError(t, repo.RemoveReference(ctx, id, "alice", time.Now().UTC()))
+	u, err = repo.GetUsage(ctx, alice)
+	require.NoError(t, err)
+	assert.Zero(t, *u)
+	u, err = repo.GetUsage(ctx, web)
+	require.NoError(t, err)
+	assert.Zero(t, *u)
+}
+
+func TestAddReference_QuotaEnforced(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	alice := domain.Owner{Kind: domain.OwnerSubject, ID: "alice"}
+	quotas := domain.QuotaPolicy{Subject: domain.Limits{MaxBytes: 1500}}
+
+	for _, id := range []domain.BlobID{domain.BlobID(validID), domain.BlobID(strings.Repeat("b", 64))} {
+		b, _ := domain.NewBlob(id, 1024, "image/png", time.Now().UTC())
+		require.NoError(t, repo.Create(ctx, b))
+		require.NoError(t, repo.MarkCommitted(ctx, id, "", time.Now().UTC()))
+	}
+
+	require.NoError(t, repo.AddReference(ctx, domain.BlobID(validID), "alice", "", quotas))
+	err := repo.AddReference(ctx, domain.BlobID(strings.Repeat("b", 64)), "alice", "", quotas)
+	assert.ErrorIs(t, err, domain.ErrQuotaExceeded)
+
+	ok, err := repo.HasReference(ctx, domain.BlobID(strings.Repeat("b", 64)), "alice")
+	require.NoError(t, err)
+	assert.False(t, ok, "a rejected reference is not recorded")
+	u, err := repo.GetUsage(ctx, alice)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Usage{Bytes: 1024, Objects: 1}, *u)
+}
+
+func TestRenditions_LinkedUntilSourceDeleted(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	source := domain.BlobID(validID)
+	thumb := domain.BlobID(strings.Repeat("b", 64))
+	now := time.Now().UTC()
+
+	for _, id := range []domain.BlobID{source, thumb} {
+		b, _ := domain.NewBlob(id, 1024, "image/png", now)
+		require.NoError(t, repo.Create(ctx, b))
+		require.NoError(t, repo.MarkCommitted(ctx, id, "", now))
+	}
+	_, err := repo.FindRendition(ctx, source, "jpeg-256")
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+
+	require.NoError(t, repo.LinkRendition(ctx, source, "jpeg-256", thumb))
+	got, err := repo.FindRendition(ctx, source, "jpeg-256")
+	require.NoError(t, err)
+	assert.Equal(t, thumb, got.ID)
+	ok, err := repo.IsRendition(ctx, thumb)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// A rendition someone referenced and released stays while it is linked.
+	require.NoError(t, repo.AddReference(ctx, thumb, "drive-service", "", domain.QuotaPolicy{}))
+	require.NoError(t, repo.RemoveReference(ctx, thumb, "drive-service", now.Add(-2*time.Hour)))
+	require.NoError(t, repo.AddReference(ctx, source, "drive-service", "", domain.QuotaPolicy{}))
+	require.NoError(t, repo.RemoveReference(ctx, source, "drive-service", now.Add(-2*time.Hour)))
+	released, err := repo.ListReleased(ctx, now.Add(-time.Hour), "", 10)
+	require.NoError(t, err)
+	require.Len(t, released, 1)
+	assert.Equal(t, source, released[0].ID)
+
+	_, deleted, err := repo.DeleteReleased(ctx, source, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.True(t, deleted)
+	ok, err = repo.IsRendition(ctx, thumb)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	released, err = repo.ListReleased(ctx, now.Add(-time.Hour), "", 10)
+	require.NoError(t, err)
+	require.Len(t, released, 1)
+	assert.Equal(t, thumb, released[0].ID, "the orphaned rendition is collectable")
+}
+
+func TestScanStates(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	clean := domain.BlobID(validID)
+	infected := domain.BlobID(strings.Repeat("b", 64))
+
+	for _, id := range []domain.BlobID{clean, infected} {
+		upload, _ := domain.NewUpload(id, domain.Caller{Subject: "drive-service"}, 1024, "text/plain", time.Now().UTC())
+		require.NoError(t, repo.CreateUpload(ctx, upload))
+		blob := upload.Blob()
+		blob.State, blob.DetectedContentType = domain.StateScanning, "text/plain; charset=utf-8"
+		_, err := repo.CommitUpload(ctx, upload, blob, domain.QuotaPolicy{})
+		require.NoError(t, err)
+	}
+
+	scanning, err := repo.ListScanning(ctx, "", 10)
+	require.NoError(t, err)
+	require.Len(t, scanning, 2)
+	assert.Equal(t, "text/plain; charset=utf-8", scanning[0].DetectedContentType)
+
+	require.NoError(t, repo.MarkCommitted(ctx, clean, scanning[0].DetectedContentType, time.Now().UTC()))
+	require.NoError(t, repo.MarkQuarantined(ctx, infected, "Eicar-Test-Signature"))
+	assert.ErrorIs(t, repo.MarkQuarantined(ctx, clean, "late"), domain.ErrAlreadyCommitted)
+
+	got, err := repo.FindByID(ctx, infected)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateQuarantined, got.State)
+	assert.Equal(t, "Eicar-Test-Signature", got.QuarantineReason)
+
+	scanning, err = repo.ListScanning(ctx, "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, scanning)
+}
+
+func TestDataKeys_ListStaleAndRewrap(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	oldKey := domain.BlobID(validID)
+	current := domain.BlobID(strings.Repeat("b", 64))
+	plain := domain.BlobID(strings.Repeat("c", 64))
+
+	for id, keyID := range map[domain.BlobID]string{oldKey: "old", current: "new", plain: ""} {
+		blob, _ := domain.NewBlob(id, 1024, "text/plain", time.Now().UTC())
+		if keyID != "" {
+			blob.KeyID, blob.WrappedKey = keyID, []byte("wrapped-"+keyID)
+		}
+		require.NoError(t, repo.Create(ctx, blob))
+	}
+
+	got, err := repo.FindByID(ctx, oldKey)
+	require.NoError(t, err)
+	assert.Equal(t, "old", got.KeyID)
+	assert.Equal(t, []byte("wrapped-old"), got.WrappedKey)
+
+	stale, err := repo.ListStaleKeys(ctx, "new", "", 10)
+	require.NoError(t, err)
+	require.Len(t, stale, 1)
+	assert.Equal(t, oldKey, stale[0].ID)
+
+	ok, err := repo.RewrapKey(ctx, oldKey, "other", "new", []byte("rewrapped"))
+	require.NoError(t, err)
+	assert.False(t, ok, "a key rewrapped concurrently must not be overwritten")
+	ok, err = repo.RewrapKey(ctx, oldKey, "old", "new", []byte("rewrapped"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	stale, err = repo.ListStaleKeys(ctx, "new", "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, stale)
+	got, err = repo.FindByID(ctx, oldKey)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("rewrapped"), got.WrappedKey)
+}
+
+func TestReplication_ListUnreplicatedAndMark(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	committed := domain.BlobID(validID)
+	pending := domain.BlobID(strings.Repeat("b", 64))
+
+	for _, id := range []domain.BlobID{committed, pending} {
+		blob, _ := domain.NewBlob(id, 1024, "text/plain", time.Now().UTC())
+		require.NoError(t, repo.Create(ctx, blob))
+	}
+	require.NoError(t, repo.MarkCommitted(ctx, committed, "text/plain", time.Now().UTC()))
+
+	blobs, err := repo.ListUnreplicated(ctx, "", 10)
+	require.NoError(t, err)
+	require.Len(t, blobs, 1, "only committed blobs are replicated")
+	assert.Equal(t, committed, blobs[0].ID)
+	assert.Nil(t, blobs[0].ReplicatedAt)
+
+	at := time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, repo.MarkReplicated(ctx, committed, at))
+
+	blobs, err = repo.ListUnreplicated(ctx, "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, blobs)
+	got, err := repo.FindByID(ctx, committed)
+	require.NoError(t, err)
+	require.NotNil(t, got.ReplicatedAt)
+	assert.True(t, at.Equal(*got.ReplicatedAt))
+}
+
+func TestMarkMissing_KeepsReferences(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	id := domain.BlobID(validID)
+	alice := domain.Owner{Kind: domain.OwnerSubject, ID: "alice"}
+	now := time.Now().UTC()
+
+	blob, _ := domain.NewBlob(id, 1024, "text/plain", now)
+	require.NoError(t, repo.Create(ctx, blob))
+	require.NoError(t, repo.AddReference(ctx, id, "alice", "web", domain.QuotaPolicy{}))
+	require.NoError(t, repo.MarkCommitted(ctx, id, "text/plain", now))
+
+	require.NoError(t, repo.MarkMissing(ctx, id))
+	got, err := repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateMissing, got.State)
+	assert.Nil(t, got.CommittedAt)
+	ok, err := repo.HasReference(ctx, id, "alice")
+	require.NoError(t, err)
+	assert.True(t, ok, "references survive the repair")
+	u, err := repo.GetUsage(ctx, alice)
+	require.NoError(t, err)
+	assert.Zero(t, *u)
+
+	err = repo.MarkMissing(ctx, id)
+	assert.ErrorIs(t, err, domain.ErrAlreadyCommitted)
+
+	upload, _ := domain.NewUpload(id, domain.Caller{Subject: "alice", ClientID: "web"}, 1024, "text/plain", now)
+	require.NoError(t, repo.CreateUpload(ctx, upload))
+	restored := upload.Blob()
+	restored.State, restored.CommittedAt = domain.StateCommitted, &now
+	stored, err := repo.CommitUpload(ctx, upload, restored, domain.QuotaPolicy{})
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateCommitted, stored.State)
+	assert.Equal(t, upload.ObjectKey, stored.R2Key)
+	u, err = repo.GetUsage(ctx, alice)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Usage{Bytes: 1024, Objects: 1}, *u, "a restored blob is charged once")
+}
+
+func TestListAll_and_FindByIDs(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	ids := []domain.BlobID{domain.BlobID(validID), domain.BlobID(strings.Repeat("b", 64))}
+	for _, id := range ids {
+		blob, _ := domain.NewBlob(id, 1, "text/plain", time.Now().UTC())
+		require.NoError(t, repo.Create(ctx, blob))
+	}
+
+	page, err := repo.ListAll(ctx, "", 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, ids[0], page[0].ID)
+	page, err = repo.ListAll(ctx, page[0].ID, 10)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, ids[1], page[0].ID)
+
+	found, err := repo.FindByIDs(ctx, []domain.BlobID{ids[1], domain.BlobID(strings.Repeat("c", 64))})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, ids[1], found[0].ID)
+}
+
+func TestRetention_HeldBlobsSurviveGC(t *testing.T) {
+	db := testhelper.NewTestDB(t)
+	repo := postgres.New(db)
+	ctx := context.Background()
+	retained := domain.BlobID(validID)
+	onHold := domain.BlobID(strings.Repeat("b", 64))
+	admin := domain.Caller{Subject: "records-officer", ClientID: "records-admin"}
+
+	for _, id := range []domain.BlobID{retained, onHold} {
+		blob, _ := domain.NewBlob(id, 1024, "application/pdf", time.Now().UTC())
+		require.NoError(t, repo.Create(ctx, blob))
+		require.NoError(t, repo.MarkCommitted(ctx, id, "", time.Now().UTC()))
+		require.NoError(t, repo.AddReference(ctx, id, "drive-service", "", domain.QuotaPolicy{}))
+		require.NoError(t, repo.RemoveReference(ctx, id, "drive-service", time.Now().UTC().Add(-2*time.Hour)))
+	}
+
+	until := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Microsecond)
+	require.NoError(t, repo.SetRetention(ctx, retained, until, admin))
+	require.NoError(t, repo.SetLegalHold(ctx, onHold, true, "litigation 2026-17", admin))
+
+	err := repo.SetRetention(ctx, retained, until.Add(-time.Hour), admin)
+	assert.ErrorIs(t, err, domain.ErrRetentionShortened)
+	err = repo.SetLegalHold(ctx, domain.BlobID(strings.Repeat("d", 64)), true, "", admin)
+	assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+
+	got, err := repo.FindByID(ctx, retained)
+	require.NoError(t, err)
+	require.NotNil(t, got.RetainUntil)
+	assert.True(t, until.Equal(*got.RetainUntil))
+	got, err = repo.FindByID(ctx, onHold)
+	require.NoError(t, err)
+	assert.True(t, got.LegalHold)
+
+	cutoff := time.Now().UTC().Add(-time.Hour)
+	released, err := repo.ListReleased(ctx, cutoff, "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, released)
+	for _, id := range []domain.BlobID{retained, onHold} {
+		_, deleted, err := repo.DeleteReleased(ctx, id, cutoff)
+		require.NoError(t, err)
+		assert.False(t, deleted, "held blobs are never deleted")
+	}
+
+	require.NoError(t, repo.SetLegalHold(ctx, onHold, false, "case closed", admin))
+	_, deleted, err := repo.DeleteReleased(ctx, onHold, cutoff)
+	require.NoError(t, err)
+	assert.True(t, deleted, "a released hold no longer protects the blob")
+
+	var actions []string
+	require.NoError(t, db.SelectContext(ctx, &actions,
+		`SELECT action FROM blob_audit_log WHERE client_id = 'records-admin' ORDER BY seq`))
+	assert.Equal(t, []string{"RETENTION_SET", "LEGAL_HOLD_SET", "LEGAL_HOLD_RELEASED"}, actions,
+		"refused changes are not audited, and the log outlives the blob")
+}
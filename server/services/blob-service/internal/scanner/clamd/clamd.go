@@ -0,0 +1,112 @@
+// Package clamdscanner implements domain.Scanner against a ClamAV daemon,
+// streaming content to it with the INSTREAM command.
+package clamdscanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+const chunkSize = 64 << 10
+
+// Scanner connects to clamd once per scan. clamd's StreamMaxLength must be
+// at least the largest blob, or those blobs can never be scanned.
+type Scanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// New returns a Scanner for the clamd listening at address, given as
+// "host:port" or "unix:/path/to/clamd.sock". Each scan is abandoned after
+// timeout, unless it is zero.
+func New(address string, timeout time.Duration) *Scanner {
+	network := "tcp"
+	if path, ok := strings.CutPrefix(address, "unix:"); ok {
+		network, address = "unix", path
+	}
+	return &Scanner{network: network, address: address, timeout: timeout}
+}
+
+func (s *Scanner) Scan(ctx context.Context, r io.Reader) (*domain.ScanResult, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return nil, fmt.Errorf("clamd: dial: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	sendErr := send(conn, r)
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		// clamd hangs up without a reply on some failures; the write error,
+		// if any, says more.
+		if sendErr != nil {
+			return nil, sendErr
+		}
+		return nil, fmt.Errorf("clamd: read reply: %w", err)
+	}
+	return parseReply(strings.TrimSuffix(reply, "\x00"))
+}
+
+// send writes the INSTREAM command followed by r in length-prefixed chunks
+// and the zero-length chunk that ends the stream.
+func send(w io.Writer, r io.Reader) error {
+	if _, err := io.WriteString(w, "zINSTREAM\x00"); err != nil {
+		return fmt.Errorf("clamd: send command: %w", err)
+	}
+	buf := make([]byte, 4+chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf, uint32(n))
+			if _, err := w.Write(buf[:4+n]); err != nil {
+				return fmt.Errorf("clamd: send chunk: %w", err)
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("clamd: read content: %w", err)
+		}
+	}
+	if _, err := w.Write(make([]byte, 4)); err != nil {
+		return fmt.Errorf("clamd: end stream: %w", err)
+	}
+	return nil
+}
+
+// parseReply interprets "stream: OK", "stream: <signature> FOUND" and
+// "<message> ERROR".
+func parseReply(reply string) (*domain.ScanResult, error) {
+	verdict := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case verdict == "OK":
+		return &domain.ScanResult{Clean: true}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return &domain.ScanResult{Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	default:
+		return nil, fmt.Errorf("clamd: %s", verdict)
+	}
+}
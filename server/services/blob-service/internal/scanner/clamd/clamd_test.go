@@ -0,0 +1,137 @@
+package clamdscanner_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	clamdscanner "github.com/barn0w1/hss-science/server/services/blob-service/internal/scanner/clamd"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd speaks enough of the clamd protocol to answer INSTREAM: content
+// containing the EICAR test string is reported infected, content longer
+// than maxLen exceeds the stream limit, and everything else is clean.
+type fakeClamd struct {
+	addr     string
+	maxLen   int
+	received chan []byte
+}
+
+func startFakeClamd(t *testing.T, maxLen int) *fakeClamd {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = lis.Close() })
+
+	f := &fakeClamd{addr: lis.Addr().String(), maxLen: maxLen, received: make(chan []byte, 10)}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeClamd) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	cmd, err := r.ReadString(0)
+	if err != nil || cmd != "zINSTREAM\x00" {
+		_, _ = io.WriteString(conn, "UNKNOWN COMMAND\x00")
+		return
+	}
+
+	var content []byte
+	for {
+		var size uint32
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return
+		}
+		if size == 0 {
+			break
+		}
+		if len(content)+int(size) > f.maxLen {
+			_, _ = io.WriteString(conn, "INSTREAM size limit exceeded. ERROR\x00")
+			// Drain the rest so closing does not reset the connection
+			// before the client reads the reply.
+			_, _ = io.Copy(io.Discard, r)
+			return
+		}
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return
+		}
+		content = append(content, chunk...)
+	}
+	f.received <- content
+
+	reply := "stream: OK\x00"
+	if bytes.Contains(content, []byte(eicar)) {
+		reply = "stream: Win.Test.EICAR_HDB-1 FOUND\x00"
+	}
+	_, _ = io.WriteString(conn, reply)
+}
+
+func TestScan_Clean(t *testing.T) {
+	f := startFakeClamd(t, 1<<20)
+	s := clamdscanner.New(f.addr, 5*time.Second)
+
+	content := bytes.Repeat([]byte("community notes "), 10_000)
+	result, err := s.Scan(context.Background(), bytes.NewReader(content))
+	require.NoError(t, err)
+	assert.True(t, result.Clean)
+	assert.Equal(t, content, <-f.received, "content spanning several chunks arrives intact")
+}
+
+func TestScan_Infected(t *testing.T) {
+	f := startFakeClamd(t, 1<<20)
+	s := clamdscanner.New(f.addr, 5*time.Second)
+
+	result, err := s.Scan(context.Background(), strings.NewReader(eicar))
+	require.NoError(t, err)
+	assert.False(t, result.Clean)
+	assert.Equal(t, "Win.Test.EICAR_HDB-1", result.Signature)
+}
+
+func TestScan_Empty(t *testing.T) {
+	f := startFakeClamd(t, 1<<20)
+	s := clamdscanner.New(f.addr, 5*time.Second)
+
+	result, err := s.Scan(context.Background(), bytes.NewReader(nil))
+	require.NoError(t, err)
+	assert.True(t, result.Clean)
+}
+
+func TestScan_ErrorReply(t *testing.T) {
+	f := startFakeClamd(t, 100)
+	s := clamdscanner.New(f.addr, 5*time.Second)
+
+	_, err := s.Scan(context.Background(), bytes.NewReader(make([]byte, 1000)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "size limit exceeded")
+}
+
+func TestScan_Unreachable(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	_, err = clamdscanner.New(addr, time.Second).Scan(context.Background(), strings.NewReader("x"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,174 @@
+package fsstorage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+// Handler serves the URLs produced by the Presigned* methods. It must be
+// mounted so that requests for baseURL + "/objects/{key}" reach it.
+func (s *Store) Handler() http.Handler {
+	return http.HandlerFunc(s.serveHTTP)
+}
+
+func (s *Store) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	key := path.Base(r.URL.Path)
+	if !validKey(key) {
+		http.NotFound(w, r)
+		return
+	}
+
+	method := r.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	q := r.URL.Query()
+	if !s.verify(method, key, q) {
+		http.Error(w, "invalid or expired signature", http.StatusForbidden)
+		return
+	}
+
+	view, err := s.requestView(r, q)
+	if err != nil {
+		http.Error(w, "SSE-C headers do not match the signed customer key", http.StatusForbidden)
+		return
+	}
+
+	switch method {
+	case http.MethodGet:
+		view.serveObject(w, r, key, q)
+	case http.MethodPut:
+		view.serveUpload(w, r, key, q)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// serveObject applies the response overrides signed into the URL the way S3
+// applies response-* query parameters.
+func (s *Store) serveObject(w http.ResponseWriter, r *http.Request, key string, q url.Values) {
+	if rng := q.Get("range"); rng != "" && r.Header.Get("Range") != rng {
+		http.Error(w, "Range does not match the signed range", http.StatusForbidden)
+		return
+	}
+
+	obj, err := s.openObject(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if errors.Is(err, errCustomerKey) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.Error("fs: open object", "key", key, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = obj.Close() }()
+
+	contentType := "application/octet-stream"
+	if ct := q.Get("response-content-type"); ct != "" {
+		contentType = ct
+	}
+	w.Header().Set("Content-Type", contentType)
+	if cd := q.Get("response-content-disposition"); cd != "" {
+		w.Header().Set("Content-Disposition", cd)
+	}
+	if cc := q.Get("response-cache-control"); cc != "" {
+		w.Header().Set("Cache-Control", cc)
+	}
+	http.ServeContent(w, r, "", obj.modTime, obj)
+}
+
+// serveUpload enforces the length, type and checksum signed into the URL.
+func (s *Store) serveUpload(w http.ResponseWriter, r *http.Request, key string, q url.Values) {
+	size, err := strconv.ParseInt(q.Get("size"), 10, 64)
+	if err != nil || size < 0 {
+		http.Error(w, "invalid size", http.StatusBadRequest)
+		return
+	}
+	if r.ContentLength != size {
+		http.Error(w, "Content-Length does not match the signed size", http.StatusForbidden)
+		return
+	}
+	if ct := q.Get("contentType"); ct != "" && r.Header.Get("Content-Type") != ct {
+		http.Error(w, "Content-Type does not match the signed content type", http.StatusForbidden)
+		return
+	}
+
+	var etag string
+	if uploadID := q.Get("uploadId"); uploadID != "" {
+		n, perr := strconv.ParseInt(q.Get("partNumber"), 10, 32)
+		if perr != nil || n < 1 || int32(n) > domain.MaxPartCount {
+			http.Error(w, "invalid partNumber", http.StatusBadRequest)
+			return
+		}
+		etag, err = s.putPart(key, uploadID, int32(n), r.Body, size)
+	} else {
+		etag, err = s.commitObject(key, r.Body, size, q.Get("checksum"))
+	}
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		http.Error(w, "no such upload", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, errBodyMismatch) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.Error("fs: store upload", "key", key, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Store) signedURL(method, key string, q url.Values, ttl time.Duration) (string, time.Time) {
+	expiresAt := time.Now().Add(ttl)
+	q.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	q.Set("signature", s.signature(method, key, q))
+	return s.baseURL + "/objects/" + key + "?" + q.Encode(), expiresAt
+}
+
+// verify checks the signature and expiry of a request. The signature covers
+// every query parameter except itself, so none can be added or altered.
+func (s *Store) verify(method, key string, q url.Values) bool {
+	sig, err := hex.DecodeString(q.Get("signature"))
+	if err != nil {
+		return false
+	}
+	q.Del("signature")
+	want, _ := hex.DecodeString(s.signature(method, key, q))
+	if !hmac.Equal(sig, want) {
+		return false
+	}
+	exp, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	return err == nil && time.Now().Unix() <= exp
+}
+
+func (s *Store) signature(method, key string, q url.Values) string {
+	unsigned := url.Values{}
+	for k, v := range q {
+		if k != "signature" {
+			unsigned[k] = v
+		}
+	}
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(method + "\n" + key + "\n" + unsigned.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,241 @@
+package fsstorage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+// SSE-C request headers, as S3 names them.
+const (
+	sseAlgorithmHeader = "X-Amz-Server-Side-Encryption-Customer-Algorithm"
+	sseKeyHeader       = "X-Amz-Server-Side-Encryption-Customer-Key"
+	sseKeyMD5Header    = "X-Amz-Server-Side-Encryption-Customer-Key-Md5"
+)
+
+// Sealed objects are stored as an IV and a key tag followed by AES-256-CTR
+// ciphertext. The tag lets a read with the wrong key be refused.
+const (
+	sealedExt       = ".sse"
+	sealedHeaderLen = aes.BlockSize + sha256.Size
+)
+
+// errCustomerKey is returned when an object is read without the customer key
+// it was written with, or with one when it was written without.
+var errCustomerKey = errors.New("fs: object requires a different customer key")
+
+func (s *Store) WithCustomerKey(key []byte) domain.ObjectStorage {
+	view := *s
+	view.customerKey = key
+	return &view
+}
+
+// plain returns the store without a customer key.
+func (s *Store) plain() *Store {
+	view := *s
+	view.customerKey = nil
+	return &view
+}
+
+// sseHeaders returns the headers presigned requests from this view must
+// carry, and signs the key's MD5 into q so that no other key is accepted.
+func (s *Store) sseHeaders(q url.Values) map[string]string {
+	headers := map[string]string{}
+	if s.customerKey == nil {
+		return headers
+	}
+	keyMD5 := customerKeyMD5(s.customerKey)
+	q.Set("sseKeyMD5", keyMD5)
+	headers[sseAlgorithmHeader] = "AES256"
+	headers[sseKeyHeader] = base64.StdEncoding.EncodeToString(s.customerKey)
+	headers[sseKeyMD5Header] = keyMD5
+	return headers
+}
+
+// requestView returns the view selected by a request's SSE-C headers, which
+// must match the key MD5 signed into its URL, if any.
+func (s *Store) requestView(r *http.Request, q url.Values) (*Store, error) {
+	want := q.Get("sseKeyMD5")
+	if want == "" {
+		return s, nil
+	}
+	if r.Header.Get(sseAlgorithmHeader) != "AES256" || r.Header.Get(sseKeyMD5Header) != want {
+		return nil, errCustomerKey
+	}
+	key, err := base64.StdEncoding.DecodeString(r.Header.Get(sseKeyHeader))
+	if err != nil || len(key) != domain.DataKeySize || customerKeyMD5(key) != want {
+		return nil, errCustomerKey
+	}
+	view := *s
+	view.customerKey = key
+	return &view, nil
+}
+
+func customerKeyMD5(key []byte) string {
+	sum := md5.Sum(key)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// storedPath is where this view keeps the object under key.
+func (s *Store) storedPath(key string) string {
+	if s.customerKey != nil {
+		return s.objectPath(key) + sealedExt
+	}
+	return s.objectPath(key)
+}
+
+// otherPath is where a view with, or without, a customer key would keep the
+// object under key if this one has none, or has one.
+func (s *Store) otherPath(key string) string {
+	if s.customerKey != nil {
+		return s.objectPath(key)
+	}
+	return s.objectPath(key) + sealedExt
+}
+
+// dropOtherVariant removes the copy of the object stored through the other
+// kind of view, once this view has written its own.
+func (s *Store) dropOtherVariant(key string) error {
+	if err := os.Remove(s.otherPath(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("fs: remove replaced object: %w", err)
+	}
+	return nil
+}
+
+// seal writes the sealed-object header to w and returns a writer that
+// encrypts into it with the view's customer key.
+func (s *Store) seal(w io.Writer) (io.Writer, error) {
+	block, err := aes.NewCipher(s.customerKey)
+	if err != nil {
+		return nil, fmt.Errorf("fs: customer key: %w", err)
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, fmt.Errorf("fs: generate iv: %w", err)
+	}
+	if _, err := w.Write(append(iv, keyTag(iv, s.customerKey)...)); err != nil {
+		return nil, err
+	}
+	return cipher.StreamWriter{S: cipher.NewCTR(block, iv), W: w}, nil
+}
+
+func keyTag(iv, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(iv)
+	return mac.Sum(nil)
+}
+
+// object is an open object, decrypted if it is sealed.
+type object struct {
+	io.ReadSeeker
+	io.Closer
+	size    int64
+	modTime time.Time
+}
+
+// openObject opens the object under key as this view sees it, failing with
+// errCustomerKey if it was stored with another key or none.
+func (s *Store) openObject(key string) (*object, error) {
+	f, err := os.Open(s.storedPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		if _, serr := os.Stat(s.otherPath(key)); serr == nil {
+			return nil, errCustomerKey
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if s.customerKey == nil {
+		return &object{ReadSeeker: f, Closer: f, size: info.Size(), modTime: info.ModTime()}, nil
+	}
+
+	r, err := s.unseal(f, info.Size()-sealedHeaderLen)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &object{ReadSeeker: r, Closer: f, size: info.Size() - sealedHeaderLen, modTime: info.ModTime()}, nil
+}
+
+func (s *Store) unseal(f *os.File, size int64) (*ctrReader, error) {
+	header := make([]byte, sealedHeaderLen)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return nil, fmt.Errorf("fs: read sealed header: %w", err)
+	}
+	iv, tag := header[:aes.BlockSize], header[aes.BlockSize:]
+	if !hmac.Equal(tag, keyTag(iv, s.customerKey)) {
+		return nil, errCustomerKey
+	}
+	block, err := aes.NewCipher(s.customerKey)
+	if err != nil {
+		return nil, fmt.Errorf("fs: customer key: %w", err)
+	}
+	return &ctrReader{f: f, block: block, iv: iv, size: size, stream: cipher.NewCTR(block, iv)}, nil
+}
+
+// ctrReader decrypts a sealed object's ciphertext. It can seek because a CTR
+// keystream can be started at any block.
+type ctrReader struct {
+	f      *os.File
+	block  cipher.Block
+	iv     []byte
+	size   int64
+	pos    int64
+	stream cipher.Stream
+}
+
+func (c *ctrReader) Read(p []byte) (int, error) {
+	n, err := c.f.Read(p)
+	c.stream.XORKeyStream(p[:n], p[:n])
+	c.pos += int64(n)
+	return n, err
+}
+
+func (c *ctrReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += c.pos
+	case io.SeekEnd:
+		offset += c.size
+	}
+	if offset < 0 {
+		return 0, errors.New("fs: negative seek position")
+	}
+	if _, err := c.f.Seek(sealedHeaderLen+offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	ctr := make([]byte, aes.BlockSize)
+	copy(ctr, c.iv)
+	carry := uint64(offset / aes.BlockSize)
+	for i := len(ctr) - 1; i >= 0 && carry > 0; i-- {
+		sum := uint64(ctr[i]) + carry&0xff
+		ctr[i] = byte(sum)
+		carry = carry>>8 + sum>>8
+	}
+	c.stream = cipher.NewCTR(c.block, ctr)
+	skip := make([]byte, offset%aes.BlockSize)
+	c.stream.XORKeyStream(skip, skip)
+	c.pos = offset
+	return offset, nil
+}
@@ -0,0 +1,529 @@
+// Package fsstorage implements domain.ObjectStorage on the local filesystem
+// for development. Presigned URLs point at Store.Handler and carry an HMAC.
+package fsstorage
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+var nameRE = regexp.MustCompile(`^[0-9A-Za-z_-]+$`)
+
+// keyRE admits dot-separated names, as domain.NewObjectKey builds, but no
+// empty segment, so ".." and "/" never reach the filesystem.
+var keyRE = regexp.MustCompile(`^[0-9A-Za-z_-]+(\.[0-9A-Za-z_-]+)*$`)
+
+// validKey reports whether key can be stored. Keys ending in sealedExt would
+// be taken for the sealed copy of another key.
+func validKey(key string) bool {
+	return keyRE.MatchString(key) && !strings.HasSuffix(key, sealedExt)
+}
+
+var errBodyMismatch = errors.New("body does not match the signed size or checksum")
+
+type Store struct {
+	root    string
+	baseURL string
+	secret  []byte
+	// customerKey is set on views returned by WithCustomerKey.
+	customerKey []byte
+}
+
+type uploadMeta struct {
+	Key         string    `json:"key"`
+	ContentType string    `json:"content_type"`
+	InitiatedAt time.Time `json:"initiated_at"`
+}
+
+// New creates a Store rooted at dir. baseURL is the externally reachable
+// address where Handler is mounted, without a trailing slash.
+func New(dir, baseURL string, secret []byte) (*Store, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("fs: signing secret is required")
+	}
+	for _, sub := range []string{"objects", "uploads", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o750); err != nil {
+			return nil, fmt.Errorf("fs: create %s dir: %w", sub, err)
+		}
+	}
+	return &Store{
+		root:    dir,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  secret,
+	}, nil
+}
+
+func (s *Store) PresignedPutURL(_ context.Context, key string, pc domain.PutConstraints, ttl time.Duration) (*domain.PresignedRequest, error) {
+	if !validKey(key) {
+		return nil, fmt.Errorf("fs: invalid key %q", key)
+	}
+	q := url.Values{"size": {strconv.FormatInt(pc.SizeBytes, 10)}}
+	headers := s.sseHeaders(q)
+	if pc.ContentType != "" {
+		q.Set("contentType", pc.ContentType)
+		headers["Content-Type"] = pc.ContentType
+	}
+	if pc.ChecksumSHA256 != "" {
+		q.Set("checksum", pc.ChecksumSHA256)
+	}
+	u, expiresAt := s.signedURL("PUT", key, q, ttl)
+	return &domain.PresignedRequest{URL: u, Headers: headers, ExpiresAt: expiresAt}, nil
+}
+
+func (s *Store) PresignedGetURL(_ context.Context, key string, o domain.GetOptions, ttl time.Duration) (*domain.PresignedRequest, error) {
+	if !validKey(key) {
+		return nil, fmt.Errorf("fs: invalid key %q", key)
+	}
+	q := url.Values{}
+	headers := s.sseHeaders(q)
+	if o.ContentDisposition != "" {
+		q.Set("response-content-disposition", o.ContentDisposition)
+	}
+	if o.ContentType != "" {
+		q.Set("response-content-type", o.ContentType)
+	}
+	if o.CacheControl != "" {
+		q.Set("response-cache-control", o.CacheControl)
+	}
+	if rng := o.Range(); rng != "" {
+		q.Set("range", rng)
+		headers["Range"] = rng
+	}
+	u, expiresAt := s.signedURL("GET", key, q, ttl)
+	return &domain.PresignedRequest{URL: u, Headers: headers, ExpiresAt: expiresAt}, nil
+}
+
+func (s *Store) CreateMultipartUpload(_ context.Context, key, contentType string) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("fs: invalid key %q", key)
+	}
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("fs: generate upload id: %w", err)
+	}
+	uploadID := hex.EncodeToString(b[:])
+
+	dir := s.uploadDir(uploadID)
+	if err := os.Mkdir(dir, 0o750); err != nil {
+		return "", fmt.Errorf("fs: create upload: %w", err)
+	}
+	meta, err := json.Marshal(uploadMeta{Key: key, ContentType: contentType, InitiatedAt: time.Now().UTC()})
+	if err != nil {
+		return "", fmt.Errorf("fs: encode upload: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "meta.json"), meta, 0o640); err != nil {
+		return "", fmt.Errorf("fs: write upload: %w", err)
+	}
+	return uploadID, nil
+}
+
+func (s *Store) PresignedPartURL(_ context.Context, key, uploadID string, partNumber int32, sizeBytes int64, ttl time.Duration) (*domain.PresignedRequest, error) {
+	if !validKey(key) || !nameRE.MatchString(uploadID) {
+		return nil, fmt.Errorf("fs: invalid key %q or upload id %q", key, uploadID)
+	}
+	q := url.Values{
+		"uploadId":   {uploadID},
+		"partNumber": {strconv.Itoa(int(partNumber))},
+		"size":       {strconv.FormatInt(sizeBytes, 10)},
+	}
+	headers := s.sseHeaders(q)
+	u, expiresAt := s.signedURL("PUT", key, q, ttl)
+	return &domain.PresignedRequest{URL: u, Headers: headers, ExpiresAt: expiresAt}, nil
+}
+
+func (s *Store) UploadPart(_ context.Context, key, uploadID string, partNumber int32, body io.Reader, sizeBytes int64) (string, error) {
+	if !validKey(key) || !nameRE.MatchString(uploadID) {
+		return "", fmt.Errorf("fs: invalid key %q or upload id %q", key, uploadID)
+	}
+	if partNumber < 1 || partNumber > domain.MaxPartCount {
+		return "", fmt.Errorf("fs: invalid part number %d", partNumber)
+	}
+	etag, err := s.putPart(key, uploadID, partNumber, body, sizeBytes)
+	if err != nil {
+		return "", fmt.Errorf("fs: upload part: %w", err)
+	}
+	return etag, nil
+}
+
+func (s *Store) CompleteMultipartUpload(_ context.Context, key, uploadID string, parts []domain.CompletedPart) error {
+	if _, err := s.loadUpload(key, uploadID); err != nil {
+		return err
+	}
+
+	dir := s.uploadDir(uploadID)
+	var last int32
+	readers := make([]io.Reader, 0, len(parts))
+	for _, p := range parts {
+		if p.PartNumber <= last {
+			return fmt.Errorf("fs: parts must be in ascending order, got %d after %d", p.PartNumber, last)
+		}
+		last = p.PartNumber
+
+		etag, err := os.ReadFile(partPath(dir, p.PartNumber, ".etag"))
+		if err != nil {
+			return fmt.Errorf("fs: part %d not uploaded", p.PartNumber)
+		}
+		if string(etag) != p.ETag {
+			return fmt.Errorf("fs: part %d etag mismatch", p.PartNumber)
+		}
+		f, err := os.Open(partPath(dir, p.PartNumber, ".part"))
+		if err != nil {
+			return fmt.Errorf("fs: open part %d: %w", p.PartNumber, err)
+		}
+		defer func() { _ = f.Close() }()
+		readers = append(readers, f)
+	}
+
+	if err := s.writeFile(s.storedPath(key), io.MultiReader(readers...)); err != nil {
+		return err
+	}
+	if err := s.dropOtherVariant(key); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("fs: remove upload: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) AbortMultipartUpload(_ context.Context, _, uploadID string) error {
+	if !nameRE.MatchString(uploadID) {
+		return nil
+	}
+	if err := os.RemoveAll(s.uploadDir(uploadID)); err != nil {
+		return fmt.Errorf("fs: abort upload: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) ListMultipartUploads(_ context.Context) ([]domain.MultipartUpload, error) {
+	entries, err := os.ReadDir(filepath.Join(s.root, "uploads"))
+	if err != nil {
+		return nil, fmt.Errorf("fs: list uploads: %w", err)
+	}
+	var uploads []domain.MultipartUpload
+	for _, e := range entries {
+		meta, err := s.readMeta(e.Name())
+		if err != nil {
+			continue
+		}
+		uploads = append(uploads, domain.MultipartUpload{
+			Key:         meta.Key,
+			UploadID:    e.Name(),
+			InitiatedAt: meta.InitiatedAt,
+		})
+	}
+	return uploads, nil
+}
+
+func (s *Store) ListParts(_ context.Context, key, uploadID string) ([]domain.UploadedPart, error) {
+	if _, err := s.loadUpload(key, uploadID); err != nil {
+		return nil, err
+	}
+
+	dir := s.uploadDir(uploadID)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("fs: list parts: %w", err)
+	}
+	var parts []domain.UploadedPart
+	for _, e := range entries {
+		num, ok := strings.CutSuffix(e.Name(), ".part")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(num, 10, 32)
+		if err != nil {
+			continue
+		}
+		etag, err := os.ReadFile(partPath(dir, int32(n), ".etag"))
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, fmt.Errorf("fs: stat part: %w", err)
+		}
+		parts = append(parts, domain.UploadedPart{
+			PartNumber: int32(n),
+			ETag:       string(etag),
+			SizeBytes:  info.Size(),
+		})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+func (s *Store) StatObject(_ context.Context, key string) (*domain.ObjectInfo, error) {
+	obj, err := s.open(key)
+	if err != nil {
+		return nil, err
+	}
+	_ = obj.Close()
+	return &domain.ObjectInfo{SizeBytes: obj.size}, nil
+}
+
+func (s *Store) GetObject(_ context.Context, key string) (io.ReadCloser, error) {
+	obj, err := s.open(key)
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (s *Store) GetObjectRange(_ context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	obj, err := s.open(key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := obj.Seek(offset, io.SeekStart); err != nil {
+		_ = obj.Close()
+		return nil, fmt.Errorf("fs: seek object: %w", err)
+	}
+	if length < 0 {
+		return obj, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(obj, length), obj}, nil
+}
+
+func (s *Store) PutObject(_ context.Context, key string, body io.Reader, sizeBytes int64, _, checksumSHA256 string) error {
+	if !validKey(key) {
+		return fmt.Errorf("fs: invalid key %q", key)
+	}
+	if _, err := s.commitObject(key, body, sizeBytes, checksumSHA256); err != nil {
+		return fmt.Errorf("fs: put object: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) DeleteObject(_ context.Context, key string) error {
+	if !validKey(key) {
+		return nil
+	}
+	for _, p := range []string{s.objectPath(key), s.objectPath(key) + sealedExt} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("fs: delete object: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListObjects reads the whole object directory on every call, which is fine
+// for the data sets this backend is meant for.
+func (s *Store) ListObjects(_ context.Context, afterKey string, limit int) ([]domain.StoredObject, error) {
+	entries, err := os.ReadDir(filepath.Join(s.root, "objects"))
+	if err != nil {
+		return nil, fmt.Errorf("fs: list objects: %w", err)
+	}
+	sizes := make(map[string]int64, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fs: list objects: %w", err)
+		}
+		key, sealed := strings.CutSuffix(e.Name(), sealedExt)
+		if key <= afterKey || !validKey(key) {
+			continue
+		}
+		if sealed {
+			sizes[key] = info.Size() - sealedHeaderLen
+		} else {
+			sizes[key] = info.Size()
+		}
+	}
+
+	keys := make([]string, 0, len(sizes))
+	for key := range sizes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+	objects := make([]domain.StoredObject, len(keys))
+	for i, key := range keys {
+		objects[i] = domain.StoredObject{Key: key, SizeBytes: sizes[key]}
+	}
+	return objects, nil
+}
+
+// Ping checks that the store's object directory is still accessible.
+func (s *Store) Ping(_ context.Context) error {
+	info, err := os.Stat(filepath.Join(s.root, "objects"))
+	if err != nil {
+		return fmt.Errorf("fs: ping: %w", err)
+	}
+	if !info.IsDir() {
+		return errors.New("fs: ping: objects is not a directory")
+	}
+	return nil
+}
+
+// open opens the object under key, mapping a missing object to
+// ErrObjectNotFound.
+func (s *Store) open(key string) (*object, error) {
+	if !validKey(key) {
+		return nil, domain.ErrObjectNotFound
+	}
+	obj, err := s.openObject(key)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, domain.ErrObjectNotFound
+	case errors.Is(err, errCustomerKey):
+		return nil, err
+	case err != nil:
+		return nil, fmt.Errorf("fs: open object: %w", err)
+	}
+	return obj, nil
+}
+
+// commitObject commits body as the object under key and returns its ETag.
+func (s *Store) commitObject(key string, body io.Reader, sizeBytes int64, checksumSHA256 string) (string, error) {
+	etag, err := s.commit(s.storedPath(key), body, sizeBytes, checksumSHA256)
+	if err != nil {
+		return "", err
+	}
+	return etag, s.dropOtherVariant(key)
+}
+
+// putPart stores the body of a part upload and returns its ETag. Parts are
+// kept in plaintext, and sealed when they are combined into the object.
+func (s *Store) putPart(key, uploadID string, partNumber int32, body io.Reader, sizeBytes int64) (string, error) {
+	if _, err := s.loadUpload(key, uploadID); err != nil {
+		return "", err
+	}
+	dir := s.uploadDir(uploadID)
+	etag, err := s.plain().commit(partPath(dir, partNumber, ".part"), body, sizeBytes, "")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(partPath(dir, partNumber, ".etag"), []byte(etag), 0o640); err != nil {
+		return "", fmt.Errorf("fs: write part etag: %w", err)
+	}
+	return etag, nil
+}
+
+// commit writes body to dst only if it has the expected size and checksum,
+// so a rejected body never replaces an existing file.
+func (s *Store) commit(dst string, body io.Reader, sizeBytes int64, checksumSHA256 string) (string, error) {
+	md5h, sha := md5.New(), sha256.New()
+	tmp, n, err := s.writeTemp(io.LimitReader(body, sizeBytes+1), io.MultiWriter(md5h, sha))
+	if err != nil {
+		return "", err
+	}
+	if n != sizeBytes || (checksumSHA256 != "" && hex.EncodeToString(sha.Sum(nil)) != checksumSHA256) {
+		_ = os.Remove(tmp)
+		return "", errBodyMismatch
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("fs: rename: %w", err)
+	}
+	return `"` + hex.EncodeToString(md5h.Sum(nil)) + `"`, nil
+}
+
+// writeFile writes r to a temporary file and renames it over dst so readers
+// never observe a partially written object.
+func (s *Store) writeFile(dst string, r io.Reader) error {
+	tmp, _, err := s.writeTemp(r, io.Discard)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("fs: rename: %w", err)
+	}
+	return nil
+}
+
+// writeTemp copies r into a new temporary file and into w. The caller must
+// rename or remove the file.
+func (s *Store) writeTemp(r io.Reader, w io.Writer) (string, int64, error) {
+	tmp, err := os.CreateTemp(filepath.Join(s.root, "tmp"), "upload-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("fs: create temp file: %w", err)
+	}
+
+	var out io.Writer = tmp
+	if s.customerKey != nil {
+		if out, err = s.seal(tmp); err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+			return "", 0, err
+		}
+	}
+	n, err := io.Copy(io.MultiWriter(out, w), r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", 0, fmt.Errorf("fs: write: %w", err)
+	}
+	return tmp.Name(), n, nil
+}
+
+func (s *Store) loadUpload(key, uploadID string) (*uploadMeta, error) {
+	if !nameRE.MatchString(uploadID) {
+		return nil, domain.ErrSessionNotFound
+	}
+	meta, err := s.readMeta(uploadID)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, domain.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if meta.Key != key {
+		return nil, domain.ErrSessionNotFound
+	}
+	return meta, nil
+}
+
+func (s *Store) readMeta(uploadID string) (*uploadMeta, error) {
+	raw, err := os.ReadFile(filepath.Join(s.uploadDir(uploadID), "meta.json"))
+	if err != nil {
+		return nil, err
+	}
+	var meta uploadMeta
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, fmt.Errorf("fs: decode upload %s: %w", uploadID, err)
+	}
+	return &meta, nil
+}
+
+func (s *Store) objectPath(key string) string {
+	return filepath.Join(s.root, "objects", key)
+}
+
+func (s *Store) uploadDir(uploadID string) string {
+	return filepath.Join(s.root, "uploads", uploadID)
+}
+
+func partPath(dir string, partNumber int32, ext string) string {
+	return filepath.Join(dir, strconv.Itoa(int(partNumber))+ext)
+}
@@ -0,0 +1,454 @@
+package fsstorage_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+	fsstorage "github.com/barn0w1/hss-science/server/services/blob-service/internal/storage/fs"
+)
+
+const testKey = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func newStore(t *testing.T) *fsstorage.Store {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	store, err := fsstorage.New(t.TempDir(), srv.URL, bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, err)
+	mux.Handle("/objects/", store.Handler())
+	return store
+}
+
+func doRequest(t *testing.T, method, url string, body []byte) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func doPut(t *testing.T, presigned *domain.PresignedRequest, body []byte) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPut, presigned.URL, bytes.NewReader(body))
+	require.NoError(t, err)
+	for k, v := range presigned.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func constraintsFor(content []byte) domain.PutConstraints {
+	sum := sha256.Sum256(content)
+	return domain.PutConstraints{
+		SizeBytes:      int64(len(content)),
+		ContentType:    "text/plain",
+		ChecksumSHA256: hex.EncodeToString(sum[:]),
+	}
+}
+
+func TestPutAndGetThroughSignedURLs(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+
+	put, err := store.PresignedPutURL(ctx, testKey, constraintsFor([]byte("hello")), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", put.Headers["Content-Type"])
+	resp := doPut(t, put, []byte("hello"))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	info, err := store.StatObject(ctx, testKey)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), info.SizeBytes)
+
+	get, err := store.PresignedGetURL(ctx, testKey, domain.GetOptions{}, time.Minute)
+	require.NoError(t, err)
+	resp = doRequest(t, http.MethodGet, get.URL, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "hello", string(body))
+}
+
+func TestUploadKeys(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+	key := domain.NewObjectKey(domain.BlobID(testKey))
+
+	put, err := store.PresignedPutURL(ctx, key, constraintsFor([]byte("hello")), time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, doPut(t, put, []byte("hello")).StatusCode)
+
+	get, err := store.PresignedGetURL(ctx, key, domain.GetOptions{}, time.Minute)
+	require.NoError(t, err)
+	resp := doRequest(t, http.MethodGet, get.URL, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "hello", string(body))
+
+	for _, bad := range []string{testKey + "..x", "." + testKey, testKey + ".", testKey + ".sse", "../" + testKey} {
+		_, err := store.PresignedPutURL(ctx, bad, constraintsFor([]byte("hello")), time.Minute)
+		assert.ErrorContains(t, err, "invalid key", bad)
+	}
+}
+
+func TestGetURL_AppliesSignedOverrides(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+	content := []byte("0123456789")
+	require.NoError(t, store.PutObject(ctx, testKey, bytes.NewReader(content), 10, "text/plain", constraintsFor(content).ChecksumSHA256))
+
+	get, err := store.PresignedGetURL(ctx, testKey, domain.GetOptions{
+		ContentDisposition: `attachment; filename="digits.txt"`,
+		ContentType:        "text/plain; charset=utf-8",
+		CacheControl:       "private, max-age=60",
+		Offset:             2,
+		Length:             3,
+	}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Range": "bytes=2-4"}, get.Headers)
+
+	resp := doRequest(t, http.MethodGet, get.URL, nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "signed range must be sent")
+
+	req, err := http.NewRequest(http.MethodGet, get.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Range", get.Headers["Range"])
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "234", string(body))
+	assert.Equal(t, `attachment; filename="digits.txt"`, resp.Header.Get("Content-Disposition"))
+	assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "private, max-age=60", resp.Header.Get("Cache-Control"))
+}
+
+func TestHandler_RejectsBadSignatures(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+
+	c := constraintsFor([]byte("x"))
+	put, err := store.PresignedPutURL(ctx, testKey, c, time.Minute)
+	require.NoError(t, err)
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := *put
+		tampered.URL = strings.Replace(put.URL, "expires=", "expires=9", 1)
+		resp := doPut(t, &tampered, []byte("x"))
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+	t.Run("wrong method", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, put.URL, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+	t.Run("expired", func(t *testing.T) {
+		expired, err := store.PresignedPutURL(ctx, testKey, c, -time.Minute)
+		require.NoError(t, err)
+		resp := doPut(t, expired, []byte("x"))
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	_, err = store.StatObject(ctx, testKey)
+	assert.ErrorIs(t, err, domain.ErrObjectNotFound)
+}
+
+func TestMultipartLifecycle(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+
+	uploadID, err := store.CreateMultipartUpload(ctx, testKey, "text/plain")
+	require.NoError(t, err)
+
+	uploads, err := store.ListMultipartUploads(ctx)
+	require.NoError(t, err)
+	require.Len(t, uploads, 1)
+	assert.Equal(t, testKey, uploads[0].Key)
+
+	var parts []domain.CompletedPart
+	for i, chunk := range []string{"hello, ", "world"} {
+		part, err := store.PresignedPartURL(ctx, testKey, uploadID, int32(i+1), int64(len(chunk)), time.Minute)
+		require.NoError(t, err)
+		resp := doPut(t, part, []byte(chunk))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		parts = append(parts, domain.CompletedPart{PartNumber: int32(i + 1), ETag: resp.Header.Get("ETag")})
+	}
+
+	listed, err := store.ListParts(ctx, testKey, uploadID)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, parts[1].ETag, listed[1].ETag)
+	assert.Equal(t, int64(5), listed[1].SizeBytes)
+
+	require.NoError(t, store.CompleteMultipartUpload(ctx, testKey, uploadID, parts))
+
+	body, err := store.GetObject(ctx, testKey)
+	require.NoError(t, err)
+	defer func() { _ = body.Close() }()
+	content, _ := io.ReadAll(body)
+	assert.Equal(t, "hello, world", string(content))
+
+	_, err = store.ListParts(ctx, testKey, uploadID)
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+}
+
+func TestUploadPart(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+
+	uploadID, err := store.CreateMultipartUpload(ctx, testKey, "text/plain")
+	require.NoError(t, err)
+	etag, err := store.UploadPart(ctx, testKey, uploadID, 1, strings.NewReader("hello"), 5)
+	require.NoError(t, err)
+
+	_, err = store.UploadPart(ctx, testKey, uploadID, 2, strings.NewReader("short"), 6)
+	assert.Error(t, err, "a body shorter than sizeBytes is rejected")
+	_, err = store.UploadPart(ctx, testKey, "missing", 1, strings.NewReader("hello"), 5)
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+
+	listed, err := store.ListParts(ctx, testKey, uploadID)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, etag, listed[0].ETag)
+	require.NoError(t, store.CompleteMultipartUpload(ctx, testKey, uploadID, []domain.CompletedPart{{PartNumber: 1, ETag: etag}}))
+}
+
+func TestCompleteMultipartUpload_RejectsWrongETag(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+
+	uploadID, err := store.CreateMultipartUpload(ctx, testKey, "text/plain")
+	require.NoError(t, err)
+	part, err := store.PresignedPartURL(ctx, testKey, uploadID, 1, 4, time.Minute)
+	require.NoError(t, err)
+	resp := doPut(t, part, []byte("data"))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	err = store.CompleteMultipartUpload(ctx, testKey, uploadID, []domain.CompletedPart{{PartNumber: 1, ETag: `"bogus"`}})
+	assert.Error(t, err)
+}
+
+func TestHandler_EnforcesSignedConstraints(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+
+	content := []byte("hello")
+	put, err := store.PresignedPutURL(ctx, testKey, constraintsFor(content), time.Minute)
+	require.NoError(t, err)
+
+	t.Run("wrong length", func(t *testing.T) {
+		resp := doPut(t, put, []byte("hello!"))
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+	t.Run("wrong content type", func(t *testing.T) {
+		wrongType := *put
+		wrongType.Headers = map[string]string{"Content-Type": "image/png"}
+		resp := doPut(t, &wrongType, content)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+	t.Run("wrong checksum", func(t *testing.T) {
+		resp := doPut(t, put, []byte("jello"))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	_, err = store.StatObject(ctx, testKey)
+	assert.ErrorIs(t, err, domain.ErrObjectNotFound)
+
+	t.Run("wrong part length", func(t *testing.T) {
+		uploadID, err := store.CreateMultipartUpload(ctx, testKey, "text/plain")
+		require.NoError(t, err)
+		part, err := store.PresignedPartURL(ctx, testKey, uploadID, 1, 4, time.Minute)
+		require.NoError(t, err)
+		resp := doPut(t, part, []byte("too long"))
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
+
+func TestPutObject_and_GetObjectRange(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+	content := []byte("0123456789")
+	sum := sha256.Sum256(content)
+	key := hex.EncodeToString(sum[:])
+
+	err := store.PutObject(ctx, key, bytes.NewReader(content), 10, "text/plain", testKey)
+	require.Error(t, err, "checksum mismatch is rejected")
+	_, err = store.StatObject(ctx, key)
+	assert.ErrorIs(t, err, domain.ErrObjectNotFound)
+
+	require.NoError(t, store.PutObject(ctx, key, bytes.NewReader(content), 10, "text/plain", key))
+
+	body, err := store.GetObjectRange(ctx, key, 3, 4)
+	require.NoError(t, err)
+	got, _ := io.ReadAll(body)
+	_ = body.Close()
+	assert.Equal(t, "3456", string(got))
+
+	body, err = store.GetObjectRange(ctx, key, 8, -1)
+	require.NoError(t, err)
+	got, _ = io.ReadAll(body)
+	_ = body.Close()
+	assert.Equal(t, "89", string(got))
+}
+
+func TestAbortAndDeleteAreIdempotent(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+
+	uploadID, err := store.CreateMultipartUpload(ctx, testKey, "text/plain")
+	require.NoError(t, err)
+	require.NoError(t, store.AbortMultipartUpload(ctx, testKey, uploadID))
+	require.NoError(t, store.AbortMultipartUpload(ctx, testKey, uploadID))
+
+	uploads, err := store.ListMultipartUploads(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, uploads)
+
+	require.NoError(t, store.DeleteObject(ctx, testKey))
+}
+
+func TestCustomerKey_SealsObjectsAtRest(t *testing.T) {
+	dir := t.TempDir()
+	store, err := fsstorage.New(dir, "http://localhost", bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, err)
+	ctx := context.Background()
+	view := store.WithCustomerKey(bytes.Repeat([]byte{1}, 32))
+	content := []byte("a sensitive document spanning several AES blocks")
+	sum := constraintsFor(content).ChecksumSHA256
+
+	require.NoError(t, view.PutObject(ctx, testKey, bytes.NewReader(content), int64(len(content)), "text/plain", sum))
+	entries, err := os.ReadDir(filepath.Join(dir, "objects"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	raw, err := os.ReadFile(filepath.Join(dir, "objects", entries[0].Name()))
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "sensitive")
+
+	info, err := view.StatObject(ctx, testKey)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), info.SizeBytes)
+
+	body, err := view.GetObjectRange(ctx, testKey, 21, 9)
+	require.NoError(t, err)
+	got, _ := io.ReadAll(body)
+	_ = body.Close()
+	assert.Equal(t, "spanning ", string(got))
+
+	_, err = store.GetObject(ctx, testKey)
+	assert.Error(t, err, "a sealed object cannot be read without its key")
+	_, err = store.WithCustomerKey(bytes.Repeat([]byte{2}, 32)).GetObject(ctx, testKey)
+	assert.Error(t, err, "a sealed object cannot be read with another key")
+
+	require.NoError(t, store.DeleteObject(ctx, testKey))
+	_, err = view.StatObject(ctx, testKey)
+	assert.ErrorIs(t, err, domain.ErrObjectNotFound)
+}
+
+func TestCustomerKey_PresignedRequestsCarryKey(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+	view := store.WithCustomerKey(bytes.Repeat([]byte{1}, 32))
+	content := []byte("0123456789abcdefghij")
+
+	put, err := view.PresignedPutURL(ctx, testKey, constraintsFor(content), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "AES256", put.Headers["X-Amz-Server-Side-Encryption-Customer-Algorithm"])
+	require.Equal(t, http.StatusOK, doPut(t, put, content).StatusCode)
+
+	get, err := view.PresignedGetURL(ctx, testKey, domain.GetOptions{Offset: 15, Length: 3}, time.Minute)
+	require.NoError(t, err)
+	resp := doRequest(t, http.MethodGet, get.URL, nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "the customer key must be sent")
+
+	req, err := http.NewRequest(http.MethodGet, get.URL, nil)
+	require.NoError(t, err)
+	for k, v := range get.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "fgh", string(body))
+
+	plain, err := store.PresignedGetURL(ctx, testKey, domain.GetOptions{}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, doRequest(t, http.MethodGet, plain.URL, nil).StatusCode)
+}
+
+func TestCustomerKey_MultipartSealsCombinedObject(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+	view := store.WithCustomerKey(bytes.Repeat([]byte{1}, 32))
+
+	uploadID, err := view.CreateMultipartUpload(ctx, testKey, "text/plain")
+	require.NoError(t, err)
+	var parts []domain.CompletedPart
+	for i, chunk := range []string{"first-", "second"} {
+		part, err := view.PresignedPartURL(ctx, testKey, uploadID, int32(i+1), int64(len(chunk)), time.Minute)
+		require.NoError(t, err)
+		resp := doPut(t, part, []byte(chunk))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		parts = append(parts, domain.CompletedPart{PartNumber: int32(i + 1), ETag: resp.Header.Get("ETag")})
+	}
+	require.NoError(t, view.CompleteMultipartUpload(ctx, testKey, uploadID, parts))
+
+	body, err := view.GetObject(ctx, testKey)
+	require.NoError(t, err)
+	got, _ := io.ReadAll(body)
+	_ = body.Close()
+	assert.Equal(t, "first-second", string(got))
+	_, err = store.StatObject(ctx, testKey)
+	assert.Error(t, err)
+}
+
+func TestListObjects(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+	plain, sealed := []byte("plain"), []byte("sealed with a customer key")
+	plainKey, sealedKey := constraintsFor(plain).ChecksumSHA256, constraintsFor(sealed).ChecksumSHA256
+	require.NoError(t, store.PutObject(ctx, plainKey, bytes.NewReader(plain), int64(len(plain)), "text/plain", plainKey))
+	view := store.WithCustomerKey(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, view.PutObject(ctx, sealedKey, bytes.NewReader(sealed), int64(len(sealed)), "text/plain", sealedKey))
+
+	want := []domain.StoredObject{
+		{Key: plainKey, SizeBytes: int64(len(plain))},
+		{Key: sealedKey, SizeBytes: int64(len(sealed))},
+	}
+	if want[1].Key < want[0].Key {
+		want[0], want[1] = want[1], want[0]
+	}
+
+	got, err := store.ListObjects(ctx, "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, want, got, "sealed objects are listed under their key with their content size")
+
+	got, err = store.ListObjects(ctx, "", 1)
+	require.NoError(t, err)
+	assert.Equal(t, want[:1], got)
+	got, err = store.ListObjects(ctx, want[0].Key, 10)
+	require.NoError(t, err)
+	assert.Equal(t, want[1:], got)
+}
@@ -0,0 +1,167 @@
+// Package instrumentedstorage times the calls made to a domain.ObjectStorage
+// and counts the presigned URLs it issues.
+package instrumentedstorage
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+// Recorder receives the measurements. metrics.Metrics implements it.
+type Recorder interface {
+	ObserveStorage(store, operation string, d time.Duration, err error)
+	Presigned(operation string)
+}
+
+// Store records every call to the store it wraps under the store's name.
+// Reads are timed until the object is opened, not until it is read through.
+type Store struct {
+	inner    domain.ObjectStorage
+	name     string
+	recorder Recorder
+}
+
+var _ domain.ObjectStorage = (*Store)(nil)
+
+// New returns a Store recording calls to inner as the store called name,
+// e.g. "primary".
+func New(inner domain.ObjectStorage, name string, recorder Recorder) *Store {
+	return &Store{inner: inner, name: name, recorder: recorder}
+}
+
+func (s *Store) observe(operation string, start time.Time, err error) {
+	s.recorder.ObserveStorage(s.name, operation, time.Since(start), err)
+}
+
+func (s *Store) presigned(operation string, start time.Time, err error) {
+	s.observe("presign_"+operation, start, err)
+	if err == nil {
+		s.recorder.Presigned(operation)
+	}
+}
+
+// WithCustomerKey returns an instrumented view of the inner store's view.
+func (s *Store) WithCustomerKey(key []byte) domain.ObjectStorage {
+	return &Store{inner: s.inner.WithCustomerKey(key), name: s.name, recorder: s.recorder}
+}
+
+// Ping passes the inner store's health check through. A store that cannot
+// check its health is reported healthy.
+func (s *Store) Ping(ctx context.Context) error {
+	p, ok := s.inner.(interface{ Ping(context.Context) error })
+	if !ok {
+		return nil
+	}
+	start := time.Now()
+	err := p.Ping(ctx)
+	s.observe("ping", start, err)
+	return err
+}
+
+func (s *Store) PresignedPutURL(ctx context.Context, key string, c domain.PutConstraints, ttl time.Duration) (*domain.PresignedRequest, error) {
+	start := time.Now()
+	req, err := s.inner.PresignedPutURL(ctx, key, c, ttl)
+	s.presigned("put", start, err)
+	return req, err
+}
+
+func (s *Store) PresignedGetURL(ctx context.Context, key string, o domain.GetOptions, ttl time.Duration) (*domain.PresignedRequest, error) {
+	start := time.Now()
+	req, err := s.inner.PresignedGetURL(ctx, key, o, ttl)
+	s.presigned("get", start, err)
+	return req, err
+}
+
+func (s *Store) CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	start := time.Now()
+	uploadID, err := s.inner.CreateMultipartUpload(ctx, key, contentType)
+	s.observe("create_multipart_upload", start, err)
+	return uploadID, err
+}
+
+func (s *Store) PresignedPartURL(ctx context.Context, key, uploadID string, partNumber int32, sizeBytes int64, ttl time.Duration) (*domain.PresignedRequest, error) {
+	start := time.Now()
+	req, err := s.inner.PresignedPartURL(ctx, key, uploadID, partNumber, sizeBytes, ttl)
+	s.presigned("part", start, err)
+	return req, err
+}
+
+func (s *Store) UploadPart(ctx context.Context, key, uploadID string, partNumber int32, body io.Reader, sizeBytes int64) (string, error) {
+	start := time.Now()
+	etag, err := s.inner.UploadPart(ctx, key, uploadID, partNumber, body, sizeBytes)
+	s.observe("upload_part", start, err)
+	return etag, err
+}
+
+func (s *Store) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []domain.CompletedPart) error {
+	start := time.Now()
+	err := s.inner.CompleteMultipartUpload(ctx, key, uploadID, parts)
+	s.observe("complete_multipart_upload", start, err)
+	return err
+}
+
+func (s *Store) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	start := time.Now()
+	err := s.inner.AbortMultipartUpload(ctx, key, uploadID)
+	s.observe("abort_multipart_upload", start, err)
+	return err
+}
+
+func (s *Store) ListMultipartUploads(ctx context.Context) ([]domain.MultipartUpload, error) {
+	start := time.Now()
+	uploads, err := s.inner.ListMultipartUploads(ctx)
+	s.observe("list_multipart_uploads", start, err)
+	return uploads, err
+}
+
+func (s *Store) ListParts(ctx context.Context, key, uploadID string) ([]domain.UploadedPart, error) {
+	start := time.Now()
+	parts, err := s.inner.ListParts(ctx, key, uploadID)
+	s.observe("list_parts", start, err)
+	return parts, err
+}
+
+func (s *Store) StatObject(ctx context.Context, key string) (*domain.ObjectInfo, error) {
+	start := time.Now()
+	info, err := s.inner.StatObject(ctx, key)
+	s.observe("stat_object", start, err)
+	return info, err
+}
+
+func (s *Store) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	start := time.Now()
+	body, err := s.inner.GetObject(ctx, key)
+	s.observe("get_object", start, err)
+	return body, err
+}
+
+func (s *Store) GetObjectRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	start := time.Now()
+	body, err := s.inner.GetObjectRange(ctx, key, offset, length)
+	s.observe("get_object_range", start, err)
+	return body, err
+}
+
+func (s *Store) PutObject(ctx context.Context, key string, body io.Reader, sizeBytes int64, contentType, checksumSHA256 string) error {
+	start := time.Now()
+	err := s.inner.PutObject(ctx, key, body, sizeBytes, contentType, checksumSHA256)
+	s.observe("put_object", start, err)
+	return err
+}
+
+func (s *Store) DeleteObject(ctx context.Context, key string) error {
+	start := time.Now()
+	err := s.inner.DeleteObject(ctx, key)
+	s.observe("delete_object", start, err)
+	return err
+}
+
+func (s *Store) ListObjects(ctx context.Context, afterKey string, limit int) ([]domain.StoredObject, error) {
+	start := time.Now()
+	objects, err := s.inner.ListObjects(ctx, afterKey, limit)
+	s.observe("list_objects", start, err)
+	return objects, err
+}
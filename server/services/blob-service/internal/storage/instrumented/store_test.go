@@ -0,0 +1,65 @@
+package instrumentedstorage_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+	fsstorage "github.com/barn0w1/hss-science/server/services/blob-service/internal/storage/fs"
+	instrumentedstorage "github.com/barn0w1/hss-science/server/services/blob-service/internal/storage/instrumented"
+)
+
+type call struct {
+	store, operation string
+	err              error
+}
+
+type fakeRecorder struct {
+	calls     []call
+	presigned []string
+}
+
+func (r *fakeRecorder) ObserveStorage(store, operation string, _ time.Duration, err error) {
+	r.calls = append(r.calls, call{store, operation, err})
+}
+
+func (r *fakeRecorder) Presigned(operation string) {
+	r.presigned = append(r.presigned, operation)
+}
+
+func TestStore_RecordsCalls(t *testing.T) {
+	inner, err := fsstorage.New(t.TempDir(), "http://primary.test", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	rec := &fakeRecorder{}
+	store := instrumentedstorage.New(inner, "primary", rec)
+	ctx := context.Background()
+
+	_, err = store.PresignedGetURL(ctx, "abc", domain.GetOptions{}, time.Minute)
+	require.NoError(t, err)
+	_, err = store.StatObject(ctx, "abc")
+	assert.ErrorIs(t, err, domain.ErrObjectNotFound, "errors pass through unchanged")
+	require.NoError(t, store.Ping(ctx))
+
+	assert.Equal(t, []call{
+		{"primary", "presign_get", nil},
+		{"primary", "stat_object", err},
+		{"primary", "ping", nil},
+	}, rec.calls)
+	assert.Equal(t, []string{"get"}, rec.presigned)
+}
+
+func TestStore_CustomerKeyViewIsInstrumented(t *testing.T) {
+	inner, err := fsstorage.New(t.TempDir(), "http://primary.test", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	rec := &fakeRecorder{}
+	view := instrumentedstorage.New(inner, "replica", rec).WithCustomerKey(bytes.Repeat([]byte{7}, 32))
+
+	require.NoError(t, view.DeleteObject(context.Background(), "abc"))
+	require.Len(t, rec.calls, 1)
+	assert.Equal(t, call{"replica", "delete_object", nil}, rec.calls[0])
+}
@@ -0,0 +1,217 @@
+// Package replicatedstorage mirrors a primary domain.ObjectStorage to a
+// secondary one so that downloads survive an outage of the primary.
+package replicatedstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+const (
+	defaultCheckInterval = 10 * time.Second
+	pingTimeout          = 3 * time.Second
+)
+
+// Pinger is implemented by stores that can check their own health. A primary
+// store that does not implement it is always considered healthy.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Store writes to its primary store only; objects reach the secondary when
+// Replicate is called for them.
+type Store struct {
+	primary   domain.ObjectStorage
+	secondary domain.ObjectStorage
+	health    *health
+}
+
+var _ domain.ReplicatedStorage = (*Store)(nil)
+
+// New returns a Store replicating primary to secondary. The primary's health
+// is checked at most once per checkInterval.
+func New(primary, secondary domain.ObjectStorage, checkInterval time.Duration) *Store {
+	if checkInterval <= 0 {
+		checkInterval = defaultCheckInterval
+	}
+	h := &health{interval: checkInterval, healthy: true}
+	if p, ok := primary.(Pinger); ok {
+		h.ping = p.Ping
+	}
+	return &Store{primary: primary, secondary: secondary, health: h}
+}
+
+// WithCustomerKey returns a view keyed on both stores. Views share the
+// primary's health state.
+func (s *Store) WithCustomerKey(key []byte) domain.ObjectStorage {
+	return &Store{
+		primary:   s.primary.WithCustomerKey(key),
+		secondary: s.secondary.WithCustomerKey(key),
+		health:    s.health,
+	}
+}
+
+func (s *Store) PresignedPutURL(ctx context.Context, key string, c domain.PutConstraints, ttl time.Duration) (*domain.PresignedRequest, error) {
+	return s.primary.PresignedPutURL(ctx, key, c, ttl)
+}
+
+func (s *Store) PresignedGetURL(ctx context.Context, key string, o domain.GetOptions, ttl time.Duration) (*domain.PresignedRequest, error) {
+	return s.primary.PresignedGetURL(ctx, key, o, ttl)
+}
+
+func (s *Store) CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	return s.primary.CreateMultipartUpload(ctx, key, contentType)
+}
+
+func (s *Store) PresignedPartURL(ctx context.Context, key, uploadID string, partNumber int32, sizeBytes int64, ttl time.Duration) (*domain.PresignedRequest, error) {
+	return s.primary.PresignedPartURL(ctx, key, uploadID, partNumber, sizeBytes, ttl)
+}
+
+func (s *Store) UploadPart(ctx context.Context, key, uploadID string, partNumber int32, body io.Reader, sizeBytes int64) (string, error) {
+	return s.primary.UploadPart(ctx, key, uploadID, partNumber, body, sizeBytes)
+}
+
+func (s *Store) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []domain.CompletedPart) error {
+	return s.primary.CompleteMultipartUpload(ctx, key, uploadID, parts)
+}
+
+func (s *Store) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	return s.primary.AbortMultipartUpload(ctx, key, uploadID)
+}
+
+func (s *Store) ListMultipartUploads(ctx context.Context) ([]domain.MultipartUpload, error) {
+	return s.primary.ListMultipartUploads(ctx)
+}
+
+func (s *Store) ListParts(ctx context.Context, key, uploadID string) ([]domain.UploadedPart, error) {
+	return s.primary.ListParts(ctx, key, uploadID)
+}
+
+func (s *Store) StatObject(ctx context.Context, key string) (*domain.ObjectInfo, error) {
+	info, err := s.primary.StatObject(ctx, key)
+	if err == nil || !canFallBack(ctx, err) {
+		return info, err
+	}
+	if info, serr := s.secondary.StatObject(ctx, key); serr == nil {
+		return info, nil
+	}
+	return nil, err
+}
+
+func (s *Store) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	body, err := s.primary.GetObject(ctx, key)
+	if err == nil || !canFallBack(ctx, err) {
+		return body, err
+	}
+	if body, serr := s.secondary.GetObject(ctx, key); serr == nil {
+		return body, nil
+	}
+	return nil, err
+}
+
+func (s *Store) GetObjectRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	body, err := s.primary.GetObjectRange(ctx, key, offset, length)
+	if err == nil || !canFallBack(ctx, err) {
+		return body, err
+	}
+	if body, serr := s.secondary.GetObjectRange(ctx, key, offset, length); serr == nil {
+		return body, nil
+	}
+	return nil, err
+}
+
+func (s *Store) PutObject(ctx context.Context, key string, body io.Reader, sizeBytes int64, contentType, checksumSHA256 string) error {
+	return s.primary.PutObject(ctx, key, body, sizeBytes, contentType, checksumSHA256)
+}
+
+// DeleteObject deletes the primary copy first, so an object is never left
+// only on the secondary store.
+func (s *Store) DeleteObject(ctx context.Context, key string) error {
+	if err := s.primary.DeleteObject(ctx, key); err != nil {
+		return err
+	}
+	if err := s.secondary.DeleteObject(ctx, key); err != nil {
+		return fmt.Errorf("replica: delete object: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) ListObjects(ctx context.Context, afterKey string, limit int) ([]domain.StoredObject, error) {
+	return s.primary.ListObjects(ctx, afterKey, limit)
+}
+
+// Replicate is a no-op if the secondary store already holds an object of the
+// expected size under key.
+func (s *Store) Replicate(ctx context.Context, key string, sizeBytes int64, contentType, checksumSHA256 string) error {
+	if info, err := s.secondary.StatObject(ctx, key); err == nil && info.SizeBytes == sizeBytes {
+		return nil
+	}
+	body, err := s.primary.GetObject(ctx, key)
+	if err != nil {
+		return fmt.Errorf("replica: read primary: %w", err)
+	}
+	defer func() { _ = body.Close() }()
+	if err := s.secondary.PutObject(ctx, key, body, sizeBytes, contentType, checksumSHA256); err != nil {
+		return fmt.Errorf("replica: write secondary: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) Failover(ctx context.Context) domain.ObjectStorage {
+	if s.health.check(ctx) {
+		return nil
+	}
+	return s.secondary
+}
+
+// canFallBack reports whether a failed primary read should be retried on the
+// secondary. A missing object is not an outage.
+func canFallBack(ctx context.Context, err error) bool {
+	return !errors.Is(err, domain.ErrObjectNotFound) && ctx.Err() == nil
+}
+
+// health caches the result of the primary store's health check.
+type health struct {
+	ping     func(ctx context.Context) error
+	interval time.Duration
+
+	mu        sync.Mutex
+	healthy   bool
+	checkedAt time.Time
+}
+
+// check reports whether the primary is healthy, pinging it when the last
+// result is stale.
+func (h *health) check(ctx context.Context) bool {
+	if h.ping == nil {
+		return true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if time.Since(h.checkedAt) < h.interval {
+		return h.healthy
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	err := h.ping(pingCtx)
+	if err != nil && ctx.Err() != nil {
+		return h.healthy
+	}
+	healthy := err == nil
+	switch {
+	case h.healthy && !healthy:
+		slog.Warn("primary object store is unhealthy, failing over to the replica", "error", err)
+	case !h.healthy && healthy:
+		slog.Info("primary object store recovered")
+	}
+	h.healthy, h.checkedAt = healthy, time.Now()
+	return healthy
+}
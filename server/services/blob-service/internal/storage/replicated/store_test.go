@@ -0,0 +1,140 @@
+package replicatedstorage_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+	fsstorage "github.com/barn0w1/hss-science/server/services/blob-service/internal/storage/fs"
+	replicatedstorage "github.com/barn0w1/hss-science/server/services/blob-service/internal/storage/replicated"
+)
+
+var content = []byte("replicated to the secondary bucket")
+
+func checksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// newStores returns two filesystem stores standing in for the primary and
+// secondary buckets, and the primary's root directory.
+func newStores(t *testing.T) (primary, secondary *fsstorage.Store, primaryDir string) {
+	t.Helper()
+	primaryDir = t.TempDir()
+	primary, err := fsstorage.New(primaryDir, "http://primary.test", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	secondary, err = fsstorage.New(t.TempDir(), "http://secondary.test", bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+	return primary, secondary, primaryDir
+}
+
+// breakStore makes a filesystem store fail its health check and its reads,
+// as an unreachable bucket would.
+func breakStore(t *testing.T, dir string) {
+	t.Helper()
+	objects := filepath.Join(dir, "objects")
+	require.NoError(t, os.RemoveAll(objects))
+	require.NoError(t, os.WriteFile(objects, nil, 0o600))
+}
+
+func readAll(t *testing.T, r io.ReadCloser) []byte {
+	t.Helper()
+	defer func() { _ = r.Close() }()
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return b
+}
+
+func TestReplicate_CopiesAndDeletesBothCopies(t *testing.T) {
+	primary, secondary, _ := newStores(t)
+	store := replicatedstorage.New(primary, secondary, time.Minute)
+	ctx := context.Background()
+	key := checksum(content)
+
+	require.NoError(t, store.PutObject(ctx, key, bytes.NewReader(content), int64(len(content)), "text/plain", key))
+	_, err := secondary.StatObject(ctx, key)
+	require.ErrorIs(t, err, domain.ErrObjectNotFound, "writes go to the primary only")
+
+	require.NoError(t, store.Replicate(ctx, key, int64(len(content)), "text/plain", key))
+	body, err := secondary.GetObject(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, content, readAll(t, body))
+	require.NoError(t, store.Replicate(ctx, key, int64(len(content)), "text/plain", key), "replicating again is a no-op")
+
+	require.NoError(t, store.DeleteObject(ctx, key))
+	_, err = primary.StatObject(ctx, key)
+	assert.ErrorIs(t, err, domain.ErrObjectNotFound)
+	_, err = secondary.StatObject(ctx, key)
+	assert.ErrorIs(t, err, domain.ErrObjectNotFound)
+}
+
+func TestReplicate_RejectsWrongChecksum(t *testing.T) {
+	primary, secondary, _ := newStores(t)
+	store := replicatedstorage.New(primary, secondary, time.Minute)
+	ctx := context.Background()
+	key := checksum(content)
+	require.NoError(t, primary.PutObject(ctx, key, bytes.NewReader(content), int64(len(content)), "text/plain", key))
+
+	err := store.Replicate(ctx, key, int64(len(content)), "text/plain", strings.Repeat("0", 64))
+	assert.Error(t, err)
+	_, err = secondary.StatObject(ctx, key)
+	assert.ErrorIs(t, err, domain.ErrObjectNotFound)
+}
+
+func TestReplicate_CustomerKeyAppliesToBothStores(t *testing.T) {
+	primary, secondary, _ := newStores(t)
+	customerKey := bytes.Repeat([]byte{9}, domain.DataKeySize)
+	view := replicatedstorage.New(primary, secondary, time.Minute).WithCustomerKey(customerKey)
+	ctx := context.Background()
+	key := checksum(content)
+
+	require.NoError(t, view.PutObject(ctx, key, bytes.NewReader(content), int64(len(content)), "text/plain", key))
+	replicas, ok := view.(domain.ReplicatedStorage)
+	require.True(t, ok)
+	require.NoError(t, replicas.Replicate(ctx, key, int64(len(content)), "text/plain", key))
+
+	_, err := secondary.GetObject(ctx, key)
+	assert.Error(t, err, "the replica must be sealed with the same key")
+	body, err := secondary.WithCustomerKey(customerKey).GetObject(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, content, readAll(t, body))
+}
+
+func TestFailover_PrimaryOutage(t *testing.T) {
+	primary, secondary, primaryDir := newStores(t)
+	store := replicatedstorage.New(primary, secondary, time.Nanosecond)
+	ctx := context.Background()
+	key := checksum(content)
+	require.NoError(t, store.PutObject(ctx, key, bytes.NewReader(content), int64(len(content)), "text/plain", key))
+	require.NoError(t, store.Replicate(ctx, key, int64(len(content)), "text/plain", key))
+
+	assert.Nil(t, store.Failover(ctx), "no failover while the primary is healthy")
+	_, err := store.StatObject(ctx, strings.Repeat("f", 64))
+	assert.ErrorIs(t, err, domain.ErrObjectNotFound, "a missing object is not an outage")
+
+	breakStore(t, primaryDir)
+
+	replica := store.Failover(ctx)
+	require.NotNil(t, replica)
+	req, err := replica.PresignedGetURL(ctx, key, domain.GetOptions{}, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(req.URL, "http://secondary.test/"), req.URL)
+
+	body, err := store.GetObject(ctx, key)
+	require.NoError(t, err, "reads fall back to the replica")
+	assert.Equal(t, content, readAll(t, body))
+	body, err = store.GetObjectRange(ctx, key, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, content[:10], readAll(t, body))
+}
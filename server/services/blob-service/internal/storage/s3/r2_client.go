@@ -2,13 +2,21 @@ package s3storage
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 
 	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
 )
@@ -17,6 +25,15 @@ type R2Client struct {
 	client  *s3.Client
 	presign *s3.PresignClient
 	bucket  string
+	sse     customerKey
+}
+
+// customerKey holds the SSE-C parameters sent with every object request made
+// through a WithCustomerKey view. Its fields are nil on the plain client.
+type customerKey struct {
+	algorithm *string
+	key       *string
+	keyMD5    *string
 }
 
 func New(endpoint, bucket, accessKeyID, secretKey string) (*R2Client, error) {
@@ -34,33 +51,78 @@ func New(endpoint, bucket, accessKeyID, secretKey string) (*R2Client, error) {
 	}, nil
 }
 
-func (c *R2Client) PresignedPutURL(ctx context.Context, key string, ttl time.Duration) (string, time.Time, error) {
-	req, err := c.presign.PresignPutObject(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(c.bucket),
-		Key:    aws.String(key),
-	}, s3.WithPresignExpires(ttl))
+func (c *R2Client) WithCustomerKey(key []byte) domain.ObjectStorage {
+	sum := md5.Sum(key)
+	view := *c
+	view.sse = customerKey{
+		algorithm: aws.String("AES256"),
+		key:       aws.String(base64.StdEncoding.EncodeToString(key)),
+		keyMD5:    aws.String(base64.StdEncoding.EncodeToString(sum[:])),
+	}
+	return &view
+}
+
+func (c *R2Client) PresignedPutURL(ctx context.Context, key string, pc domain.PutConstraints, ttl time.Duration) (*domain.PresignedRequest, error) {
+	in := &s3.PutObjectInput{
+		Bucket:               aws.String(c.bucket),
+		Key:                  aws.String(key),
+		ContentLength:        aws.Int64(pc.SizeBytes),
+		SSECustomerAlgorithm: c.sse.algorithm,
+		SSECustomerKey:       c.sse.key,
+		SSECustomerKeyMD5:    c.sse.keyMD5,
+	}
+	if pc.ContentType != "" {
+		in.ContentType = aws.String(pc.ContentType)
+	}
+	if pc.ChecksumSHA256 != "" {
+		raw, err := hex.DecodeString(pc.ChecksumSHA256)
+		if err != nil {
+			return nil, fmt.Errorf("r2: presign put: invalid checksum: %w", err)
+		}
+		in.ChecksumSHA256 = aws.String(base64.StdEncoding.EncodeToString(raw))
+	}
+	req, err := c.presign.PresignPutObject(ctx, in, s3.WithPresignExpires(ttl))
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("r2: presign put: %w", err)
+		return nil, fmt.Errorf("r2: presign put: %w", err)
 	}
-	return req.URL, time.Now().Add(ttl), nil
+	return presigned(req, ttl), nil
 }
 
-func (c *R2Client) PresignedGetURL(ctx context.Context, key string, ttl time.Duration) (string, time.Time, error) {
-	req, err := c.presign.PresignGetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(c.bucket),
-		Key:    aws.String(key),
-	}, s3.WithPresignExpires(ttl))
+func (c *R2Client) PresignedGetURL(ctx context.Context, key string, o domain.GetOptions, ttl time.Duration) (*domain.PresignedRequest, error) {
+	in := &s3.GetObjectInput{
+		Bucket:               aws.String(c.bucket),
+		Key:                  aws.String(key),
+		SSECustomerAlgorithm: c.sse.algorithm,
+		SSECustomerKey:       c.sse.key,
+		SSECustomerKeyMD5:    c.sse.keyMD5,
+	}
+	if o.ContentDisposition != "" {
+		in.ResponseContentDisposition = aws.String(o.ContentDisposition)
+	}
+	if o.ContentType != "" {
+		in.ResponseContentType = aws.String(o.ContentType)
+	}
+	if o.CacheControl != "" {
+		in.ResponseCacheControl = aws.String(o.CacheControl)
+	}
+	if rng := o.Range(); rng != "" {
+		in.Range = aws.String(rng)
+	}
+	req, err := c.presign.PresignGetObject(ctx, in, s3.WithPresignExpires(ttl))
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("r2: presign get: %w", err)
+		return nil, fmt.Errorf("r2: presign get: %w", err)
 	}
-	return req.URL, time.Now().Add(ttl), nil
+	return presigned(req, ttl), nil
 }
 
 func (c *R2Client) CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
 	out, err := c.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
-		Bucket:      aws.String(c.bucket),
-		Key:         aws.String(key),
-		ContentType: aws.String(contentType),
+		Bucket:               aws.String(c.bucket),
+		Key:                  aws.String(key),
+		ContentType:          aws.String(contentType),
+		SSECustomerAlgorithm: c.sse.algorithm,
+		SSECustomerKey:       c.sse.key,
+		SSECustomerKeyMD5:    c.sse.keyMD5,
 	})
 	if err != nil {
 		return "", fmt.Errorf("r2: create multipart upload: %w", err)
@@ -68,17 +130,42 @@ func (c *R2Client) CreateMultipartUpload(ctx context.Context, key, contentType s
 	return aws.ToString(out.UploadId), nil
 }
 
-func (c *R2Client) PresignedPartURL(ctx context.Context, key, uploadID string, partNumber int32, ttl time.Duration) (string, time.Time, error) {
+func (c *R2Client) PresignedPartURL(ctx context.Context, key, uploadID string, partNumber int32, sizeBytes int64, ttl time.Duration) (*domain.PresignedRequest, error) {
 	req, err := c.presign.PresignUploadPart(ctx, &s3.UploadPartInput{
-		Bucket:     aws.String(c.bucket),
-		Key:        aws.String(key),
-		UploadId:   aws.String(uploadID),
-		PartNumber: aws.Int32(partNumber),
+		Bucket:               aws.String(c.bucket),
+		Key:                  aws.String(key),
+		UploadId:             aws.String(uploadID),
+		PartNumber:           aws.Int32(partNumber),
+		ContentLength:        aws.Int64(sizeBytes),
+		SSECustomerAlgorithm: c.sse.algorithm,
+		SSECustomerKey:       c.sse.key,
+		SSECustomerKeyMD5:    c.sse.keyMD5,
 	}, s3.WithPresignExpires(ttl))
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("r2: presign part: %w", err)
+		return nil, fmt.Errorf("r2: presign part: %w", err)
+	}
+	return presigned(req, ttl), nil
+}
+
+func (c *R2Client) UploadPart(ctx context.Context, key, uploadID string, partNumber int32, body io.Reader, sizeBytes int64) (string, error) {
+	out, err := c.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:               aws.String(c.bucket),
+		Key:                  aws.String(key),
+		UploadId:             aws.String(uploadID),
+		PartNumber:           aws.Int32(partNumber),
+		Body:                 body,
+		ContentLength:        aws.Int64(sizeBytes),
+		SSECustomerAlgorithm: c.sse.algorithm,
+		SSECustomerKey:       c.sse.key,
+		SSECustomerKeyMD5:    c.sse.keyMD5,
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return "", domain.ErrSessionNotFound
+		}
+		return "", fmt.Errorf("r2: upload part: %w", err)
 	}
-	return req.URL, time.Now().Add(ttl), nil
+	return aws.ToString(out.ETag), nil
 }
 
 func (c *R2Client) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []domain.CompletedPart) error {
@@ -96,6 +183,9 @@ func (c *R2Client) CompleteMultipartUpload(ctx context.Context, key, uploadID st
 		MultipartUpload: &types.CompletedMultipartUpload{
 			Parts: completed,
 		},
+		SSECustomerAlgorithm: c.sse.algorithm,
+		SSECustomerKey:       c.sse.key,
+		SSECustomerKeyMD5:    c.sse.keyMD5,
 	})
 	if err != nil {
 		return fmt.Errorf("r2: complete multipart: %w", err)
@@ -109,8 +199,215 @@ func (c *R2Client) AbortMultipartUpload(ctx context.Context, key, uploadID strin
 		Key:      aws.String(key),
 		UploadId: aws.String(uploadID),
 	})
-	if err != nil {
+	if err != nil && !isNotFound(err) {
 		return fmt.Errorf("r2: abort multipart: %w", err)
 	}
 	return nil
 }
+
+func (c *R2Client) ListMultipartUploads(ctx context.Context) ([]domain.MultipartUpload, error) {
+	var uploads []domain.MultipartUpload
+	p := s3.NewListMultipartUploadsPaginator(c.client, &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(c.bucket),
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("r2: list multipart uploads: %w", err)
+		}
+		for _, u := range page.Uploads {
+			uploads = append(uploads, domain.MultipartUpload{
+				Key:         aws.ToString(u.Key),
+				UploadID:    aws.ToString(u.UploadId),
+				InitiatedAt: aws.ToTime(u.Initiated),
+			})
+		}
+	}
+	return uploads, nil
+}
+
+func (c *R2Client) ListParts(ctx context.Context, key, uploadID string) ([]domain.UploadedPart, error) {
+	var parts []domain.UploadedPart
+	p := s3.NewListPartsPaginator(c.client, &s3.ListPartsInput{
+		Bucket:   aws.String(c.bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			if isNotFound(err) {
+				return nil, domain.ErrSessionNotFound
+			}
+			return nil, fmt.Errorf("r2: list parts: %w", err)
+		}
+		for _, part := range page.Parts {
+			parts = append(parts, domain.UploadedPart{
+				PartNumber: aws.ToInt32(part.PartNumber),
+				ETag:       aws.ToString(part.ETag),
+				SizeBytes:  aws.ToInt64(part.Size),
+			})
+		}
+	}
+	return parts, nil
+}
+
+func (c *R2Client) StatObject(ctx context.Context, key string) (*domain.ObjectInfo, error) {
+	out, err := c.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:               aws.String(c.bucket),
+		Key:                  aws.String(key),
+		ChecksumMode:         types.ChecksumModeEnabled,
+		SSECustomerAlgorithm: c.sse.algorithm,
+		SSECustomerKey:       c.sse.key,
+		SSECustomerKeyMD5:    c.sse.keyMD5,
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, domain.ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("r2: head object: %w", err)
+	}
+	info := &domain.ObjectInfo{SizeBytes: aws.ToInt64(out.ContentLength)}
+	if out.ChecksumType != types.ChecksumTypeComposite {
+		info.ChecksumSHA256 = decodeChecksum(aws.ToString(out.ChecksumSHA256))
+	}
+	return info, nil
+}
+
+func (c *R2Client) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := c.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket:               aws.String(c.bucket),
+		Key:                  aws.String(key),
+		SSECustomerAlgorithm: c.sse.algorithm,
+		SSECustomerKey:       c.sse.key,
+		SSECustomerKeyMD5:    c.sse.keyMD5,
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, domain.ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("r2: get object: %w", err)
+	}
+	return out.Body, nil
+}
+
+func (c *R2Client) GetObjectRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	rng := fmt.Sprintf("bytes=%d-", offset)
+	if length >= 0 {
+		rng = fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+	}
+	out, err := c.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket:               aws.String(c.bucket),
+		Key:                  aws.String(key),
+		Range:                aws.String(rng),
+		SSECustomerAlgorithm: c.sse.algorithm,
+		SSECustomerKey:       c.sse.key,
+		SSECustomerKeyMD5:    c.sse.keyMD5,
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, domain.ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("r2: get object range: %w", err)
+	}
+	return out.Body, nil
+}
+
+func (c *R2Client) PutObject(ctx context.Context, key string, body io.Reader, sizeBytes int64, contentType, checksumSHA256 string) error {
+	raw, err := hex.DecodeString(checksumSHA256)
+	if err != nil {
+		return fmt.Errorf("r2: put object: invalid checksum: %w", err)
+	}
+	_, err = c.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:               aws.String(c.bucket),
+		Key:                  aws.String(key),
+		Body:                 body,
+		ContentLength:        aws.Int64(sizeBytes),
+		ContentType:          aws.String(contentType),
+		ChecksumSHA256:       aws.String(base64.StdEncoding.EncodeToString(raw)),
+		SSECustomerAlgorithm: c.sse.algorithm,
+		SSECustomerKey:       c.sse.key,
+		SSECustomerKeyMD5:    c.sse.keyMD5,
+	})
+	if err != nil {
+		return fmt.Errorf("r2: put object: %w", err)
+	}
+	return nil
+}
+
+func (c *R2Client) DeleteObject(ctx context.Context, key string) error {
+	_, err := c.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("r2: delete object: %w", err)
+	}
+	return nil
+}
+
+func (c *R2Client) ListObjects(ctx context.Context, afterKey string, limit int) ([]domain.StoredObject, error) {
+	out, err := c.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket:     aws.String(c.bucket),
+		StartAfter: aws.String(afterKey),
+		MaxKeys:    aws.Int32(int32(limit)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("r2: list objects: %w", err)
+	}
+	objects := make([]domain.StoredObject, 0, len(out.Contents))
+	for _, o := range out.Contents {
+		objects = append(objects, domain.StoredObject{
+			Key:       aws.ToString(o.Key),
+			SizeBytes: aws.ToInt64(o.Size),
+		})
+	}
+	return objects, nil
+}
+
+// Ping checks that the bucket is reachable with the client's credentials.
+func (c *R2Client) Ping(ctx context.Context) error {
+	_, err := c.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(c.bucket)})
+	if err != nil {
+		return fmt.Errorf("r2: head bucket: %w", err)
+	}
+	return nil
+}
+
+func isNotFound(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "NotFound", "NoSuchKey", "NoSuchUpload":
+			return true
+		}
+	}
+	return false
+}
+
+// presigned drops Host and Content-Length from the signed headers, since
+// HTTP clients set them from the URL and body themselves.
+func presigned(req *v4.PresignedHTTPRequest, ttl time.Duration) *domain.PresignedRequest {
+	headers := make(map[string]string, len(req.SignedHeader))
+	for name, values := range req.SignedHeader {
+		switch http.CanonicalHeaderKey(name) {
+		case "Host", "Content-Length":
+			continue
+		}
+		if len(values) > 0 {
+			headers[http.CanonicalHeaderKey(name)] = values[0]
+		}
+	}
+	return &domain.PresignedRequest{URL: req.URL, Headers: headers, ExpiresAt: time.Now().Add(ttl)}
+}
+
+// decodeChecksum converts a base64 x-amz-checksum-sha256 value to hex.
+// Composite multipart checksums do not describe the full object and are
+// discarded.
+func decodeChecksum(b64 string) string {
+	raw, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil || len(raw) != 32 {
+		return ""
+	}
+	return hex.EncodeToString(raw)
+}
@@ -11,15 +11,36 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-type contextKey struct{}
+type contextKey int
 
-var callerSubKey = contextKey{}
+const (
+	callerSubKey contextKey = iota
+	callerClientKey
+	callerScopesKey
+)
 
 func CallerSub(ctx context.Context) string {
 	v, _ := ctx.Value(callerSubKey).(string)
 	return v
 }
 
+// WithCallerSub returns a copy of ctx carrying sub as the authenticated caller.
+func WithCallerSub(ctx context.Context, sub string) context.Context {
+	return context.WithValue(ctx, callerSubKey, sub)
+}
+
+// CallerClientID returns the OAuth client the caller's token was issued to,
+// or "" if the token did not name one.
+func CallerClientID(ctx context.Context) string {
+	v, _ := ctx.Value(callerClientKey).(string)
+	return v
+}
+
+// WithCallerClientID returns a copy of ctx carrying the caller's client ID.
+func WithCallerClientID(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, callerClientKey, clientID)
+}
+
 type AuthInterceptor struct {
 	verifier *oidc.IDTokenVerifier
 }
@@ -30,45 +51,90 @@ func NewAuthInterceptor(provider *oidc.Provider, audience string) *AuthIntercept
 	}
 }
 
+// Unary authenticates the caller and checks the method's scope.
 func (a *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
-	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isPublic(info.FullMethod) {
+			return handler(ctx, req)
+		}
 		ctx, err := a.authenticate(ctx)
 		if err != nil {
 			return nil, err
 		}
+		if err := Authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
 		return handler(ctx, req)
 	}
 }
 
+// Stream authenticates the caller and checks the method's scope.
 func (a *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
-	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isPublic(info.FullMethod) {
+			return handler(srv, ss)
+		}
 		ctx, err := a.authenticate(ss.Context())
 		if err != nil {
 			return err
 		}
+		if err := Authorize(ctx, info.FullMethod); err != nil {
+			return err
+		}
 		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
 	}
 }
 
+// publicServices answer without a token: load balancers and orchestrators
+// probe health anonymously, and reflection is only registered when enabled.
+var publicServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+func isPublic(fullMethod string) bool {
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *AuthInterceptor) authenticate(ctx context.Context) (context.Context, error) {
 	rawToken, err := extractBearerToken(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return a.Authenticate(ctx, rawToken)
+}
 
+// Authenticate verifies a raw bearer token and returns a copy of ctx carrying
+// the caller it names and the scopes it grants, for transports that do not
+// go through the gRPC interceptors. Errors are Unauthenticated statuses.
+func (a *AuthInterceptor) Authenticate(ctx context.Context, rawToken string) (context.Context, error) {
 	token, err := a.verifier.Verify(ctx, rawToken)
 	if err != nil {
 		return nil, status.Error(codes.Unauthenticated, "invalid token")
 	}
 
 	var claims struct {
-		Sub string `json:"sub"`
+		Sub      string `json:"sub"`
+		AZP      string `json:"azp"`
+		ClientID string `json:"client_id"`
+		Scope    string `json:"scope"`
 	}
 	if err := token.Claims(&claims); err != nil || claims.Sub == "" {
 		return nil, status.Error(codes.Unauthenticated, "missing sub claim")
 	}
 
-	return context.WithValue(ctx, callerSubKey, claims.Sub), nil
+	clientID := claims.AZP
+	if clientID == "" {
+		clientID = claims.ClientID
+	}
+	ctx = WithCallerClientID(WithCallerSub(ctx, claims.Sub), clientID)
+	return WithCallerScopes(ctx, strings.Fields(claims.Scope)...), nil
 }
 
 func extractBearerToken(ctx context.Context) (string, error) {
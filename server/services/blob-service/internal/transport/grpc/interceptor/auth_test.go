@@ -84,16 +84,22 @@ func (ots *oidcTestServer) signToken(t *testing.T, claims map[string]any) string
 
 func validClaims(issuer string) map[string]any {
 	return map[string]any{
-		"iss": issuer,
-		"aud": []string{"blob-service"},
-		"sub": "drive-service",
-		"exp": time.Now().Add(time.Hour).Unix(),
-		"iat": time.Now().Unix(),
+		"iss":   issuer,
+		"aud":   []string{"blob-service"},
+		"sub":   "drive-service",
+		"scope": "blob.read blob.write",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
 	}
 }
 
 func setupAuthServer(t *testing.T, ots *oidcTestServer) (pb.BlobServiceClient, func()) {
 	t.Helper()
+	return setupAuthServerWith(t, ots, &noopBlobServer{})
+}
+
+func setupAuthServerWith(t *testing.T, ots *oidcTestServer, blobSrv pb.BlobServiceServer) (pb.BlobServiceClient, func()) {
+	t.Helper()
 
 	ctx := context.Background()
 	provider, err := oidc.NewProvider(ctx, ots.issuer)
@@ -105,7 +111,7 @@ func setupAuthServer(t *testing.T, ots *oidcTestServer) (pb.BlobServiceClient, f
 	srv := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(auth.Unary()),
 	)
-	pb.RegisterBlobServiceServer(srv, &noopBlobServer{})
+	pb.RegisterBlobServiceServer(srv, blobSrv)
 
 	go func() { _ = srv.Serve(lis) }()
 
@@ -137,6 +143,38 @@ func TestAuthInterceptor_ValidToken(t *testing.T) {
 	assert.NotEqual(t, codes.Unauthenticated, st.Code(), "valid token should not get Unauthenticated")
 }
 
+func TestAuthInterceptor_ClientID(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra map[string]any
+		want  string
+	}{
+		{"azp", map[string]any{"azp": "drive-web", "client_id": "ignored"}, "drive-web"},
+		{"client_id", map[string]any{"client_id": "drive-sync"}, "drive-sync"},
+		{"none", nil, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ots := newOIDCTestServer(t)
+			capture := &captureServer{}
+			client, cleanup := setupAuthServerWith(t, ots, capture)
+			defer cleanup()
+
+			claims := validClaims(ots.issuer)
+			for k, v := range tc.extra {
+				claims[k] = v
+			}
+			ctx := metadata.NewOutgoingContext(context.Background(),
+				metadata.Pairs("authorization", "Bearer "+ots.signToken(t, claims)))
+
+			_, err := client.GetBlobInfo(ctx, &pb.GetBlobInfoRequest{})
+			require.NoError(t, err)
+			assert.Equal(t, "drive-service", capture.sub)
+			assert.Equal(t, tc.want, capture.clientID)
+		})
+	}
+}
+
 func TestAuthInterceptor_MissingToken(t *testing.T) {
 	ots := newOIDCTestServer(t)
 	client, cleanup := setupAuthServer(t, ots)
@@ -175,6 +213,38 @@ func TestAuthInterceptor_ExpiredToken(t *testing.T) {
 	assert.Equal(t, codes.Unauthenticated, st.Code())
 }
 
+func TestAuthInterceptor_Scopes(t *testing.T) {
+	ots := newOIDCTestServer(t)
+	client, cleanup := setupAuthServer(t, ots)
+	defer cleanup()
+
+	call := func(scope string, rpc func(context.Context) error) codes.Code {
+		claims := validClaims(ots.issuer)
+		claims["scope"] = scope
+		ctx := metadata.NewOutgoingContext(context.Background(),
+			metadata.Pairs("authorization", "Bearer "+ots.signToken(t, claims)))
+		return status.Code(rpc(ctx))
+	}
+	getInfo := func(ctx context.Context) error {
+		_, err := client.GetBlobInfo(ctx, &pb.GetBlobInfoRequest{BlobId: "abc"})
+		return err
+	}
+	addRef := func(ctx context.Context) error {
+		_, err := client.AddReference(ctx, &pb.AddReferenceRequest{BlobId: "abc"})
+		return err
+	}
+	setHold := func(ctx context.Context) error {
+		_, err := client.SetLegalHold(ctx, &pb.SetLegalHoldRequest{BlobId: "abc"})
+		return err
+	}
+
+	assert.Equal(t, codes.NotFound, call("blob.read", getInfo))
+	assert.Equal(t, codes.PermissionDenied, call("blob.read", addRef))
+	assert.Equal(t, codes.PermissionDenied, call("blob.read blob.write", setHold))
+	assert.Equal(t, codes.Unimplemented, call("blob.admin", setHold), "the handler is reached")
+	assert.Equal(t, codes.PermissionDenied, call("", getInfo))
+}
+
 func TestAuthInterceptor_WrongAudience(t *testing.T) {
 	ots := newOIDCTestServer(t)
 	client, cleanup := setupAuthServer(t, ots)
@@ -198,3 +268,38 @@ type noopBlobServer struct {
 func (n *noopBlobServer) GetBlobInfo(_ context.Context, req *pb.GetBlobInfoRequest) (*pb.GetBlobInfoResponse, error) {
 	return nil, status.Errorf(codes.NotFound, "blob %s not found", req.BlobId)
 }
+
+type captureServer struct {
+	pb.UnimplementedBlobServiceServer
+	sub, clientID string
+}
+
+func (c *captureServer) GetBlobInfo(ctx context.Context, _ *pb.GetBlobInfoRequest) (*pb.GetBlobInfoResponse, error) {
+	c.sub = interceptor.CallerSub(ctx)
+	c.clientID = interceptor.CallerClientID(ctx)
+	return &pb.GetBlobInfoResponse{}, nil
+}
+
+func TestAuthInterceptor_Authenticate(t *testing.T) {
+	ots := newOIDCTestServer(t)
+	provider, err := oidc.NewProvider(context.Background(), ots.issuer)
+	require.NoError(t, err)
+	auth := interceptor.NewAuthInterceptor(provider, "blob-service")
+
+	claims := validClaims(ots.issuer)
+	claims["azp"] = "drive-web"
+	ctx, err := auth.Authenticate(context.Background(), ots.signToken(t, claims))
+	require.NoError(t, err)
+	assert.Equal(t, "drive-service", interceptor.CallerSub(ctx))
+	assert.Equal(t, "drive-web", interceptor.CallerClientID(ctx))
+	assert.Equal(t, []string{"blob.read", "blob.write"}, interceptor.CallerScopes(ctx))
+	assert.NoError(t, interceptor.Authorize(ctx, pb.BlobService_ListBlobs_FullMethodName))
+	st, _ := status.FromError(interceptor.Authorize(ctx, pb.BlobService_SetRetention_FullMethodName))
+	assert.Equal(t, codes.PermissionDenied, st.Code())
+	st, _ = status.FromError(interceptor.Authorize(ctx, pb.BlobService_WatchBlobEvents_FullMethodName))
+	assert.Equal(t, codes.PermissionDenied, st.Code(), "the event stream spans tenants")
+
+	_, err = auth.Authenticate(context.Background(), "not-a-jwt")
+	st, _ = status.FromError(err)
+	assert.Equal(t, codes.Unauthenticated, st.Code())
+}
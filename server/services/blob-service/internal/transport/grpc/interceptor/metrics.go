@@ -0,0 +1,37 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RPCRecorder receives the outcome of every call. metrics.Metrics implements
+// it.
+type RPCRecorder interface {
+	ObserveRPC(fullMethod string, code codes.Code, d time.Duration)
+}
+
+// UnaryMetrics records each unary call's status code and latency. Chain it
+// ahead of the auth interceptor so refused calls are recorded too.
+func UnaryMetrics(rec RPCRecorder) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		rec.ObserveRPC(info.FullMethod, status.Code(err), time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamMetrics records each stream's status code and how long it was open.
+func StreamMetrics(rec RPCRecorder) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		rec.ObserveRPC(info.FullMethod, status.Code(err), time.Since(start))
+		return err
+	}
+}
@@ -0,0 +1,80 @@
+package interceptor_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/barn0w1/hss-science/server/gen/blob/v1"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/transport/grpc/interceptor"
+)
+
+type rpcRecord struct {
+	method string
+	code   codes.Code
+}
+
+type fakeRPCRecorder struct {
+	mu      sync.Mutex
+	records []rpcRecord
+}
+
+func (r *fakeRPCRecorder) ObserveRPC(fullMethod string, code codes.Code, _ time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, rpcRecord{fullMethod, code})
+}
+
+func TestMetrics_RecordsCallsAndHealthIsPublic(t *testing.T) {
+	ots := newOIDCTestServer(t)
+	provider, err := oidc.NewProvider(context.Background(), ots.issuer)
+	require.NoError(t, err)
+	auth := interceptor.NewAuthInterceptor(provider, "blob-service")
+	rec := &fakeRPCRecorder{}
+
+	lis := bufconn.Listen(bufSize)
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(interceptor.UnaryMetrics(rec), auth.Unary()),
+		grpc.ChainStreamInterceptor(interceptor.StreamMetrics(rec), auth.Stream()),
+	)
+	pb.RegisterBlobServiceServer(srv, &noopBlobServer{})
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough://bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err, "health checks need no token")
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	_, err = pb.NewBlobServiceClient(conn).GetBlobInfo(context.Background(), &pb.GetBlobInfoRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	assert.Equal(t, []rpcRecord{
+		{"/grpc.health.v1.Health/Check", codes.OK},
+		{pb.BlobService_GetBlobInfo_FullMethodName, codes.Unauthenticated},
+	}, rec.records)
+}
@@ -13,6 +13,7 @@ import (
 	pb "github.com/barn0w1/hss-science/server/gen/blob/v1"
 	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
 	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/transport/grpc/interceptor"
 )
 
 type Server struct {
@@ -24,8 +25,37 @@ func NewServer(a *app.App) *Server {
 	return &Server{app: a}
 }
 
+func (s *Server) PlanUpload(ctx context.Context, req *pb.PlanUploadRequest) (*pb.PlanUploadResponse, error) {
+	if _, err := callerFrom(ctx); err != nil {
+		return nil, err
+	}
+	plan, err := s.app.PlanUpload(req.SizeBytes)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	parts := make([]*pb.PartRange, len(plan.Parts))
+	for i, p := range plan.Parts {
+		parts[i] = &pb.PartRange{
+			PartNumber: p.PartNumber,
+			Offset:     p.Offset,
+			SizeBytes:  p.SizeBytes,
+		}
+	}
+	return &pb.PlanUploadResponse{
+		Multipart:     plan.Multipart,
+		PartSizeBytes: plan.PartSize,
+		PartCount:     int32(len(parts)),
+		Parts:         parts,
+	}, nil
+}
+
 func (s *Server) InitiateUpload(ctx context.Context, req *pb.InitiateUploadRequest) (*pb.InitiateUploadResponse, error) {
-	result, err := s.app.InitiateUpload(ctx, domain.BlobID(req.BlobId), req.SizeBytes, req.ContentType)
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.app.InitiateUpload(ctx, caller, domain.BlobID(req.BlobId), req.SizeBytes, req.ContentType, req.Encrypt)
 	if err != nil {
 		return nil, mapError(err)
 	}
@@ -33,24 +63,34 @@ func (s *Server) InitiateUpload(ctx context.Context, req *pb.InitiateUploadReque
 	resp := &pb.InitiateUploadResponse{AlreadyExists: result.AlreadyExists}
 	if !result.AlreadyExists {
 		resp.PresignedPutUrl = result.PresignedPutURL
+		resp.RequiredHeaders = result.RequiredHeaders
 		resp.UrlExpiresAt = timestamppb.New(result.ExpiresAt)
 	}
 	return resp, nil
 }
 
 func (s *Server) CompleteUpload(ctx context.Context, req *pb.CompleteUploadRequest) (*pb.CompleteUploadResponse, error) {
-	result, err := s.app.CompleteUpload(ctx, domain.BlobID(req.BlobId))
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.app.CompleteUpload(ctx, caller, domain.BlobID(req.BlobId))
 	if err != nil {
 		return nil, mapError(err)
 	}
 	return &pb.CompleteUploadResponse{
 		BlobId:      string(result.BlobID),
-		CommittedAt: timestamppb.New(result.CommittedAt),
+		CommittedAt: optionalTimestamp(result.CommittedAt),
+		UploadState: stateToProto(result.State),
 	}, nil
 }
 
 func (s *Server) InitiateMultipartUpload(ctx context.Context, req *pb.InitiateMultipartUploadRequest) (*pb.InitiateMultipartUploadResponse, error) {
-	result, err := s.app.InitiateMultipartUpload(ctx, domain.BlobID(req.BlobId), req.SizeBytes, req.ContentType, req.PartCount)
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.app.InitiateMultipartUpload(ctx, caller, domain.BlobID(req.BlobId), req.SizeBytes, req.ContentType, req.PartCount, req.Encrypt)
 	if err != nil {
 		return nil, mapError(err)
 	}
@@ -64,6 +104,7 @@ func (s *Server) InitiateMultipartUpload(ctx context.Context, req *pb.InitiateMu
 		parts[i] = &pb.PartUploadURL{
 			PartNumber:      p.PartNumber,
 			PresignedPutUrl: p.PresignedPutURL,
+			RequiredHeaders: p.RequiredHeaders,
 		}
 	}
 	return &pb.InitiateMultipartUploadResponse{
@@ -73,70 +114,385 @@ func (s *Server) InitiateMultipartUpload(ctx context.Context, req *pb.InitiateMu
 	}, nil
 }
 
+func (s *Server) ResumeMultipartUpload(ctx context.Context, req *pb.ResumeMultipartUploadRequest) (*pb.ResumeMultipartUploadResponse, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.app.ResumeMultipartUpload(ctx, caller, domain.BlobID(req.BlobId))
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	if result.AlreadyExists {
+		return &pb.ResumeMultipartUploadResponse{AlreadyExists: true}, nil
+	}
+
+	uploaded := make([]*pb.UploadedPart, len(result.UploadedParts))
+	for i, p := range result.UploadedParts {
+		uploaded[i] = &pb.UploadedPart{
+			PartNumber: p.PartNumber,
+			Etag:       p.ETag,
+			SizeBytes:  p.SizeBytes,
+		}
+	}
+	missing := make([]*pb.PartUploadURL, len(result.MissingParts))
+	for i, p := range result.MissingParts {
+		missing[i] = &pb.PartUploadURL{
+			PartNumber:      p.PartNumber,
+			PresignedPutUrl: p.PresignedPutURL,
+			RequiredHeaders: p.RequiredHeaders,
+		}
+	}
+	resp := &pb.ResumeMultipartUploadResponse{
+		UploadId:      result.UploadID,
+		UploadedParts: uploaded,
+		MissingParts:  missing,
+	}
+	if len(missing) > 0 {
+		resp.UrlExpiresAt = timestamppb.New(result.ExpiresAt)
+	}
+	return resp, nil
+}
+
 func (s *Server) CompleteMultipartUpload(ctx context.Context, req *pb.CompleteMultipartUploadRequest) (*pb.CompleteMultipartUploadResponse, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
 	parts := make([]domain.CompletedPart, len(req.Parts))
 	for i, p := range req.Parts {
 		parts[i] = domain.CompletedPart{PartNumber: p.PartNumber, ETag: p.Etag}
 	}
 
-	result, err := s.app.CompleteMultipartUpload(ctx, domain.BlobID(req.BlobId), req.UploadId, parts)
+	result, err := s.app.CompleteMultipartUpload(ctx, caller, domain.BlobID(req.BlobId), req.UploadId, parts)
 	if err != nil {
 		return nil, mapError(err)
 	}
 	return &pb.CompleteMultipartUploadResponse{
 		BlobId:      string(result.BlobID),
-		CommittedAt: timestamppb.New(result.CommittedAt),
+		CommittedAt: optionalTimestamp(result.CommittedAt),
+		UploadState: stateToProto(result.State),
 	}, nil
 }
 
 func (s *Server) AbortMultipartUpload(ctx context.Context, req *pb.AbortMultipartUploadRequest) (*pb.AbortMultipartUploadResponse, error) {
-	if err := s.app.AbortMultipartUpload(ctx, domain.BlobID(req.BlobId), req.UploadId); err != nil {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.app.AbortMultipartUpload(ctx, caller, domain.BlobID(req.BlobId), req.UploadId); err != nil {
 		return nil, mapError(err)
 	}
 	return &pb.AbortMultipartUploadResponse{}, nil
 }
 
 func (s *Server) GetDownloadURL(ctx context.Context, req *pb.GetDownloadURLRequest) (*pb.GetDownloadURLResponse, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
 	ttl := time.Duration(req.TtlSeconds) * time.Second
-	result, err := s.app.GetDownloadURL(ctx, domain.BlobID(req.BlobId), ttl)
+	opts := app.DownloadOptions{
+		Disposition:  dispositionFromProto(req.Disposition),
+		Filename:     req.Filename,
+		ContentType:  req.ResponseContentType,
+		CacheControl: req.CacheControl,
+		Offset:       req.RangeOffset,
+		Length:       req.RangeLength,
+	}
+	result, err := s.app.GetDownloadURL(ctx, caller, domain.BlobID(req.BlobId), ttl, opts)
 	if err != nil {
 		return nil, mapError(err)
 	}
 	return &pb.GetDownloadURLResponse{
 		PresignedGetUrl: result.PresignedGetURL,
 		UrlExpiresAt:    timestamppb.New(result.ExpiresAt),
+		RequiredHeaders: result.RequiredHeaders,
+	}, nil
+}
+
+func (s *Server) GetRendition(ctx context.Context, req *pb.GetRenditionRequest) (*pb.GetRenditionResponse, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	spec := domain.RenditionSpec{
+		Format:       renditionFormatFromProto(req.GetSpec().GetFormat()),
+		MaxDimension: int(req.GetSpec().GetMaxDimension()),
+	}
+	ttl := time.Duration(req.TtlSeconds) * time.Second
+	result, err := s.app.GetRendition(ctx, caller, domain.BlobID(req.BlobId), spec, ttl)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &pb.GetRenditionResponse{
+		RenditionBlobId: string(result.Blob.ID),
+		SizeBytes:       result.Blob.SizeBytes,
+		ContentType:     result.Blob.ContentType,
+		PresignedGetUrl: result.PresignedGetURL,
+		UrlExpiresAt:    timestamppb.New(result.ExpiresAt),
+		RequiredHeaders: result.RequiredHeaders,
 	}, nil
 }
 
 func (s *Server) GetBlobInfo(ctx context.Context, req *pb.GetBlobInfoRequest) (*pb.GetBlobInfoResponse, error) {
-	blob, err := s.app.GetBlobInfo(ctx, domain.BlobID(req.BlobId))
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := s.app.GetBlobInfo(ctx, caller, domain.BlobID(req.BlobId))
 	if err != nil {
 		return nil, mapError(err)
 	}
 
 	resp := &pb.GetBlobInfoResponse{
-		BlobId:      string(blob.ID),
-		SizeBytes:   blob.SizeBytes,
-		ContentType: blob.ContentType,
-		UploadState: stateToProto(blob.State),
+		BlobId:              string(blob.ID),
+		SizeBytes:           blob.SizeBytes,
+		ContentType:         blob.ContentType,
+		DetectedContentType: blob.DetectedContentType,
+		Encrypted:           blob.Encrypted(),
+		UploadState:         stateToProto(blob.State),
 	}
 	if blob.CommittedAt != nil {
 		resp.CommittedAt = timestamppb.New(*blob.CommittedAt)
 	}
+	if blob.RetainUntil != nil {
+		resp.RetainUntil = timestamppb.New(*blob.RetainUntil)
+	}
+	resp.LegalHold = blob.LegalHold
+	return resp, nil
+}
+
+func (s *Server) BatchGetBlobInfo(ctx context.Context, req *pb.BatchGetBlobInfoRequest) (*pb.BatchGetBlobInfoResponse, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]domain.BlobID, len(req.BlobIds))
+	for i, id := range req.BlobIds {
+		ids[i] = domain.BlobID(id)
+	}
+	results, err := s.app.BatchGetBlobInfo(ctx, caller, ids)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	resp := &pb.BatchGetBlobInfoResponse{Results: make([]*pb.BlobInfoResult, len(results))}
+	for i, r := range results {
+		out := &pb.BlobInfoResult{BlobId: string(r.BlobID)}
+		if r.Err != nil {
+			st := status.Convert(mapError(r.Err))
+			out.ErrorCode = int32(st.Code())
+			out.ErrorMessage = st.Message()
+		} else {
+			out.Info = blobInfoToProto(r.Blob)
+		}
+		resp.Results[i] = out
+	}
+	return resp, nil
+}
+
+func (s *Server) ListBlobs(ctx context.Context, req *pb.ListBlobsRequest) (*pb.ListBlobsResponse, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f := domain.BlobFilter{
+		State:       stateFromProto(req.State),
+		ContentType: req.ContentType,
+	}
+	if req.CreatedAfter != nil {
+		f.CreatedAfter = req.CreatedAfter.AsTime()
+	}
+	if req.CreatedBefore != nil {
+		f.CreatedBefore = req.CreatedBefore.AsTime()
+	}
+	if req.CommittedAfter != nil {
+		f.CommittedAfter = req.CommittedAfter.AsTime()
+	}
+	if req.CommittedBefore != nil {
+		f.CommittedBefore = req.CommittedBefore.AsTime()
+	}
+
+	result, err := s.app.ListBlobs(ctx, caller, f, int(req.PageSize), req.PageToken)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	blobs := make([]*pb.BlobInfo, len(result.Blobs))
+	for i, b := range result.Blobs {
+		blobs[i] = blobInfoToProto(b)
+	}
+	return &pb.ListBlobsResponse{Blobs: blobs, NextPageToken: result.NextPageToken}, nil
+}
+
+func (s *Server) AddReference(ctx context.Context, req *pb.AddReferenceRequest) (*pb.AddReferenceResponse, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.app.AddReference(ctx, caller, domain.BlobID(req.BlobId), req.Subject); err != nil {
+		return nil, mapError(err)
+	}
+	return &pb.AddReferenceResponse{}, nil
+}
+
+func (s *Server) ReleaseReference(ctx context.Context, req *pb.ReleaseReferenceRequest) (*pb.ReleaseReferenceResponse, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.app.ReleaseReference(ctx, caller, domain.BlobID(req.BlobId)); err != nil {
+		return nil, mapError(err)
+	}
+	return &pb.ReleaseReferenceResponse{}, nil
+}
+
+func (s *Server) GetUsage(ctx context.Context, _ *pb.GetUsageRequest) (*pb.GetUsageResponse, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.app.GetUsage(ctx, caller)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	resp := &pb.GetUsageResponse{}
+	for _, u := range usage {
+		msg := &pb.Usage{
+			Bytes:          u.Usage.Bytes,
+			Objects:        u.Usage.Objects,
+			MaxBytes:       u.Limits.MaxBytes,
+			MaxObjects:     u.Limits.MaxObjects,
+			MaxObjectBytes: u.Limits.MaxObjectBytes,
+		}
+		switch u.Owner.Kind {
+		case domain.OwnerSubject:
+			resp.Subject = msg
+		case domain.OwnerClient:
+			resp.Client = msg
+		}
+	}
 	return resp, nil
 }
 
+// SetRetention treats a missing retain_until as the epoch, which is
+// rejected as being in the past.
+func (s *Server) SetRetention(ctx context.Context, req *pb.SetRetentionRequest) (*pb.SetRetentionResponse, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = s.app.SetRetention(ctx, caller, domain.BlobID(req.BlobId), req.RetainUntil.AsTime())
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &pb.SetRetentionResponse{}, nil
+}
+
+func (s *Server) SetLegalHold(ctx context.Context, req *pb.SetLegalHoldRequest) (*pb.SetLegalHoldResponse, error) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.app.SetLegalHold(ctx, caller, domain.BlobID(req.BlobId), req.LegalHold, req.Reason); err != nil {
+		return nil, mapError(err)
+	}
+	return &pb.SetLegalHoldResponse{}, nil
+}
+
+func callerFrom(ctx context.Context) (domain.Caller, error) {
+	sub := interceptor.CallerSub(ctx)
+	if sub == "" {
+		return domain.Caller{}, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	return domain.Caller{Subject: sub, ClientID: interceptor.CallerClientID(ctx)}, nil
+}
+
 func stateToProto(s domain.UploadState) pb.UploadState {
 	switch s {
 	case domain.StatePending:
 		return pb.UploadState_PENDING
 	case domain.StateCommitted:
 		return pb.UploadState_COMMITTED
+	case domain.StateScanning:
+		return pb.UploadState_SCANNING
+	case domain.StateQuarantined:
+		return pb.UploadState_QUARANTINED
+	case domain.StateMissing:
+		return pb.UploadState_MISSING
 	default:
 		return pb.UploadState_UPLOAD_STATE_UNSPECIFIED
 	}
 }
 
+// optionalTimestamp leaves unset times unset rather than sending the epoch.
+func optionalTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func blobInfoToProto(b *domain.Blob) *pb.BlobInfo {
+	info := &pb.BlobInfo{
+		BlobId:              string(b.ID),
+		SizeBytes:           b.SizeBytes,
+		ContentType:         b.ContentType,
+		DetectedContentType: b.DetectedContentType,
+		Encrypted:           b.Encrypted(),
+		UploadState:         stateToProto(b.State),
+		CreatedAt:           timestamppb.New(b.CreatedAt),
+	}
+	if b.CommittedAt != nil {
+		info.CommittedAt = timestamppb.New(*b.CommittedAt)
+	}
+	if b.RetainUntil != nil {
+		info.RetainUntil = timestamppb.New(*b.RetainUntil)
+	}
+	info.LegalHold = b.LegalHold
+	return info
+}
+
+func stateFromProto(s pb.UploadState) domain.UploadState {
+	switch s {
+	case pb.UploadState_PENDING:
+		return domain.StatePending
+	case pb.UploadState_COMMITTED:
+		return domain.StateCommitted
+	case pb.UploadState_SCANNING:
+		return domain.StateScanning
+	case pb.UploadState_QUARANTINED:
+		return domain.StateQuarantined
+	case pb.UploadState_MISSING:
+		return domain.StateMissing
+	default:
+		return ""
+	}
+}
+
+func dispositionFromProto(d pb.ContentDisposition) string {
+	switch d {
+	case pb.ContentDisposition_INLINE:
+		return domain.DispositionInline
+	case pb.ContentDisposition_ATTACHMENT:
+		return domain.DispositionAttachment
+	default:
+		return ""
+	}
+}
+
+func renditionFormatFromProto(f pb.RenditionFormat) domain.RenditionFormat {
+	switch f {
+	case pb.RenditionFormat_JPEG:
+		return domain.RenditionJPEG
+	case pb.RenditionFormat_WEBP:
+		return domain.RenditionWebP
+	default:
+		return ""
+	}
+}
+
 func mapError(err error) error {
 	switch {
 	case errors.Is(err, domain.ErrInvalidBlobID):
@@ -145,7 +501,45 @@ func mapError(err error) error {
 		return status.Error(codes.NotFound, err.Error())
 	case errors.Is(err, domain.ErrAlreadyCommitted):
 		return status.Error(codes.FailedPrecondition, err.Error())
-	case errors.Is(err, domain.ErrBlobPending):
+	case errors.Is(err, domain.ErrBlobPending),
+		errors.Is(err, domain.ErrBlobScanning),
+		errors.Is(err, domain.ErrBlobQuarantined),
+		errors.Is(err, domain.ErrBlobMissing):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrObjectNotFound):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrContentMismatch):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrSessionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrInvalidSize), errors.Is(err, domain.ErrInvalidPartCount):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrInvalidDownloadOption),
+		errors.Is(err, domain.ErrInvalidPageToken),
+		errors.Is(err, domain.ErrTooManyBlobIDs):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrInvalidRange):
+		return status.Error(codes.OutOfRange, err.Error())
+	case errors.Is(err, domain.ErrUnknownRendition):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrNotRenderable):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrRenditionPending):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, domain.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, domain.ErrContentTypeNotAllowed):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domain.ErrEncryptionUnavailable),
+		errors.Is(err, domain.ErrUnknownMasterKey):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrOffsetMismatch):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domain.ErrInvalidRetention):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrRetentionShortened):
 		return status.Error(codes.FailedPrecondition, err.Error())
 	default:
 		slog.Error("internal error", "error", err)
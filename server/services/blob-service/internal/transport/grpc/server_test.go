@@ -1,8 +1,16 @@
 package grpctransport_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/png"
+	"io"
 	"net"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,24 +21,65 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/barn0w1/hss-science/server/gen/blob/v1"
 	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
 	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/keyring"
 	grpctransport "github.com/barn0w1/hss-science/server/services/blob-service/internal/transport/grpc"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/transport/grpc/interceptor"
 )
 
 const (
-	bufSize = 1024 * 1024
-	validID = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	bufSize    = 1024 * 1024
+	validID    = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	testCaller = "drive-service"
 )
 
 type mockRepo struct {
-	blobs map[domain.BlobID]*domain.Blob
+	blobs    map[domain.BlobID]*domain.Blob
+	refs     map[domain.BlobID]map[string]bool
+	uploads  map[uploadKey]*domain.Upload
+	sessions map[uploadKey]*domain.MultipartSession
+	events   []*domain.BlobEvent
+	usage    map[domain.Owner]domain.Usage
+	links    map[renditionKey]domain.BlobID
+}
+
+type uploadKey struct {
+	id      domain.BlobID
+	subject string
+}
+
+type renditionKey struct {
+	source domain.BlobID
+	spec   string
 }
 
 func newMockRepo() *mockRepo {
-	return &mockRepo{blobs: make(map[domain.BlobID]*domain.Blob)}
+	return &mockRepo{
+		blobs:    make(map[domain.BlobID]*domain.Blob),
+		refs:     make(map[domain.BlobID]map[string]bool),
+		uploads:  make(map[uploadKey]*domain.Upload),
+		sessions: make(map[uploadKey]*domain.MultipartSession),
+		usage:    make(map[domain.Owner]domain.Usage),
+		links:    make(map[renditionKey]domain.BlobID),
+	}
+}
+
+// seed stores b with a reference held by testCaller, or as testCaller's
+// upload if it is PENDING.
+func (m *mockRepo) seed(b *domain.Blob) {
+	if b.State == domain.StatePending {
+		m.uploads[uploadKey{b.ID, testCaller}] = &domain.Upload{
+			BlobID: b.ID, Subject: testCaller, ObjectKey: string(b.ID),
+			SizeBytes: b.SizeBytes, ContentType: b.ContentType, CreatedAt: b.CreatedAt,
+		}
+		return
+	}
+	m.blobs[b.ID] = b
+	_ = m.AddReference(context.Background(), b.ID, testCaller, "", domain.QuotaPolicy{})
 }
 
 func (m *mockRepo) FindByID(_ context.Context, id domain.BlobID) (*domain.Blob, error) {
@@ -47,37 +96,278 @@ func (m *mockRepo) Create(_ context.Context, b *domain.Blob) error {
 	return nil
 }
 
-func (m *mockRepo) MarkCommitted(_ context.Context, id domain.BlobID, at time.Time) error {
+func (m *mockRepo) MarkCommitted(_ context.Context, id domain.BlobID, detectedContentType string, at time.Time) error {
 	b, ok := m.blobs[id]
 	if !ok {
 		return domain.ErrBlobNotFound
 	}
 	b.State = domain.StateCommitted
+	b.DetectedContentType = detectedContentType
 	b.CommittedAt = &at
 	return nil
 }
 
+func (m *mockRepo) MarkQuarantined(_ context.Context, id domain.BlobID, reason string) error {
+	b, ok := m.blobs[id]
+	if !ok || b.State != domain.StateScanning {
+		return domain.ErrAlreadyCommitted
+	}
+	b.State = domain.StateQuarantined
+	b.QuarantineReason = reason
+	return nil
+}
+
+func (m *mockRepo) ListScanning(context.Context, domain.BlobID, int) ([]*domain.Blob, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) ListStaleKeys(context.Context, string, domain.BlobID, int) ([]*domain.Blob, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) RewrapKey(context.Context, domain.BlobID, string, string, []byte) (bool, error) {
+	return false, nil
+}
+
+func (m *mockRepo) MarkMissing(context.Context, domain.BlobID) error {
+	return nil
+}
+
+func (m *mockRepo) SetRetention(context.Context, domain.BlobID, time.Time, domain.Caller) error {
+	return nil
+}
+
+func (m *mockRepo) SetLegalHold(context.Context, domain.BlobID, bool, string, domain.Caller) error {
+	return nil
+}
+
+func (m *mockRepo) ListAll(context.Context, domain.BlobID, int) ([]*domain.Blob, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) FindByIDs(context.Context, []domain.BlobID) ([]*domain.Blob, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) ListUnreplicated(context.Context, domain.BlobID, int) ([]*domain.Blob, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) MarkReplicated(context.Context, domain.BlobID, time.Time) error {
+	return nil
+}
+
+func (m *mockRepo) FindReferenced(_ context.Context, ids []domain.BlobID, subject string) ([]*domain.Blob, error) {
+	var out []*domain.Blob
+	for _, id := range ids {
+		if b, ok := m.blobs[id]; ok && m.refs[id][subject] {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepo) ListBlobs(_ context.Context, f domain.BlobFilter, afterID domain.BlobID, limit int) ([]*domain.Blob, error) {
+	var out []*domain.Blob
+	for id, b := range m.blobs {
+		if id > afterID && m.refs[id][f.Subject] && (f.State == "" || b.State == f.State) {
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (m *mockRepo) AddReference(_ context.Context, id domain.BlobID, subject, _ string, _ domain.QuotaPolicy) error {
+	if _, ok := m.blobs[id]; !ok {
+		return domain.ErrBlobNotFound
+	}
+	if m.refs[id] == nil {
+		m.refs[id] = make(map[string]bool)
+	}
+	m.refs[id][subject] = true
+	return nil
+}
+
+func (m *mockRepo) LinkRendition(_ context.Context, source domain.BlobID, spec string, rendition domain.BlobID) error {
+	m.links[renditionKey{source, spec}] = rendition
+	return nil
+}
+
+func (m *mockRepo) FindRendition(_ context.Context, source domain.BlobID, spec string) (*domain.Blob, error) {
+	b, ok := m.blobs[m.links[renditionKey{source, spec}]]
+	if !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+	return b, nil
+}
+
+func (m *mockRepo) IsRendition(_ context.Context, id domain.BlobID) (bool, error) {
+	for _, rid := range m.links {
+		if rid == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockRepo) GetUsage(_ context.Context, o domain.Owner) (*domain.Usage, error) {
+	u := m.usage[o]
+	return &u, nil
+}
+
+func (m *mockRepo) HasReference(_ context.Context, id domain.BlobID, subject string) (bool, error) {
+	return m.refs[id][subject], nil
+}
+
+func (m *mockRepo) RemoveReference(_ context.Context, id domain.BlobID, subject string, at time.Time) error {
+	if !m.refs[id][subject] {
+		return domain.ErrBlobNotFound
+	}
+	delete(m.refs[id], subject)
+	if len(m.refs[id]) == 0 {
+		m.blobs[id].ReleasedAt = &at
+	}
+	return nil
+}
+
+func (m *mockRepo) ListReleased(context.Context, time.Time, domain.BlobID, int) ([]*domain.Blob, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) DeleteReleased(context.Context, domain.BlobID, time.Time) (string, bool, error) {
+	return "", false, nil
+}
+
+func (m *mockRepo) RecordMultipartUpload(_ context.Context, s *domain.MultipartSession) error {
+	m.sessions[uploadKey{s.BlobID, s.Subject}] = s
+	return nil
+}
+
+func (m *mockRepo) FindMultipartUpload(_ context.Context, id domain.BlobID, subject string) (*domain.MultipartSession, error) {
+	s, ok := m.sessions[uploadKey{id, subject}]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+	return s, nil
+}
+
+func (m *mockRepo) DeleteMultipartUpload(context.Context, string) error {
+	return nil
+}
+
+func (m *mockRepo) HasMultipartUpload(context.Context, string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockRepo) ClaimResumableNumber(context.Context, string, int64, bool) (int32, error) {
+	return 1, nil
+}
+
+func (m *mockRepo) AdvanceResumableUpload(context.Context, *domain.MultipartSession, int64) error {
+	return nil
+}
+
+func (m *mockRepo) CreateUpload(_ context.Context, u *domain.Upload) error {
+	m.uploads[uploadKey{u.BlobID, u.Subject}] = u
+	return nil
+}
+
+func (m *mockRepo) FindUpload(_ context.Context, id domain.BlobID, subject string) (*domain.Upload, error) {
+	u, ok := m.uploads[uploadKey{id, subject}]
+	if !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+	return u, nil
+}
+
+func (m *mockRepo) FindUploads(context.Context, []domain.BlobID) ([]*domain.Upload, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) DeleteUpload(_ context.Context, u *domain.Upload) (bool, error) {
+	k := uploadKey{u.BlobID, u.Subject}
+	_, ok := m.uploads[k]
+	delete(m.uploads, k)
+	return ok, nil
+}
+
+func (m *mockRepo) ListExpiredUploads(context.Context, time.Time, string, int) ([]*domain.Upload, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) CommitUpload(ctx context.Context, u *domain.Upload, b *domain.Blob, quotas domain.QuotaPolicy) (*domain.Blob, error) {
+	if ok, _ := m.DeleteUpload(ctx, u); !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+	if _, ok := m.blobs[b.ID]; !ok {
+		cp := *b
+		m.blobs[b.ID] = &cp
+	}
+	_ = m.AddReference(ctx, b.ID, u.Subject, u.ClientID, quotas)
+	return m.blobs[b.ID], nil
+}
+
+func (m *mockRepo) ListEvents(_ context.Context, afterSeq int64, limit int) ([]*domain.BlobEvent, error) {
+	var out []*domain.BlobEvent
+	for _, e := range m.events {
+		if e.Seq > afterSeq && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepo) DeleteEventsBefore(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockRepo) FindEventCursor(context.Context, string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockRepo) SaveEventCursor(context.Context, string, int64) error {
+	return nil
+}
+
 type mockStorage struct {
 	putURL   string
 	getURL   string
 	uploadID string
 	partURL  string
+	objects  map[string][]byte
+	parts    []domain.UploadedPart
 }
 
-func (m *mockStorage) PresignedPutURL(_ context.Context, _ string, _ time.Duration) (string, time.Time, error) {
-	return m.putURL, time.Now().Add(time.Minute), nil
+func (m *mockStorage) PresignedPutURL(_ context.Context, _ string, c domain.PutConstraints, _ time.Duration) (*domain.PresignedRequest, error) {
+	return &domain.PresignedRequest{
+		URL:       m.putURL,
+		Headers:   map[string]string{"Content-Type": c.ContentType},
+		ExpiresAt: time.Now().Add(time.Minute),
+	}, nil
 }
 
-func (m *mockStorage) PresignedGetURL(_ context.Context, _ string, ttl time.Duration) (string, time.Time, error) {
-	return m.getURL, time.Now().Add(ttl), nil
+func (m *mockStorage) PresignedGetURL(_ context.Context, _ string, o domain.GetOptions, ttl time.Duration) (*domain.PresignedRequest, error) {
+	headers := map[string]string{}
+	if rng := o.Range(); rng != "" {
+		headers["Range"] = rng
+	}
+	return &domain.PresignedRequest{URL: m.getURL, Headers: headers, ExpiresAt: time.Now().Add(ttl)}, nil
 }
 
 func (m *mockStorage) CreateMultipartUpload(_ context.Context, _, _ string) (string, error) {
 	return m.uploadID, nil
 }
 
-func (m *mockStorage) PresignedPartURL(_ context.Context, _, _ string, _ int32, _ time.Duration) (string, time.Time, error) {
-	return m.partURL, time.Now().Add(time.Minute), nil
+func (m *mockStorage) PresignedPartURL(_ context.Context, _, _ string, _ int32, _ int64, _ time.Duration) (*domain.PresignedRequest, error) {
+	return &domain.PresignedRequest{URL: m.partURL, ExpiresAt: time.Now().Add(time.Minute)}, nil
+}
+
+func (m *mockStorage) UploadPart(context.Context, string, string, int32, io.Reader, int64) (string, error) {
+	return "", nil
 }
 
 func (m *mockStorage) CompleteMultipartUpload(_ context.Context, _, _ string, _ []domain.CompletedPart) error {
@@ -88,16 +378,100 @@ func (m *mockStorage) AbortMultipartUpload(_ context.Context, _, _ string) error
 	return nil
 }
 
+func (m *mockStorage) ListMultipartUploads(context.Context) ([]domain.MultipartUpload, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) ListParts(context.Context, string, string) ([]domain.UploadedPart, error) {
+	return m.parts, nil
+}
+
+func (m *mockStorage) StatObject(_ context.Context, key string) (*domain.ObjectInfo, error) {
+	obj, ok := m.objects[key]
+	if !ok {
+		return nil, domain.ErrObjectNotFound
+	}
+	return &domain.ObjectInfo{SizeBytes: int64(len(obj))}, nil
+}
+
+func (m *mockStorage) GetObject(_ context.Context, key string) (io.ReadCloser, error) {
+	obj, ok := m.objects[key]
+	if !ok {
+		return nil, domain.ErrObjectNotFound
+	}
+	return io.NopCloser(bytes.NewReader(obj)), nil
+}
+
+func (m *mockStorage) GetObjectRange(_ context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	obj, ok := m.objects[key]
+	if !ok {
+		return nil, domain.ErrObjectNotFound
+	}
+	end := int64(len(obj))
+	if length >= 0 {
+		end = min(end, offset+length)
+	}
+	return io.NopCloser(bytes.NewReader(obj[offset:end])), nil
+}
+
+func (m *mockStorage) PutObject(_ context.Context, key string, body io.Reader, _ int64, _, _ string) error {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if m.objects == nil {
+		m.objects = make(map[string][]byte)
+	}
+	m.objects[key] = content
+	return nil
+}
+
+func (m *mockStorage) DeleteObject(_ context.Context, key string) error {
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *mockStorage) ListObjects(context.Context, string, int) ([]domain.StoredObject, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) WithCustomerKey([]byte) domain.ObjectStorage {
+	return m
+}
+
+type callerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *callerStream) Context() context.Context { return s.ctx }
+
 func setupServer(t *testing.T, repo domain.BlobRepository, storage domain.ObjectStorage) pb.BlobServiceClient {
 	t.Helper()
-
-	blobApp := app.New(repo, storage, app.Config{
+	return setupServerWith(t, repo, storage, app.Config{
 		PresignPutTTL:    15 * time.Minute,
 		PresignGetMaxTTL: time.Hour,
 	})
+}
+
+func setupServerWith(t *testing.T, repo domain.BlobRepository, storage domain.ObjectStorage, cfg app.Config) pb.BlobServiceClient {
+	t.Helper()
+
+	blobApp := app.New(repo, storage, cfg)
 
 	lis := bufconn.Listen(bufSize)
-	srv := grpc.NewServer()
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				return handler(interceptor.WithCallerSub(ctx, testCaller), req)
+			},
+		),
+		grpc.ChainStreamInterceptor(
+			func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				return handler(srv, &callerStream{ServerStream: ss, ctx: interceptor.WithCallerSub(ss.Context(), testCaller)})
+			},
+		),
+	)
 	pb.RegisterBlobServiceServer(srv, grpctransport.NewServer(blobApp))
 
 	go func() { _ = srv.Serve(lis) }()
@@ -127,6 +501,20 @@ func TestServer_InitiateUpload_NewBlob(t *testing.T) {
 	require.NoError(t, err)
 	assert.False(t, resp.AlreadyExists)
 	assert.Equal(t, "https://r2.example.com/put", resp.PresignedPutUrl)
+	assert.Equal(t, map[string]string{"Content-Type": "image/png"}, resp.RequiredHeaders)
+}
+
+func TestServer_InitiateUpload_EncryptionUnavailable(t *testing.T) {
+	client := setupServer(t, newMockRepo(), &mockStorage{})
+
+	_, err := client.InitiateUpload(context.Background(), &pb.InitiateUploadRequest{
+		BlobId:      validID,
+		SizeBytes:   1024,
+		ContentType: "image/png",
+		Encrypt:     true,
+	})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
 }
 
 func TestServer_InitiateUpload_InvalidID(t *testing.T) {
@@ -147,10 +535,90 @@ func TestServer_GetBlobInfo_NotFound(t *testing.T) {
 	assert.Equal(t, codes.NotFound, st.Code())
 }
 
+func TestServer_BatchGetBlobInfo(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 2048, "application/pdf", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+
+	client := setupServer(t, repo, &mockStorage{})
+
+	resp, err := client.BatchGetBlobInfo(context.Background(), &pb.BatchGetBlobInfoRequest{
+		BlobIds: []string{validID, "bogus"},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, int64(2048), resp.Results[0].Info.SizeBytes)
+	assert.Equal(t, int32(codes.OK), resp.Results[0].ErrorCode)
+	assert.Nil(t, resp.Results[1].Info)
+	assert.Equal(t, int32(codes.InvalidArgument), resp.Results[1].ErrorCode)
+}
+
+func TestServer_ListBlobs(t *testing.T) {
+	repo := newMockRepo()
+	committed, _ := domain.NewBlob(domain.BlobID(validID), 2048, "application/pdf", time.Now())
+	_ = committed.Commit(time.Now())
+	repo.seed(committed)
+	scanning, _ := domain.NewBlob(domain.BlobID(strings.Repeat("a", 64)), 1, "text/plain", time.Now())
+	scanning.State = domain.StateScanning
+	repo.seed(scanning)
+
+	client := setupServer(t, repo, &mockStorage{})
+
+	resp, err := client.ListBlobs(context.Background(), &pb.ListBlobsRequest{PageSize: 1})
+	require.NoError(t, err)
+	require.Len(t, resp.Blobs, 1)
+	assert.NotEmpty(t, resp.NextPageToken)
+
+	resp, err = client.ListBlobs(context.Background(), &pb.ListBlobsRequest{State: pb.UploadState_COMMITTED})
+	require.NoError(t, err)
+	require.Len(t, resp.Blobs, 1)
+	assert.Equal(t, validID, resp.Blobs[0].BlobId)
+	assert.NotNil(t, resp.Blobs[0].CommittedAt)
+	assert.Empty(t, resp.NextPageToken)
+
+	_, err = client.ListBlobs(context.Background(), &pb.ListBlobsRequest{PageToken: "!"})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+func TestServer_WatchBlobEvents(t *testing.T) {
+	repo := newMockRepo()
+	for i, kind := range []domain.EventKind{domain.EventCommitted, domain.EventCommitted, domain.EventDeleted} {
+		repo.events = append(repo.events, &domain.BlobEvent{
+			Seq:        int64(i + 1),
+			Kind:       kind,
+			BlobID:     domain.BlobID(validID),
+			SizeBytes:  1024,
+			OccurredAt: time.Now(),
+		})
+	}
+	client := setupServer(t, repo, &mockStorage{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.WatchBlobEvents(ctx, &pb.WatchBlobEventsRequest{AfterSequence: 1})
+	require.NoError(t, err)
+
+	first, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), first.Event.Sequence)
+	assert.Equal(t, pb.BlobEventKind_BLOB_COMMITTED, first.Event.Kind)
+
+	second, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), second.Event.Sequence)
+	assert.Equal(t, pb.BlobEventKind_BLOB_DELETED, second.Event.Kind)
+
+	cancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
 func TestServer_GetDownloadURL_Pending(t *testing.T) {
 	repo := newMockRepo()
 	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
-	repo.blobs[domain.BlobID(validID)] = blob
+	repo.seed(blob)
 
 	client := setupServer(t, repo, &mockStorage{getURL: "https://r2.example.com/get"})
 
@@ -166,7 +634,7 @@ func TestServer_GetDownloadURL_Committed(t *testing.T) {
 	repo := newMockRepo()
 	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
 	_ = blob.Commit(time.Now())
-	repo.blobs[domain.BlobID(validID)] = blob
+	repo.seed(blob)
 
 	client := setupServer(t, repo, &mockStorage{getURL: "https://r2.example.com/get"})
 
@@ -178,19 +646,146 @@ func TestServer_GetDownloadURL_Committed(t *testing.T) {
 	assert.Equal(t, "https://r2.example.com/get", resp.PresignedGetUrl)
 }
 
-func TestServer_CompleteUpload_HappyPath(t *testing.T) {
+func TestServer_GetDownloadURL_UnknownMasterKey(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	_ = blob.Commit(time.Now())
+	blob.KeyID, blob.WrappedKey = "retired", []byte("wrapped")
+	repo.seed(blob)
+	keys, err := keyring.New([32]byte{1})
+	require.NoError(t, err)
+
+	client := setupServerWith(t, repo, &mockStorage{getURL: "https://r2.example.com/get"}, app.Config{
+		PresignGetMaxTTL: time.Hour,
+		Keys:             keys,
+	})
+
+	_, err = client.GetDownloadURL(context.Background(), &pb.GetDownloadURLRequest{BlobId: validID})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+}
+
+func TestServer_GetDownloadURL_Options(t *testing.T) {
 	repo := newMockRepo()
 	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
-	repo.blobs[domain.BlobID(validID)] = blob
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
 
-	client := setupServer(t, repo, &mockStorage{})
+	client := setupServer(t, repo, &mockStorage{getURL: "https://r2.example.com/get"})
+
+	resp, err := client.GetDownloadURL(context.Background(), &pb.GetDownloadURLRequest{
+		BlobId:      validID,
+		Disposition: pb.ContentDisposition_ATTACHMENT,
+		Filename:    "photo.png",
+		RangeOffset: 10,
+		RangeLength: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Range": "bytes=10-19"}, resp.RequiredHeaders)
+
+	_, err = client.GetDownloadURL(context.Background(), &pb.GetDownloadURLRequest{
+		BlobId:   validID,
+		Filename: "a\nb",
+	})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+func TestServer_CompleteUpload_HappyPath(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 0, "text/plain", time.Now())
+	repo.seed(blob)
+
+	client := setupServer(t, repo, &mockStorage{objects: map[string][]byte{validID: {}}})
 
 	resp, err := client.CompleteUpload(context.Background(), &pb.CompleteUploadRequest{BlobId: validID})
 	require.NoError(t, err)
 	assert.Equal(t, validID, resp.BlobId)
+	assert.Equal(t, pb.UploadState_COMMITTED, resp.UploadState)
 	assert.NotNil(t, resp.CommittedAt)
 }
 
+type cleanScanner struct{}
+
+func (cleanScanner) Scan(context.Context, io.Reader) (*domain.ScanResult, error) {
+	return &domain.ScanResult{Clean: true}, nil
+}
+
+func TestServer_CompleteUpload_Scanning(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 0, "text/plain", time.Now())
+	repo.seed(blob)
+
+	client := setupServerWith(t, repo, &mockStorage{objects: map[string][]byte{validID: {}}}, app.Config{
+		PresignGetMaxTTL: time.Hour,
+		Scanner:          cleanScanner{},
+	})
+
+	resp, err := client.CompleteUpload(context.Background(), &pb.CompleteUploadRequest{BlobId: validID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.UploadState_SCANNING, resp.UploadState)
+	assert.Nil(t, resp.CommittedAt)
+
+	_, err = client.GetDownloadURL(context.Background(), &pb.GetDownloadURLRequest{BlobId: validID})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+}
+
+func TestServer_CompleteUpload_ContentMismatch(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 4, "text/plain", time.Now())
+	repo.seed(blob)
+
+	client := setupServer(t, repo, &mockStorage{objects: map[string][]byte{validID: []byte("evil")}})
+
+	_, err := client.CompleteUpload(context.Background(), &pb.CompleteUploadRequest{BlobId: validID})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+func TestServer_CompleteUpload_ObjectMissing(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 1024, "image/png", time.Now())
+	repo.seed(blob)
+
+	client := setupServer(t, repo, &mockStorage{})
+
+	_, err := client.CompleteUpload(context.Background(), &pb.CompleteUploadRequest{BlobId: validID})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+}
+
+func TestServer_PlanUpload(t *testing.T) {
+	client := setupServer(t, newMockRepo(), &mockStorage{})
+
+	resp, err := client.PlanUpload(context.Background(), &pb.PlanUploadRequest{SizeBytes: 40 * 1024 * 1024})
+	require.NoError(t, err)
+	assert.True(t, resp.Multipart)
+	assert.Equal(t, int32(len(resp.Parts)), resp.PartCount)
+	assert.Equal(t, int64(0), resp.Parts[0].Offset)
+}
+
+func TestServer_PlanUpload_NegativeSize(t *testing.T) {
+	client := setupServer(t, newMockRepo(), &mockStorage{})
+
+	_, err := client.PlanUpload(context.Background(), &pb.PlanUploadRequest{SizeBytes: -1})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+func TestServer_InitiateMultipartUpload_InvalidPartCount(t *testing.T) {
+	client := setupServer(t, newMockRepo(), &mockStorage{uploadID: "mpu-1"})
+
+	_, err := client.InitiateMultipartUpload(context.Background(), &pb.InitiateMultipartUploadRequest{
+		BlobId:      validID,
+		SizeBytes:   50 * 1024 * 1024,
+		ContentType: "video/mp4",
+		PartCount:   20,
+	})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
 func TestServer_InitiateMultipartUpload(t *testing.T) {
 	storage := &mockStorage{uploadID: "mpu-1", partURL: "https://r2.example.com/part"}
 	client := setupServer(t, newMockRepo(), storage)
@@ -207,20 +802,136 @@ func TestServer_InitiateMultipartUpload(t *testing.T) {
 }
 
 func TestServer_AbortMultipartUpload(t *testing.T) {
-	client := setupServer(t, newMockRepo(), &mockStorage{})
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 50*1024*1024, "video/mp4", time.Now())
+	repo.seed(blob)
+
+	repo.sessions[uploadKey{blob.ID, testCaller}] = &domain.MultipartSession{BlobID: blob.ID, Subject: testCaller, UploadID: "mpu-1", PartCount: 3}
+
+	client := setupServer(t, repo, &mockStorage{})
 
 	_, err := client.AbortMultipartUpload(context.Background(), &pb.AbortMultipartUploadRequest{
+		BlobId:   validID,
+		UploadId: "mpu-other",
+	})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+
+	_, err = client.AbortMultipartUpload(context.Background(), &pb.AbortMultipartUploadRequest{
 		BlobId:   validID,
 		UploadId: "mpu-1",
 	})
 	require.NoError(t, err)
 }
 
+func TestServer_ResumeMultipartUpload(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 50*1024*1024, "video/mp4", time.Now())
+	repo.seed(blob)
+	repo.sessions[uploadKey{blob.ID, testCaller}] = &domain.MultipartSession{BlobID: blob.ID, Subject: testCaller, UploadID: "mpu-1", PartCount: 3}
+
+	storage := &mockStorage{
+		partURL: "https://r2.example.com/part",
+		parts:   []domain.UploadedPart{{PartNumber: 2, ETag: `"etag-2"`, SizeBytes: 16 * 1024 * 1024}},
+	}
+	client := setupServer(t, repo, storage)
+
+	resp, err := client.ResumeMultipartUpload(context.Background(), &pb.ResumeMultipartUploadRequest{BlobId: validID})
+	require.NoError(t, err)
+	assert.Equal(t, "mpu-1", resp.UploadId)
+	require.Len(t, resp.UploadedParts, 1)
+	assert.Equal(t, `"etag-2"`, resp.UploadedParts[0].Etag)
+	require.Len(t, resp.MissingParts, 2)
+	assert.Equal(t, int32(1), resp.MissingParts[0].PartNumber)
+	assert.Equal(t, int32(3), resp.MissingParts[1].PartNumber)
+}
+
+func TestServer_ResumeMultipartUpload_NoSession(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 50*1024*1024, "video/mp4", time.Now())
+	repo.seed(blob)
+
+	client := setupServer(t, repo, &mockStorage{})
+
+	_, err := client.ResumeMultipartUpload(context.Background(), &pb.ResumeMultipartUploadRequest{BlobId: validID})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+}
+
+func TestServer_UploadStream_and_DownloadStream(t *testing.T) {
+	repo := newMockRepo()
+	storage := &mockStorage{}
+	client := setupServer(t, repo, storage)
+	ctx := context.Background()
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), 40*1024)
+	up, err := client.UploadStream(ctx)
+	require.NoError(t, err)
+	require.NoError(t, up.Send(&pb.UploadStreamRequest{Payload: &pb.UploadStreamRequest_Metadata{
+		Metadata: &pb.UploadStreamMetadata{ContentType: "application/octet-stream"},
+	}}))
+	for off := 0; off < len(content); off += 100 * 1024 {
+		end := min(off+100*1024, len(content))
+		require.NoError(t, up.Send(&pb.UploadStreamRequest{Payload: &pb.UploadStreamRequest_Chunk{Chunk: content[off:end]}}))
+	}
+	resp, err := up.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), resp.SizeBytes)
+	assert.False(t, resp.AlreadyExists)
+
+	down, err := client.DownloadStream(ctx, &pb.DownloadStreamRequest{BlobId: resp.BlobId, Offset: 16, Length: 300 * 1024})
+	require.NoError(t, err)
+	var got []byte
+	for i := 0; ; i++ {
+		msg, err := down.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if i == 0 {
+			assert.Equal(t, "application/octet-stream", msg.ContentType)
+			assert.Equal(t, int64(len(content)), msg.SizeBytes)
+		}
+		got = append(got, msg.Chunk...)
+	}
+	assert.Equal(t, content[16:16+300*1024], got)
+}
+
+func TestServer_UploadStream_MissingMetadata(t *testing.T) {
+	client := setupServer(t, newMockRepo(), &mockStorage{})
+
+	up, err := client.UploadStream(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, up.Send(&pb.UploadStreamRequest{Payload: &pb.UploadStreamRequest_Chunk{Chunk: []byte("x")}}))
+	_, err = up.CloseAndRecv()
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+func TestServer_DownloadStream_OutOfRange(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 0, "text/plain", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+
+	client := setupServer(t, repo, &mockStorage{objects: map[string][]byte{validID: {}}})
+
+	down, err := client.DownloadStream(context.Background(), &pb.DownloadStreamRequest{BlobId: validID, Offset: 1})
+	require.NoError(t, err)
+	_, err = down.Recv()
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.OutOfRange, st.Code())
+}
+
 func TestServer_GetBlobInfo_Committed(t *testing.T) {
 	repo := newMockRepo()
 	blob, _ := domain.NewBlob(domain.BlobID(validID), 2048, "application/pdf", time.Now())
 	_ = blob.Commit(time.Now())
-	repo.blobs[domain.BlobID(validID)] = blob
+	blob.DetectedContentType = "application/pdf"
+	blob.KeyID, blob.WrappedKey = "k1", []byte("wrapped")
+	until := time.Now().Add(time.Hour)
+	blob.RetainUntil, blob.LegalHold = &until, true
+	repo.seed(blob)
 
 	client := setupServer(t, repo, &mockStorage{})
 
@@ -228,6 +939,159 @@ func TestServer_GetBlobInfo_Committed(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, validID, resp.BlobId)
 	assert.Equal(t, int64(2048), resp.SizeBytes)
+	assert.Equal(t, "application/pdf", resp.DetectedContentType)
+	assert.True(t, resp.Encrypted)
 	assert.Equal(t, pb.UploadState_COMMITTED, resp.UploadState)
 	assert.NotNil(t, resp.CommittedAt)
+	assert.True(t, until.Equal(resp.RetainUntil.AsTime()))
+	assert.True(t, resp.LegalHold)
+}
+
+func TestServer_GetBlobInfo_OtherCallersBlob(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 2048, "application/pdf", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.blobs[blob.ID] = blob
+	_ = repo.AddReference(context.Background(), blob.ID, "chat-service", "", domain.QuotaPolicy{})
+
+	client := setupServer(t, repo, &mockStorage{})
+
+	_, err := client.GetBlobInfo(context.Background(), &pb.GetBlobInfoRequest{BlobId: validID})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+}
+
+func TestServer_ReleaseReference(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 2048, "application/pdf", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+
+	client := setupServer(t, repo, &mockStorage{})
+
+	_, err := client.ReleaseReference(context.Background(), &pb.ReleaseReferenceRequest{BlobId: validID})
+	require.NoError(t, err)
+
+	_, err = client.ReleaseReference(context.Background(), &pb.ReleaseReferenceRequest{BlobId: validID})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+}
+
+func TestServer_SetRetention_NotRetentionClient(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 2048, "application/pdf", time.Now())
+	repo.seed(blob)
+
+	client := setupServerWith(t, repo, &mockStorage{}, app.Config{RetentionClients: []string{"records-admin"}})
+
+	_, err := client.SetRetention(context.Background(), &pb.SetRetentionRequest{
+		BlobId:      validID,
+		RetainUntil: timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.PermissionDenied, st.Code())
+
+	_, err = client.SetLegalHold(context.Background(), &pb.SetLegalHoldRequest{BlobId: validID, LegalHold: true})
+	st, _ = status.FromError(err)
+	assert.Equal(t, codes.PermissionDenied, st.Code())
+}
+
+func TestServer_AddReference(t *testing.T) {
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(validID), 2048, "application/pdf", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+
+	client := setupServer(t, repo, &mockStorage{})
+
+	_, err := client.AddReference(context.Background(), &pb.AddReferenceRequest{BlobId: validID, Subject: "bob"})
+	require.NoError(t, err)
+	assert.True(t, repo.refs[blob.ID]["bob"])
+
+	blob.State = domain.StateScanning
+	_, err = client.AddReference(context.Background(), &pb.AddReferenceRequest{BlobId: validID, Subject: "carol"})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+
+	delete(repo.refs[blob.ID], testCaller)
+	_, err = client.AddReference(context.Background(), &pb.AddReferenceRequest{BlobId: validID, Subject: "carol"})
+	st, _ = status.FromError(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+}
+
+func TestServer_InitiateUpload_QuotaExceeded(t *testing.T) {
+	repo := newMockRepo()
+	client := setupServerWith(t, repo, &mockStorage{}, app.Config{
+		PresignPutTTL: 15 * time.Minute,
+		Quotas:        domain.QuotaPolicy{Subject: domain.Limits{MaxObjectBytes: 1024}},
+	})
+
+	_, err := client.InitiateUpload(context.Background(), &pb.InitiateUploadRequest{
+		BlobId:      validID,
+		SizeBytes:   2048,
+		ContentType: "application/pdf",
+	})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+}
+
+func TestServer_GetUsage(t *testing.T) {
+	repo := newMockRepo()
+	repo.usage[domain.Owner{Kind: domain.OwnerSubject, ID: testCaller}] = domain.Usage{Bytes: 4096, Objects: 2}
+	client := setupServerWith(t, repo, &mockStorage{}, app.Config{
+		Quotas: domain.QuotaPolicy{Subject: domain.Limits{MaxBytes: 1 << 20}},
+	})
+
+	resp, err := client.GetUsage(context.Background(), &pb.GetUsageRequest{})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Subject)
+	assert.Equal(t, int64(4096), resp.Subject.Bytes)
+	assert.Equal(t, int64(2), resp.Subject.Objects)
+	assert.Equal(t, int64(1<<20), resp.Subject.MaxBytes)
+	assert.Nil(t, resp.Client, "the test caller has no client ID")
+}
+
+func TestServer_GetRendition(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 40, 20))))
+	sum := sha256.Sum256(buf.Bytes())
+	id := hex.EncodeToString(sum[:])
+
+	repo := newMockRepo()
+	blob, _ := domain.NewBlob(domain.BlobID(id), int64(buf.Len()), "image/png", time.Now())
+	_ = blob.Commit(time.Now())
+	repo.seed(blob)
+	storage := &mockStorage{getURL: "https://r2.example.com/get", objects: map[string][]byte{id: buf.Bytes()}}
+	client := setupServerWith(t, repo, storage, app.Config{
+		PresignGetMaxTTL: time.Hour,
+		Renditions:       []domain.RenditionSpec{{Format: domain.RenditionWebP, MaxDimension: 10}},
+	})
+	req := &pb.GetRenditionRequest{
+		BlobId: id,
+		Spec:   &pb.RenditionSpec{Format: pb.RenditionFormat_WEBP, MaxDimension: 10},
+	}
+
+	_, err := client.GetRendition(context.Background(), req)
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.Unavailable, st.Code(), "the worker has not rendered it yet")
+
+	rendered := []byte("rendered")
+	sum = sha256.Sum256(rendered)
+	thumb, _ := domain.NewBlob(domain.BlobID(hex.EncodeToString(sum[:])), int64(len(rendered)), "image/webp", time.Now())
+	_ = thumb.Commit(time.Now())
+	repo.blobs[thumb.ID] = thumb
+	require.NoError(t, repo.LinkRendition(context.Background(), blob.ID, "webp-10", thumb.ID))
+
+	resp, err := client.GetRendition(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "image/webp", resp.ContentType)
+	assert.Equal(t, "https://r2.example.com/get", resp.PresignedGetUrl)
+	assert.Equal(t, string(thumb.ID), resp.RenditionBlobId)
+
+	_, err = client.GetRendition(context.Background(), &pb.GetRenditionRequest{
+		BlobId: id,
+		Spec:   &pb.RenditionSpec{Format: pb.RenditionFormat_JPEG, MaxDimension: 11},
+	})
+	st, _ = status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
 }
@@ -0,0 +1,164 @@
+package grpctransport
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/barn0w1/hss-science/server/gen/blob/v1"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+)
+
+const downloadChunkSize = 256 * 1024
+
+func (s *Server) UploadStream(stream pb.BlobService_UploadStreamServer) error {
+	ctx := stream.Context()
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return err
+	}
+
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, "upload stream is empty")
+	}
+	if err != nil {
+		return err
+	}
+	meta := first.GetMetadata()
+	if meta == nil {
+		return status.Error(codes.InvalidArgument, "first message must carry metadata")
+	}
+
+	body := &uploadStreamReader{stream: stream}
+	result, err := s.app.UploadStream(ctx, caller, body, meta.ContentType, domain.BlobID(meta.ExpectedBlobId), meta.Encrypt)
+	if body.err != nil {
+		return body.err
+	}
+	if err != nil {
+		return mapError(err)
+	}
+	return stream.SendAndClose(&pb.UploadStreamResponse{
+		BlobId:        string(result.BlobID),
+		SizeBytes:     result.SizeBytes,
+		AlreadyExists: result.AlreadyExists,
+		CommittedAt:   optionalTimestamp(result.CommittedAt),
+		UploadState:   stateToProto(result.State),
+	})
+}
+
+func (s *Server) DownloadStream(req *pb.DownloadStreamRequest, stream pb.BlobService_DownloadStreamServer) error {
+	ctx := stream.Context()
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := s.app.DownloadStream(ctx, caller, domain.BlobID(req.BlobId), req.Offset, req.Length)
+	if err != nil {
+		return mapError(err)
+	}
+	defer func() { _ = result.Body.Close() }()
+
+	msg := &pb.DownloadStreamResponse{
+		ContentType: result.Blob.ContentType,
+		SizeBytes:   result.Blob.SizeBytes,
+	}
+	buf := make([]byte, downloadChunkSize)
+	var sent int64
+	for {
+		n, err := io.ReadFull(result.Body, buf)
+		if n > 0 || sent == 0 {
+			msg.Chunk = buf[:n]
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+			msg = &pb.DownloadStreamResponse{}
+			sent += int64(n)
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			slog.Error("download stream read failed", "blob_id", req.BlobId, "error", err)
+			return status.Error(codes.Internal, "internal error")
+		}
+	}
+	if sent != result.Length {
+		slog.Error("download stream truncated", "blob_id", req.BlobId, "sent", sent, "want", result.Length)
+		return status.Error(codes.Internal, "internal error")
+	}
+	return nil
+}
+
+func (s *Server) WatchBlobEvents(req *pb.WatchBlobEventsRequest, stream pb.BlobService_WatchBlobEventsServer) error {
+	ctx := stream.Context()
+	if _, err := callerFrom(ctx); err != nil {
+		return err
+	}
+	err := s.app.WatchEvents(ctx, req.AfterSequence, func(e *domain.BlobEvent) error {
+		return stream.Send(&pb.WatchBlobEventsResponse{Event: &pb.BlobEvent{
+			Sequence:    e.Seq,
+			Kind:        eventKindToProto(e.Kind),
+			BlobId:      string(e.BlobID),
+			SizeBytes:   e.SizeBytes,
+			ContentType: e.ContentType,
+			OccurredAt:  timestamppb.New(e.OccurredAt),
+		}})
+	})
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if _, ok := status.FromError(err); ok {
+		// Send failures are already gRPC statuses.
+		return err
+	}
+	return mapError(err)
+}
+
+func eventKindToProto(k domain.EventKind) pb.BlobEventKind {
+	switch k {
+	case domain.EventCommitted:
+		return pb.BlobEventKind_BLOB_COMMITTED
+	case domain.EventDeleted:
+		return pb.BlobEventKind_BLOB_DELETED
+	default:
+		return pb.BlobEventKind_BLOB_EVENT_KIND_UNSPECIFIED
+	}
+}
+
+// uploadStreamReader exposes the chunks of an UploadStream as an io.Reader.
+// Stream and protocol errors are kept in err so the handler can return them
+// unchanged instead of mapping them as internal errors.
+type uploadStreamReader struct {
+	stream pb.BlobService_UploadStreamServer
+	buf    []byte
+	err    error
+}
+
+func (r *uploadStreamReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		msg, err := r.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return 0, io.EOF
+		}
+		if err != nil {
+			r.err = err
+			return 0, err
+		}
+		if msg.GetMetadata() != nil {
+			r.err = status.Error(codes.InvalidArgument, "metadata may only be sent in the first message")
+			return 0, r.err
+		}
+		r.buf = msg.GetChunk()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,171 @@
+// Package httptransport serves BlobService as JSON over HTTP for callers that
+// cannot speak gRPC, such as browser apps. Requests and responses are the
+// proto messages in their protojson form, and every route calls straight into
+// the gRPC server, so validation, authorization and error mapping are shared.
+//
+// The streaming RPCs are not exposed; browsers move object bytes through the
+// presigned URLs instead.
+package httptransport
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/cors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/barn0w1/hss-science/server/gen/blob/v1"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/transport/grpc/interceptor"
+)
+
+// maxBodyBytes bounds request bodies. The largest is a CompleteMultipartUpload
+// listing 10,000 part ETags.
+const maxBodyBytes = 2 << 20
+
+// Authenticator verifies a raw bearer token and returns a context carrying
+// the caller, as interceptor.AuthInterceptor does.
+type Authenticator interface {
+	Authenticate(ctx context.Context, rawToken string) (context.Context, error)
+}
+
+// NewGateway returns a handler for the routes below, all under /v1. Requests
+// must carry an "Authorization: Bearer" header whose token holds the scope
+// the RPC requires over gRPC. Cross-origin requests are
+// allowed from allowedOrigins only.
+//
+//	GET    /v1/usage                                      GetUsage
+//	POST   /v1/upload-plans                               PlanUpload
+//	POST   /v1/blobs/batch-get                            BatchGetBlobInfo
+//	POST   /v1/blobs/list                                 ListBlobs
+//	GET    /v1/blobs/{blob_id}                            GetBlobInfo
+//	POST   /v1/blobs/{blob_id}/upload                     InitiateUpload
+//	POST   /v1/blobs/{blob_id}/upload/complete            CompleteUpload
+//	POST   /v1/blobs/{blob_id}/multipart-upload           InitiateMultipartUpload
+//	POST   /v1/blobs/{blob_id}/multipart-upload/resume    ResumeMultipartUpload
+//	POST   /v1/blobs/{blob_id}/multipart-upload/complete  CompleteMultipartUpload
+//	POST   /v1/blobs/{blob_id}/multipart-upload/abort     AbortMultipartUpload
+//	POST   /v1/blobs/{blob_id}/download-url               GetDownloadURL
+//	POST   /v1/blobs/{blob_id}/rendition                  GetRendition
+//	PUT    /v1/blobs/{blob_id}/reference                  AddReference
+//	DELETE /v1/blobs/{blob_id}/reference                  ReleaseReference
+//	PUT    /v1/blobs/{blob_id}/retention                  SetRetention
+//	PUT    /v1/blobs/{blob_id}/legal-hold                 SetLegalHold
+//
+// The {blob_id} path segment overrides any blob_id in the body.
+func NewGateway(srv pb.BlobServiceServer, auth Authenticator, allowedOrigins []string) http.Handler {
+	r := chi.NewRouter()
+	r.Use(authenticate(auth))
+
+	r.Get("/v1/usage", unary(pb.BlobService_GetUsage_FullMethodName, srv.GetUsage))
+	r.Post("/v1/upload-plans", unary(pb.BlobService_PlanUpload_FullMethodName, srv.PlanUpload))
+	r.Post("/v1/blobs/batch-get", unary(pb.BlobService_BatchGetBlobInfo_FullMethodName, srv.BatchGetBlobInfo))
+	r.Post("/v1/blobs/list", unary(pb.BlobService_ListBlobs_FullMethodName, srv.ListBlobs))
+	r.Route("/v1/blobs/{blob_id}", func(r chi.Router) {
+		r.Get("/", unary(pb.BlobService_GetBlobInfo_FullMethodName, srv.GetBlobInfo))
+		r.Post("/upload", unary(pb.BlobService_InitiateUpload_FullMethodName, srv.InitiateUpload))
+		r.Post("/upload/complete", unary(pb.BlobService_CompleteUpload_FullMethodName, srv.CompleteUpload))
+		r.Post("/multipart-upload", unary(pb.BlobService_InitiateMultipartUpload_FullMethodName, srv.InitiateMultipartUpload))
+		r.Post("/multipart-upload/resume", unary(pb.BlobService_ResumeMultipartUpload_FullMethodName, srv.ResumeMultipartUpload))
+		r.Post("/multipart-upload/complete", unary(pb.BlobService_CompleteMultipartUpload_FullMethodName, srv.CompleteMultipartUpload))
+		r.Post("/multipart-upload/abort", unary(pb.BlobService_AbortMultipartUpload_FullMethodName, srv.AbortMultipartUpload))
+		r.Post("/download-url", unary(pb.BlobService_GetDownloadURL_FullMethodName, srv.GetDownloadURL))
+		r.Post("/rendition", unary(pb.BlobService_GetRendition_FullMethodName, srv.GetRendition))
+		r.Put("/reference", unary(pb.BlobService_AddReference_FullMethodName, srv.AddReference))
+		r.Delete("/reference", unary(pb.BlobService_ReleaseReference_FullMethodName, srv.ReleaseReference))
+		r.Put("/retention", unary(pb.BlobService_SetRetention_FullMethodName, srv.SetRetention))
+		r.Put("/legal-hold", unary(pb.BlobService_SetLegalHold_FullMethodName, srv.SetLegalHold))
+	})
+
+	// Bearer tokens are sent explicitly, so no cookies are shared and
+	// credentials are not allowed.
+	return cors.New(cors.Options{
+		AllowedOrigins: allowedOrigins,
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+	}).Handler(r)
+}
+
+func authenticate(auth Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const prefix = "Bearer "
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				writeError(w, status.Error(codes.Unauthenticated, "missing authorization header"))
+				return
+			}
+			if !strings.HasPrefix(header, prefix) {
+				writeError(w, status.Error(codes.Unauthenticated, "authorization must use Bearer scheme"))
+				return
+			}
+			ctx, err := auth.Authenticate(r.Context(), strings.TrimPrefix(header, prefix))
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// unary adapts a unary RPC to an HTTP handler. The caller must hold the
+// scope fullMethod requires. The request message is read from the JSON body,
+// if any, and then from the route's path parameters.
+func unary[Req any, PReq interface {
+	*Req
+	proto.Message
+}, Resp proto.Message](fullMethod string, call func(context.Context, PReq) (Resp, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := interceptor.Authorize(r.Context(), fullMethod); err != nil {
+			writeError(w, err)
+			return
+		}
+		req := PReq(new(Req))
+		if err := decodeRequest(r, req); err != nil {
+			writeError(w, err)
+			return
+		}
+		resp, err := call(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		body, err := protojson.Marshal(resp)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}
+}
+
+func decodeRequest(r *http.Request, req proto.Message) error {
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxBodyBytes))
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "read request body: %v", err)
+	}
+	if len(body) > 0 {
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, req); err != nil {
+			return status.Errorf(codes.InvalidArgument, "malformed request body: %v", err)
+		}
+	}
+
+	msg := req.ProtoReflect()
+	params := chi.RouteContext(r.Context()).URLParams
+	for i, key := range params.Keys {
+		field := msg.Descriptor().Fields().ByName(protoreflect.Name(key))
+		if field == nil || field.Kind() != protoreflect.StringKind || field.IsList() {
+			continue
+		}
+		msg.Set(field, protoreflect.ValueOfString(params.Values[i]))
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package httptransport_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/barn0w1/hss-science/server/gen/blob/v1"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/transport/grpc/interceptor"
+	httptransport "github.com/barn0w1/hss-science/server/services/blob-service/internal/transport/http"
+)
+
+const validID = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+type fakeAuth struct{}
+
+func (fakeAuth) Authenticate(ctx context.Context, rawToken string) (context.Context, error) {
+	switch rawToken {
+	case "good-token":
+		ctx = interceptor.WithCallerScopes(ctx, interceptor.ScopeRead, interceptor.ScopeWrite)
+	case "read-token":
+		ctx = interceptor.WithCallerScopes(ctx, interceptor.ScopeRead)
+	default:
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return interceptor.WithCallerSub(ctx, "user-1"), nil
+}
+
+type fakeBlobServer struct {
+	pb.UnimplementedBlobServiceServer
+	initiated *pb.InitiateUploadRequest
+	caller    string
+}
+
+func (s *fakeBlobServer) InitiateUpload(ctx context.Context, req *pb.InitiateUploadRequest) (*pb.InitiateUploadResponse, error) {
+	s.initiated, s.caller = req, interceptor.CallerSub(ctx)
+	return &pb.InitiateUploadResponse{PresignedPutUrl: "https://r2.example.com/put"}, nil
+}
+
+func (s *fakeBlobServer) GetBlobInfo(_ context.Context, req *pb.GetBlobInfoRequest) (*pb.GetBlobInfoResponse, error) {
+	if req.BlobId != validID {
+		return nil, status.Error(codes.NotFound, "blob not found")
+	}
+	return &pb.GetBlobInfoResponse{BlobId: req.BlobId, SizeBytes: 1024, UploadState: pb.UploadState_COMMITTED}, nil
+}
+
+func serve(t *testing.T, srv pb.BlobServiceServer, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer good-token")
+	rec := httptest.NewRecorder()
+	httptransport.NewGateway(srv, fakeAuth{}, []string{"https://drive.example.com"}).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGateway_InitiateUpload(t *testing.T) {
+	srv := &fakeBlobServer{}
+	rec := serve(t, srv, http.MethodPost, "/v1/blobs/"+validID+"/upload",
+		`{"blobId": "ignored", "sizeBytes": "1024", "contentType": "image/png", "unknownField": 1}`)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"presignedPutUrl": "https://r2.example.com/put"}`, rec.Body.String())
+	assert.Equal(t, validID, srv.initiated.BlobId, "the path overrides the body")
+	assert.Equal(t, int64(1024), srv.initiated.SizeBytes)
+	assert.Equal(t, "image/png", srv.initiated.ContentType)
+	assert.Equal(t, "user-1", srv.caller)
+}
+
+func TestGateway_GetBlobInfo(t *testing.T) {
+	rec := serve(t, &fakeBlobServer{}, http.MethodGet, "/v1/blobs/"+validID, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"blobId": "`+validID+`", "sizeBytes": "1024", "uploadState": "COMMITTED"}`, rec.Body.String())
+
+	rec = serve(t, &fakeBlobServer{}, http.MethodGet, "/v1/blobs/"+strings.Repeat("a", 64), "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"code": "NOT_FOUND", "message": "blob not found"}`, rec.Body.String())
+}
+
+func TestGateway_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+		code   string
+	}{
+		{"malformed body", http.MethodPost, "/v1/blobs/" + validID + "/upload", `{"sizeBytes":`, http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{"unimplemented rpc", http.MethodPost, "/v1/upload-plans", `{}`, http.StatusNotImplemented, "UNIMPLEMENTED"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(t, &fakeBlobServer{}, tc.method, tc.path, tc.body)
+			assert.Equal(t, tc.want, rec.Code)
+			var body map[string]string
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tc.code, body["code"])
+		})
+	}
+}
+
+func TestGateway_RequiresBearerToken(t *testing.T) {
+	gw := httptransport.NewGateway(&fakeBlobServer{}, fakeAuth{}, nil)
+	for _, header := range []string{"", "Basic dXNlcjpwYXNz", "Bearer bad-token"} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/blobs/"+validID, nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		gw.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, header)
+	}
+}
+
+func TestGateway_RequiresScope(t *testing.T) {
+	srv := &fakeBlobServer{}
+	gw := httptransport.NewGateway(srv, fakeAuth{}, nil)
+	request := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(`{"sizeBytes": "1024"}`))
+		req.Header.Set("Authorization", "Bearer read-token")
+		rec := httptest.NewRecorder()
+		gw.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/v1/blobs/"+validID).Code)
+	rec := request(http.MethodPost, "/v1/blobs/"+validID+"/upload")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "PERMISSION_DENIED")
+	assert.Nil(t, srv.initiated)
+}
+
+func TestGateway_CORSPreflight(t *testing.T) {
+	gw := httptransport.NewGateway(&fakeBlobServer{}, fakeAuth{}, []string{"https://drive.example.com"})
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/v1/blobs/"+validID+"/upload", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "authorization,content-type")
+		rec := httptest.NewRecorder()
+		gw.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := preflight("https://drive.example.com")
+	assert.Equal(t, "https://drive.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+
+	rec = preflight("https://evil.example.com")
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,343 @@
+package httptransport
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/cors"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/transport/grpc/interceptor"
+)
+
+const tusVersion = "1.0.0"
+
+// statusChecksumMismatch is the status tus defines for a chunk that does not
+// match its Upload-Checksum.
+const statusChecksumMismatch = 460
+
+// ResumableUploads is the part of the app that the tus handler drives.
+type ResumableUploads interface {
+	CreateResumableUpload(ctx context.Context, caller domain.Caller, id domain.BlobID, sizeBytes int64, contentType string, encrypt bool) (*app.ResumableUpload, error)
+	GetResumableUpload(ctx context.Context, caller domain.Caller, id domain.BlobID) (*app.ResumableUpload, error)
+	AppendResumableUpload(ctx context.Context, caller domain.Caller, id domain.BlobID, offset int64, chunk io.ReaderAt, size int64) (*app.ResumableUpload, error)
+}
+
+// NewTusHandler returns a handler for tus 1.0 resumable uploads under /files,
+// with the creation and checksum extensions:
+//
+//	OPTIONS /files            protocol discovery
+//	POST    /files            create an upload
+//	HEAD    /files/{blob_id}  report the offset
+//	PATCH   /files/{blob_id}  append a chunk
+//
+// The upload URL is named after the blob, so a client creates the upload
+// with Upload-Metadata carrying blob_id, and optionally content_type (or
+// filetype) and encrypt ("true"). Creating an upload that exists returns it
+// again. Requests other than OPTIONS must carry an "Authorization: Bearer"
+// header whose token holds the blob.write scope. The upload is committed
+// when its last byte arrives.
+func NewTusHandler(uploads ResumableUploads, auth Authenticator, allowedOrigins []string) http.Handler {
+	h := &tusHandler{uploads: uploads}
+
+	r := chi.NewRouter()
+	r.Use(tusResumable)
+	r.Options("/files", h.options)
+	r.Options("/files/{blob_id}", h.options)
+	r.Group(func(r chi.Router) {
+		r.Use(authenticate(auth), requireScope(interceptor.ScopeWrite))
+		r.Post("/files", h.create)
+		r.Head("/files/{blob_id}", h.head)
+		r.Patch("/files/{blob_id}", h.patch)
+	})
+
+	return cors.New(cors.Options{
+		AllowedOrigins: allowedOrigins,
+		AllowedMethods: []string{http.MethodPost, http.MethodHead, http.MethodPatch},
+		AllowedHeaders: []string{
+			"Authorization", "Content-Type", "Tus-Resumable",
+			"Upload-Length", "Upload-Metadata", "Upload-Offset", "Upload-Checksum",
+		},
+		ExposedHeaders: []string{
+			"Location", "Tus-Resumable", "Tus-Version", "Tus-Extension",
+			"Tus-Max-Size", "Tus-Checksum-Algorithm", "Upload-Offset", "Upload-Length",
+		},
+	}).Handler(r)
+}
+
+func requireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := interceptor.RequireScope(r.Context(), scope); err != nil {
+				writeError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// tusHandler keeps no state of its own: concurrent PATCHes to one upload,
+// which tus leaves undefined, may reach different replicas, and the app
+// accepts only the first chunk for an offset.
+type tusHandler struct {
+	uploads ResumableUploads
+}
+
+// tusResumable sets Tus-Resumable on every response and refuses requests
+// for other protocol versions. OPTIONS is exempt so clients can discover
+// the supported versions.
+func tusResumable(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Tus-Resumable", tusVersion)
+		if r.Method != http.MethodOptions && r.Header.Get("Tus-Resumable") != tusVersion {
+			w.Header().Set("Tus-Version", tusVersion)
+			http.Error(w, "unsupported tus version", http.StatusPreconditionFailed)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *tusHandler) options(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Tus-Version", tusVersion)
+	w.Header().Set("Tus-Extension", "creation,checksum")
+	w.Header().Set("Tus-Max-Size", strconv.FormatInt(domain.MaxObjectSize, 10))
+	w.Header().Set("Tus-Checksum-Algorithm", "sha1,sha256")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *tusHandler) create(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Upload-Defer-Length") != "" {
+		http.Error(w, "Upload-Defer-Length is not supported", http.StatusBadRequest)
+		return
+	}
+	size, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || size < 0 {
+		http.Error(w, "missing or invalid Upload-Length", http.StatusBadRequest)
+		return
+	}
+	if size > domain.MaxObjectSize {
+		http.Error(w, "upload exceeds Tus-Max-Size", http.StatusRequestEntityTooLarge)
+		return
+	}
+	meta, err := parseMetadata(r.Header.Get("Upload-Metadata"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	contentType := meta["content_type"]
+	if contentType == "" {
+		contentType = meta["filetype"]
+	}
+
+	id := domain.BlobID(meta["blob_id"])
+	upload, err := h.uploads.CreateResumableUpload(r.Context(), tusCaller(r.Context()), id, size, contentType, meta["encrypt"] == "true")
+	if err != nil {
+		writeTusError(w, err)
+		return
+	}
+	w.Header().Set("Location", path.Join(r.URL.Path, string(id)))
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *tusHandler) head(w http.ResponseWriter, r *http.Request) {
+	upload, err := h.uploads.GetResumableUpload(r.Context(), tusCaller(r.Context()), domain.BlobID(chi.URLParam(r, "blob_id")))
+	if err != nil {
+		writeTusError(w, err)
+		return
+	}
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.SizeBytes, 10))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *tusHandler) patch(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		http.Error(w, "Content-Type must be application/offset+octet-stream", http.StatusUnsupportedMediaType)
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		http.Error(w, "missing or invalid Upload-Offset", http.StatusBadRequest)
+		return
+	}
+	var checksum *uploadChecksum
+	if v := r.Header.Get("Upload-Checksum"); v != "" {
+		if checksum, err = parseChecksum(v); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	id := domain.BlobID(chi.URLParam(r, "blob_id"))
+	ctx, caller := r.Context(), tusCaller(r.Context())
+	upload, err := h.uploads.GetResumableUpload(ctx, caller, id)
+	if err != nil {
+		writeTusError(w, err)
+		return
+	}
+	if offset != upload.Offset {
+		http.Error(w, "Upload-Offset does not match the bytes received", http.StatusConflict)
+		return
+	}
+	remaining := upload.SizeBytes - upload.Offset
+	if r.ContentLength > remaining {
+		http.Error(w, "chunk exceeds Upload-Length", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	chunk, n, readErr := bufferChunk(r.Body, remaining, checksum)
+	if chunk != nil {
+		defer func() {
+			_ = chunk.Close()
+			_ = os.Remove(chunk.Name())
+		}()
+	}
+	switch {
+	case n > remaining:
+		http.Error(w, "chunk exceeds Upload-Length", http.StatusRequestEntityTooLarge)
+		return
+	case readErr != nil && (checksum != nil || n == 0):
+		// A chunk cut short can only be checked against its checksum
+		// whole. Without one, whatever arrived is kept below.
+		writeTusError(w, readErr)
+		return
+	case checksum != nil && !checksum.matches():
+		http.Error(w, "chunk does not match Upload-Checksum", statusChecksumMismatch)
+		return
+	}
+
+	if n > 0 {
+		if upload, err = h.uploads.AppendResumableUpload(ctx, caller, id, offset, chunk, n); err != nil {
+			writeTusError(w, err)
+			return
+		}
+	}
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bufferChunk copies up to limit+1 bytes of body to a temporary file, so the
+// app can read the chunk as many times as it needs to, and so a chunk longer
+// than limit is detected. The file is returned with the number of bytes
+// copied even if reading the body failed part way.
+func bufferChunk(body io.Reader, limit int64, checksum *uploadChecksum) (*os.File, int64, error) {
+	f, err := os.CreateTemp("", "tus-chunk-*")
+	if err != nil {
+		return nil, 0, fmt.Errorf("create chunk buffer: %w", err)
+	}
+	var dst io.Writer = f
+	if checksum != nil {
+		dst = io.MultiWriter(f, checksum.hash)
+	}
+	n, err := io.Copy(dst, io.LimitReader(body, limit+1))
+	if err != nil {
+		return f, n, fmt.Errorf("read chunk: %w", err)
+	}
+	return f, n, nil
+}
+
+type uploadChecksum struct {
+	hash hash.Hash
+	want []byte
+}
+
+// parseChecksum parses an Upload-Checksum header, "<algorithm> <base64>".
+func parseChecksum(v string) (*uploadChecksum, error) {
+	algo, encoded, ok := strings.Cut(v, " ")
+	if !ok {
+		return nil, errors.New("malformed Upload-Checksum")
+	}
+	want, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("malformed Upload-Checksum")
+	}
+	switch algo {
+	case "sha1":
+		return &uploadChecksum{hash: sha1.New(), want: want}, nil
+	case "sha256":
+		return &uploadChecksum{hash: sha256.New(), want: want}, nil
+	default:
+		return nil, fmt.Errorf("unsupported checksum algorithm %q", algo)
+	}
+}
+
+func (c *uploadChecksum) matches() bool {
+	return subtle.ConstantTimeCompare(c.hash.Sum(nil), c.want) == 1
+}
+
+// parseMetadata parses an Upload-Metadata header: comma-separated pairs of
+// a key and a base64 value, where the value may be left out.
+func parseMetadata(v string) (map[string]string, error) {
+	meta := make(map[string]string)
+	for pair := range strings.SplitSeq(v, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, encoded, _ := strings.Cut(pair, " ")
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("malformed Upload-Metadata value for %q", key)
+		}
+		meta[key] = string(value)
+	}
+	return meta, nil
+}
+
+func tusCaller(ctx context.Context) domain.Caller {
+	return domain.Caller{Subject: interceptor.CallerSub(ctx), ClientID: interceptor.CallerClientID(ctx)}
+}
+
+// writeTusError reports an app error as a plain-text tus response. A blob
+// that does not hash to its ID fails as a checksum mismatch would.
+func writeTusError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrInvalidBlobID),
+		errors.Is(err, domain.ErrInvalidSize),
+		errors.Is(err, domain.ErrInvalidPartCount),
+		errors.Is(err, domain.ErrEncryptionUnavailable):
+		code = http.StatusBadRequest
+	case errors.Is(err, domain.ErrBlobNotFound), errors.Is(err, domain.ErrSessionNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, domain.ErrOffsetMismatch),
+		errors.Is(err, domain.ErrAlreadyCommitted),
+		errors.Is(err, domain.ErrBlobScanning),
+		errors.Is(err, domain.ErrBlobQuarantined),
+		errors.Is(err, domain.ErrBlobMissing),
+		errors.Is(err, domain.ErrObjectNotFound),
+		errors.Is(err, domain.ErrUnknownMasterKey):
+		code = http.StatusConflict
+	case errors.Is(err, domain.ErrContentMismatch):
+		code = statusChecksumMismatch
+	case errors.Is(err, domain.ErrContentTypeNotAllowed),
+		errors.Is(err, domain.ErrPermissionDenied),
+		errors.Is(err, domain.ErrQuotaExceeded):
+		code = http.StatusForbidden
+	}
+	if code == http.StatusInternalServerError {
+		slog.Error("internal error", "error", err)
+		http.Error(w, "internal error", code)
+		return
+	}
+	http.Error(w, err.Error(), code)
+}
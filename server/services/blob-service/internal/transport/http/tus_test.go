@@ -0,0 +1,187 @@
+package httptransport_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/app"
+	"github.com/barn0w1/hss-science/server/services/blob-service/internal/domain"
+	httptransport "github.com/barn0w1/hss-science/server/services/blob-service/internal/transport/http"
+)
+
+// fakeUploads keeps one upload's bytes in memory.
+type fakeUploads struct {
+	id          domain.BlobID
+	size        int64
+	data        []byte
+	contentType string
+	caller      domain.Caller
+}
+
+func (f *fakeUploads) CreateResumableUpload(_ context.Context, caller domain.Caller, id domain.BlobID, sizeBytes int64, contentType string, _ bool) (*app.ResumableUpload, error) {
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+	f.id, f.size, f.contentType, f.caller = id, sizeBytes, contentType, caller
+	return f.progress(), nil
+}
+
+func (f *fakeUploads) GetResumableUpload(_ context.Context, _ domain.Caller, id domain.BlobID) (*app.ResumableUpload, error) {
+	if id != f.id {
+		return nil, domain.ErrBlobNotFound
+	}
+	return f.progress(), nil
+}
+
+func (f *fakeUploads) AppendResumableUpload(_ context.Context, _ domain.Caller, id domain.BlobID, offset int64, chunk io.ReaderAt, size int64) (*app.ResumableUpload, error) {
+	if offset != int64(len(f.data)) {
+		return nil, domain.ErrOffsetMismatch
+	}
+	buf := make([]byte, size)
+	if _, err := chunk.ReadAt(buf, 0); err != nil {
+		return nil, err
+	}
+	f.data = append(f.data, buf...)
+	return f.progress(), nil
+}
+
+func (f *fakeUploads) progress() *app.ResumableUpload {
+	state := domain.StatePending
+	if int64(len(f.data)) == f.size {
+		state = domain.StateCommitted
+	}
+	return &app.ResumableUpload{BlobID: f.id, SizeBytes: f.size, Offset: int64(len(f.data)), State: state}
+}
+
+func tusRequest(method, path string, body string, headers ...string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer good-token")
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	for i := 0; i < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	return req
+}
+
+func serveTus(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func metadata(pairs ...string) string {
+	var out []string
+	for i := 0; i < len(pairs); i += 2 {
+		out = append(out, pairs[i]+" "+base64.StdEncoding.EncodeToString([]byte(pairs[i+1])))
+	}
+	return strings.Join(out, ",")
+}
+
+func TestTus_Upload(t *testing.T) {
+	uploads := &fakeUploads{}
+	h := httptransport.NewTusHandler(uploads, fakeAuth{}, nil)
+
+	rec := serveTus(h, tusRequest(http.MethodPost, "/files", "",
+		"Upload-Length", "11",
+		"Upload-Metadata", metadata("blob_id", validID, "filetype", "text/plain")))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "/files/"+validID, rec.Header().Get("Location"))
+	assert.Equal(t, "1.0.0", rec.Header().Get("Tus-Resumable"))
+	assert.Equal(t, "text/plain", uploads.contentType)
+	assert.Equal(t, "user-1", uploads.caller.Subject)
+
+	sum := sha256.Sum256([]byte("hello "))
+	rec = serveTus(h, tusRequest(http.MethodPatch, "/files/"+validID, "hello ",
+		"Content-Type", "application/offset+octet-stream",
+		"Upload-Offset", "0",
+		"Upload-Checksum", "sha256 "+base64.StdEncoding.EncodeToString(sum[:])))
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	assert.Equal(t, "6", rec.Header().Get("Upload-Offset"))
+
+	rec = serveTus(h, tusRequest(http.MethodHead, "/files/"+validID, ""))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "6", rec.Header().Get("Upload-Offset"))
+	assert.Equal(t, "11", rec.Header().Get("Upload-Length"))
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+	rec = serveTus(h, tusRequest(http.MethodPatch, "/files/"+validID, "world",
+		"Content-Type", "application/offset+octet-stream",
+		"Upload-Offset", "6"))
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	assert.Equal(t, "11", rec.Header().Get("Upload-Offset"))
+	assert.Equal(t, "hello world", string(uploads.data))
+}
+
+func TestTus_PatchRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		headers []string
+		want    int
+	}{
+		{"wrong content type", "abc", []string{"Content-Type", "application/octet-stream", "Upload-Offset", "0"}, http.StatusUnsupportedMediaType},
+		{"offset mismatch", "abc", []string{"Content-Type", "application/offset+octet-stream", "Upload-Offset", "3"}, http.StatusConflict},
+		{"past upload length", "abcdef", []string{"Content-Type", "application/offset+octet-stream", "Upload-Offset", "0"}, http.StatusRequestEntityTooLarge},
+		{"checksum mismatch", "abc", []string{"Content-Type", "application/offset+octet-stream", "Upload-Offset", "0", "Upload-Checksum", "sha1 " + base64.StdEncoding.EncodeToString(make([]byte, 20))}, 460},
+		{"unsupported checksum", "abc", []string{"Content-Type", "application/offset+octet-stream", "Upload-Offset", "0", "Upload-Checksum", "md5 AAAA"}, http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			uploads := &fakeUploads{id: validID, size: 5}
+			h := httptransport.NewTusHandler(uploads, fakeAuth{}, nil)
+			rec := serveTus(h, tusRequest(http.MethodPatch, "/files/"+validID, tc.body, tc.headers...))
+			assert.Equal(t, tc.want, rec.Code, rec.Body.String())
+			assert.Empty(t, uploads.data)
+		})
+	}
+}
+
+func TestTus_Create(t *testing.T) {
+	h := httptransport.NewTusHandler(&fakeUploads{}, fakeAuth{}, nil)
+
+	rec := serveTus(h, tusRequest(http.MethodPost, "/files", "", "Upload-Defer-Length", "1"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serveTus(h, tusRequest(http.MethodPost, "/files", "", "Upload-Length", "6000000000000"))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = serveTus(h, tusRequest(http.MethodPost, "/files", "",
+		"Upload-Length", "5", "Upload-Metadata", metadata("blob_id", "not-a-hash")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serveTus(h, tusRequest(http.MethodHead, "/files/"+validID, ""))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestTus_ProtocolHeaders(t *testing.T) {
+	h := httptransport.NewTusHandler(&fakeUploads{}, fakeAuth{}, nil)
+
+	req := httptest.NewRequest(http.MethodOptions, "/files", nil)
+	rec := serveTus(h, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code, "discovery needs no token")
+	assert.Equal(t, "1.0.0", rec.Header().Get("Tus-Version"))
+	assert.Equal(t, "creation,checksum", rec.Header().Get("Tus-Extension"))
+	assert.Equal(t, "sha1,sha256", rec.Header().Get("Tus-Checksum-Algorithm"))
+
+	req = tusRequest(http.MethodHead, "/files/"+validID, "", "Tus-Resumable", "0.2.2")
+	rec = serveTus(h, req)
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	assert.Equal(t, "1.0.0", rec.Header().Get("Tus-Version"))
+
+	req = tusRequest(http.MethodHead, "/files/"+validID, "", "Authorization", "Bearer bad-token")
+	rec = serveTus(h, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = tusRequest(http.MethodHead, "/files/"+validID, "", "Authorization", "Bearer read-token")
+	rec = serveTus(h, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code, "uploads need blob.write")
+}
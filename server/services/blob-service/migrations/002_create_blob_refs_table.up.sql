@@ -0,0 +1,8 @@
+CREATE TABLE blob_refs (
+    blob_id    CHAR(64)    NOT NULL REFERENCES blobs (id) ON DELETE CASCADE,
+    subject    TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (blob_id, subject)
+);
+
+CREATE INDEX blob_refs_subject_idx ON blob_refs (subject);
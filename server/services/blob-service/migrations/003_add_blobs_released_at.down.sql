@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS blobs_released_at_idx;
+
+ALTER TABLE blobs DROP COLUMN IF EXISTS released_at;
@@ -0,0 +1,3 @@
+ALTER TABLE blobs ADD COLUMN released_at TIMESTAMPTZ;
+
+CREATE INDEX blobs_released_at_idx ON blobs (released_at) WHERE released_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS blobs_pending_created_at_idx;
+
+DROP TABLE IF EXISTS multipart_uploads;
@@ -0,0 +1,9 @@
+CREATE TABLE multipart_uploads (
+    upload_id  TEXT        PRIMARY KEY,
+    blob_id    CHAR(64)    NOT NULL REFERENCES blobs (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX multipart_uploads_blob_id_idx ON multipart_uploads (blob_id);
+
+CREATE INDEX blobs_pending_created_at_idx ON blobs (created_at) WHERE state = 'PENDING';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS multipart_uploads_blob_id_key;
+
+CREATE INDEX multipart_uploads_blob_id_idx ON multipart_uploads (blob_id);
+
+ALTER TABLE multipart_uploads DROP COLUMN IF EXISTS part_count;
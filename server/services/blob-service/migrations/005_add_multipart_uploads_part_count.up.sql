@@ -0,0 +1,12 @@
+ALTER TABLE multipart_uploads ADD COLUMN part_count INTEGER NOT NULL DEFAULT 1 CHECK (part_count > 0);
+
+-- Keep only the newest session per blob; the older uploads are left for the
+-- reaper to abort as untracked bucket uploads.
+DELETE FROM multipart_uploads m
+USING multipart_uploads n
+WHERE m.blob_id = n.blob_id
+  AND (m.created_at, m.upload_id) < (n.created_at, n.upload_id);
+
+DROP INDEX IF EXISTS multipart_uploads_blob_id_idx;
+
+CREATE UNIQUE INDEX multipart_uploads_blob_id_key ON multipart_uploads (blob_id);
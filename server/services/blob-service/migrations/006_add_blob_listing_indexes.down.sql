@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS blobs_committed_at_idx;
+
+DROP INDEX IF EXISTS blobs_created_at_idx;
+
+DROP INDEX IF EXISTS blobs_content_type_idx;
+
+DROP INDEX IF EXISTS blob_refs_subject_blob_id_idx;
+
+CREATE INDEX blob_refs_subject_idx ON blob_refs (subject);
@@ -0,0 +1,10 @@
+-- ListBlobs walks a subject's references in blob ID order.
+DROP INDEX IF EXISTS blob_refs_subject_idx;
+
+CREATE INDEX blob_refs_subject_blob_id_idx ON blob_refs (subject, blob_id);
+
+CREATE INDEX blobs_content_type_idx ON blobs (content_type text_pattern_ops);
+
+CREATE INDEX blobs_created_at_idx ON blobs (created_at);
+
+CREATE INDEX blobs_committed_at_idx ON blobs (committed_at) WHERE committed_at IS NOT NULL;
@@ -0,0 +1,10 @@
+CREATE TABLE blob_events (
+    seq          BIGSERIAL   PRIMARY KEY,
+    kind         TEXT        NOT NULL CHECK (kind IN ('COMMITTED', 'DELETED')),
+    blob_id      CHAR(64)    NOT NULL,
+    size_bytes   BIGINT      NOT NULL,
+    content_type TEXT        NOT NULL DEFAULT '',
+    occurred_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX blob_events_occurred_at_idx ON blob_events (occurred_at);
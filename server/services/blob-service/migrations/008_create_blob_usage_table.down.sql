@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS blob_usage;
+
+ALTER TABLE blob_refs DROP COLUMN IF EXISTS client_id;
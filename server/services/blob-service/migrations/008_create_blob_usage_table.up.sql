@@ -0,0 +1,17 @@
+ALTER TABLE blob_refs ADD COLUMN client_id TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE blob_usage (
+    owner_kind TEXT   NOT NULL CHECK (owner_kind IN ('subject', 'client')),
+    owner_id   TEXT   NOT NULL,
+    bytes      BIGINT NOT NULL DEFAULT 0,
+    objects    BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (owner_kind, owner_id)
+);
+
+-- Existing references predate client tracking, so only subjects are charged.
+INSERT INTO blob_usage (owner_kind, owner_id, bytes, objects)
+SELECT 'subject', r.subject, SUM(b.size_bytes), COUNT(*)
+FROM blob_refs r
+JOIN blobs b ON b.id = r.blob_id
+WHERE b.state = 'COMMITTED'
+GROUP BY r.subject;
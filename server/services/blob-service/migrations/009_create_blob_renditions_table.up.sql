@@ -0,0 +1,11 @@
+CREATE TABLE blob_renditions (
+    source_id    CHAR(64)    NOT NULL REFERENCES blobs (id) ON DELETE CASCADE,
+    spec         TEXT        NOT NULL,
+    -- A rendition left PENDING by a failed store keeps its link, and the next
+    -- generation for the source stores it again.
+    rendition_id CHAR(64)    NOT NULL REFERENCES blobs (id) ON DELETE CASCADE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (source_id, spec)
+);
+
+CREATE INDEX blob_renditions_rendition_id_idx ON blob_renditions (rendition_id);
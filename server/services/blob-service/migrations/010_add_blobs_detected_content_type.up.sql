@@ -0,0 +1,2 @@
+-- Empty for blobs committed before content sniffing was introduced.
+ALTER TABLE blobs ADD COLUMN detected_content_type TEXT NOT NULL DEFAULT '';
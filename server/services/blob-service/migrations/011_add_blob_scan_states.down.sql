@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS blobs_scanning_idx;
+
+ALTER TABLE blobs DROP COLUMN IF EXISTS quarantine_reason;
+
+-- Unscanned and quarantined blobs fall back to PENDING, which the reaper
+-- deletes once they expire.
+UPDATE blobs SET state = 'PENDING' WHERE state IN ('SCANNING', 'QUARANTINED');
+ALTER TABLE blobs DROP CONSTRAINT IF EXISTS blobs_state_check;
+ALTER TABLE blobs ADD CONSTRAINT blobs_state_check CHECK (state IN ('PENDING', 'COMMITTED'));
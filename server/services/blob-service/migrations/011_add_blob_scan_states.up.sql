@@ -0,0 +1,7 @@
+ALTER TABLE blobs DROP CONSTRAINT IF EXISTS blobs_state_check;
+ALTER TABLE blobs ADD CONSTRAINT blobs_state_check
+    CHECK (state IN ('PENDING', 'SCANNING', 'COMMITTED', 'QUARANTINED'));
+
+ALTER TABLE blobs ADD COLUMN quarantine_reason TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX blobs_scanning_idx ON blobs (id) WHERE state = 'SCANNING';
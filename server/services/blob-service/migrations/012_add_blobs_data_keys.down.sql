@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS blobs_key_id_idx;
+
+ALTER TABLE blobs DROP COLUMN IF EXISTS wrapped_key;
+ALTER TABLE blobs DROP COLUMN IF EXISTS key_id;
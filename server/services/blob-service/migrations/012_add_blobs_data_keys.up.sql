@@ -0,0 +1,4 @@
+ALTER TABLE blobs ADD COLUMN key_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE blobs ADD COLUMN wrapped_key BYTEA;
+
+CREATE INDEX blobs_key_id_idx ON blobs (key_id, id) WHERE key_id <> '';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS blobs_unreplicated_idx;
+
+ALTER TABLE blobs DROP COLUMN IF EXISTS replicated_at;
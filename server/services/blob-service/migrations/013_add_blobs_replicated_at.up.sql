@@ -0,0 +1,3 @@
+ALTER TABLE blobs ADD COLUMN replicated_at TIMESTAMPTZ;
+
+CREATE INDEX blobs_unreplicated_idx ON blobs (id) WHERE state = 'COMMITTED' AND replicated_at IS NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS blob_audit_log;
+
+ALTER TABLE blobs DROP COLUMN IF EXISTS legal_hold;
+ALTER TABLE blobs DROP COLUMN IF EXISTS retain_until;
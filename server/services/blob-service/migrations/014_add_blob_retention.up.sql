@@ -0,0 +1,16 @@
+ALTER TABLE blobs ADD COLUMN retain_until TIMESTAMPTZ;
+ALTER TABLE blobs ADD COLUMN legal_hold BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE blob_audit_log (
+    seq          BIGSERIAL   PRIMARY KEY,
+    -- Not a foreign key: the log outlives the blobs it describes.
+    blob_id      CHAR(64)    NOT NULL,
+    action       TEXT        NOT NULL,
+    subject      TEXT        NOT NULL,
+    client_id    TEXT        NOT NULL DEFAULT '',
+    retain_until TIMESTAMPTZ,
+    reason       TEXT        NOT NULL DEFAULT '',
+    occurred_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX blob_audit_log_blob_id_idx ON blob_audit_log (blob_id, seq);
@@ -0,0 +1,16 @@
+-- Blobs stored under an upload's key cannot be addressed by a CHAR(64) key,
+-- so the column stays TEXT.
+CREATE INDEX blobs_pending_created_at_idx ON blobs (created_at) WHERE state = 'PENDING';
+
+DROP TABLE IF EXISTS multipart_uploads;
+
+CREATE TABLE multipart_uploads (
+    upload_id  TEXT        PRIMARY KEY,
+    blob_id    CHAR(64)    NOT NULL REFERENCES blobs (id) ON DELETE CASCADE,
+    part_count INTEGER     NOT NULL DEFAULT 1 CHECK (part_count > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX multipart_uploads_blob_id_key ON multipart_uploads (blob_id);
+
+DROP TABLE IF EXISTS blob_uploads;
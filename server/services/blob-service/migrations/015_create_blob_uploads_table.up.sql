@@ -0,0 +1,40 @@
+-- Uploads are tracked per caller and stored under a key of their own, and a
+-- blob row is only created once an upload of its content verifies.
+CREATE TABLE blob_uploads (
+    blob_id      CHAR(64)    NOT NULL,
+    subject      TEXT        NOT NULL,
+    client_id    TEXT        NOT NULL DEFAULT '',
+    object_key   TEXT        NOT NULL UNIQUE,
+    size_bytes   BIGINT      NOT NULL,
+    content_type TEXT        NOT NULL DEFAULT '',
+    key_id       TEXT        NOT NULL DEFAULT '',
+    wrapped_key  BYTEA,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (blob_id, subject)
+);
+
+CREATE INDEX blob_uploads_created_at_idx ON blob_uploads (created_at);
+
+-- Sessions of pending blobs go with them; their bucket uploads are left for
+-- the reaper to abort as untracked.
+DROP TABLE multipart_uploads;
+
+CREATE TABLE multipart_uploads (
+    upload_id  TEXT        PRIMARY KEY,
+    blob_id    CHAR(64)    NOT NULL,
+    subject    TEXT        NOT NULL,
+    part_count INTEGER     NOT NULL CHECK (part_count > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (blob_id, subject),
+    FOREIGN KEY (blob_id, subject) REFERENCES blob_uploads (blob_id, subject) ON DELETE CASCADE
+);
+
+-- Referenced PENDING blobs lost their content to a repair; they are kept for
+-- 016 to mark MISSING rather than dropped with their references.
+DELETE FROM blobs b
+WHERE state = 'PENDING' AND NOT legal_hold AND (retain_until IS NULL OR retain_until <= now())
+  AND NOT EXISTS (SELECT 1 FROM blob_refs WHERE blob_id = b.id);
+
+DROP INDEX IF EXISTS blobs_pending_created_at_idx;
+
+ALTER TABLE blobs ALTER COLUMN r2_key TYPE TEXT;
@@ -0,0 +1,4 @@
+UPDATE blobs SET state = 'PENDING' WHERE state = 'MISSING';
+ALTER TABLE blobs DROP CONSTRAINT IF EXISTS blobs_state_check;
+ALTER TABLE blobs ADD CONSTRAINT blobs_state_check
+    CHECK (state IN ('PENDING', 'SCANNING', 'COMMITTED', 'QUARANTINED'));
@@ -0,0 +1,10 @@
+-- Blobs whose content was lost are MISSING rather than PENDING, so they keep
+-- their references until a holder uploads them again.
+ALTER TABLE blobs DROP CONSTRAINT IF EXISTS blobs_state_check;
+ALTER TABLE blobs ADD CONSTRAINT blobs_state_check
+    CHECK (state IN ('PENDING', 'SCANNING', 'COMMITTED', 'QUARANTINED', 'MISSING'));
+
+-- Since uploads moved to blob_uploads, a referenced PENDING blob can only be
+-- one that an earlier repair moved back.
+UPDATE blobs b SET state = 'MISSING'
+WHERE state = 'PENDING' AND EXISTS (SELECT 1 FROM blob_refs WHERE blob_id = b.id);
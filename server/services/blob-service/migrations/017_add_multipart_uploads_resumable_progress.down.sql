@@ -0,0 +1,8 @@
+ALTER TABLE multipart_uploads
+    DROP COLUMN IF EXISTS resumable,
+    DROP COLUMN IF EXISTS received_bytes,
+    DROP COLUMN IF EXISTS staged_offset,
+    DROP COLUMN IF EXISTS parts,
+    DROP COLUMN IF EXISTS staged,
+    DROP COLUMN IF EXISTS next_part,
+    DROP COLUMN IF EXISTS next_stage;
@@ -0,0 +1,12 @@
+-- Resumable uploads are appended to through the service, by whichever
+-- replica a chunk reaches, so their progress is kept with the session and
+-- advanced only from the offset a chunk was accepted at. Sessions left over
+-- from before are treated as presigned and replaced when resumed.
+ALTER TABLE multipart_uploads
+    ADD COLUMN resumable      BOOLEAN   NOT NULL DEFAULT false,
+    ADD COLUMN received_bytes BIGINT    NOT NULL DEFAULT 0,
+    ADD COLUMN staged_offset  BIGINT    NOT NULL DEFAULT 0,
+    ADD COLUMN parts          INTEGER[] NOT NULL DEFAULT '{}',
+    ADD COLUMN staged         INTEGER[] NOT NULL DEFAULT '{}',
+    ADD COLUMN next_part      INTEGER   NOT NULL DEFAULT 1,
+    ADD COLUMN next_stage     INTEGER   NOT NULL DEFAULT 1;
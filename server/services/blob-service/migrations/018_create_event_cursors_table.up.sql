@@ -0,0 +1,7 @@
+-- Background consumers of blob_events record how far they got, so they
+-- resume there after a restart instead of replaying the log.
+CREATE TABLE event_cursors (
+    consumer   TEXT        PRIMARY KEY,
+    seq        BIGINT      NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
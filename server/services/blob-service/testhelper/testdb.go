@@ -78,7 +78,9 @@ func runMigrations(db *sqlx.DB) error {
 
 func CleanTables(t testing.TB, db *sqlx.DB) {
 	t.Helper()
-	if _, err := db.Exec("DELETE FROM blobs"); err != nil {
-		t.Fatalf("clean blobs table: %v", err)
+	for _, table := range []string{"multipart_uploads", "blob_refs", "blobs"} {
+		if _, err := db.Exec("DELETE FROM " + table); err != nil {
+			t.Fatalf("clean %s table: %v", table, err)
+		}
 	}
 }